@@ -0,0 +1,108 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// accountIDPattern matches a 12-digit AWS account ID.
+var accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+// roleSessionName identifies this tool's sessions in CloudTrail when it assumes --account-roles.
+const roleSessionName = "reconcile-tfstate"
+
+// loadAccountRoles reads the --account-roles file: a JSON object mapping account IDs to the role ARN
+// to assume for resources owned by that account, e.g. {"111122223333": "arn:aws:iam::111122223333:role/Reader"}.
+func loadAccountRoles(path string) (map[string]string, error) {
+	roles := make(map[string]string)
+	if path == "" {
+		return roles, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account roles file '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(data, &roles); err != nil {
+		return nil, fmt.Errorf("failed to parse account roles file '%s': %w", path, err)
+	}
+	for accountID, roleARN := range roles {
+		if !accountIDPattern.MatchString(accountID) {
+			return nil, fmt.Errorf("account roles file '%s': %q is not a 12-digit account ID", path, accountID)
+		}
+		if !strings.HasPrefix(roleARN, "arn:") || !strings.Contains(roleARN, ":role/") {
+			return nil, fmt.Errorf("account roles file '%s': %q for account %s is not a role ARN", path, roleARN, accountID)
+		}
+	}
+	return roles, nil
+}
+
+// newAccountClients prepares the per-account clients for appConfig.AccountRoles. It returns nil when
+// no roles are mapped, so every resource is verified with the base clients.
+func newAccountClients(base aws.Config, appConfig Config) *accountClients {
+	if len(appConfig.AccountRoles) == 0 {
+		return nil
+	}
+	return &accountClients{
+		base:      base,
+		appConfig: appConfig,
+		roles:     appConfig.AccountRoles,
+		clients:   make(map[string]*AWSClient),
+	}
+}
+
+// extractAccountFromARN returns the account ID field of an ARN, or "" if there is none (e.g. S3 buckets).
+func extractAccountFromARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) >= 5 {
+		return parts[4] // ARN format: arn:partition:service:region:account-id:resource
+	}
+	return ""
+}
+
+// resourceAccountID works out which account owns a resource, from its ARN or, for EC2 resources
+// that carry one, the owner_id attribute.
+func resourceAccountID(arnInState string, attributes map[string]interface{}) string {
+	if accountID := extractAccountFromARN(arnInState); accountIDPattern.MatchString(accountID) {
+		return accountID
+	}
+	if ownerID, ok := attributes["owner_id"].(string); ok && accountIDPattern.MatchString(ownerID) {
+		return ownerID
+	}
+	return ""
+}
+
+// forAccount returns the clients to verify a resource owned by accountID with. Accounts without a
+// mapped role use c itself. Clients for a mapped account are built once and shared.
+func (c *AWSClient) forAccount(accountID string) *AWSClient {
+	if c.Accounts == nil || accountID == "" {
+		return c
+	}
+	roleARN, ok := c.Accounts.roles[accountID]
+	if !ok {
+		return c
+	}
+
+	c.Accounts.mu.Lock()
+	defer c.Accounts.mu.Unlock()
+	if clients, ok := c.Accounts.clients[accountID]; ok {
+		return clients
+	}
+	cfg := c.Accounts.base.Copy()
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(c.Accounts.base, func(o *sts.Options) {
+		o.BaseEndpoint = cmp.Or(endpointFor(c.Accounts.appConfig, "sts"), o.BaseEndpoint)
+	}), roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = roleSessionName
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	clients := newServiceClients(cfg, c.Accounts.appConfig)
+	c.Accounts.clients[accountID] = clients
+	return clients
+}
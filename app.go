@@ -3,7 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
+
+	"github.com/open-policy-agent/opa/v1/ast"
 )
 
 // runApplication contains the main logic, allowing `main` to handle panics/errors with defer.
@@ -11,30 +14,37 @@ func runApplication(config Config) error {
 	ctx := context.Background()
 
 	// 1. Initialize core components and ensure backup directory
-	awsClients, err := NewAWSClient(ctx, config.AWSRegion)
+	awsClients, err := NewAWSClient(ctx, config)
 	if err != nil {
 		return fmt.Errorf("failed to initialize AWS clients: %w", err)
 	}
 	globalAWSClients = awsClients // Store globally for panic handler
 
-	if err := os.MkdirAll(config.BackupsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create backups directory '%s': %w", config.BackupsDir, err)
+	if !config.DryRun && !config.NoBackups {
+		if err := os.MkdirAll(config.BackupsDir, 0755); err != nil {
+			return fmt.Errorf("failed to create backups directory '%s': %w", config.BackupsDir, err)
+		}
+	}
+	if !config.DryRun && config.ReportDir != config.BackupsDir {
+		if err := os.MkdirAll(config.ReportDir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory '%s': %w", config.ReportDir, err)
+		}
+	}
+	if config.IsS3State && !config.NoBackups && awsClients.objectLocked() {
+		if err := checkObjectLockEnabled(ctx, awsClients, s3BackupBucket(config)); err != nil {
+			return err
+		}
 	}
 
 	// 2. Setup state file for processing and take initial backup
 	localStateFilePath, originalStateFileHash, err := setupStateFileForProcessing(
-		ctx, awsClients, config, globalOriginalBaseFileName, globalTimestamp)
+		ctx, awsClients, config, globalOriginalBaseFileName, globalRunDir)
 	if err != nil {
 		return fmt.Errorf("failed to setup state file: %w", err)
 	}
 	globalLocalStateFilePath = localStateFilePath       // Store globally for panic handler
 	globalOriginalStateFileHash = originalStateFileHash // Store globally for panic handler
 
-	// Ensure temp local S3 file is cleaned up AFTER main exits (only if S3 state)
-	if config.IsS3State {
-		defer func() { _ = os.Remove(localStateFilePath) }()
-	}
-
 	// Determine statePathForTerraformCLI from config AFTER localStateFilePath is set up
 	var statePathForTerraformCLI string
 	if config.IsS3State {
@@ -44,35 +54,212 @@ func runApplication(config Config) error {
 	}
 
 	// 3. Perform the core reconciliation logic
-	tfStateFile := openAndReadStateFile(localStateFilePath)
+	tfStateFile, err := openAndReadStateFile(localStateFilePath)
+	if err != nil {
+		return err
+	}
 	globalTfStateFile = tfStateFile // Store globally for panic handler
+	if config.ExpectedLineage != "" && tfStateFile.Lineage != config.ExpectedLineage {
+		return fmt.Errorf("the state has lineage %q, not the expected %q: it is the state of another configuration or environment", tfStateFile.Lineage, config.ExpectedLineage)
+	}
+	if tfStateFile.UpgradedFrom != 0 && config.ExecuteCommands {
+		return fmt.Errorf("the state is in format version %d, which can only be reconciled read-only. Upgrade it with Terraform first to run the suggested commands", tfStateFile.UpgradedFrom)
+	}
+
+	var policies []*ast.Module
+	if len(config.Policies) > 0 {
+		if policies, err = loadPolicies(config.Policies); err != nil {
+			return err
+		}
+	}
+
+	// Without --region, verify in the region the state's resources live in
+	if config.AWSRegion == "" {
+		region, reason, err := inferRegion(tfStateFile, awsClients.ConfiguredRegion)
+		if err != nil {
+			return err
+		}
+		log.Printf("Using region %s, selected from %s", region, reason)
+		config.AWSRegion = region
+		globalConfig.AWSRegion = region
+		stateClients := awsClients
+		if awsClients, err = NewAWSClient(ctx, config); err != nil {
+			return fmt.Errorf("failed to initialize AWS clients for region '%s': %w", region, err)
+		}
+		// The state bucket keeps the region it was reached in; only verification moves.
+		awsClients.StateS3Client = stateClients.StateS3Client
+		awsClients.S3Downloader = stateClients.S3Downloader
+		awsClients.StateSQSClient = stateClients.StateSQSClient
+		awsClients.StateKMSClient = stateClients.StateKMSClient
+		awsClients.StateDynamoDBClient = stateClients.StateDynamoDBClient
+		awsClients.BackupS3Client = stateClients.BackupS3Client
+		globalAWSClients = awsClients
+	}
 
 	// Only print header if not in JSON mode
 	if !config.JsonOutput {
 		printReportHeader(localStateFilePath, tfStateFile, config.AWSRegion, config.Concurrency, config.BackupsDir)
 	}
 
-	results := processResources(ctx, awsClients, tfStateFile, config.AWSRegion, config.Concurrency)
+	// Filters only narrow what is verified; backups and the incremental store still cover the whole state.
+	verifyState := tfStateFile
+	var planChanges map[string]plannedChange
+	if config.PlanPath != "" {
+		plan, err := readPlan(config.PlanPath)
+		if err != nil {
+			return err
+		}
+		if verifyState, planChanges, err = plannedState(plan, tfStateFile); err != nil {
+			return fmt.Errorf("failed to read plan '%s': %w", config.PlanPath, err)
+		}
+		if !config.JsonOutput {
+			fmt.Printf("Verifying the %d resource instances %s creates, updates or replaces instead of the state's resources.\n", len(planChanges), config.PlanPath)
+		}
+	}
+	filter, err := newResourceFilter(config)
+	if err != nil {
+		return err
+	}
+	if filter != nil {
+		for _, target := range unmatchedTargets(verifyState, config.Targets) {
+			log.Printf("WARNING: --target %s does not match any resource in the state.", target)
+		}
+		filtered := filterState(verifyState, filter)
+		if !config.JsonOutput {
+			fmt.Printf("Filters selected %d of %d resource instances for verification.\n", countInstances(filtered), countInstances(verifyState))
+		}
+		verifyState = filtered
+	}
+
+	if !config.SkipPreflight {
+		awsClients.DeniedActions = runPreflight(ctx, awsClients, verifyState)
+	}
+
+	checkpointPath := config.CheckpointPath
+	if checkpointPath == "" {
+		checkpointPath = defaultCheckpointPath(config.BackupsDir, globalOriginalBaseFileName)
+	}
+	var planned []plannedWrite
+	var checkpoint *checkpointStore
+	switch {
+	case config.DryRun:
+		if !config.NoBackups {
+			originalBackupPath := createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "original", globalRunDir, ".tfstate")
+			planned = append(planned, planLocalWrite(originalBackupPath, "original state backup"))
+			if writesHashFiles(config) {
+				planned = append(planned, planLocalWrite(originalBackupPath+".sha256", "original state hash"))
+			}
+		}
+		if (!config.NoBackups || config.CheckpointPath != "") && config.PlanPath == "" {
+			planned = append(planned, planLocalWrite(checkpointPath, "checkpoint"))
+		}
+	case config.PlanPath != "":
+		// Results of planned changes are not results of the state and must not be resumed as such
+	case config.NoBackups && config.CheckpointPath == "":
+		// The default checkpoint lives in --backups-dir
+	default:
+		if checkpoint, err = openCheckpoint(checkpointPath, tfStateFile, config.Resume); err != nil {
+			return fmt.Errorf("failed to set up checkpoint: %w", err)
+		}
+	}
+	var incremental *incrementalStore
+	if config.Incremental {
+		if incremental, err = openIncremental(defaultIncrementalPath(config.BackupsDir, globalOriginalBaseFileName), config.IncrementalTTL, incrementalScope(ctx, awsClients, config)); err != nil {
+			return fmt.Errorf("failed to set up incremental verification: %w", err)
+		}
+	}
+	var prof *profiler
+	if config.ProfileDir != "" {
+		if prof, err = startProfiler(config.ProfileDir); err != nil {
+			return fmt.Errorf("failed to start profiling: %w", err)
+		}
+	}
+	// The deadline only bounds verification; reports and backups below still use the parent context.
+	verifyCtx, cancelVerify := context.WithCancel(ctx)
+	if config.Deadline > 0 {
+		verifyCtx, cancelVerify = context.WithTimeout(ctx, config.Deadline)
+	}
+	results := processResources(verifyCtx, awsClients, verifyState, config.AWSRegion, config.Concurrency, config.APITimeout, checkpoint, incremental)
+	if planChanges != nil {
+		results = reconcilePlan(results, planChanges)
+	}
+	if config.FollowRemoteState {
+		results.LinkedStates = followRemoteStates(verifyCtx, awsClients, config, tfStateFile)
+	}
+	cancelVerify()
+	if err := prof.stop(results); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	if config.DryRun && incremental != nil {
+		planned = append(planned, planLocalWrite(incremental.path, "incremental verification results"))
+	} else if err := incremental.save(tfStateFile); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+	if incremental != nil && !config.JsonOutput {
+		fmt.Printf("Incremental mode: %d resources reused results verified within the last %s.\n", incremental.reused, config.IncrementalTTL)
+	}
+	if prof != nil && !config.JsonOutput {
+		fmt.Printf("Profiles and timings written to %s\n", config.ProfileDir)
+	}
+	interrupted := interruptedResults(results)
+	checkpoint.finish(len(interrupted) == 0)
+	if len(interrupted) > 0 && !config.JsonOutput {
+		fmt.Printf("Deadline of %s reached: %d resources were skipped. Re-run with --resume to verify them.\n", config.Deadline, len(interrupted))
+	}
+	results.PlannedWrites = planned
+	results.FailedChecks = failedChecks(tfStateFile)
+	if config.CompareBackup {
+		results.RecentChanges = loadRecentChanges(config.StateFilePath, tfStateFile)
+	}
+	if policies != nil {
+		if results.PolicyViolations, err = evaluatePolicies(ctx, policies, policyInput(config, tfStateFile, results)); err != nil {
+			return err
+		}
+		if len(deniedPolicies(results.PolicyViolations)) > 0 && config.ExecuteCommands {
+			log.Printf("WARNING: Policies denied these results; the remediation commands are not executed.")
+			config.ExecuteCommands = false
+		}
+	}
 	globalResults = results // Store globally for panic handler
 	sortResults(results)
 
 	stateFileModified := false // Initialize here, globalStateFileModified will be updated in handleExecution
-	handleExecution(ctx, awsClients, &config, results, localStateFilePath, statePathForTerraformCLI, &stateFileModified)
+	handleExecution(ctx, awsClients, &config, results, localStateFilePath, statePathForTerraformCLI, tfStateFile.Lineage, &stateFileModified)
 	globalStateFileModified = stateFileModified // Update global flag after handleExecution
 
 	// 4. Handle post-reconciliation backups and report generation
-	originalBackupLocalPath := createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "original", globalTimestamp, ".tfstate")
-	newLocalStatePathPlaceholder := createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "new", globalTimestamp, ".tfstate")
-	reportLocalPathMD := createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "report", globalTimestamp, ".txt")
-	reportLocalPathJSON := createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "report", globalTimestamp, ".json")
+	originalBackupLocalPath := createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "original", globalRunDir, ".tfstate")
+	newLocalStatePathPlaceholder := createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "new", globalRunDir, ".tfstate")
+	reportLocalPathMD := createBackupPath(config.ReportDir, globalOriginalBaseFileName, "report", globalRunDir, ".txt")
+	reportLocalPathJSON := createBackupPath(config.ReportDir, globalOriginalBaseFileName, "report", globalRunDir, ".json")
 
 	err = handlePostReconciliationBackupsAndUpload(
 		ctx, awsClients, config, results, localStateFilePath, tfStateFile,
-		globalOriginalBaseFileName, globalTimestamp, globalStateFileModified, globalOriginalStateFileHash,
+		globalOriginalBaseFileName, globalRunDir, globalStateFileModified, globalOriginalStateFileHash,
 		originalBackupLocalPath, newLocalStatePathPlaceholder, reportLocalPathMD, reportLocalPathJSON)
 	if err != nil {
 		return fmt.Errorf("failed to complete post-reconciliation steps: %w", err)
 	}
+	if config.SecurityHub && !config.DryRun {
+		imported, err := exportToSecurityHub(ctx, awsClients, config, results)
+		if err != nil {
+			log.Printf("WARNING: %v", err)
+		}
+		if !config.JsonOutput {
+			fmt.Printf("Imported %d findings into Security Hub.\n", imported)
+		}
+	}
+	if config.DatadogAPIKey != "" && !config.DryRun {
+		if err := sendToDatadog(ctx, config, results); err != nil {
+			log.Printf("WARNING: %v", err)
+		}
+	}
+	if config.ReportGitRepo != "" && !config.DryRun {
+		if err := pushReportsToGit(ctx, config, []string{reportLocalPathMD, reportLocalPathJSON}); err != nil {
+			log.Printf("WARNING: Failed to commit the reports to %s: %v", config.ReportGitRepo, err)
+		}
+	}
+	recordRunAudit(ctx, awsClients, config, results, tfStateFile, localStateFilePath, globalStateFileModified)
 
 	if config.JsonOutput {
 		jsonOutput, err := renderResultsToJson(
@@ -93,6 +280,9 @@ func runApplication(config Config) error {
 		fmt.Println(jsonOutput)
 	} else {
 		printDetailedResultsToStdout(results)
+		if config.DryRun {
+			printPlannedWrites(results.PlannedWrites)
+		}
 		fmt.Println("\n--- End of Report ---")
 		fmt.Println("NOTE: This tool covers only a few resource types. Extend 'processResourceInstance' for full coverage.")
 	}
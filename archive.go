@@ -0,0 +1,71 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeRunArchive bundles the files of a run that exist into the gzipped tarball archivePath, for --archive.
+// Files from the run directory in --backups-dir are stored by name; those from a separate --report-dir
+// run directory under reports/, so the two SHA256SUMS files do not collide.
+func writeRunArchive(archivePath string, paths []string) (err error) {
+	done := globalTemps.trackPartial(archivePath)
+	defer func() { done(err) }()
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	backupsRunDir := filepath.Dir(archivePath)
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if filepath.Dir(path) != backupsRunDir {
+			name = "reports/" + name
+		}
+		if err := addFileToArchive(tw, path, name); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return file.Close()
+}
+
+// addFileToArchive writes the file at path into tw as name, skipping files that do not exist.
+func addFileToArchive(tw *tar.Writer, path, name string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open '%s' for the archive: %w", path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add '%s' to the archive: %w", path, err)
+	}
+	if _, err := io.Copy(tw, file); err != nil {
+		return fmt.Errorf("failed to add '%s' to the archive: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// recordRunAudit puts an item describing the run into --audit-table: who ran it and when, the state
+// and its serial before and after, the result counts per category and the commands executed. The
+// table's partition key is "state" and its sort key "run_at", so the runs against a state can be
+// queried in order. Failures are logged; the audit log never fails a run.
+func recordRunAudit(
+	ctx context.Context,
+	awsClients *AWSClient,
+	config Config,
+	results *categorizedResults,
+	tfStateFile *TFStateFile,
+	localStateFilePath string,
+	stateFileModified bool,
+) {
+	if config.AuditTable == "" || results == nil {
+		return
+	}
+	if config.DryRun {
+		results.PlannedWrites = append(results.PlannedWrites, plannedWrite{
+			Target:      "dynamodb://" + config.AuditTable,
+			Action:      "write",
+			Description: "audit log item",
+		})
+		return
+	}
+	if awsClients == nil {
+		log.Printf("WARNING: Not recording the run in audit table %s: AWS clients were not initialized.", config.AuditTable)
+		return
+	}
+
+	state := config.S3State
+	if !config.IsS3State {
+		state = config.StateFilePath
+		if abs, err := filepath.Abs(config.StateFilePath); err == nil {
+			state = abs
+		}
+	}
+	item := map[string]dynamodbtypes.AttributeValue{
+		"state":          &dynamodbtypes.AttributeValueMemberS{Value: state},
+		"run_at":         &dynamodbtypes.AttributeValueMemberS{Value: globalRunStarted.UTC().Format(time.RFC3339Nano)},
+		"finished_at":    &dynamodbtypes.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		"run_dir":        &dynamodbtypes.AttributeValueMemberS{Value: globalRunDir},
+		"version":        &dynamodbtypes.AttributeValueMemberS{Value: Version()},
+		"state_modified": &dynamodbtypes.AttributeValueMemberBOOL{Value: stateFileModified},
+		"counts": &dynamodbtypes.AttributeValueMemberM{Value: map[string]dynamodbtypes.AttributeValue{
+			"ok":               auditNumber(len(results.OkResults)),
+			"info":             auditNumber(len(results.InfoResults)),
+			"warning":          auditNumber(len(results.WarningResults)),
+			"error":            auditNumber(len(results.ErrorResults)),
+			"potential_import": auditNumber(len(results.PotentialImportResults)),
+			"dangerous":        auditNumber(len(results.DangerousResults)),
+			"region_mismatch":  auditNumber(len(results.RegionMismatchResults)),
+			"stale":            auditNumber(len(results.StaleResults)),
+			"pending_deletion": auditNumber(len(results.PendingDeletionResults)),
+			"skipped":          auditNumber(len(results.SkippedResults)),
+		}},
+	}
+	if identity, err := awsClients.callerIdentity(ctx); err == nil {
+		item["caller_arn"] = &dynamodbtypes.AttributeValueMemberS{Value: aws.ToString(identity.Arn)}
+		item["account"] = &dynamodbtypes.AttributeValueMemberS{Value: aws.ToString(identity.Account)}
+	}
+	if current, err := user.Current(); err == nil {
+		item["user"] = &dynamodbtypes.AttributeValueMemberS{Value: current.Username}
+	}
+	if host, err := os.Hostname(); err == nil {
+		item["host"] = &dynamodbtypes.AttributeValueMemberS{Value: host}
+	}
+	if tfStateFile != nil {
+		item["lineage"] = &dynamodbtypes.AttributeValueMemberS{Value: cmp.Or(tfStateFile.Lineage, "unknown")}
+		item["serial_before"] = &dynamodbtypes.AttributeValueMemberN{Value: strconv.FormatUint(tfStateFile.Serial, 10)}
+	}
+	if after, err := openAndReadStateFile(localStateFilePath); err == nil {
+		item["serial_after"] = &dynamodbtypes.AttributeValueMemberN{Value: strconv.FormatUint(after.Serial, 10)}
+	}
+	if len(results.CommandExecutionLogs) > 0 {
+		commands := make([]dynamodbtypes.AttributeValue, 0, len(results.CommandExecutionLogs))
+		failed := 0
+		for _, execution := range results.CommandExecutionLogs {
+			commands = append(commands, &dynamodbtypes.AttributeValueMemberS{Value: execution.Command})
+			if execution.Error != "" || execution.ExitCode != 0 {
+				failed++
+			}
+		}
+		item["commands"] = &dynamodbtypes.AttributeValueMemberL{Value: commands}
+		item["commands_failed"] = auditNumber(failed)
+	}
+	if results.ApplicationError != "" {
+		item["application_error"] = &dynamodbtypes.AttributeValueMemberS{Value: results.ApplicationError}
+	}
+
+	_, err := awsClients.StateDynamoDBClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(config.AuditTable),
+		Item:      item,
+	})
+	if err != nil {
+		log.Printf("ERROR: Failed to record the run in audit table %s: %v", config.AuditTable, err)
+		return
+	}
+	if !config.JsonOutput {
+		fmt.Printf("Recorded the run in audit table %s.\n", config.AuditTable)
+	}
+}
+
+// auditNumber returns n as a DynamoDB number.
+func auditNumber(n int) dynamodbtypes.AttributeValue {
+	return &dynamodbtypes.AttributeValueMemberN{Value: strconv.Itoa(n)}
+}
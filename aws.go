@@ -1,52 +1,412 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"log"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/acm"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/appsync"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/codepipeline"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/dlm"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticsearchservice"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/mq"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	"github.com/aws/smithy-go/middleware"
 )
 
+// maxRetryBackoff caps the exponential backoff between retries of a single API call.
+const maxRetryBackoff = 30 * time.Second
+
+// endpointServices are the service names accepted by --endpoint-urls, one per client in AWSClient.
+var endpointServices = []string{
+	"acm", "apigateway", "apigatewayv2", "appsync", "autoscaling", "batch", "cloudfront", "cloudtrail",
+	"cloudwatch", "codebuild", "codedeploy", "codepipeline", "cognito-identity", "cognito-idp", "config", "dlm",
+	"docdb", "dynamodb", "ec2", "ecr", "ecs", "efs", "eks", "elasticache", "elbv2", "es", "events", "firehose",
+	"guardduty", "iam", "kafka", "kinesis", "kms", "lambda", "logs", "mq", "neptune", "opensearch", "rds",
+	"redshift", "route53", "s3", "secretsmanager", "securityhub", "ses", "sesv2", "sfn", "sns", "sqs", "ssm",
+	"sts", "wafv2",
+}
+
+// endpointFor returns the --endpoint-urls override for service, or nil to use --endpoint-url or the default.
+func endpointFor(appConfig Config, service string) *string {
+	if url, ok := appConfig.ServiceEndpoints[service]; ok {
+		return aws.String(url)
+	}
+	return nil
+}
+
 // NewAWSClient initializes and returns AWS service clients
-func NewAWSClient(ctx context.Context, region string) (*AWSClient, error) {
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+func NewAWSClient(ctx context.Context, appConfig Config) (*AWSClient, error) {
+	loadOptions := []func(*config.LoadOptions) error{
+		// Adaptive mode slows the client down when AWS starts throttling; backoff uses full jitter.
+		// The retry quota is disabled so throttle storms keep retrying instead of failing fast.
+		config.WithRetryer(func() aws.Retryer {
+			return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+				o.StandardOptions = append(o.StandardOptions, func(so *retry.StandardOptions) {
+					so.MaxAttempts = appConfig.MaxAttempts
+					so.Backoff = retry.NewExponentialJitterBackoff(maxRetryBackoff)
+					so.RateLimiter = ratelimit.None
+				})
+			})
+		}),
+	}
+	// Without --region the SDK's own resolution (AWS_REGION, the shared config) applies.
+	if appConfig.AWSRegion != "" {
+		loadOptions = append(loadOptions, config.WithRegion(appConfig.AWSRegion))
+	}
+	// Without these the SDK falls back to AWS_PROFILE, AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE.
+	if appConfig.AWSProfile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigProfile(appConfig.AWSProfile))
+	}
+	if appConfig.SharedConfigFile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigFiles([]string{appConfig.SharedConfigFile}))
+	}
+	if appConfig.SharedCredentialsFile != "" {
+		loadOptions = append(loadOptions, config.WithSharedCredentialsFiles([]string{appConfig.SharedCredentialsFile}))
+	}
+	if appConfig.EndpointURL != "" {
+		loadOptions = append(loadOptions, config.WithBaseEndpoint(appConfig.EndpointURL))
+	}
+	if appConfig.UseFIPSEndpoints {
+		loadOptions = append(loadOptions, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+	if appConfig.UseDualStack {
+		loadOptions = append(loadOptions, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+	if appConfig.RateLimit > 0 {
+		limiter := newServiceRateLimiter(appConfig.RateLimit, appConfig.RateBurst)
+		loadOptions = append(loadOptions, config.WithAPIOptions([]func(*middleware.Stack) error{limiter.addToStack}))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
 	if err != nil {
+		if appConfig.AWSProfile != "" {
+			return nil, fmt.Errorf("failed to load AWS SDK config for profile '%s': %w", appConfig.AWSProfile, err)
+		}
 		return nil, fmt.Errorf("failed to load AWS SDK config: %w", err)
 	}
 
+	configuredRegion := cfg.Region
+	if cfg.Region == "" {
+		cfg.Region = fallbackRegion
+	}
+	useWebIdentity(&cfg, appConfig)
+
+	clients := newServiceClients(cfg, appConfig)
+	clients.ConfiguredRegion = configuredRegion
+	if err := diagnoseCredentials(ctx, cfg, clients, appConfig); err != nil {
+		return nil, err
+	}
+	clients.Accounts = newAccountClients(cfg, appConfig)
+
+	stateCfg, err := stateBucketConfig(ctx, cfg, loadOptions, appConfig)
+	if err != nil {
+		return nil, err
+	}
+	clients.StateS3Client = newS3Client(stateCfg, appConfig)
+	clients.S3Downloader = manager.NewDownloader(clients.StateS3Client)
+	clients.SSE = appConfig.SSE
+	clients.BackupBucket = appConfig.BackupBucket
+	clients.BackupS3Client = clients.StateS3Client
+	if appConfig.BackupBucket != "" && appConfig.BackupBucket != appConfig.S3Bucket {
+		// The backup bucket may live in another region than the state bucket
+		backupCfg := stateCfg.Copy()
+		if region, err := manager.GetBucketRegion(ctx, clients.StateS3Client, appConfig.BackupBucket); err == nil {
+			backupCfg.Region = region
+		} else {
+			log.Printf("WARNING: Failed to look up the region of --backup-bucket %s, using %s: %v", appConfig.BackupBucket, backupCfg.Region, err)
+		}
+		clients.BackupS3Client = newS3Client(backupCfg, appConfig)
+	}
+	clients.SSEKMSKeyID = appConfig.SSEKMSKeyID
+	clients.ObjectLockMode = appConfig.ObjectLockMode
+	clients.ObjectLockRetention = appConfig.ObjectLockRetention
+	clients.ObjectLockLegalHold = appConfig.ObjectLockLegalHold
+	clients.StateSQSClient = sqs.NewFromConfig(stateCfg, func(o *sqs.Options) {
+		o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sqs"), o.BaseEndpoint)
+	})
+	clients.StateDynamoDBClient = dynamodb.NewFromConfig(stateCfg, func(o *dynamodb.Options) {
+		o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "dynamodb"), o.BaseEndpoint)
+	})
+	clients.StateKMSClient = kms.NewFromConfig(stateCfg, func(o *kms.Options) {
+		o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "kms"), o.BaseEndpoint)
+		if keyARN, err := arn.Parse(strings.TrimPrefix(appConfig.SignKey, kmsKeyPrefix)); err == nil {
+			o.Region = keyARN.Region // A key given by ARN is signed with in its own region
+		}
+	})
+	return clients, nil
+}
+
+// stateBucketConfig returns the configuration for reading and writing the state file, backups and
+// reports. It is cfg itself unless --state-profile, --state-role-arn or --state-region point the
+// state bucket at different credentials, e.g. a central tooling account.
+func stateBucketConfig(ctx context.Context, cfg aws.Config, loadOptions []func(*config.LoadOptions) error, appConfig Config) (aws.Config, error) {
+	if appConfig.StateProfile == "" && appConfig.StateRoleARN == "" && appConfig.StateRegion == "" {
+		return cfg, nil
+	}
+	stateCfg := cfg.Copy()
+	if appConfig.StateProfile != "" {
+		var err error
+		stateCfg, err = config.LoadDefaultConfig(ctx, append(slices.Clone(loadOptions), config.WithSharedConfigProfile(appConfig.StateProfile))...)
+		if err != nil {
+			return aws.Config{}, fmt.Errorf("failed to load AWS SDK config for state profile '%s': %w", appConfig.StateProfile, err)
+		}
+		if stateCfg.Region == "" {
+			stateCfg.Region = cfg.Region
+		}
+	}
+	if appConfig.StateRegion != "" {
+		stateCfg.Region = appConfig.StateRegion
+	}
+	if appConfig.StateRoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(stateCfg, func(o *sts.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sts"), o.BaseEndpoint)
+		}), appConfig.StateRoleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = roleSessionName
+		})
+		stateCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+	return stateCfg, nil
+}
+
+// newS3Client builds an S3 client honoring the endpoint overrides.
+func newS3Client(cfg aws.Config, appConfig Config) *s3.Client {
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "s3"), o.BaseEndpoint)
+		// Custom endpoints (LocalStack, VPC endpoints) generally cannot serve virtual-hosted bucket names.
+		o.UsePathStyle = o.BaseEndpoint != nil
+	})
+}
+
+// newServiceClients builds every service client from cfg. Each AWSClient gets its own batch results
+// and call cache since both are only valid for the account its credentials belong to.
+func newServiceClients(cfg aws.Config, appConfig Config) *AWSClient {
 	return &AWSClient{
-		S3Client:             s3.NewFromConfig(cfg),
-		CloudWatchLogsClient: cloudwatchlogs.NewFromConfig(cfg),
-		EC2Client:            ec2.NewFromConfig(cfg),
-		Route53Client:        route53.NewFromConfig(cfg),
-		ELBV2Client:          elasticloadbalancingv2.NewFromConfig(cfg),
-		S3Downloader:         manager.NewDownloader(s3.NewFromConfig(cfg)),
-		ACMClient:            acm.NewFromConfig(cfg),
-		SSMClient:            ssm.NewFromConfig(cfg),
-		SecretsManagerClient: secretsmanager.NewFromConfig(cfg),
-		ECSClient:            ecs.NewFromConfig(cfg),
-		AutoscalingClient:    autoscaling.NewFromConfig(cfg),
-		CloudWatchClient:     cloudwatch.NewFromConfig(cfg),
-		IAMClient:            iam.NewFromConfig(cfg),
-		LambdaClient:         lambda.NewFromConfig(cfg),
-		CloudFrontClient:     cloudfront.NewFromConfig(cfg),
-	}, nil
+		S3Client: newS3Client(cfg, appConfig),
+		CloudWatchLogsClient: cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "logs"), o.BaseEndpoint)
+		}),
+		EC2Client: ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "ec2"), o.BaseEndpoint)
+		}),
+		Route53Client: route53.NewFromConfig(cfg, func(o *route53.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "route53"), o.BaseEndpoint)
+		}),
+		ELBV2Client: elasticloadbalancingv2.NewFromConfig(cfg, func(o *elasticloadbalancingv2.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "elbv2"), o.BaseEndpoint)
+		}),
+		ACMClient: acm.NewFromConfig(cfg, func(o *acm.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "acm"), o.BaseEndpoint)
+		}),
+		SSMClient: ssm.NewFromConfig(cfg, func(o *ssm.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "ssm"), o.BaseEndpoint)
+		}),
+		SecretsManagerClient: secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "secretsmanager"), o.BaseEndpoint)
+		}),
+		ECSClient: ecs.NewFromConfig(cfg, func(o *ecs.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "ecs"), o.BaseEndpoint)
+		}),
+		AutoscalingClient: autoscaling.NewFromConfig(cfg, func(o *autoscaling.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "autoscaling"), o.BaseEndpoint)
+		}),
+		CloudWatchClient: cloudwatch.NewFromConfig(cfg, func(o *cloudwatch.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "cloudwatch"), o.BaseEndpoint)
+		}),
+		IAMClient: iam.NewFromConfig(cfg, func(o *iam.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "iam"), o.BaseEndpoint)
+		}),
+		LambdaClient: lambda.NewFromConfig(cfg, func(o *lambda.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "lambda"), o.BaseEndpoint)
+		}),
+		CloudFrontClient: cloudfront.NewFromConfig(cfg, func(o *cloudfront.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "cloudfront"), o.BaseEndpoint)
+		}),
+		RDSClient: rds.NewFromConfig(cfg, func(o *rds.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "rds"), o.BaseEndpoint)
+		}),
+		DynamoDBClient: dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "dynamodb"), o.BaseEndpoint)
+		}),
+		SQSClient: sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sqs"), o.BaseEndpoint)
+		}),
+		SNSClient: sns.NewFromConfig(cfg, func(o *sns.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sns"), o.BaseEndpoint)
+		}),
+		KMSClient: kms.NewFromConfig(cfg, func(o *kms.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "kms"), o.BaseEndpoint)
+		}),
+		EKSClient: eks.NewFromConfig(cfg, func(o *eks.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "eks"), o.BaseEndpoint)
+		}),
+		ElastiCacheClient: elasticache.NewFromConfig(cfg, func(o *elasticache.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "elasticache"), o.BaseEndpoint)
+		}),
+		ECRClient: ecr.NewFromConfig(cfg, func(o *ecr.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "ecr"), o.BaseEndpoint)
+		}),
+		APIGatewayClient: apigateway.NewFromConfig(cfg, func(o *apigateway.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "apigateway"), o.BaseEndpoint)
+		}),
+		APIGatewayV2Client: apigatewayv2.NewFromConfig(cfg, func(o *apigatewayv2.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "apigatewayv2"), o.BaseEndpoint)
+		}),
+		SFNClient: sfn.NewFromConfig(cfg, func(o *sfn.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sfn"), o.BaseEndpoint)
+		}),
+		EventBridgeClient: eventbridge.NewFromConfig(cfg, func(o *eventbridge.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "events"), o.BaseEndpoint)
+		}),
+		KinesisClient: kinesis.NewFromConfig(cfg, func(o *kinesis.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "kinesis"), o.BaseEndpoint)
+		}),
+		FirehoseClient: firehose.NewFromConfig(cfg, func(o *firehose.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "firehose"), o.BaseEndpoint)
+		}),
+		EFSClient: efs.NewFromConfig(cfg, func(o *efs.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "efs"), o.BaseEndpoint)
+		}),
+		DLMClient: dlm.NewFromConfig(cfg, func(o *dlm.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "dlm"), o.BaseEndpoint)
+		}),
+		WAFV2Client: wafv2.NewFromConfig(cfg, func(o *wafv2.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "wafv2"), o.BaseEndpoint)
+		}),
+		WAFV2GlobalClient: wafv2.NewFromConfig(cfg, func(o *wafv2.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "wafv2"), o.BaseEndpoint)
+			o.Region = "us-east-1"
+		}),
+		CloudTrailClient: cloudtrail.NewFromConfig(cfg, func(o *cloudtrail.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "cloudtrail"), o.BaseEndpoint)
+		}),
+		ConfigServiceClient: configservice.NewFromConfig(cfg, func(o *configservice.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "config"), o.BaseEndpoint)
+		}),
+		GuardDutyClient: guardduty.NewFromConfig(cfg, func(o *guardduty.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "guardduty"), o.BaseEndpoint)
+		}),
+		SESClient: ses.NewFromConfig(cfg, func(o *ses.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "ses"), o.BaseEndpoint)
+		}),
+		SESV2Client: sesv2.NewFromConfig(cfg, func(o *sesv2.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sesv2"), o.BaseEndpoint)
+		}),
+		CognitoIDPClient: cognitoidentityprovider.NewFromConfig(cfg, func(o *cognitoidentityprovider.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "cognito-idp"), o.BaseEndpoint)
+		}),
+		CognitoIDClient: cognitoidentity.NewFromConfig(cfg, func(o *cognitoidentity.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "cognito-identity"), o.BaseEndpoint)
+		}),
+		AppSyncClient: appsync.NewFromConfig(cfg, func(o *appsync.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "appsync"), o.BaseEndpoint)
+		}),
+		CodeBuildClient: codebuild.NewFromConfig(cfg, func(o *codebuild.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "codebuild"), o.BaseEndpoint)
+		}),
+		CodePipelineClient: codepipeline.NewFromConfig(cfg, func(o *codepipeline.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "codepipeline"), o.BaseEndpoint)
+		}),
+		CodeDeployClient: codedeploy.NewFromConfig(cfg, func(o *codedeploy.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "codedeploy"), o.BaseEndpoint)
+		}),
+		BatchClient: batch.NewFromConfig(cfg, func(o *batch.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "batch"), o.BaseEndpoint)
+		}),
+		MSKClient: kafka.NewFromConfig(cfg, func(o *kafka.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "kafka"), o.BaseEndpoint)
+		}),
+		MQClient: mq.NewFromConfig(cfg, func(o *mq.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "mq"), o.BaseEndpoint)
+		}),
+		NeptuneClient: neptune.NewFromConfig(cfg, func(o *neptune.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "neptune"), o.BaseEndpoint)
+		}),
+		DocDBClient: docdb.NewFromConfig(cfg, func(o *docdb.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "docdb"), o.BaseEndpoint)
+		}),
+		OpenSearchClient: opensearch.NewFromConfig(cfg, func(o *opensearch.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "opensearch"), o.BaseEndpoint)
+		}),
+		ElasticsearchClient: elasticsearchservice.NewFromConfig(cfg, func(o *elasticsearchservice.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "es"), o.BaseEndpoint)
+		}),
+		RedshiftClient: redshift.NewFromConfig(cfg, func(o *redshift.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "redshift"), o.BaseEndpoint)
+		}),
+		STSClient: sts.NewFromConfig(cfg, func(o *sts.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sts"), o.BaseEndpoint)
+		}),
+		SecurityHubClient: securityhub.NewFromConfig(cfg, func(o *securityhub.Options) {
+			o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "securityhub"), o.BaseEndpoint)
+		}),
+		Batch: newBatchLookup(),
+		Cache: newCallCache(),
+	}
+}
+
+// isGlobalWAFV2ARN reports whether arn is a CLOUDFRONT-scope WAFv2 resource. Its ARN names us-east-1, but
+// the resource is global and is verified through WAFV2GlobalClient whatever --region is.
+func isGlobalWAFV2ARN(arn string) bool {
+	parts := strings.SplitN(arn, ":", 6)
+	return len(parts) == 6 && parts[2] == "wafv2" && strings.HasPrefix(parts[5], "global/")
 }
 
 // extractRegionFromARN attempts to parse the region from an AWS ARN.
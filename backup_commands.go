@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupArtifactPrefixes are the file name prefixes createBackupPath writes into a run directory.
+var backupArtifactPrefixes = []string{"original.", "new.", "report.", "manifest.", "restore.", "archive."}
+
+// listBackupRuns returns every directory under backupsDir holding backups or reports, newest first.
+func listBackupRuns(backupsDir string) ([]backupRun, error) {
+	runs := make(map[string]*backupRun)
+	err := filepath.WalkDir(backupsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isBackupArtifact(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(path)
+		run, ok := runs[dir]
+		if !ok {
+			run = &backupRun{dir: dir}
+			runs[dir] = run
+		}
+		run.files = append(run.files, entry.Name())
+		run.size += info.Size()
+		if info.ModTime().After(run.modified) {
+			run.modified = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups directory '%s': %w", backupsDir, err)
+	}
+	sorted := make([]backupRun, 0, len(runs))
+	for _, run := range runs {
+		sort.Strings(run.files)
+		sorted = append(sorted, *run)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].modified.After(sorted[j].modified)
+	})
+	return sorted, nil
+}
+
+// isBackupArtifact reports whether name is a state backup, report or one of their hashes.
+func isBackupArtifact(name string) bool {
+	if name == checksumsFileName {
+		return true
+	}
+	for _, prefix := range backupArtifactPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// runBackupList prints the run directories in --backups-dir with their artifacts.
+func runBackupList(flags *flag.FlagSet, args []string) error {
+	backupsDir := flags.String("backups-dir", filepath.Join(".", "backups"), "Directory the backups and reports were written to.")
+	parseFlags(flags, args)
+
+	runs, err := listBackupRuns(*backupsDir)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No backups found in %s\n", *backupsDir)
+		return nil
+	}
+	for _, run := range runs {
+		fmt.Printf("%s  %s  %d files, %d bytes\n", run.modified.Format(time.RFC3339), run.dir, len(run.files), run.size)
+		for _, file := range run.files {
+			fmt.Printf("    %s\n", file)
+		}
+	}
+	return nil
+}
+
+// runBackupPrune removes run directories beyond --keep or older than --older-than.
+func runBackupPrune(flags *flag.FlagSet, args []string) error {
+	backupsDir := flags.String("backups-dir", filepath.Join(".", "backups"), "Directory the backups and reports were written to.")
+	keep := flags.Int("keep", 0, "Optional: Number of most recent run directories to keep.")
+	olderThan := flags.Duration("older-than", 0, "Optional: Remove run directories last written longer ago than this (e.g. 720h).")
+	dryRun := flags.Bool("dry-run", false, "If true, only print the run directories that would be removed.")
+	parseFlags(flags, args)
+
+	if *keep <= 0 && *olderThan <= 0 {
+		return errors.New("--keep or --older-than is required")
+	}
+	runs, err := listBackupRuns(*backupsDir)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-*olderThan)
+	removed := 0
+	for i, run := range runs {
+		if (*keep <= 0 || i < *keep) && (*olderThan <= 0 || run.modified.After(cutoff)) {
+			continue
+		}
+		if *dryRun {
+			fmt.Printf("Would remove %s\n", run.dir)
+			continue
+		}
+		for _, file := range run.files {
+			if err := os.Remove(filepath.Join(run.dir, file)); err != nil {
+				return fmt.Errorf("failed to remove backup: %w", err)
+			}
+		}
+		// Drop the run directory and the --backup-layout directories above it once they are empty.
+		for dir := run.dir; dir != filepath.Clean(*backupsDir) && strings.HasPrefix(dir, filepath.Clean(*backupsDir)); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+		log.Printf("Removed %s", run.dir)
+		removed++
+	}
+	if !*dryRun {
+		fmt.Printf("Removed %d of %d run directories from %s\n", removed, len(runs), *backupsDir)
+	}
+	return nil
+}
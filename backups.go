@@ -1,29 +1,138 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+// checksumsFileName is the sha256sum-compatible file --checksums writes into every run directory.
+const checksumsFileName = "SHA256SUMS"
+
+// The values of --checksums: .sha256 files next to each artifact, one SHA256SUMS file per run directory, or both.
+const (
+	checksumsFiles = "files"
+	checksumsSums  = "sums"
+	checksumsBoth  = "both"
+)
+
+// defaultTimestampFormat is the Go time layout of --timestamp-format: DD-HH-MM-SS.
+const defaultTimestampFormat = "02-15-04-05"
+
+// defaultBackupLayout is the --backup-layout template: YYYY/MM/<timestamp>.
+const defaultBackupLayout = "{{.Year}}/{{.Month}}/{{.Timestamp}}"
+
 // createBackupPath generates a timestamped path for backup files.
 // baseDir: the configured backups directory
 // originalFileName: the base name of the state file (e.g., "dev.tfstate" or "mykey")
 // prefix: "original", "new", "report"
-// timestamp: formatted timestamp string (e.g., "02-15-04")
+// runDir: the run's directory below baseDir, rendered from --backup-layout (e.g., "2024/05/21-10-30-00")
 // finalExtension: the desired final extension for the file, e.g., ".tfstate", ".json", ".txt", ".sha256"
-func createBackupPath(baseDir, originalFileName, prefix, timestamp, finalExtension string) string {
+func createBackupPath(baseDir, originalFileName, prefix, runDir, finalExtension string) string {
+	cleanBaseName := stateBaseName(originalFileName)
+
+	// Format: <baseDir>/<runDir>/<prefix>.<cleanBaseName><finalExtension>
+	// Create subdirectories if they don't exist
+	dir := filepath.Join(baseDir, filepath.FromSlash(runDir))
+	if globalConfig.DryRun || (globalConfig.NoBackups && baseDir == globalConfig.BackupsDir) {
+		return filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, cleanBaseName, finalExtension))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		// Log the warning, but don't stop execution. Fallback to baseDir if creation fails.
+		log.Printf("WARNING: Failed to create backup subdirectory '%s': %v. Storing in base directory.", dir, err)
+		dir = baseDir
+	}
+
+	// Combine components: prefix.cleanBaseName.finalExtension
+	return filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, cleanBaseName, finalExtension))
+}
+
+// defaultBackupPrefix is the key prefix of --backup-prefix.
+const defaultBackupPrefix = "state-backups"
+
+// s3BackupPrefix is the key prefix backups and reports of the run in runDir are uploaded under.
+func s3BackupPrefix(config Config, runDir string) string {
+	return path.Join(config.BackupPrefix, runDir) + "/"
+}
+
+// s3BackupBucket is the bucket backups and reports of S3 states are uploaded to.
+func s3BackupBucket(config Config) string {
+	return cmp.Or(config.BackupBucket, config.S3Bucket)
+}
+
+// s3ClientFor returns the client for bucket: the --backup-bucket client for the backup bucket, the
+// state bucket client for any other.
+func (c *AWSClient) s3ClientFor(bucket string) *s3.Client {
+	if c.BackupBucket != "" && bucket == c.BackupBucket && c.BackupS3Client != nil {
+		return c.BackupS3Client
+	}
+	return c.StateS3Client
+}
+
+// newBackupLayout returns the --backup-layout data of a run of the state originalFileName started at now.
+func newBackupLayout(config Config, now time.Time, timestamp, originalFileName string) backupLayout {
+	return backupLayout{
+		Year:      now.Format("2006"),
+		Month:     now.Format("01"),
+		Day:       now.Format("02"),
+		Timestamp: timestamp,
+		State:     stateBaseName(originalFileName),
+		Workspace: terraformWorkspace(config.TerraformWorkingDir),
+		Region:    cmp.Or(config.AWSRegion, "auto"),
+	}
+}
+
+// renderBackupLayout renders a --backup-layout template into a run directory relative to the backups
+// directory, using forward slashes so the same directory serves as S3 key prefix.
+func renderBackupLayout(layout string, data backupLayout) (string, error) {
+	tmpl, err := template.New("backup-layout").Option("missingkey=error").Parse(layout)
+	if err != nil {
+		return "", fmt.Errorf("invalid backup layout %q: %w", layout, err)
+	}
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("invalid backup layout %q: %w", layout, err)
+	}
+	dir := path.Clean(strings.ReplaceAll(builder.String(), "\\", "/"))
+	if dir == "." || path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", fmt.Errorf("backup layout %q must render to a directory inside the backups directory, got %q", layout, builder.String())
+	}
+	return dir, nil
+}
+
+// terraformWorkspace returns the selected Terraform workspace: TF_WORKSPACE, else the workspace
+// 'terraform workspace select' recorded in tfDir, else "default".
+func terraformWorkspace(tfDir string) string {
+	if workspace := os.Getenv("TF_WORKSPACE"); workspace != "" {
+		return workspace
+	}
+	if data, err := os.ReadFile(filepath.Join(tfDir, ".terraform", "environment")); err == nil {
+		if workspace := strings.TrimSpace(string(data)); workspace != "" {
+			return workspace
+		}
+	}
+	return "default"
+}
+
+// stateBaseName strips the .tfstate (and any other) extension from a state file name.
+func stateBaseName(originalFileName string) string {
 	// Ensure base name does not include existing extensions to avoid "file.tfstate.tfstate"
 	cleanBaseName := strings.TrimSuffix(originalFileName, ".tfstate")
 	cleanBaseName = strings.TrimSuffix(cleanBaseName, filepath.Ext(cleanBaseName)) // remove any other extension before .tfstate
@@ -31,36 +140,95 @@ func createBackupPath(baseDir, originalFileName, prefix, timestamp, finalExtensi
 	if cleanBaseName == "" { // Fallback if originalFileName was just an extension or empty
 		cleanBaseName = "state"
 	}
+	return cleanBaseName
+}
 
-	// Format: <baseDir>/YYYY/MM/<timestamp>/<prefix>.<cleanBaseName><finalExtension>
-	yearMonth := time.Now().Format("2006/01") // YYYY/MM
+// newPutObjectInput returns the input of an upload of body to s3://bucket/key, encrypted as --sse and
+// --sse-kms-key-id ask. The uploader applies it to multipart uploads of large files as well.
+func newPutObjectInput(awsClients *AWSClient, bucket, key string, body io.Reader) *s3.PutObjectInput {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	}
+	if awsClients.SSE != "" {
+		input.ServerSideEncryption = s3types.ServerSideEncryption(awsClients.SSE)
+	}
+	if awsClients.SSEKMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(awsClients.SSEKMSKeyID)
+	}
+	return input
+}
 
-	// Create subdirectories if they don't exist
-	dir := filepath.Join(baseDir, yearMonth, timestamp)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		// Log the warning, but don't stop execution. Fallback to baseDir if creation fails.
-		log.Printf("WARNING: Failed to create backup subdirectory '%s': %v. Storing in base directory.", dir, err)
-		dir = baseDir
+// withObjectLock sets the --object-lock-mode retention and --object-lock-legal-hold of an upload.
+func (c *AWSClient) withObjectLock(input *s3.PutObjectInput) {
+	if c.ObjectLockMode != "" {
+		input.ObjectLockMode = s3types.ObjectLockMode(c.ObjectLockMode)
+		input.ObjectLockRetainUntilDate = aws.Time(time.Now().Add(c.ObjectLockRetention).UTC())
+	}
+	if c.ObjectLockLegalHold {
+		input.ObjectLockLegalHoldStatus = s3types.ObjectLockLegalHoldStatusOn
 	}
+}
 
-	// Combine components: prefix.cleanBaseName.finalExtension
-	return filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, cleanBaseName, finalExtension))
+// objectLocked reports whether uploaded backups and reports are locked with --object-lock-mode or --object-lock-legal-hold.
+func (c *AWSClient) objectLocked() bool {
+	return c.ObjectLockMode != "" || c.ObjectLockLegalHold
+}
+
+// checkObjectLockEnabled fails unless bucket has Object Lock enabled, so a misconfigured bucket is
+// reported before the run instead of through failed uploads at its end.
+func checkObjectLockEnabled(ctx context.Context, awsClients *AWSClient, bucket string) error {
+	out, err := awsClients.s3ClientFor(bucket).GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return fmt.Errorf("failed to read the Object Lock configuration of bucket '%s': %w", bucket, err)
+	}
+	if out.ObjectLockConfiguration == nil || out.ObjectLockConfiguration.ObjectLockEnabled != s3types.ObjectLockEnabledEnabled {
+		return fmt.Errorf("bucket '%s' does not have Object Lock enabled; backups and reports could not be locked", bucket)
+	}
+	return nil
+}
+
+// confirmObjectLock reads back the retention and legal hold of an uploaded object, returning when its
+// retention ends, or an error if the object is not locked as configured.
+func confirmObjectLock(ctx context.Context, awsClients *AWSClient, bucket, key string) (retainUntil string, err error) {
+	client := awsClients.s3ClientFor(bucket)
+	if awsClients.ObjectLockMode != "" {
+		out, err := client.GetObjectRetention(ctx, &s3.GetObjectRetentionInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		if err != nil {
+			return "", fmt.Errorf("uploaded, but its retention could not be confirmed: %w", err)
+		}
+		if out.Retention == nil || out.Retention.RetainUntilDate == nil || string(out.Retention.Mode) != awsClients.ObjectLockMode {
+			return "", fmt.Errorf("uploaded without %s retention", awsClients.ObjectLockMode)
+		}
+		retainUntil = out.Retention.RetainUntilDate.UTC().Format(time.RFC3339)
+	}
+	if awsClients.ObjectLockLegalHold {
+		out, err := client.GetObjectLegalHold(ctx, &s3.GetObjectLegalHoldInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		if err != nil {
+			return retainUntil, fmt.Errorf("uploaded, but its legal hold could not be confirmed: %w", err)
+		}
+		if out.LegalHold == nil || out.LegalHold.Status != s3types.ObjectLockLegalHoldStatusOn {
+			return retainUntil, errors.New("uploaded without a legal hold")
+		}
+	}
+	return retainUntil, nil
 }
 
 // uploadFileToS3 uploads a local file to S3.
-func uploadFileToS3(ctx context.Context, awsClients *AWSClient, localPath, bucket, key string) error {
+func uploadFileToS3(ctx context.Context, awsClients *AWSClient, localPath, bucket, key string, options ...func(*s3.PutObjectInput)) error {
 	file, err := os.Open(localPath)
 	if err != nil {
 		return fmt.Errorf("failed to open local file '%s' for S3 upload: %w", localPath, err)
 	}
 	defer file.Close()
 
-	uploader := manager.NewUploader(awsClients.S3Client)
-	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   file,
-	})
+	input := newPutObjectInput(awsClients, bucket, key, file)
+	for _, option := range options {
+		option(input)
+	}
+	uploader := manager.NewUploader(awsClients.s3ClientFor(bucket))
+	_, err = uploader.Upload(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to upload '%s' to s3://%s/%s: %w", localPath, bucket, key, err)
 	}
@@ -68,33 +236,150 @@ func uploadFileToS3(ctx context.Context, awsClients *AWSClient, localPath, bucke
 }
 
 // uploadStringContentToS3 uploads a string content to S3 (e.g., for hash files)
-func uploadStringContentToS3(ctx context.Context, awsClients *AWSClient, content, bucket, key string) error {
-	reader := strings.NewReader(content)
-	uploader := manager.NewUploader(awsClients.S3Client)
-	_, err := uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   reader,
-	})
+func uploadStringContentToS3(ctx context.Context, awsClients *AWSClient, content, bucket, key string, options ...func(*s3.PutObjectInput)) error {
+	input := newPutObjectInput(awsClients, bucket, key, strings.NewReader(content))
+	for _, option := range options {
+		option(input)
+	}
+	uploader := manager.NewUploader(awsClients.s3ClientFor(bucket))
+	_, err := uploader.Upload(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to upload string content to s3://%s/%s: %w", bucket, key, err)
 	}
 	return nil
 }
 
+// readS3Object returns the content of a small S3 object, such as a .sha256 file.
+func readS3Object(ctx context.Context, awsClients *AWSClient, bucket, key string) ([]byte, error) {
+	out, err := awsClients.s3ClientFor(bucket).GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", bucket, key, err)
+	}
+	defer func() {
+		_ = out.Body.Close()
+	}()
+	return io.ReadAll(out.Body)
+}
+
+// writeHashFile writes hash to the .sha256 file next to path, unless --no-hash-files or --checksums sums is set.
+func writeHashFile(config Config, path, hash string) error {
+	if !writesHashFiles(config) {
+		return nil
+	}
+	return writeReportToFile(path+".sha256", hash)
+}
+
+// writesHashFiles reports whether .sha256 files are written next to local backups and reports.
+func writesHashFiles(config Config) bool {
+	return !config.NoHashFiles && config.Checksums != checksumsSums
+}
+
+// writesChecksumsFile reports whether SHA256SUMS files are written into local run directories.
+func writesChecksumsFile(config Config) bool {
+	return !config.NoHashFiles && config.Checksums != checksumsFiles
+}
+
+// writeChecksumsFile adds the SHA256 of each existing file in paths to the SHA256SUMS file of its
+// directory, keeping the entries already there, so that 'sha256sum -c SHA256SUMS' checks a whole run.
+func writeChecksumsFile(config Config, paths ...string) error {
+	if !writesChecksumsFile(config) {
+		return nil
+	}
+	byDir := make(map[string]map[string]string)
+	for _, filePath := range paths {
+		if _, err := os.Stat(filePath); err != nil {
+			continue
+		}
+		hash, err := calculateFileSHA256(filePath)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(filePath)
+		if byDir[dir] == nil {
+			byDir[dir] = make(map[string]string)
+			if existing, err := os.ReadFile(filepath.Join(dir, checksumsFileName)); err == nil {
+				byDir[dir] = parseChecksums(existing)
+			}
+		}
+		byDir[dir][filepath.Base(filePath)] = hash
+	}
+	for dir, hashes := range byDir {
+		sumsPath := filepath.Join(dir, checksumsFileName)
+		if err := writeReportToFile(sumsPath, renderChecksums(hashes)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", sumsPath, err)
+		}
+	}
+	return nil
+}
+
+// renderChecksums renders file name -> SHA256 in the format of sha256sum, sorted by name.
+func renderChecksums(hashes map[string]string) string {
+	names := make([]string, 0, len(hashes))
+	for name := range hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sums strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sums, "%s  %s\n", hashes[name], name)
+	}
+	return sums.String()
+}
+
+// parseChecksums reads a SHA256SUMS file into file name -> SHA256. Binary-mode entries ("hash *name")
+// written by other tools are accepted too.
+func parseChecksums(data []byte) map[string]string {
+	hashes := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		hash, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		hashes[strings.TrimPrefix(strings.TrimSpace(name), "*")] = hash
+	}
+	return hashes
+}
+
+// s3ChecksumArtifacts adapts the artifacts uploaded into the S3 run directory at prefix to --checksums:
+// SHA256SUMS lists the uploaded files unless it is "files", and "sums" drops the .sha256 objects it replaces.
+func s3ChecksumArtifacts(config Config, prefix string, artifacts []s3Artifact) []s3Artifact {
+	if config.Checksums == checksumsFiles {
+		return artifacts
+	}
+	hashes := make(map[string]string)
+	kept := artifacts[:0]
+	for _, artifact := range artifacts {
+		if artifact.localPath == "" && strings.HasSuffix(artifact.key, ".sha256") {
+			if config.Checksums == checksumsBoth {
+				kept = append(kept, artifact)
+			}
+			continue
+		}
+		kept = append(kept, artifact)
+		if hash, err := calculateFileSHA256(artifact.localPath); err == nil {
+			hashes[path.Base(artifact.key)] = hash
+		}
+	}
+	return append(kept, s3Artifact{name: "checksums", content: renderChecksums(hashes), key: prefix + checksumsFileName})
+}
+
 // writeReportToFile writes the given report content to a specified file.
-func writeReportToFile(filePath string, content string) error {
+func writeReportToFile(filePath string, content string) (err error) {
+	done := globalTemps.trackPartial(filePath)
+	defer func() { done(err) }()
 	return os.WriteFile(filePath, []byte(content), 0644)
 }
 
 // Helper to copy files.
-func copyFile(src, dst string) error {
+func copyFile(src, dst string) (err error) {
 	in, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("couldn't open source file: %w", err)
 	}
 	defer in.Close()
 
+	done := globalTemps.trackPartial(dst)
+	defer func() { done(err) }()
 	out, err := os.Create(dst)
 	if err != nil {
 		return fmt.Errorf("couldn't create dest file: %w", err)
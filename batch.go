@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// batchChunkSize is the number of IDs sent per bulk describe call (EC2 filters accept up to 200 values).
+const batchChunkSize = 100
+
+// batchFetcher performs one bulk lookup and returns requested ID -> live ID for every ID found.
+type batchFetcher func(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error)
+
+// batchFetchers maps the Terraform resource types that can be verified in bulk to their fetcher.
+// The state attribute holding the ID is "id" for all of them except aws_eip.
+var batchFetchers = map[string]batchFetcher{
+	"aws_instance":         fetchInstancesBatch,
+	"aws_subnet":           fetchSubnetsBatch,
+	"aws_vpc":              fetchVPCsBatch,
+	"aws_internet_gateway": fetchInternetGatewaysBatch,
+	"aws_nat_gateway":      fetchNatGatewaysBatch,
+	"aws_route_table":      fetchRouteTablesBatch,
+	"aws_security_group":   fetchSecurityGroupsBatch,
+	"aws_eip":              fetchEIPsBatch,
+}
+
+// newBatchLookup returns an empty batchLookup.
+func newBatchLookup() *batchLookup {
+	return &batchLookup{
+		found:   make(map[string]map[string]string),
+		checked: make(map[string]map[string]bool),
+	}
+}
+
+// lookup returns the bulk result for id of resourceType. ok is false when the ID was not part of
+// a successful bulk call, in which case the caller should fall back to a single lookup.
+func (b *batchLookup) lookup(resourceType, id string) (liveID string, exists bool, ok bool) {
+	if b == nil || id == "" {
+		return "", false, false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.checked[resourceType][id] {
+		return "", false, false
+	}
+	liveID, exists = b.found[resourceType][id]
+	return liveID, exists, true
+}
+
+// record stores the outcome of a bulk call for the given IDs.
+func (b *batchLookup) record(resourceType string, ids []string, found map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.checked[resourceType] == nil {
+		b.checked[resourceType] = make(map[string]bool)
+		b.found[resourceType] = make(map[string]string)
+	}
+	for _, id := range ids {
+		b.checked[resourceType][id] = true
+		if liveID, ok := found[id]; ok {
+			b.found[resourceType][id] = liveID
+		}
+	}
+}
+
+// prefetchBatches groups the IDs of every batchable resource in the state by type and resolves them
+// with bulk describe calls, so per-resource verifiers can answer from memory. Failed batches are logged
+// and left unrecorded; the affected resources then fall back to individual calls.
+func prefetchBatches(ctx context.Context, clients *AWSClient, tfState *TFStateFile) {
+	idsByType := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, resource := range tfState.Resources {
+		if resource.Mode == "data" || batchFetchers[resource.Type] == nil {
+			continue
+		}
+		idAttribute := "id"
+		if resource.Type == "aws_eip" {
+			idAttribute = "allocation_id"
+		}
+		for _, instance := range resource.Instances {
+			id := instanceStringAttribute(instance, idAttribute)
+			key := resource.Type + "/" + id
+			if id == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			idsByType[resource.Type] = append(idsByType[resource.Type], id)
+		}
+	}
+
+	if needsRouteTableIndex(tfState) {
+		if _, err := clients.routeTables(ctx); err != nil {
+			log.Printf("WARNING: Listing route tables failed, route and association checks will report errors: %v", err)
+		}
+	}
+
+	for resourceType, ids := range idsByType {
+		fetch := batchFetchers[resourceType]
+		for start := 0; start < len(ids); start += batchChunkSize {
+			end := min(start+batchChunkSize, len(ids))
+			chunk := ids[start:end]
+			found, err := fetch(ctx, clients, chunk)
+			if err != nil {
+				log.Printf("WARNING: Bulk lookup of %d %s resources failed, falling back to individual lookups: %v", len(chunk), resourceType, err)
+				continue
+			}
+			clients.Batch.record(resourceType, chunk, found)
+		}
+	}
+}
+
+// instanceStringAttribute reads a single string attribute from an instance's JSON or flatmap attributes.
+func instanceStringAttribute(instance InstanceObjectStateV4, key string) string {
+	if len(instance.AttributesRaw) > 0 {
+		var attributes map[string]interface{}
+		if err := json.Unmarshal(instance.AttributesRaw, &attributes); err != nil {
+			return ""
+		}
+		value, _ := attributes[key].(string)
+		return value
+	}
+	return instance.AttributesFlat[key]
+}
+
+// idFilter builds the EC2 filter used to select a batch of IDs without failing on missing ones.
+func idFilter(name string, ids []string) []ec2types.Filter {
+	return []ec2types.Filter{{Name: aws.String(name), Values: ids}}
+}
+
+// fetchInstancesBatch looks up a batch of EC2 instances by ID.
+func fetchInstancesBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	paginator := ec2.NewDescribeInstancesPaginator(c.EC2Client, &ec2.DescribeInstancesInput{Filters: idFilter("instance-id", ids)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe EC2 instances: %w", err)
+		}
+		for _, reservation := range resp.Reservations {
+			for _, instance := range reservation.Instances {
+				// Same rule as verifyInstance: terminated or shutting-down instances count as missing.
+				if instance.State != nil &&
+					(instance.State.Name == ec2types.InstanceStateNameTerminated || instance.State.Name == ec2types.InstanceStateNameShuttingDown) {
+					continue
+				}
+				found[aws.ToString(instance.InstanceId)] = aws.ToString(instance.InstanceId)
+			}
+		}
+	}
+	return found, nil
+}
+
+// fetchSubnetsBatch looks up a batch of subnets by ID.
+func fetchSubnetsBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	paginator := ec2.NewDescribeSubnetsPaginator(c.EC2Client, &ec2.DescribeSubnetsInput{Filters: idFilter("subnet-id", ids)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe subnets: %w", err)
+		}
+		for _, subnet := range resp.Subnets {
+			found[aws.ToString(subnet.SubnetId)] = aws.ToString(subnet.SubnetId)
+		}
+	}
+	return found, nil
+}
+
+// fetchVPCsBatch looks up a batch of VPCs by ID.
+func fetchVPCsBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	paginator := ec2.NewDescribeVpcsPaginator(c.EC2Client, &ec2.DescribeVpcsInput{Filters: idFilter("vpc-id", ids)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe VPCs: %w", err)
+		}
+		for _, vpc := range resp.Vpcs {
+			found[aws.ToString(vpc.VpcId)] = aws.ToString(vpc.VpcId)
+		}
+	}
+	return found, nil
+}
+
+// fetchInternetGatewaysBatch looks up a batch of internet gateways by ID.
+func fetchInternetGatewaysBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	paginator := ec2.NewDescribeInternetGatewaysPaginator(c.EC2Client, &ec2.DescribeInternetGatewaysInput{Filters: idFilter("internet-gateway-id", ids)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe internet gateways: %w", err)
+		}
+		for _, igw := range resp.InternetGateways {
+			found[aws.ToString(igw.InternetGatewayId)] = aws.ToString(igw.InternetGatewayId)
+		}
+	}
+	return found, nil
+}
+
+// fetchNatGatewaysBatch looks up a batch of NAT gateways by ID.
+func fetchNatGatewaysBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	paginator := ec2.NewDescribeNatGatewaysPaginator(c.EC2Client, &ec2.DescribeNatGatewaysInput{Filter: idFilter("nat-gateway-id", ids)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe NAT gateways: %w", err)
+		}
+		for _, nat := range resp.NatGateways {
+			found[aws.ToString(nat.NatGatewayId)] = aws.ToString(nat.NatGatewayId)
+		}
+	}
+	return found, nil
+}
+
+// fetchRouteTablesBatch looks up a batch of route tables by ID.
+func fetchRouteTablesBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	paginator := ec2.NewDescribeRouteTablesPaginator(c.EC2Client, &ec2.DescribeRouteTablesInput{Filters: idFilter("route-table-id", ids)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe route tables: %w", err)
+		}
+		for _, rt := range resp.RouteTables {
+			found[aws.ToString(rt.RouteTableId)] = aws.ToString(rt.RouteTableId)
+		}
+	}
+	return found, nil
+}
+
+// fetchSecurityGroupsBatch looks up a batch of security groups by ID.
+func fetchSecurityGroupsBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(c.EC2Client, &ec2.DescribeSecurityGroupsInput{Filters: idFilter("group-id", ids)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe security groups: %w", err)
+		}
+		for _, sg := range resp.SecurityGroups {
+			found[aws.ToString(sg.GroupId)] = aws.ToString(sg.GroupId)
+		}
+	}
+	return found, nil
+}
+
+// fetchEIPsBatch looks up a batch of elastic IP allocations by ID.
+func fetchEIPsBatch(ctx context.Context, c *AWSClient, ids []string) (map[string]string, error) {
+	found := make(map[string]string)
+	// DescribeAddresses is not paginated; it returns every matching address in one response.
+	resp, err := c.EC2Client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{Filters: idFilter("allocation-id", ids)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe elastic IPs: %w", err)
+	}
+	for _, addr := range resp.Addresses {
+		found[aws.ToString(addr.AllocationId)] = aws.ToString(addr.AllocationId)
+	}
+	return found, nil
+}
+
+// needsRouteTableIndex reports whether the state has any resource answered from the route table index.
+func needsRouteTableIndex(tfState *TFStateFile) bool {
+	for _, resource := range tfState.Resources {
+		if resource.Mode != "data" && (resource.Type == "aws_route" || resource.Type == "aws_route_table_association") {
+			return true
+		}
+	}
+	return false
+}
+
+// routeTables returns the route table index, listing every route table in the region on first use.
+// Every route and association check in the run shares the one listing.
+func (c *AWSClient) routeTables(ctx context.Context) (*routeTableIndex, error) {
+	return cachedCall(ctx, c, cacheKey("ec2", "DescribeRouteTables", "*"), func() (*routeTableIndex, error) {
+		index := &routeTableIndex{
+			tables:       make(map[string]ec2types.RouteTable),
+			associations: make(map[string]string),
+		}
+		paginator := ec2.NewDescribeRouteTablesPaginator(c.EC2Client, &ec2.DescribeRouteTablesInput{})
+		for paginator.HasMorePages() {
+			resp, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list route tables: %w", err)
+			}
+			for _, rt := range resp.RouteTables {
+				routeTableID := aws.ToString(rt.RouteTableId)
+				index.tables[routeTableID] = rt
+				for _, assoc := range rt.Associations {
+					if assoc.RouteTableAssociationId != nil {
+						index.associations[*assoc.RouteTableAssociationId] = routeTableID
+					}
+				}
+			}
+		}
+		return index, nil
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+// newCallCache returns an empty callCache.
+func newCallCache() *callCache {
+	return &callCache{entries: make(map[string]*callCacheEntry)}
+}
+
+// cacheKey joins the service, operation and parameters of an API call into a cache key.
+func cacheKey(service, operation string, params ...string) string {
+	return service + ":" + operation + ":" + strings.Join(params, "\x00")
+}
+
+// cachedCall runs fn at most once per key for the lifetime of the client. Concurrent callers with
+// the same key wait for the first call to finish and share its result. Only successes and not-found
+// errors are shared: any other error, such as throttling or a timeout, says nothing about the resource,
+// so it is returned to the caller whose call failed and every waiting caller runs its own fn instead.
+// fn must use the ctx of its own caller, so no caller's call is cut short by another caller's context.
+// Callers must treat the returned value as read-only since it is shared.
+func cachedCall[T any](ctx context.Context, c *AWSClient, key string, fn func() (T, error)) (T, error) {
+	if c.Cache == nil {
+		return fn()
+	}
+
+	for {
+		c.Cache.mu.Lock()
+		entry, found := c.Cache.entries[key]
+		if !found {
+			entry = &callCacheEntry{done: make(chan struct{})}
+			c.Cache.entries[key] = entry
+		}
+		c.Cache.mu.Unlock()
+
+		if !found {
+			value, err := fn()
+			entry.value, entry.err = value, err
+			if err != nil && !isNotFoundError(err) {
+				c.Cache.mu.Lock()
+				delete(c.Cache.entries, key)
+				c.Cache.mu.Unlock()
+				entry.failed = true
+			}
+			close(entry.done)
+			return value, err
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		if entry.failed {
+			continue // Make the call again, with this caller's fn
+		}
+		if entry.err != nil {
+			var zero T
+			return zero, entry.err
+		}
+		return entry.value.(T), nil
+	}
+}
+
+// isNotFoundError reports whether err is an AWS API error saying the resource does not exist, like
+// ResourceNotFoundException, InvalidVpcID.NotFound or NoSuchBucket.
+func isNotFoundError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	code := apiErr.ErrorCode()
+	return strings.Contains(code, "NotFound") || strings.HasPrefix(code, "NoSuch")
+}
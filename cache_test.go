@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestCachedCall(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"success is cached", nil, 1},
+		{"not found is cached", &smithy.GenericAPIError{Code: "ResourceNotFoundException"}, 1},
+		{"no such entity is cached", &smithy.GenericAPIError{Code: "NoSuchEntity"}, 1},
+		{"throttling is retried", &smithy.GenericAPIError{Code: "ThrottlingException"}, 2},
+		{"timeout is retried", context.DeadlineExceeded, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AWSClient{Cache: newCallCache()}
+			calls := 0
+			fn := func() (string, error) {
+				calls++
+				if tt.err != nil {
+					return "", tt.err
+				}
+				return "value", nil
+			}
+			for range 2 {
+				value, err := cachedCall(context.Background(), c, cacheKey("svc", "Op", "id"), fn)
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				if err == nil && value != "value" {
+					t.Fatalf("value = %q, want %q", value, "value")
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCachedCallWaiterRetriesFailedCall(t *testing.T) {
+	c := &AWSClient{Cache: newCallCache()}
+	key := cacheKey("svc", "Op", "id")
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_, _ = cachedCall(context.Background(), c, key, func() (string, error) {
+			close(started)
+			<-release
+			return "", &smithy.GenericAPIError{Code: "ThrottlingException"}
+		})
+	}()
+	<-started
+
+	result := make(chan error, 1)
+	go func() {
+		value, err := cachedCall(context.Background(), c, key, func() (string, error) { return "mine", nil })
+		if err == nil && value != "mine" {
+			err = errors.New("got " + value)
+		}
+		result <- err
+	}()
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("waiting caller did not run its own call after the first one was throttled: %v", err)
+	}
+}
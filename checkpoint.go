@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// defaultCheckpointPath returns the checkpoint file used for a state when --checkpoint is not set.
+func defaultCheckpointPath(backupsDir, originalFileName string) string {
+	return filepath.Join(backupsDir, fmt.Sprintf("checkpoint.%s.jsonl", stateBaseName(originalFileName)))
+}
+
+// openCheckpoint prepares the checkpoint file for a run. When resume is true and the existing checkpoint
+// belongs to the same lineage and serial, its results are loaded so those addresses are not verified again;
+// otherwise the file is truncated and started fresh.
+func openCheckpoint(path string, tfState *TFStateFile, resume bool) (*checkpointStore, error) {
+	store := &checkpointStore{path: path, done: make(map[string]ResourceStatus)}
+
+	if resume {
+		loaded, err := loadCheckpoint(path, tfState)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Printf("WARNING: No checkpoint found at '%s'. Starting a full run.", path)
+		case err != nil:
+			log.Printf("WARNING: Ignoring checkpoint '%s': %v", path, err)
+		default:
+			store.done = loaded
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if len(store.done) > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint file '%s': %w", path, err)
+	}
+	store.file = file
+	store.encoder = json.NewEncoder(file)
+	if len(store.done) == 0 {
+		if err := store.encoder.Encode(checkpointRecord{Lineage: tfState.Lineage, Serial: tfState.Serial}); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to write checkpoint header to '%s': %w", path, err)
+		}
+	}
+	return store, nil
+}
+
+// loadCheckpoint reads previously completed results from a checkpoint file, rejecting checkpoints
+// written for a different state lineage or serial.
+func loadCheckpoint(path string, tfState *TFStateFile) (map[string]ResourceStatus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	done := make(map[string]ResourceStatus)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	header := true
+	for scanner.Scan() {
+		var record checkpointRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// A run killed mid-write leaves a truncated last line; everything before it is still valid.
+			log.Printf("WARNING: Skipping unreadable checkpoint line in '%s': %v", path, err)
+			continue
+		}
+		if header {
+			header = false
+			if record.Lineage != tfState.Lineage || record.Serial != tfState.Serial {
+				return nil, fmt.Errorf("checkpoint was written for lineage %q serial %d, but the state is lineage %q serial %d", record.Lineage, record.Serial, tfState.Lineage, tfState.Serial)
+			}
+			continue
+		}
+		if record.Address != "" {
+			done[record.Address] = record.toStatus()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return done, nil
+}
+
+// completed returns the checkpointed result for address, if any.
+func (c *checkpointStore) completed(address string) (ResourceStatus, bool) {
+	if c == nil {
+		return ResourceStatus{}, false
+	}
+	status, ok := c.done[address]
+	return status, ok
+}
+
+// record appends a finished result to the checkpoint. Failures are logged, not fatal.
+func (c *checkpointStore) record(status ResourceStatus) {
+	if c == nil || c.encoder == nil {
+		return
+	}
+	if _, ok := c.done[status.TerraformAddress]; ok {
+		return // Already in the file from a previous run
+	}
+	if err := c.encoder.Encode(newCheckpointRecord(status)); err != nil {
+		log.Printf("WARNING: Failed to write checkpoint for %s: %v", status.TerraformAddress, err)
+	}
+}
+
+// finish closes the checkpoint and, once a run completed every resource, removes it so the next run starts fresh.
+func (c *checkpointStore) finish(complete bool) {
+	if c == nil || c.file == nil {
+		return
+	}
+	_ = c.file.Close()
+	if complete {
+		if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("WARNING: Failed to remove checkpoint file '%s': %v", c.path, err)
+		}
+	}
+}
+
+// newCheckpointRecord converts a ResourceStatus into its checkpoint form.
+func newCheckpointRecord(status ResourceStatus) checkpointRecord {
+	record := checkpointRecord{
+		Address:  status.TerraformAddress,
+		Category: status.Category,
+		Message:  status.Message,
+		Command:  status.Command,
+		Kind:     status.Kind,
+		StateID:  status.StateID,
+		LiveID:   status.LiveID,
+		TFID:     status.TFID,
+		AWSID:    status.AWSID,
+		Exists:   status.ExistsInAWS,
+	}
+	if status.Error != nil {
+		record.Error = status.Error.Error()
+	}
+	return record
+}
+
+// toStatus converts a checkpoint record back into a ResourceStatus.
+func (r checkpointRecord) toStatus() ResourceStatus {
+	status := ResourceStatus{
+		TerraformAddress: r.Address,
+		Category:         r.Category,
+		Message:          r.Message,
+		Command:          r.Command,
+		Kind:             r.Kind,
+		StateID:          r.StateID,
+		LiveID:           r.LiveID,
+		TFID:             r.TFID,
+		AWSID:            r.AWSID,
+		ExistsInAWS:      r.Exists,
+	}
+	if r.Error != "" {
+		status.Error = errors.New(r.Error)
+	}
+	return status
+}
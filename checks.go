@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// failedChecks returns the objects whose checks failed ("fail") or could not be evaluated ("error") when
+// the state was last applied: check blocks, resource preconditions and postconditions, output and variable
+// validations. A check that failed without recording its objects is returned with its configuration address.
+func failedChecks(tfStateFile *TFStateFile) []failedCheck {
+	var failed []failedCheck
+	for _, check := range sortedCheckResults(tfStateFile.CheckResults) {
+		found := false
+		for _, object := range check.Objects {
+			if object.Status != "fail" && object.Status != "error" {
+				continue
+			}
+			found = true
+			failed = append(failed, failedCheck{
+				FailureMessages: object.FailureMessages,
+				ObjectAddr:      object.ObjectAddr,
+				ConfigAddr:      check.ConfigAddr,
+				ObjectKind:      check.ObjectKind,
+				Status:          object.Status,
+			})
+		}
+		if !found && (check.Status == "fail" || check.Status == "error") {
+			failed = append(failed, failedCheck{
+				ObjectAddr: check.ConfigAddr,
+				ConfigAddr: check.ConfigAddr,
+				ObjectKind: check.ObjectKind,
+				Status:     check.Status,
+			})
+		}
+	}
+	return failed
+}
+
+// renderFailedChecks renders the failed checks of the state with their failure messages.
+func renderFailedChecks(checks []failedCheck) string {
+	if len(checks) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n--- FAILED CHECKS (%d) ---\n", len(checks)))
+	for _, check := range checks {
+		builder.WriteString(fmt.Sprintf("%s: %s (%s)\n", strings.ToUpper(check.Status), check.ObjectAddr, check.ObjectKind))
+		for _, message := range check.FailureMessages {
+			builder.WriteString(fmt.Sprintf("   %s\n", message))
+		}
+	}
+	return builder.String()
+}
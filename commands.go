@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// programName is the name the CLI is invoked as in usage messages.
+const programName = "reconcile-tfstate"
+
+// commands are the subcommands of the CLI, in the order they are listed by help. They are set in
+// init because their help text lists commands itself.
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "check", usage: "check [flags]", summary: "Verify every resource in the state against AWS and write backups and reports (default)", run: runCheck},
+		{name: "fix", usage: "fix [flags]", summary: "Like check, then run the suggested 'terraform import' and 'terraform state rm' commands", run: runFix},
+		{name: "report", usage: "report [flags]", summary: "Print the JSON report of a previous run, by default the newest one in --backups-dir", run: runReport},
+		{name: "backup", usage: "backup list|restore|prune|verify [flags]", summary: "List, restore, prune or verify the state backups and reports in --backups-dir", run: runBackup},
+		{name: "diff", usage: "diff [flags] <old-state> <new-state>", summary: "Show the resource instances added, removed or changed between two state files", run: runDiff},
+		{name: "version", usage: "version [flags]", summary: "Print the version, commit, build date, Go version and supported state versions", run: runVersion},
+		{name: "serve", usage: "serve [flags]", summary: "Serve a REST API to trigger reconciliations, follow their progress and fetch their results", run: runServe},
+	}
+}
+
+// backupCommands are the subcommands of backup.
+var backupCommands = []command{
+	{name: "backup list", usage: "backup list [flags]", summary: "List the run directories in --backups-dir, newest first", run: runBackupList},
+	{name: "backup restore", usage: "backup restore --from <backup> [flags]", summary: "Verify a state backup against its hash and restore it to the local or S3 state it was taken of", run: runBackupRestore},
+	{name: "backup prune", usage: "backup prune [flags]", summary: "Remove old run directories from --backups-dir", run: runBackupPrune},
+	{name: "backup verify", usage: "backup verify --manifest <manifest> [flags]", summary: "Check the artifacts of a run against its manifest and the manifest against its signature", run: runBackupVerify},
+}
+
+// rootCommand is used when no subcommand is given. It accepts the check flags, including
+// --should-execute and -v, so invocations written for earlier versions keep working.
+var rootCommand = command{name: programName, usage: "[command] [flags]", run: runCheck}
+
+// selectCommand returns the subcommand named by the first argument and the arguments left for it.
+func selectCommand(args []string) (command, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return rootCommand, args
+	}
+	if args[0] == "help" {
+		printCommands(os.Stdout)
+		os.Exit(0)
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd, args[1:]
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n", args[0])
+	printCommands(os.Stderr)
+	os.Exit(2)
+	return command{}, nil
+}
+
+// newCommandFlagSet returns an empty flag set whose help text documents cmd.
+func newCommandFlagSet(cmd command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: %s %s\n\n", programName, cmd.usage)
+		if cmd.name == programName {
+			printCommands(out)
+			fmt.Fprintln(out, "\nWithout a command the check flags below apply.")
+		} else {
+			fmt.Fprintf(out, "%s.\n", cmd.summary)
+		}
+		fmt.Fprintln(out, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// printCommands writes the list of subcommands.
+func printCommands(out io.Writer) {
+	fmt.Fprintln(out, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nRun '%s <command> -h' for the flags of a command.\n", programName)
+}
+
+// runCheck reconciles the state without executing remediation commands unless --should-execute is given.
+func runCheck(fs *flag.FlagSet, args []string) error {
+	reconcile(parseAndValidateConfig(fs, args))
+	return nil
+}
+
+// runFix reconciles the state and executes the suggested remediation commands.
+func runFix(fs *flag.FlagSet, args []string) error {
+	config := parseAndValidateConfig(fs, args)
+	if config.NoBackups {
+		return errors.New("fix cannot be used with --no-backups: the state must be backed up before it is modified")
+	}
+	config.ExecuteCommands = true
+	reconcile(config)
+	return nil
+}
+
+// runBackup dispatches to the backup subcommand named by the first argument.
+func runBackup(fs *flag.FlagSet, args []string) error {
+	if len(args) > 0 {
+		for _, cmd := range backupCommands {
+			if cmd.name == "backup "+args[0] {
+				return cmd.run(newCommandFlagSet(cmd), args[1:])
+			}
+		}
+	}
+	out := fs.Output()
+	fmt.Fprintf(out, "Usage: %s backup list|restore|prune|verify [flags]\n\n", programName)
+	for _, cmd := range backupCommands {
+		fmt.Fprintf(out, "  %-8s %s\n", strings.TrimPrefix(cmd.name, "backup "), cmd.summary)
+	}
+	os.Exit(2)
+	return nil
+}
@@ -1,26 +1,100 @@
 package main
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
-// parseAndValidateConfig parses command-line flags and validates the input.
-func parseAndValidateConfig() Config {
-	stateFilePath := flag.String("state", fmt.Sprintf("terraform.%s", tfState), "Path to the Terraform state file (can be S3 URI like s3://bucket/key)")
-	awsRegion := flag.String("region", "us-west-2", "AWS Region to check resources against")
-	concurrency := flag.Int("concurrency", 10, "Number of concurrent AWS API calls")
-	s3State := flag.String("s3-state", "", "Optional: S3 URI of the state file (e.g., s3://bucket/key). If provided, state will be downloaded/uploaded.")
-	showVersion := flag.Bool("v", false, "Show version")
-	shouldExecute := flag.Bool("should-execute", false, "If true, automatically execute the suggested 'terraform import' and 'terraform state rm' commands.") // New flag
-	backupsDir := flag.String("backups-dir", filepath.Join(".", "backups"), "Directory to store local backups and reports.")
-	jsonOutput := flag.Bool("json", false, "If true, render results in JSON format to stdout.") // NEW: JSON flag
-	terraformWorkingDir := flag.String("tf-dir", ".", "Optional: The directory where 'terraform' commands should be executed. Defaults to the current directory.")
+// parseAndValidateConfig registers the reconciliation flags on fs, parses args and validates the input.
+func parseAndValidateConfig(fs *flag.FlagSet, args []string) Config {
+	stateFilePath := fs.String("state", fmt.Sprintf("terraform.%s", tfState), "Path to the Terraform state file (can be S3 URI like s3://bucket/key)")
+	awsRegion := fs.String("region", "", "AWS Region to check resources against. Defaults to the region most ARNs in the state belong to.")
+	awsProfile := fs.String("profile", "", "Optional: Named profile from the AWS shared config to use. Defaults to AWS_PROFILE, then the default profile.")
+	sharedConfigFile := fs.String("aws-config-file", "", "Optional: Path of the AWS shared config file. Defaults to AWS_CONFIG_FILE, then ~/.aws/config.")
+	sharedCredentialsFile := fs.String("aws-credentials-file", "", "Optional: Path of the AWS shared credentials file. Defaults to AWS_SHARED_CREDENTIALS_FILE, then ~/.aws/credentials.")
+	endpointURL := fs.String("endpoint-url", "", "Optional: Send every AWS API call to this endpoint instead of the public one (e.g. http://localhost:4566 for LocalStack).")
+	serviceEndpoints := fs.String("endpoint-urls", "", "Optional: Per-service endpoint overrides as comma-separated service=url pairs (e.g. s3=https://bucket.vpce-123.s3.us-west-2.vpce.amazonaws.com,ec2=https://vpce-456.ec2.us-west-2.vpce.amazonaws.com). Services: "+strings.Join(endpointServices, ", ")+".")
+	accountRolesFile := fs.String("account-roles", "", "Optional: JSON file mapping AWS account IDs to role ARNs, e.g. {\"111122223333\": \"arn:aws:iam::111122223333:role/Reader\"}. Resources whose ARN or owner_id names a mapped account are verified by assuming that role.")
+	roleARN := fs.String("role-arn", "", "Optional: Role to assume with --web-identity-token-file (AssumeRoleWithWebIdentity), e.g. the IRSA role of an EKS service account. Without these flags AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE are still honored.")
+	webIdentityTokenFile := fs.String("web-identity-token-file", "", "Optional: Path of the OIDC token to exchange for --role-arn credentials (on EKS: /var/run/secrets/eks.amazonaws.com/serviceaccount/token).")
+	useFIPSEndpoints := fs.Bool("use-fips-endpoints", false, "If true, use FIPS 140-2 validated AWS endpoints (required in GovCloud/FedRAMP environments).")
+	useDualStack := fs.Bool("use-dualstack", false, "If true, use dual-stack (IPv4 and IPv6) AWS endpoints, for IPv6-only networks.")
+	stateProfile := fs.String("state-profile", "", "Optional: Named profile for the S3 state bucket, backups and reports, when they live in a different account than the resources.")
+	stateRoleARN := fs.String("state-role-arn", "", "Optional: Role to assume for the S3 state bucket, backups and reports.")
+	sse := fs.String("sse", "", "Optional: Server-side encryption of every uploaded state, backup, report and hash: AES256, aws:kms or aws:kms:dsse. Defaults to the bucket's default encryption.")
+	sseKMSKeyID := fs.String("sse-kms-key-id", "", "Optional: KMS key ID, ARN or alias to encrypt uploads with when --sse is aws:kms or aws:kms:dsse. Defaults to the AWS managed key aws/s3.")
+	backupBucket := fs.String("backup-bucket", "", "Optional: S3 bucket to upload the backups and reports of S3 states to instead of the state bucket. It may be in another region or account; the state bucket's credentials are used.")
+	signKey := fs.String("sign-key", "", "Optional: Sign the manifest of every run with a KMS asymmetric key (kms:<key ID, ARN or alias>) or a PKCS #8 PEM private key file (Ed25519, ECDSA or RSA).")
+	objectLockMode := fs.String("object-lock-mode", "", "Optional: Object Lock retention mode of uploaded backups and reports: GOVERNANCE or COMPLIANCE. Requires --object-lock-retention and a backup bucket with Object Lock enabled.")
+	objectLockRetention := fs.Duration("object-lock-retention", 0, "Optional: How long uploaded backups and reports are retained under --object-lock-mode (e.g. 2160h).")
+	objectLockLegalHold := fs.Bool("object-lock-legal-hold", false, "Optional: Place a legal hold on uploaded backups and reports. Requires a backup bucket with Object Lock enabled.")
+	auditTable := fs.String("audit-table", "", "Optional: DynamoDB table to record every run in, with partition key 'state' and sort key 'run_at' (both strings). Written with the state bucket's credentials.")
+	backupPrefix := fs.String("backup-prefix", defaultBackupPrefix, "Key prefix of the uploaded backups and reports, followed by the --backup-layout directory of the run.")
+	stateRegion := fs.String("state-region", "", "Optional: Region of the S3 state bucket, when it differs from --region.")
+	concurrency := fs.Int("concurrency", 10, "Number of concurrent AWS API calls")
+	s3State := fs.String("s3-state", "", "Optional: S3 URI of the state file (e.g., s3://bucket/key). If provided, state will be downloaded/uploaded.")
+	showVersion := fs.Bool("v", false, "Show version")
+	dryRun := fs.Bool("dry-run", false, "If true, write nothing: no backups, reports, checkpoints or S3 uploads, and no remediation commands. Prints every file and S3 object that would have been created or overwritten.")
+	noBackups := fs.Bool("no-backups", false, "If true, write nothing to --backups-dir: no state backups, report files or default checkpoint. Results are still printed, and reports are still written to --report-dir if set. Cannot be used with --should-execute or --incremental.")
+	checksums := fs.String("checksums", checksumsBoth, "How backups and reports are hashed: 'files' writes a .sha256 file next to each, 'sums' one sha256sum-compatible SHA256SUMS file per run directory, 'both' both. Applies locally and in S3.")
+	archive := fs.Bool("archive", false, "If true, also bundle the backups, reports, hashes and manifest of every run into archive.<state>.tar.gz in its run directory, uploaded with the other artifacts of S3 states. Cannot be used with --no-backups.")
+	alwaysUpload := fs.Bool("always-upload", false, "If true, upload the backups and reports of S3 states even when the state did not change, for a complete audit trail. The state itself is only uploaded when it changed.")
+	keepTemp := fs.Bool("keep-temp", false, "If true, keep temporary downloads and partially written artifacts instead of removing them when the run ends, for debugging.")
+	noHashFiles := fs.Bool("no-hash-files", false, "If true, do not write .sha256 files next to local backups and reports. Hashes are still computed and included in the reports.")
+	shouldExecute := fs.Bool("should-execute", false, "If true, automatically execute the suggested 'terraform import' and 'terraform state rm' commands.") // New flag
+	backupsDir := fs.String("backups-dir", filepath.Join(".", "backups"), "Directory to store local backups and reports.")
+	reportDir := fs.String("report-dir", "", "Optional: Directory to write the Markdown and JSON reports to, in the same --backup-layout as the backups. Defaults to --backups-dir.")
+	backupLayout := fs.String("backup-layout", defaultBackupLayout, "Template of each run's directory below --backups-dir and, in S3, below --backup-prefix. Fields: {{.Year}}, {{.Month}}, {{.Day}}, {{.Timestamp}}, {{.State}}, {{.Workspace}} and {{.Region}}.")
+	timestampFormat := fs.String("timestamp-format", defaultTimestampFormat, "Go time layout of {{.Timestamp}} in --backup-layout, e.g. 2006-01-02T15-04-05 to sort across days and months.")
+	jsonOutput := fs.Bool("json", false, "If true, render results in JSON format to stdout.") // NEW: JSON flag
+	terraformWorkingDir := fs.String("tf-dir", ".", "Optional: The directory where 'terraform' commands should be executed. Defaults to the current directory.")
+	rateLimit := fs.Float64("rate-limit", 10, "Maximum AWS API requests per second, per service. Set to 0 to disable client-side rate limiting.")
+	rateBurst := fs.Int("rate-burst", 20, "Number of AWS API requests per service allowed to burst above --rate-limit.")
+	apiTimeout := fs.Duration("api-timeout", 0, "Optional: Timeout for each resource's AWS verification calls (e.g. 30s). Timed-out resources are retried once. 0 uses the SDK defaults.")
+	deadline := fs.Duration("deadline", 0, "Optional: Overall time budget for verification (e.g. 30m). Resources not verified in time are reported as SKIPPED; reports and backups are still written.")
+	profileDir := fs.String("profile-dir", "", "Optional: Directory to write CPU/heap pprof profiles and a per-resource-type timing breakdown (timings.txt) to.")
+	incremental := fs.Bool("incremental", false, "Optional: Only verify resources whose attributes changed since their last successful check in the same region and account, or whose check is older than --incremental-ttl. Results are kept in verified.<state>.json in the backups directory.")
+	incrementalTTL := fs.Duration("incremental-ttl", 24*time.Hour, "Optional: How long a result is trusted in --incremental mode before the resource is verified again.")
+	includeTypes := fs.String("include-types", "", "Optional: Comma-separated resource types to verify, others are left out (e.g. aws_instance,aws_route53_*). * and ? are wildcards.")
+	excludeTypes := fs.String("exclude-types", "", "Optional: Comma-separated resource types not to verify (e.g. aws_iam_*).")
+	includeAddresses := fs.String("include-address", "", "Optional: Comma-separated resource addresses to verify, as globs (module.network.*) or /regular expressions/ (/^module\\.app\\./).")
+	policies := fs.String("policy", "", "Optional: Comma-separated Rego files or directories whose deny and warn rules are evaluated against the results (input.results, input.commands). Deny messages fail the run and stop --should-execute.")
+	excludeModules := fs.String("exclude-module", "", "Optional: Comma-separated modules whose resources, including nested modules, are not verified (e.g. module.legacy).")
+	var targets stringList
+	fs.Var(&targets, "target", "Optional: Only verify and remediate this resource, resource instance or module, as terraform's -target does (e.g. module.app.aws_instance.web[0]). Repeat for several targets.")
+	watch := fs.Bool("watch", false, "If true, keep running and reconcile again every --interval, and whenever --watch-queue-url reports a change to the state.")
+	watchInterval := fs.Duration("interval", time.Hour, "Time between reconciliations in --watch mode.")
+	watchListen := fs.String("watch-listen", "", "Optional: Address to serve the last --watch result on (e.g. :9090): /results (JSON), /metrics (Prometheus) and /healthz.")
+	watchQueueURL := fs.String("watch-queue-url", "", "Optional: SQS queue receiving the S3 event notifications of the state bucket. In --watch mode, a change to the --s3-state object starts a reconciliation right away.")
+	checkpointPath := fs.String("checkpoint", "", "Optional: Path of the checkpoint file recording finished results. Defaults to checkpoint.<state>.jsonl in --backups-dir.")
+	resume := fs.Bool("resume", false, "If true, reuse results from the checkpoint of an interrupted run of the same state and only verify the remaining resources.")
+	ssoLogin := fs.Bool("sso-login", false, "If true and the AWS SSO session of the active profile has expired, run 'aws sso login' for it before starting instead of failing.")
+	skipPreflight := fs.Bool("skip-preflight", false, "If true, skip simulating the IAM permissions needed for the resource types in the state before verification starts.")
+	failUnknownProviders := fs.Bool("fail-unknown-providers", false, "If true, exit with status 1 after reporting when the state has resources of providers other than aws that manage infrastructure (e.g. google, azurerm). Providers such as random and null, which only live in the state, do not count.")
+	expectedLineage := fs.String("expected-lineage", "", "Optional: Lineage the state must have. The run stops before verification if it differs, and an updated S3 state is only uploaded if it still has it. Without it, an updated S3 state must keep the lineage it was downloaded with.")
+	reportGitRepo := fs.String("report-git-repo", "", "Optional: Git repository (URL or path) to commit the Markdown and JSON reports of every run to, in <state>/<YYYY-MM-DD>/, and push. Uses the git on PATH and its credentials.")
+	reportGitBranch := fs.String("report-git-branch", "", "Optional: Existing branch of --report-git-repo to commit the reports to. Defaults to the repository's default branch.")
+	securityHub := fs.Bool("security-hub", false, "If true, import the DANGEROUS (HIGH), POTENTIAL_IMPORT (MEDIUM) and ERROR (LOW) results as findings into AWS Security Hub in --region, in the account of the credentials.")
+	datadogAPIKey := fs.String("datadog-api-key", "", "Optional: Datadog API key. If set, send the result counts, commands and duration of every run as metrics, and an event per category needing attention, to Datadog. Prefer RECONCILE_DATADOG_API_KEY to passing it on the command line.")
+	datadogSite := fs.String("datadog-site", "datadoghq.com", "Datadog site to send metrics and events to, e.g. datadoghq.eu or us5.datadoghq.com.")
+	datadogEnv := fs.String("datadog-env", "", "Optional: env tag of the Datadog metrics and events, e.g. production.")
+	datadogTags := fs.String("datadog-tags", "", "Optional: Comma-separated extra tags of the Datadog metrics and events (e.g. team:platform,service:network).")
+	planPath := fs.String("plan", "", "Optional: JSON plan (terraform show -json plan.tfplan) whose creations, updates and replacements are verified instead of the state's resources, flagging planned creations that already exist in AWS and planned changes to objects that no longer do.")
+	compareBackup := fs.Bool("compare-backup", false, "If true, compare a local --state with the .backup Terraform wrote next to it before the last apply, and report what that apply added, removed and changed.")
+	followRemoteState := fs.Bool("follow-remote-state", false, "If true, also verify the states referenced by terraform_remote_state data sources with the s3 or local backend, and theirs in turn up to --remote-state-depth, in a linked report. Linked states are only reported on, never remediated.")
+	remoteStateDepth := fs.Int("remote-state-depth", 3, "Maximum number of terraform_remote_state hops --follow-remote-state follows from the state.")
+	maxAttempts := fs.Int("max-attempts", 8, "Maximum attempts per AWS API call, retrying throttling and transient errors with exponential backoff and jitter.")
 
-	flag.Parse()
+	parseFlags(fs, args)
 
 	if *showVersion {
 		return Config{ShowVersion: true}
@@ -29,33 +103,309 @@ func parseAndValidateConfig() Config {
 	if *stateFilePath == "" && *s3State == "" {
 		log.Fatal("State file path (--state) or S3 state path (--s3-state) is required.")
 	}
-	if *awsRegion == "" {
-		log.Fatal("AWS region is required. Use --region <region>")
-	}
 	if *concurrency <= 0 {
 		log.Fatal("Concurrency must be a positive integer.")
 	}
+	if *rateLimit < 0 {
+		log.Fatal("Rate limit must not be negative.")
+	}
+	if *rateBurst <= 0 {
+		log.Fatal("Rate burst must be a positive integer.")
+	}
+	if *apiTimeout < 0 {
+		log.Fatal("API timeout must not be negative.")
+	}
+	if *deadline < 0 {
+		log.Fatal("Deadline must not be negative.")
+	}
+	if *incremental && *incrementalTTL <= 0 {
+		log.Fatal("Incremental TTL must be positive.")
+	}
+	if *watch && *watchInterval < time.Minute {
+		log.Fatal("Interval must be at least one minute.")
+	}
+	if !*watch && (*watchListen != "" || *watchQueueURL != "") {
+		log.Fatal("--watch-listen and --watch-queue-url require --watch.")
+	}
+	if *watchQueueURL != "" && *s3State == "" {
+		log.Fatal("--watch-queue-url requires --s3-state.")
+	}
+	if *noBackups && (*shouldExecute || *incremental) {
+		log.Fatal("--no-backups cannot be used with --should-execute or --incremental.")
+	}
+	if *noBackups && *resume && *checkpointPath == "" {
+		log.Fatal("--resume with --no-backups requires --checkpoint.")
+	}
+	if *planPath != "" && (*watch || *resume || *incremental || *checkpointPath != "") {
+		log.Fatal("--plan cannot be used with --watch, --resume, --incremental or --checkpoint.")
+	}
+	if *reportGitBranch != "" && *reportGitRepo == "" {
+		log.Fatal("--report-git-branch requires --report-git-repo.")
+	}
+	if *reportGitRepo != "" && *noBackups && *reportDir == "" {
+		log.Fatal("--report-git-repo with --no-backups requires --report-dir: without it no reports are written.")
+	}
+	if *datadogAPIKey == "" && (*datadogEnv != "" || *datadogTags != "") {
+		log.Fatal("--datadog-env and --datadog-tags require --datadog-api-key.")
+	}
+	if *failUnknownProviders && *watch {
+		log.Fatal("--fail-unknown-providers cannot be used with --watch.")
+	}
+	if *compareBackup && *s3State != "" {
+		log.Fatal("--compare-backup requires a local --state; S3 states have no .backup file.")
+	}
+	if *followRemoteState && *remoteStateDepth <= 0 {
+		log.Fatal("--remote-state-depth must be a positive integer.")
+	}
+	if *noBackups && *archive {
+		log.Fatal("--archive cannot be used with --no-backups.")
+	}
+	if strings.ContainsAny(time.Now().Format(*timestampFormat), `/\`) {
+		log.Fatal("--timestamp-format must not produce path separators; use --backup-layout for directories.")
+	}
+	if err := validateBackupLayout(*backupLayout, *timestampFormat); err != nil {
+		log.Fatal(err)
+	}
+	switch s3types.ServerSideEncryption(*sse) {
+	case "", s3types.ServerSideEncryptionAes256, s3types.ServerSideEncryptionAwsKms, s3types.ServerSideEncryptionAwsKmsDsse:
+	default:
+		log.Fatalf("Invalid --sse %q: expected AES256, aws:kms or aws:kms:dsse.", *sse)
+	}
+	if *sseKMSKeyID != "" && !strings.HasPrefix(*sse, "aws:kms") {
+		log.Fatal("--sse-kms-key-id requires --sse aws:kms or aws:kms:dsse.")
+	}
+	switch *checksums {
+	case checksumsFiles, checksumsSums, checksumsBoth:
+	default:
+		log.Fatalf("Invalid --checksums %q: expected files, sums or both.", *checksums)
+	}
+	switch s3types.ObjectLockMode(*objectLockMode) {
+	case "":
+		if *objectLockRetention != 0 {
+			log.Fatal("--object-lock-retention requires --object-lock-mode.")
+		}
+	case s3types.ObjectLockModeGovernance, s3types.ObjectLockModeCompliance:
+		if *objectLockRetention <= 0 {
+			log.Fatal("--object-lock-mode requires a positive --object-lock-retention.")
+		}
+	default:
+		log.Fatalf("Invalid --object-lock-mode %q: expected GOVERNANCE or COMPLIANCE.", *objectLockMode)
+	}
+	if *signKey != "" && !strings.HasPrefix(*signKey, kmsKeyPrefix) {
+		if _, err := os.Stat(*signKey); err != nil {
+			log.Fatalf("Invalid --sign-key: %v", err)
+		}
+	}
+	parsedServiceEndpoints, err := parseServiceEndpoints(*serviceEndpoints)
+	if err != nil {
+		log.Fatalf("Invalid --endpoint-urls: %v", err)
+	}
+	if (*roleARN == "") != (*webIdentityTokenFile == "") {
+		log.Fatal("--role-arn and --web-identity-token-file must be used together.")
+	}
+	accountRoles, err := loadAccountRoles(*accountRolesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *maxAttempts <= 0 {
+		log.Fatal("Max attempts must be a positive integer.")
+	}
 
 	config := Config{
-		StateFilePath:       *stateFilePath,
-		AWSRegion:           *awsRegion,
-		Concurrency:         *concurrency,
-		S3State:             *s3State,
-		ExecuteCommands:     *shouldExecute,
-		BackupsDir:          *backupsDir,
-		JsonOutput:          *jsonOutput,
-		TerraformWorkingDir: *terraformWorkingDir,
+		StateFilePath:         *stateFilePath,
+		AWSRegion:             *awsRegion,
+		AWSProfile:            *awsProfile,
+		RoleARN:               *roleARN,
+		StateProfile:          *stateProfile,
+		StateRoleARN:          *stateRoleARN,
+		StateRegion:           *stateRegion,
+		WebIdentityTokenFile:  *webIdentityTokenFile,
+		SharedConfigFile:      *sharedConfigFile,
+		SharedCredentialsFile: *sharedCredentialsFile,
+		Concurrency:           *concurrency,
+		S3State:               *s3State,
+		ExecuteCommands:       *shouldExecute,
+		DryRun:                *dryRun,
+		NoBackups:             *noBackups,
+		NoHashFiles:           *noHashFiles,
+		KeepTemp:              *keepTemp,
+		Archive:               *archive,
+		AlwaysUpload:          *alwaysUpload,
+		Checksums:             *checksums,
+		BackupsDir:            *backupsDir,
+		ReportDir:             cmp.Or(*reportDir, *backupsDir),
+		SSE:                   *sse,
+		SSEKMSKeyID:           *sseKMSKeyID,
+		ObjectLockMode:        *objectLockMode,
+		ObjectLockRetention:   *objectLockRetention,
+		ObjectLockLegalHold:   *objectLockLegalHold,
+		BackupBucket:          strings.TrimPrefix(*backupBucket, "s3://"),
+		BackupPrefix:          strings.Trim(*backupPrefix, "/"),
+		SignKey:               *signKey,
+		AuditTable:            *auditTable,
+		BackupLayout:          *backupLayout,
+		TimestampFormat:       *timestampFormat,
+		JsonOutput:            *jsonOutput,
+		TerraformWorkingDir:   *terraformWorkingDir,
+		RateLimit:             *rateLimit,
+		RateBurst:             *rateBurst,
+		MaxAttempts:           *maxAttempts,
+		RemoteStateDepth:      *remoteStateDepth,
+		FollowRemoteState:     *followRemoteState,
+		CompareBackup:         *compareBackup,
+		PlanPath:              *planPath,
+		ReportGitRepo:         *reportGitRepo,
+		ReportGitBranch:       *reportGitBranch,
+		DatadogAPIKey:         *datadogAPIKey,
+		DatadogSite:           strings.TrimPrefix(*datadogSite, "https://"),
+		DatadogEnv:            *datadogEnv,
+		ExpectedLineage:       *expectedLineage,
+		FailUnknownProviders:  *failUnknownProviders,
+		SecurityHub:           *securityHub,
+		APITimeout:            *apiTimeout,
+		Deadline:              *deadline,
+		IncrementalTTL:        *incrementalTTL,
+		Incremental:           *incremental,
+		SkipPreflight:         *skipPreflight,
+		SSOLogin:              *ssoLogin,
+		UseFIPSEndpoints:      *useFIPSEndpoints,
+		UseDualStack:          *useDualStack,
+		CheckpointPath:        *checkpointPath,
+		ProfileDir:            *profileDir,
+		Watch:                 *watch,
+		WatchInterval:         *watchInterval,
+		WatchListen:           *watchListen,
+		WatchQueueURL:         *watchQueueURL,
+		EndpointURL:           *endpointURL,
+		ServiceEndpoints:      parsedServiceEndpoints,
+		AccountRoles:          accountRoles,
+		IncludeTypes:          splitList(*includeTypes),
+		Policies:              splitList(*policies),
+		DatadogTags:           splitList(*datadogTags),
+		ExcludeTypes:          splitList(*excludeTypes),
+		IncludeAddresses:      splitList(*includeAddresses),
+		ExcludeModules:        splitList(*excludeModules),
+		Targets:               targets,
+		Resume:                *resume,
+	}
+
+	if _, err := newResourceFilter(config); err != nil {
+		log.Fatal(err)
 	}
 
 	if *s3State != "" {
 		config.IsS3State = true
-		s3Parts := strings.SplitN(strings.TrimPrefix(*s3State, "s3://"), "/", 2)
-		if len(s3Parts) != 2 {
-			log.Fatalf("Invalid S3 state path format: %s. Expected s3://bucket/key", *s3State)
+		if config.S3Bucket, config.S3Key, err = parseS3URI(*s3State); err != nil {
+			log.Fatal(err)
 		}
-		config.S3Bucket = s3Parts[0]
-		config.S3Key = s3Parts[1]
 	}
 
 	return config
 }
+
+// validateBackupLayout checks that layout renders to a directory inside the backups directory, and that
+// runs a second apart get different directories so they never overwrite each other's backups.
+func validateBackupLayout(layout, timestampFormat string) error {
+	first := time.Date(2024, time.May, 21, 10, 30, 0, 0, time.Local)
+	second := first.Add(time.Second)
+	data := backupLayout{State: "state", Workspace: "default", Region: "us-east-1"}
+	var dirs []string
+	for _, t := range []time.Time{first, second} {
+		data.Year, data.Month, data.Day, data.Timestamp = t.Format("2006"), t.Format("01"), t.Format("02"), t.Format(timestampFormat)
+		dir, err := renderBackupLayout(layout, data)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, dir)
+	}
+	if dirs[0] == dirs[1] {
+		return fmt.Errorf("--backup-layout %q with --timestamp-format %q gives runs a second apart the same directory; include {{.Timestamp}} with seconds", layout, timestampFormat)
+	}
+	return nil
+}
+
+// flagGiven reports whether the named flag of fs was set on the command line or through its environment variable.
+func flagGiven(fs *flag.FlagSet, name string) bool {
+	given := false
+	fs.Visit(func(f *flag.Flag) {
+		given = given || f.Name == name
+	})
+	_, inEnvironment := os.LookupEnv(flagEnvName(name))
+	return given || inEnvironment
+}
+
+// parseS3URI splits s3://bucket/key into its bucket and key.
+func parseS3URI(uri string) (string, string, error) {
+	s3Parts := strings.SplitN(strings.TrimPrefix(uri, "s3://"), "/", 2)
+	if len(s3Parts) != 2 || s3Parts[0] == "" || s3Parts[1] == "" {
+		return "", "", fmt.Errorf("Invalid S3 state path format: %s. Expected s3://bucket/key", uri)
+	}
+	return s3Parts[0], s3Parts[1], nil
+}
+
+// parseServiceEndpoints parses "service=url,service=url" into a map, rejecting unknown services.
+func parseServiceEndpoints(value string) (map[string]string, error) {
+	endpoints := make(map[string]string)
+	if value == "" {
+		return endpoints, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		service, url, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		service = strings.ToLower(strings.TrimSpace(service))
+		if !ok || service == "" || url == "" {
+			return nil, fmt.Errorf("expected service=url, got %q", pair)
+		}
+		if !slices.Contains(endpointServices, service) {
+			return nil, fmt.Errorf("unknown service %q (supported: %s)", service, strings.Join(endpointServices, ", "))
+		}
+		endpoints[service] = strings.TrimSpace(url)
+	}
+	return endpoints, nil
+}
+
+// String implements flag.Value.
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+// Set implements flag.Value, adding one more value.
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// envPrefix is prepended to a flag's name, upper-cased with dashes turned into underscores, to form
+// the environment variable that sets it: --state is RECONCILE_STATE, --api-timeout RECONCILE_API_TIMEOUT.
+const envPrefix = "RECONCILE_"
+
+// flagEnvName returns the environment variable that sets the named flag.
+func flagEnvName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// parseFlags parses args into fs, then sets every flag the command line did not set from its RECONCILE_*
+// environment variable. Values given on the command line win, and repeatable flags such as --target
+// do not add the environment's value to them.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	defaultUsage := fs.Usage
+	fs.Usage = func() {
+		defaultUsage()
+		fmt.Fprintf(fs.Output(), "\nEvery flag can also be set through the environment as %s<FLAG>, e.g. %s=s3://bucket/key or %s=true. Command-line flags take precedence.\n",
+			envPrefix, flagEnvName("s3-state"), flagEnvName("json"))
+	}
+	_ = fs.Parse(args)
+
+	onCommandLine := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		onCommandLine[f.Name] = true
+	})
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(flagEnvName(f.Name))
+		if !ok || onCommandLine[f.Name] {
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			log.Fatalf("Invalid value %q for %s (--%s): %v", value, flagEnvName(f.Name), f.Name, err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// credentialEnvVars are the environment variables the SDK's default credential chain reads,
+// listed in diagnostics so a misconfigured pod or runner shows what it actually had.
+var credentialEnvVars = []string{
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AWS_PROFILE",
+	"AWS_CONFIG_FILE",
+	"AWS_SHARED_CREDENTIALS_FILE",
+	"AWS_ROLE_ARN",
+	"AWS_WEB_IDENTITY_TOKEN_FILE",
+	"AWS_CONTAINER_CREDENTIALS_FULL_URI",
+	"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+	"AWS_EC2_METADATA_DISABLED",
+}
+
+// useWebIdentity replaces cfg's credentials with AssumeRoleWithWebIdentity for --role-arn and
+// --web-identity-token-file, the same exchange EKS IRSA performs through AWS_ROLE_ARN and
+// AWS_WEB_IDENTITY_TOKEN_FILE, but without depending on the pod environment being injected.
+func useWebIdentity(cfg *aws.Config, appConfig Config) {
+	if appConfig.RoleARN == "" || appConfig.WebIdentityTokenFile == "" {
+		return
+	}
+	client := sts.NewFromConfig(*cfg, func(o *sts.Options) {
+		o.BaseEndpoint = cmp.Or(endpointFor(appConfig, "sts"), o.BaseEndpoint)
+	})
+	provider := stscreds.NewWebIdentityRoleProvider(client, appConfig.RoleARN, stscreds.IdentityTokenFile(appConfig.WebIdentityTokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+		o.RoleSessionName = roleSessionName
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+}
+
+// diagnoseCredentials resolves credentials once at startup and logs which source supplied them and
+// the identity they belong to. Failing to obtain credentials at all is returned as an error that
+// lists what the credential chain had to work with; an identity lookup failure is only logged.
+func diagnoseCredentials(ctx context.Context, cfg aws.Config, clients *AWSClient, appConfig Config) error {
+	if cfg.Credentials == nil {
+		return fmt.Errorf("no AWS credential provider is configured\n%s", credentialHints())
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil && appConfig.RoleARN == "" {
+		// An SSO profile that cannot produce credentials almost always means the session expired.
+		if profile := activeProfile(appConfig); isSSOProfile(ctx, appConfig, profile) {
+			if !appConfig.SSOLogin {
+				return fmt.Errorf("the AWS SSO session for profile '%s' has expired or was never started: run `aws sso login --profile %s` (or pass --sso-login) and try again: %w", profile, profile, err)
+			}
+			if loginErr := ssoLogin(ctx, appConfig, profile); loginErr != nil {
+				return loginErr
+			}
+			creds, err = cfg.Credentials.Retrieve(ctx)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to obtain AWS credentials: %w\n%s", err, credentialHints())
+	}
+	log.Printf("AWS credentials loaded from %s", creds.Source)
+
+	identity, err := clients.callerIdentity(ctx)
+	if err != nil {
+		log.Printf("WARNING: Could not resolve the caller identity for these credentials: %v", err)
+		return nil
+	}
+	log.Printf("AWS identity: %s (account %s)", aws.ToString(identity.Arn), aws.ToString(identity.Account))
+	return nil
+}
+
+// credentialHints describes the credential-related environment for error messages. Only whether a
+// variable is set is shown, never its value, except for non-secret paths and names.
+func credentialHints() string {
+	var b strings.Builder
+	b.WriteString("Credential environment:")
+	for _, name := range credentialEnvVars {
+		value, ok := os.LookupEnv(name)
+		switch {
+		case !ok:
+			fmt.Fprintf(&b, "\n  %s: not set", name)
+		case strings.Contains(name, "KEY") || (strings.Contains(name, "TOKEN") && !strings.HasSuffix(name, "_FILE")):
+			fmt.Fprintf(&b, "\n  %s: set", name)
+		default:
+			fmt.Fprintf(&b, "\n  %s: %s", name, value)
+		}
+	}
+	if tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"); tokenFile != "" {
+		if _, err := os.Stat(tokenFile); err != nil {
+			fmt.Fprintf(&b, "\n  web identity token file is not readable: %v", err)
+		}
+	}
+	b.WriteString("\nOn EKS with IRSA, check the service account's eks.amazonaws.com/role-arn annotation, or pass --role-arn and --web-identity-token-file.")
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// datadogMaxEventAddresses is how many resource addresses an event lists before summarizing the rest.
+const datadogMaxEventAddresses = 25
+
+// datadogAlertTypes are the categories sent to Datadog as events with --datadog-api-key, and the
+// alert type of their events. OK, INFO and SKIPPED results only appear in the metrics.
+var datadogAlertTypes = map[string]string{
+	"DANGEROUS":        "error",
+	"POTENTIAL_IMPORT": "warning",
+	"REGION_MISMATCH":  "warning",
+	"ERROR":            "warning",
+	"WARNING":          "info",
+	"STALE":            "info",
+	"PENDING_DELETION": "warning",
+}
+
+// datadogTags returns the tags of the metrics and events of the run: the state, its bucket for S3
+// states, the region, the --datadog-env and every --datadog-tags tag.
+func datadogTags(config Config) []string {
+	tags := []string{"region:" + config.AWSRegion}
+	if config.IsS3State {
+		tags = append(tags, "state:"+config.S3Key, "state_bucket:"+config.S3Bucket)
+	} else {
+		tags = append(tags, "state:"+filepath.ToSlash(config.StateFilePath))
+	}
+	if config.DatadogEnv != "" {
+		tags = append(tags, "env:"+config.DatadogEnv)
+	}
+	return append(tags, config.DatadogTags...)
+}
+
+// datadogMetrics returns the summary of the run as gauges: the resources per category, the remediation
+// commands suggested and failed, and how long the run took.
+func datadogMetrics(results *categorizedResults, tags []string, now time.Time) []datadogSeries {
+	gauge := func(metric, unit string, value float64, extraTags ...string) datadogSeries {
+		return datadogSeries{
+			Points: []datadogPoint{{Timestamp: now.Unix(), Value: value}},
+			Tags:   append(append([]string{}, tags...), extraTags...),
+			Metric: metric,
+			Unit:   unit,
+			Type:   3,
+		}
+	}
+	var series []datadogSeries
+	for _, category := range []struct {
+		name     string
+		statuses []ResourceStatus
+	}{
+		{"INFO", results.InfoResults},
+		{"OK", results.OkResults},
+		{"POTENTIAL_IMPORT", results.PotentialImportResults},
+		{"REGION_MISMATCH", results.RegionMismatchResults},
+		{"WARNING", results.WarningResults},
+		{"ERROR", results.ErrorResults},
+		{"DANGEROUS", results.DangerousResults},
+		{"STALE", results.StaleResults},
+		{"PENDING_DELETION", results.PendingDeletionResults},
+		{"SKIPPED", results.SkippedResults},
+	} {
+		series = append(series, gauge("reconcile_tfstate.resources", "resource", float64(len(category.statuses)), "category:"+strings.ToLower(category.name)))
+	}
+	failed := 0
+	for _, execution := range results.CommandExecutionLogs {
+		if execution.Error != "" || execution.ExitCode != 0 {
+			failed++
+		}
+	}
+	series = append(series,
+		gauge("reconcile_tfstate.commands", "command", float64(len(results.RunCommands))),
+		gauge("reconcile_tfstate.commands_failed", "command", float64(failed)),
+		gauge("reconcile_tfstate.duration", "second", now.Sub(globalRunStarted).Seconds()),
+	)
+	return series
+}
+
+// datadogEvents returns an event for every category of datadogAlertTypes with results, listing the
+// addresses of its resources. The aggregation key groups the events of a state and category over runs.
+func datadogEvents(config Config, results *categorizedResults, tags []string, now time.Time) []datadogEvent {
+	state := config.StateFilePath
+	if config.IsS3State {
+		state = config.S3State
+	}
+	var events []datadogEvent
+	for _, category := range []struct {
+		name     string
+		statuses []ResourceStatus
+	}{
+		{"DANGEROUS", results.DangerousResults},
+		{"POTENTIAL_IMPORT", results.PotentialImportResults},
+		{"REGION_MISMATCH", results.RegionMismatchResults},
+		{"ERROR", results.ErrorResults},
+		{"WARNING", results.WarningResults},
+		{"STALE", results.StaleResults},
+		{"PENDING_DELETION", results.PendingDeletionResults},
+	} {
+		if len(category.statuses) == 0 {
+			continue
+		}
+		var text strings.Builder
+		for i, status := range category.statuses {
+			if i == datadogMaxEventAddresses {
+				text.WriteString(fmt.Sprintf("... and %d more\n", len(category.statuses)-i))
+				break
+			}
+			text.WriteString(fmt.Sprintf("%s: %s\n", status.TerraformAddress, status.Message))
+		}
+		events = append(events, datadogEvent{
+			Tags:           append(append([]string{}, tags...), "category:"+strings.ToLower(category.name)),
+			Title:          fmt.Sprintf("%d %s resources in %s", len(category.statuses), category.name, state),
+			Text:           truncate(text.String(), 4000),
+			AlertType:      datadogAlertTypes[category.name],
+			AggregationKey: truncate(state+"/"+category.name, 100),
+			SourceTypeName: programName,
+			DateHappened:   now.Unix(),
+		})
+	}
+	return events
+}
+
+// sendToDatadog sends the metrics and events of results to the Datadog API of --datadog-site.
+func sendToDatadog(ctx context.Context, config Config, results *categorizedResults) error {
+	now := time.Now()
+	tags := datadogTags(config)
+	api := "https://api." + config.DatadogSite
+	series := datadogMetrics(results, tags, now)
+	if err := postToDatadog(ctx, config, api+"/api/v2/series", map[string]interface{}{"series": series}); err != nil {
+		return fmt.Errorf("failed to send metrics to Datadog: %w", err)
+	}
+	events := datadogEvents(config, results, tags, now)
+	for _, event := range events {
+		if err := postToDatadog(ctx, config, api+"/api/v1/events", event); err != nil {
+			return fmt.Errorf("failed to send the %s event to Datadog: %w", event.Title, err)
+		}
+	}
+	if !config.JsonOutput {
+		fmt.Printf("Sent %d metrics and %d events to Datadog.\n", len(series), len(events))
+	}
+	return nil
+}
+
+// postToDatadog posts body as JSON to a Datadog API endpoint, authenticated with --datadog-api-key.
+func postToDatadog(ctx context.Context, config Config, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", config.DatadogAPIKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// runDiff compares two state files by resource instance address and attribute hash.
+func runDiff(fs *flag.FlagSet, args []string) error {
+	jsonOutput := fs.Bool("json", false, "If true, print the differences as JSON.")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected two state files")
+	}
+	oldState, err := openAndReadStateFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newState, err := openAndReadStateFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	diff := diffStates(oldState, newState)
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(diff, "", "\t")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Printf("--- %s (serial %d) -> %s (serial %d) ---\n", fs.Arg(0), oldState.Serial, fs.Arg(1), newState.Serial)
+	if oldState.Lineage != newState.Lineage {
+		fmt.Printf("WARNING: the states have different lineages (%s and %s)\n", oldState.Lineage, newState.Lineage)
+	}
+	for _, address := range diff.Added {
+		fmt.Printf("+ %s\n", address)
+	}
+	for _, address := range diff.Removed {
+		fmt.Printf("- %s\n", address)
+	}
+	for _, address := range diff.Changed {
+		fmt.Printf("~ %s\n", address)
+	}
+	fmt.Printf("%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	return nil
+}
+
+// diffStates returns the instance addresses only in newState, only in oldState, and in both with
+// different attributes, each sorted.
+func diffStates(oldState, newState *TFStateFile) stateDiff {
+	oldHashes, newHashes := stateInstanceHashes(oldState), stateInstanceHashes(newState)
+	var diff stateDiff
+	for address, hash := range newHashes {
+		oldHash, ok := oldHashes[address]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, address)
+		case oldHash != hash:
+			diff.Changed = append(diff.Changed, address)
+		}
+	}
+	for address := range oldHashes {
+		if _, ok := newHashes[address]; !ok {
+			diff.Removed = append(diff.Removed, address)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// stateInstanceHashes maps every resource instance address in tfStateFile to its attributes hash.
+func stateInstanceHashes(tfStateFile *TFStateFile) map[string]string {
+	hashes := make(map[string]string)
+	for _, resource := range tfStateFile.Resources {
+		for _, instance := range resource.Instances {
+			address := resourceInstanceAddress(resource, instance)
+			hashes[address] = attributesHash("", resource, instance)
+		}
+	}
+	return hashes
+}
+
+// loadRecentChanges diffs tfStateFile against the .backup Terraform keeps next to the local state at
+// statePath, for --compare-backup. It returns nil, with a warning, when there is no readable backup.
+func loadRecentChanges(statePath string, tfStateFile *TFStateFile) *recentChanges {
+	backupPath := statePath + ".backup"
+	backupState, err := openAndReadStateFile(backupPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("WARNING: --compare-backup: %s does not exist, so there are no recent changes to report.", backupPath)
+		} else {
+			log.Printf("WARNING: --compare-backup: %v", err)
+		}
+		return nil
+	}
+	return &recentChanges{
+		Diff:           diffStates(backupState, tfStateFile),
+		Backup:         backupPath,
+		BackupSerial:   backupState.Serial,
+		StateSerial:    tfStateFile.Serial,
+		LineageDiffers: backupState.Lineage != tfStateFile.Lineage,
+	}
+}
+
+// renderRecentChanges renders what the last apply changed, in the notation of the diff command.
+func renderRecentChanges(changes *recentChanges) string {
+	if changes == nil {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n--- RECENT CHANGES (%s serial %d -> serial %d) ---\n", changes.Backup, changes.BackupSerial, changes.StateSerial))
+	if changes.LineageDiffers {
+		builder.WriteString("WARNING: the backup has a different lineage, so it is not the previous version of this state.\n")
+	}
+	for _, address := range changes.Diff.Added {
+		builder.WriteString(fmt.Sprintf("+ %s\n", address))
+	}
+	for _, address := range changes.Diff.Removed {
+		builder.WriteString(fmt.Sprintf("- %s\n", address))
+	}
+	for _, address := range changes.Diff.Changed {
+		builder.WriteString(fmt.Sprintf("~ %s\n", address))
+	}
+	builder.WriteString(fmt.Sprintf("%d added, %d removed, %d changed by the last apply\n", len(changes.Diff.Added), len(changes.Diff.Removed), len(changes.Diff.Changed)))
+	return builder.String()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// planLocalWrite describes the local file a --dry-run run did not write to path.
+func planLocalWrite(path, description string) plannedWrite {
+	action := "create"
+	if _, err := os.Stat(path); err == nil {
+		action = "overwrite"
+	}
+	return plannedWrite{Target: path, Action: action, Description: description}
+}
+
+// planS3Write describes the S3 object a --dry-run run did not upload to s3://bucket/key.
+func planS3Write(ctx context.Context, awsClients *AWSClient, bucket, key, description string) plannedWrite {
+	write := plannedWrite{Target: fmt.Sprintf("s3://%s/%s", bucket, key), Action: "overwrite", Description: description}
+	_, err := awsClients.s3ClientFor(bucket).HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	var notFound *s3types.NotFound
+	switch {
+	case errors.As(err, &notFound):
+		write.Action = "create"
+	case err != nil:
+		write.Action = "write"
+		write.Description += fmt.Sprintf(" (could not check for an existing object: %v)", err)
+	}
+	return write
+}
+
+// planPostReconciliationWrites lists what handlePostReconciliationBackupsAndUpload would have written:
+// the reports, the manifest, the archive and the new state backup with their hashes, and for S3 states
+// whose state would change or with --always-upload, the uploaded backups and, if it changed, the state itself.
+func planPostReconciliationWrites(
+	ctx context.Context,
+	awsClients *AWSClient,
+	config Config,
+	results *categorizedResults,
+	originalBaseFileName string,
+	runDir string,
+	originalBackupLocalPath string,
+	newLocalStatePath string,
+	reportLocalPathMD string,
+	reportLocalPathJSON string,
+) []plannedWrite {
+	type localFile struct{ path, description string }
+	var files []localFile
+	if !config.NoBackups || config.ReportDir != config.BackupsDir {
+		files = append(files, localFile{reportLocalPathMD, "Markdown report"}, localFile{reportLocalPathJSON, "JSON report"},
+			localFile{createBackupPath(config.ReportDir, originalBaseFileName, "manifest", runDir, ".json"), "manifest"})
+	}
+	if !config.NoBackups {
+		files = append(files, localFile{newLocalStatePath, "new state backup"})
+	}
+	if config.Archive {
+		files = append(files, localFile{createBackupPath(config.BackupsDir, originalBaseFileName, "archive", runDir, ".tar.gz"), "archive"})
+	}
+	var planned []plannedWrite
+	checksumDirs := make(map[string]bool)
+	for _, file := range files {
+		planned = append(planned, planLocalWrite(file.path, file.description))
+		if writesHashFiles(config) {
+			planned = append(planned, planLocalWrite(file.path+".sha256", file.description+" hash"))
+		}
+		if dir := filepath.Dir(file.path); writesChecksumsFile(config) && !checksumDirs[dir] {
+			checksumDirs[dir] = true
+			planned = append(planned, planLocalWrite(filepath.Join(dir, checksumsFileName), "checksums"))
+		}
+		if file.description == "manifest" && config.SignKey != "" {
+			planned = append(planned, planLocalWrite(file.path+".sig", "manifest signature"))
+		}
+	}
+
+	if config.SecurityHub {
+		planned = append(planned, plannedWrite{
+			Target:      "Security Hub in " + config.AWSRegion,
+			Action:      "import",
+			Description: fmt.Sprintf("%d findings", len(results.DangerousResults)+len(results.PotentialImportResults)+len(results.ErrorResults)),
+		})
+	}
+	if config.DatadogAPIKey != "" {
+		planned = append(planned, plannedWrite{
+			Target:      "Datadog " + config.DatadogSite,
+			Action:      "send",
+			Description: "run metrics and events",
+		})
+	}
+	if config.ReportGitRepo != "" {
+		planned = append(planned, plannedWrite{
+			Target:      config.ReportGitRepo + " " + gitReportDir(config, globalRunStarted.Format("2006-01-02")),
+			Action:      "commit",
+			Description: "Markdown and JSON reports",
+		})
+	}
+
+	stateWouldChange := config.ExecuteCommands && len(results.RunCommands) > 0
+	if stateWouldChange && !config.IsS3State {
+		planned = append(planned, planLocalWrite(config.StateFilePath, fmt.Sprintf("state modified by %d remediation commands", len(results.RunCommands))))
+	}
+	stateChanged := stateWouldChange || results.ApplicationError != ""
+	if !config.IsS3State || config.NoBackups || (!stateChanged && !config.AlwaysUpload) {
+		return planned
+	}
+	prefix := s3BackupPrefix(config, runDir)
+	backupBucket := s3BackupBucket(config)
+	for _, object := range []struct{ key, description string }{
+		{prefix + "original." + originalBaseFileName + ".tfstate", "original state backup"},
+		{prefix + "original." + originalBaseFileName + ".tfstate.sha256", "original state hash"},
+		{prefix + "new." + originalBaseFileName + ".tfstate", "new state backup"},
+		{prefix + "new." + originalBaseFileName + ".tfstate.sha256", "new state hash"},
+		{prefix + "report." + originalBaseFileName + ".txt", "Markdown report"},
+		{prefix + "report." + originalBaseFileName + ".txt.sha256", "Markdown report hash"},
+		{prefix + "report." + originalBaseFileName + ".json", "JSON report"},
+		{prefix + "report." + originalBaseFileName + ".json.sha256", "JSON report hash"},
+		{prefix + "manifest." + originalBaseFileName + ".json", "manifest"},
+		{prefix + "manifest." + originalBaseFileName + ".json.sha256", "manifest hash"},
+	} {
+		if config.Checksums == checksumsSums && strings.HasSuffix(object.key, ".sha256") {
+			continue
+		}
+		planned = append(planned, planS3Write(ctx, awsClients, backupBucket, object.key, object.description))
+	}
+	if config.SignKey != "" {
+		planned = append(planned, planS3Write(ctx, awsClients, backupBucket, prefix+"manifest."+originalBaseFileName+".json.sig", "manifest signature"))
+	}
+	if config.Archive {
+		planned = append(planned, planS3Write(ctx, awsClients, backupBucket, prefix+"archive."+originalBaseFileName+".tar.gz", "archive"))
+		if config.Checksums != checksumsSums {
+			planned = append(planned, planS3Write(ctx, awsClients, backupBucket, prefix+"archive."+originalBaseFileName+".tar.gz.sha256", "archive hash"))
+		}
+	}
+	if config.Checksums != checksumsFiles {
+		planned = append(planned, planS3Write(ctx, awsClients, backupBucket, prefix+checksumsFileName, "checksums"))
+	}
+	if !stateChanged {
+		return planned
+	}
+	return append(planned, planS3Write(ctx, awsClients, config.S3Bucket, config.S3Key, "state"))
+}
+
+// printPlannedWrites prints what a --dry-run run left unwritten.
+func printPlannedWrites(planned []plannedWrite) {
+	fmt.Printf("\n--- DRY RUN: NOTHING WAS WRITTEN. A REAL RUN WOULD WRITE (%d) ---\n", len(planned))
+	for _, write := range planned {
+		fmt.Printf("   %-9s %s (%s)\n", write.Action, write.Target, write.Description)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -12,7 +13,14 @@ import (
 )
 
 // handleExecution encapsulates the logic for executing commands and uploading the state file.
-func handleExecution(ctx context.Context, awsClients *AWSClient, config *Config, results *categorizedResults, localStateFilePath, statePathForTerraformCLI string, stateFileModified *bool) {
+// The updated state is only uploaded if it has --expected-lineage, or else lineage, the one it was downloaded with.
+func handleExecution(ctx context.Context, awsClients *AWSClient, config *Config, results *categorizedResults, localStateFilePath, statePathForTerraformCLI, lineage string, stateFileModified *bool) {
+	if config.ExecuteCommands && config.DryRun {
+		if !config.JsonOutput && len(results.RunCommands) > 0 {
+			fmt.Printf("\n--- DRY RUN: %d REMEDIATION COMMANDS NOT EXECUTED ---\n", len(results.RunCommands))
+		}
+		return
+	}
 	if config.ExecuteCommands {
 		// Pass relevant config fields instead of the whole config object to executeCommands
 		stateWasModifiedByCommands, commandExecutionLogs, err := executeCommands(
@@ -20,6 +28,7 @@ func handleExecution(ctx context.Context, awsClients *AWSClient, config *Config,
 			statePathForTerraformCLI,
 			config.TerraformWorkingDir,
 			config.JsonOutput, // Pass JsonOutput here
+			terraformEnvironment(*config),
 		)
 
 		// Store command execution logs regardless of success or failure of commands
@@ -39,6 +48,10 @@ func handleExecution(ctx context.Context, awsClients *AWSClient, config *Config,
 				if !config.JsonOutput {
 					fmt.Println("\n--- UPLOADING UPDATED STATE FILE TO S3 ---")
 				}
+				if err := checkStateLineage(localStateFilePath, cmp.Or(config.ExpectedLineage, lineage)); err != nil {
+					log.Printf("ERROR: Refusing to upload the updated state to %s: %v", config.S3State, err)
+					return // Exit this function but allow main to continue
+				}
 				err := uploadStateFileToS3(ctx, awsClients, localStateFilePath, config.S3Bucket, config.S3Key)
 				if err != nil {
 					log.Printf("ERROR: Failed to upload updated state file to S3: %v", err)
@@ -62,7 +75,7 @@ func handleExecution(ctx context.Context, awsClients *AWSClient, config *Config,
 // It returns a boolean indicating if any state-altering command was targeted,
 // a slice of CommandExecutionLog detailing each command's outcome,
 // and an error if any command failed.
-func executeCommands(commands []string, statePathForTerraformCLI, terraformWorkingDir string, jsonOutput bool) (bool, []CommandExecutionLog, error) { // Added jsonOutput
+func executeCommands(commands []string, statePathForTerraformCLI, terraformWorkingDir string, jsonOutput bool, env []string) (bool, []CommandExecutionLog, error) { // Added jsonOutput
 	if len(commands) == 0 {
 		if !jsonOutput { // Use passed jsonOutput
 			fmt.Println("\nNo remediation commands to execute.")
@@ -107,7 +120,7 @@ func executeCommands(commands []string, statePathForTerraformCLI, terraformWorki
 					}
 					allCommandLogs = append(allCommandLogs, cmdLog)
 					if firstError == nil {
-						firstError = fmt.Errorf(cmdLog.Error)
+						firstError = errors.New(cmdLog.Error)
 					}
 					continue // Skip execution of this malformed command
 				}
@@ -140,7 +153,7 @@ func executeCommands(commands []string, statePathForTerraformCLI, terraformWorki
 					}
 					allCommandLogs = append(allCommandLogs, cmdLog)
 					if firstError == nil {
-						firstError = fmt.Errorf(cmdLog.Error)
+						firstError = errors.New(cmdLog.Error)
 					}
 					continue // Skip execution
 				}
@@ -172,7 +185,7 @@ func executeCommands(commands []string, statePathForTerraformCLI, terraformWorki
 		}
 
 		cmd := exec.Command(cmdName, finalArgs...)
-		cmd.Env = os.Environ()
+		cmd.Env = env
 		cmd.Dir = terraformWorkingDir // Set the working directory for the command
 
 		var stdoutBuf, stderrBuf bytes.Buffer
@@ -206,3 +219,28 @@ func executeCommands(commands []string, statePathForTerraformCLI, terraformWorki
 	}
 	return stateAlteringCommandExecuted, allCommandLogs, firstError
 }
+
+// terraformEnvironment returns the environment for terraform commands, pointing them at the same
+// AWS profile, shared config files and endpoints the tool itself was told to use.
+func terraformEnvironment(config Config) []string {
+	env := os.Environ()
+	if config.AWSProfile != "" {
+		env = append(env, "AWS_PROFILE="+config.AWSProfile)
+	}
+	if config.EndpointURL != "" {
+		env = append(env, "AWS_ENDPOINT_URL="+config.EndpointURL)
+	}
+	if config.UseFIPSEndpoints {
+		env = append(env, "AWS_USE_FIPS_ENDPOINT=true")
+	}
+	if config.UseDualStack {
+		env = append(env, "AWS_USE_DUALSTACK_ENDPOINT=true")
+	}
+	if config.SharedConfigFile != "" {
+		env = append(env, "AWS_CONFIG_FILE="+config.SharedConfigFile)
+	}
+	if config.SharedCredentialsFile != "" {
+		env = append(env, "AWS_SHARED_CREDENTIALS_FILE="+config.SharedCredentialsFile)
+	}
+	return env
+}
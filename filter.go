@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// splitList splits a comma-separated flag value into its trimmed, non-empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// compilePattern compiles a filter pattern. A pattern wrapped in slashes, like /^module\.app\./, is a
+// regular expression; anything else is a glob matching the whole value, in which only * and ? are
+// wildcards so the brackets of instance keys like aws_instance.web[0] match literally.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// compilePatterns compiles every pattern given to flag.
+func compilePatterns(flag string, patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", flag, pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// newResourceFilter builds the filter described by config, or returns nil when no filter flag is set.
+func newResourceFilter(config Config) (*resourceFilter, error) {
+	if len(config.IncludeTypes) == 0 && len(config.ExcludeTypes) == 0 && len(config.IncludeAddresses) == 0 &&
+		len(config.ExcludeModules) == 0 && len(config.Targets) == 0 {
+		return nil, nil
+	}
+	filter := &resourceFilter{targets: config.Targets}
+	var err error
+	if filter.includeTypes, err = compilePatterns("--include-types", config.IncludeTypes); err != nil {
+		return nil, err
+	}
+	if filter.excludeTypes, err = compilePatterns("--exclude-types", config.ExcludeTypes); err != nil {
+		return nil, err
+	}
+	if filter.includeAddresses, err = compilePatterns("--include-address", config.IncludeAddresses); err != nil {
+		return nil, err
+	}
+	for _, module := range config.ExcludeModules {
+		if !strings.HasPrefix(module, "module.") {
+			module = "module." + module
+		}
+		path, err := parseModulePath(module)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude-module %q: %w", module, err)
+		}
+		filter.excludeModules = append(filter.excludeModules, path)
+	}
+	for _, target := range config.Targets {
+		if !targetPattern.MatchString(target) {
+			return nil, fmt.Errorf("invalid --target %q: expected a resource or module address like module.app.aws_instance.web[0]", target)
+		}
+	}
+	return filter, nil
+}
+
+// matchesAny reports whether value matches one of patterns.
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// selects reports whether the resource instance passes every filter.
+func (f *resourceFilter) selects(resource ResourceStateV4, instance InstanceObjectStateV4) bool {
+	if len(f.includeTypes) > 0 && !matchesAny(f.includeTypes, resource.Type) {
+		return false
+	}
+	if matchesAny(f.excludeTypes, resource.Type) {
+		return false
+	}
+	if len(f.excludeModules) > 0 {
+		path, _ := parseModulePath(resource.Module) // Validated when the state was read
+		for _, module := range f.excludeModules {
+			if path.within(module) {
+				return false
+			}
+		}
+	}
+	if len(f.targets) > 0 && !targeted(f.targets, resourceInstanceAddress(resource, instance)) {
+		return false
+	}
+	return len(f.includeAddresses) == 0 || matchesAny(f.includeAddresses, resourceInstanceAddress(resource, instance))
+}
+
+// targetPattern matches the addresses --target accepts: a module instance, or a resource or resource
+// instance optionally inside modules, with data sources prefixed by data. as in terraform.
+var targetPattern = regexp.MustCompile(`^(module\.[\w-]+(\[[^\]]+\])?\.)*(module\.[\w-]+(\[[^\]]+\])?|(data\.)?[\w-]+\.[\w-]+(\[[^\]]+\])?)$`)
+
+// targeted follows terraform's -target semantics: a target selects the instance with exactly its
+// address, every instance of a resource given without an index, and everything inside a targeted
+// module or module instance.
+func targeted(targets []string, address string) bool {
+	for _, target := range targets {
+		if address == target || strings.HasPrefix(address, target+".") || strings.HasPrefix(address, target+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// filterState returns a copy of tfState holding only the instances the filter selects.
+func filterState(tfState *TFStateFile, filter *resourceFilter) *TFStateFile {
+	filtered := *tfState
+	filtered.Resources = nil
+	for _, resource := range tfState.Resources {
+		var instances []InstanceObjectStateV4
+		for _, instance := range resource.Instances {
+			if filter.selects(resource, instance) {
+				instances = append(instances, instance)
+			}
+		}
+		if len(instances) > 0 {
+			resource.Instances = instances
+			filtered.Resources = append(filtered.Resources, resource)
+		}
+	}
+	return &filtered
+}
+
+// unmatchedTargets returns the --target addresses that select no instance in tfState.
+func unmatchedTargets(tfState *TFStateFile, targets []string) []string {
+	var unmatched []string
+	for _, target := range targets {
+		found := false
+		for _, resource := range tfState.Resources {
+			for _, instance := range resource.Instances {
+				if targeted([]string{target}, resourceInstanceAddress(resource, instance)) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, target)
+		}
+	}
+	return unmatched
+}
+
+// countInstances returns the number of resource instances in tfState.
+func countInstances(tfState *TFStateFile) int {
+	count := 0
+	for _, resource := range tfState.Resources {
+		count += len(resource.Instances)
+	}
+	return count
+}
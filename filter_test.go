@@ -0,0 +1,186 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testFilterState holds resources at the root, in a module with a for_each key containing dots, in a
+// module whose name starts with another's, and in a nested module instance.
+var testFilterState = &TFStateFile{Resources: []ResourceStateV4{
+	{Mode: "managed", Type: "aws_instance", Name: "web", EachMode: "list", Instances: []InstanceObjectStateV4{{IndexKey: float64(0)}, {IndexKey: float64(1)}}},
+	{Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Instances: []InstanceObjectStateV4{{}}},
+	{Mode: "data", Type: "aws_ami", Name: "ubuntu", Instances: []InstanceObjectStateV4{{}}},
+	{Module: "module.app", Mode: "managed", Type: "aws_iam_role", Name: "app", Instances: []InstanceObjectStateV4{{}}},
+	{Module: `module.app["eu.west"]`, Mode: "managed", Type: "aws_sqs_queue", Name: "jobs", Instances: []InstanceObjectStateV4{{}}},
+	{Module: "module.app_extra", Mode: "managed", Type: "aws_s3_bucket", Name: "assets", Instances: []InstanceObjectStateV4{{}}},
+	{Module: "module.network.module.subnets[0]", Mode: "managed", Type: "aws_subnet", Name: "private", Instances: []InstanceObjectStateV4{{}}},
+}}
+
+// filteredAddresses returns the addresses of the instances of testFilterState that config selects.
+func filteredAddresses(t *testing.T, config Config) []string {
+	t.Helper()
+	filter, err := newResourceFilter(config)
+	if err != nil {
+		t.Fatalf("newResourceFilter: %v", err)
+	}
+	addresses := []string{}
+	for _, resource := range filterState(testFilterState, filter).Resources {
+		for _, instance := range resource.Instances {
+			addresses = append(addresses, resourceInstanceAddress(resource, instance))
+		}
+	}
+	return addresses
+}
+
+func TestResourceFilterSelects(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"include types glob", Config{IncludeTypes: []string{"aws_s3_*"}}, []string{
+			"aws_s3_bucket.logs", "module.app_extra.aws_s3_bucket.assets",
+		}},
+		{"include types regex", Config{IncludeTypes: []string{"/^aws_(sqs|subnet)/"}}, []string{
+			`module.app["eu.west"].aws_sqs_queue.jobs`, "module.network.module.subnets[0].aws_subnet.private",
+		}},
+		{"glob matches the whole type", Config{IncludeTypes: []string{"aws_s3"}}, []string{}},
+		{"exclude types", Config{ExcludeTypes: []string{"aws_i*", "aws_s3_bucket"}}, []string{
+			"data.aws_ami.ubuntu", `module.app["eu.west"].aws_sqs_queue.jobs`, "module.network.module.subnets[0].aws_subnet.private",
+		}},
+		{"include indexed address", Config{IncludeAddresses: []string{"aws_instance.web[0]"}}, []string{"aws_instance.web[0]"}},
+		{"include every index", Config{IncludeAddresses: []string{"aws_instance.web[?]"}}, []string{"aws_instance.web[0]", "aws_instance.web[1]"}},
+		{"include data address", Config{IncludeAddresses: []string{"data.*"}}, []string{"data.aws_ami.ubuntu"}},
+		{"include module address", Config{IncludeAddresses: []string{"module.app.*"}}, []string{"module.app.aws_iam_role.app"}},
+		{"include module prefix", Config{IncludeAddresses: []string{"module.app*"}}, []string{
+			"module.app.aws_iam_role.app", `module.app["eu.west"].aws_sqs_queue.jobs`, "module.app_extra.aws_s3_bucket.assets",
+		}},
+		{"include for_each module key", Config{IncludeAddresses: []string{`module.app["eu.west"].*`}}, []string{
+			`module.app["eu.west"].aws_sqs_queue.jobs`,
+		}},
+		{"include address regex", Config{IncludeAddresses: []string{`/\.subnets\[\d+\]\./`}}, []string{
+			"module.network.module.subnets[0].aws_subnet.private",
+		}},
+		{"exclude module", Config{ExcludeModules: []string{"app"}}, []string{
+			"aws_instance.web[0]", "aws_instance.web[1]", "aws_s3_bucket.logs", "data.aws_ami.ubuntu",
+			"module.app_extra.aws_s3_bucket.assets", "module.network.module.subnets[0].aws_subnet.private",
+		}},
+		{"exclude module instance", Config{ExcludeModules: []string{`module.app["eu.west"]`}}, []string{
+			"aws_instance.web[0]", "aws_instance.web[1]", "aws_s3_bucket.logs", "data.aws_ami.ubuntu",
+			"module.app.aws_iam_role.app", "module.app_extra.aws_s3_bucket.assets",
+			"module.network.module.subnets[0].aws_subnet.private",
+		}},
+		{"exclude parent module", Config{ExcludeModules: []string{"network"}, IncludeAddresses: []string{"module.*"}}, []string{
+			"module.app.aws_iam_role.app", `module.app["eu.west"].aws_sqs_queue.jobs`, "module.app_extra.aws_s3_bucket.assets",
+		}},
+		{"exclude types over include types", Config{IncludeTypes: []string{"aws_s3_*", "aws_instance"}, ExcludeTypes: []string{"aws_instance"}}, []string{
+			"aws_s3_bucket.logs", "module.app_extra.aws_s3_bucket.assets",
+		}},
+		{"exclude module over include address", Config{IncludeAddresses: []string{"module.app*"}, ExcludeModules: []string{"app"}}, []string{
+			"module.app_extra.aws_s3_bucket.assets",
+		}},
+		{"exclude types over include address", Config{IncludeAddresses: []string{"aws_instance.web[0]"}, ExcludeTypes: []string{"aws_instance"}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filteredAddresses(t, tt.config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResourceFilterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{"invalid type regex", Config{IncludeTypes: []string{"/aws_(/"}}, `invalid --include-types pattern "/aws_(/"`},
+		{"invalid address regex", Config{IncludeAddresses: []string{"/[/"}}, `invalid --include-address pattern "/[/"`},
+		{"invalid module key", Config{ExcludeModules: []string{"app[x]"}}, `invalid --exclude-module "module.app[x]"`},
+		{"unterminated module key", Config{ExcludeModules: []string{`app["eu`}}, "invalid --exclude-module"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newResourceFilter(tt.config); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("newResourceFilter error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+	if filter, err := newResourceFilter(Config{}); filter != nil || err != nil {
+		t.Errorf("newResourceFilter without filters = %v, %v; want nil, nil", filter, err)
+	}
+}
+
+func TestResourceFilterTargets(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"resource", Config{Targets: []string{"aws_instance.web"}}, []string{"aws_instance.web[0]", "aws_instance.web[1]"}},
+		{"resource instance", Config{Targets: []string{"aws_instance.web[1]"}}, []string{"aws_instance.web[1]"}},
+		{"name prefix is not a target", Config{Targets: []string{"aws_instance.we"}}, []string{}},
+		{"data source", Config{Targets: []string{"data.aws_ami.ubuntu"}}, []string{"data.aws_ami.ubuntu"}},
+		{"module and its instances", Config{Targets: []string{"module.app"}}, []string{
+			"module.app.aws_iam_role.app", `module.app["eu.west"].aws_sqs_queue.jobs`,
+		}},
+		{"module instance", Config{Targets: []string{`module.app["eu.west"]`}}, []string{`module.app["eu.west"].aws_sqs_queue.jobs`}},
+		{"resource in module instance", Config{Targets: []string{`module.app["eu.west"].aws_sqs_queue.jobs`}}, []string{
+			`module.app["eu.west"].aws_sqs_queue.jobs`,
+		}},
+		{"nested module", Config{Targets: []string{"module.network"}}, []string{"module.network.module.subnets[0].aws_subnet.private"}},
+		{"several targets", Config{Targets: []string{"aws_s3_bucket.logs", "module.app_extra"}}, []string{
+			"aws_s3_bucket.logs", "module.app_extra.aws_s3_bucket.assets",
+		}},
+		{"targets and include types", Config{Targets: []string{"module.app"}, IncludeTypes: []string{"aws_sqs_*"}}, []string{
+			`module.app["eu.west"].aws_sqs_queue.jobs`,
+		}},
+		{"targets and exclude module", Config{Targets: []string{"aws_instance.web[0]", "module.app"}, ExcludeModules: []string{"app"}}, []string{
+			"aws_instance.web[0]",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filteredAddresses(t, tt.config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetPattern(t *testing.T) {
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"aws_instance.web", true},
+		{"aws_instance.web[0]", true},
+		{`aws_instance.web["k"]`, true},
+		{"data.aws_ami.ubuntu", true},
+		{"module.app", true},
+		{`module.app["eu.west"].aws_sqs_queue.jobs`, true},
+		{"module.network.module.subnets[0].aws_subnet.private[2]", true},
+		{"aws_instance", false},
+		{"module.app.", false},
+		{"aws_instance.web[0", false},
+		{"aws_instance.web.id", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			if got := targetPattern.MatchString(tt.target); got != tt.want {
+				t.Errorf("targetPattern matches %q = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmatchedTargets(t *testing.T) {
+	targets := []string{"aws_instance.web[0]", "aws_instance.web[2]", "module.app", "module.ap"}
+	if got, want := unmatchedTargets(testFilterState, targets), []string{"aws_instance.web[2]", "module.ap"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatchedTargets = %q, want %q", got, want)
+	}
+}
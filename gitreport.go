@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// gitPushAttempts is how many times pushReportsToGit rebases and pushes again when another run pushed
+// to the branch first.
+const gitPushAttempts = 3
+
+// gitReportDir returns the directory of the state's reports of day in the --report-git-repo:
+// bucket/key for S3 states, the state's base name for local ones, followed by the day.
+func gitReportDir(config Config, day string) string {
+	state := stateBaseName(filepath.Base(config.StateFilePath))
+	if config.IsS3State {
+		state = path.Join(config.S3Bucket, strings.TrimSuffix(config.S3Key, ".tfstate"))
+	}
+	return path.Join(state, day)
+}
+
+// pushReportsToGit commits the reports of the run to --report-git-branch of --report-git-repo, in the
+// state's directory of the day, and pushes the commit. A later run of the same day replaces the reports,
+// so the history of each file is the drift of that state over the day.
+func pushReportsToGit(ctx context.Context, config Config, reports []string) error {
+	clone, err := os.MkdirTemp("", "reconcile-reports-")
+	if err != nil {
+		return fmt.Errorf("failed to create a directory to clone %s into: %w", config.ReportGitRepo, err)
+	}
+	if config.KeepTemp {
+		log.Printf("Keeping temporary clone %s (--keep-temp).", clone)
+	} else {
+		defer os.RemoveAll(clone)
+	}
+
+	cloneArgs := []string{"clone", "--quiet", "--depth", "1"}
+	if config.ReportGitBranch != "" {
+		cloneArgs = append(cloneArgs, "--branch", config.ReportGitBranch)
+	}
+	if _, err := runGit(ctx, "", append(cloneArgs, config.ReportGitRepo, clone)...); err != nil {
+		return err
+	}
+
+	dir := gitReportDir(config, globalRunStarted.Format("2006-01-02"))
+	if err := os.MkdirAll(filepath.Join(clone, filepath.FromSlash(dir)), 0755); err != nil {
+		return fmt.Errorf("failed to create %s in the clone: %w", dir, err)
+	}
+	for _, report := range reports {
+		content, err := os.ReadFile(report)
+		if err != nil {
+			return fmt.Errorf("failed to read report %s: %w", report, err)
+		}
+		name := path.Join(dir, filepath.Base(report))
+		if err := os.WriteFile(filepath.Join(clone, filepath.FromSlash(name)), content, 0644); err != nil {
+			return fmt.Errorf("failed to copy report %s into the clone: %w", report, err)
+		}
+		if _, err := runGit(ctx, clone, "add", "--", name); err != nil {
+			return err
+		}
+	}
+	if changes, err := runGit(ctx, clone, "status", "--porcelain"); err != nil {
+		return err
+	} else if changes == "" {
+		if !config.JsonOutput {
+			fmt.Printf("Reports in %s are unchanged; nothing to commit.\n", config.ReportGitRepo)
+		}
+		return nil
+	}
+
+	if email, _ := runGit(ctx, clone, "config", "user.email"); email == "" {
+		// Runners often have no git identity; commit as the tool rather than fail.
+		for key, value := range map[string]string{"user.name": programName, "user.email": programName + "@localhost"} {
+			if _, err := runGit(ctx, clone, "config", key, value); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := runGit(ctx, clone, "commit", "--quiet", "-m", fmt.Sprintf("Reconcile %s at %s", dir, globalTimestamp)); err != nil {
+		return err
+	}
+	branch, err := runGit(ctx, clone, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		_, err := runGit(ctx, clone, "push", "--quiet", "origin", "HEAD:"+branch)
+		if err == nil {
+			break
+		}
+		if attempt == gitPushAttempts {
+			return err
+		}
+		// Another run pushed first; replay this commit on top of it.
+		if _, err := runGit(ctx, clone, "pull", "--quiet", "--rebase", "origin", branch); err != nil {
+			return err
+		}
+	}
+	if !config.JsonOutput {
+		fmt.Printf("Committed the reports to %s in %s.\n", config.ReportGitRepo, dir)
+	}
+	return nil
+}
+
+// runGit runs git with args in dir and returns its trimmed output, or an error including what git
+// printed to stderr.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
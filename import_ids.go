@@ -0,0 +1,158 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// importIDFormats builds the ID terraform import expects for resource types whose import ID is composed
+// from several attributes rather than being the ID their verifier returns. Resources of these types are
+// verified by the same attributes, so finding them in AWS means the state already tracks them.
+var importIDFormats = map[string]func(attributes map[string]interface{}) string{
+	"aws_api_gateway_stage":                    joinedImportID("rest_api_id", "stage_name"),
+	"aws_api_gateway_method":                   joinedImportID("rest_api_id", "resource_id", "http_method"),
+	"aws_api_gateway_integration":              joinedImportID("rest_api_id", "resource_id", "http_method"),
+	"aws_apigatewayv2_stage":                   joinedImportID("api_id", "name"),
+	"aws_cloudwatch_event_target":              eventTargetImportID,
+	"aws_network_acl_rule":                     networkACLRuleImportID,
+	"aws_route":                                routeImportID,
+	"aws_route_table_association":              routeTableAssociationImportID,
+	"aws_security_group_rule":                  securityGroupRuleImportID,
+	"aws_volume_attachment":                    volumeAttachmentImportID,
+	"aws_vpc_endpoint_route_table_association": joinedImportID("vpc_endpoint_id", "route_table_id"),
+	"aws_wafv2_web_acl":                        joinedImportID("id", "name", "scope"),
+	"aws_wafv2_rule_group":                     joinedImportID("id", "name", "scope"),
+	"aws_wafv2_ip_set":                         joinedImportID("id", "name", "scope"),
+}
+
+// importIDParents are the resource types whose import ID is PARENT/ID, PARENT being the value of the
+// attribute they map to, such as the REST API of an API Gateway resource.
+var importIDParents = map[string]string{
+	"aws_api_gateway_deployment":   "rest_api_id",
+	"aws_api_gateway_resource":     "rest_api_id",
+	"aws_apigatewayv2_route":       "api_id",
+	"aws_apigatewayv2_integration": "api_id",
+	"aws_cognito_user_pool_client": "user_pool_id",
+}
+
+// importID returns the ID to give terraform import for the live object liveID of a resourceType resource
+// with attributes: the composite ID for the types in importIDFormats, liveID after its parent's ID for
+// the types in importIDParents, otherwise liveID itself.
+func importID(resourceType string, attributes map[string]interface{}, liveID string) string {
+	if format, ok := importIDFormats[resourceType]; ok {
+		if id := format(attributes); id != "" {
+			return id
+		}
+	}
+	if key, ok := importIDParents[resourceType]; ok {
+		if parent, _ := attributes[key].(string); parent != "" {
+			return parent + "/" + liveID
+		}
+	}
+	return liveID
+}
+
+// joinedImportID returns an import ID format joining the values of keys with slashes, or returning ""
+// if any of them is missing.
+func joinedImportID(keys ...string) func(attributes map[string]interface{}) string {
+	return func(attributes map[string]interface{}) string {
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			value, _ := attributes[key].(string)
+			if value == "" {
+				return ""
+			}
+			values = append(values, value)
+		}
+		return strings.Join(values, "/")
+	}
+}
+
+// routeImportID returns ROUTETABLEID_DESTINATION, the destination being the IPv4 or IPv6 CIDR block or
+// the prefix list ID of the route.
+func routeImportID(attributes map[string]interface{}) string {
+	routeTableID, _ := attributes["route_table_id"].(string)
+	if routeTableID == "" {
+		return ""
+	}
+	for _, key := range []string{"destination_cidr_block", "destination_ipv6_cidr_block", "destination_prefix_list_id"} {
+		if destination, _ := attributes[key].(string); destination != "" {
+			return routeTableID + "_" + destination
+		}
+	}
+	return ""
+}
+
+// routeTableAssociationImportID returns SUBNETID/ROUTETABLEID, or GATEWAYID/ROUTETABLEID for gateway
+// associations.
+func routeTableAssociationImportID(attributes map[string]interface{}) string {
+	routeTableID, _ := attributes["route_table_id"].(string)
+	target, _ := attributes["subnet_id"].(string)
+	if target == "" {
+		target, _ = attributes["gateway_id"].(string)
+	}
+	if routeTableID == "" || target == "" {
+		return ""
+	}
+	return target + "/" + routeTableID
+}
+
+// securityGroupRuleImportID returns SECURITYGROUPID_TYPE_PROTOCOL_FROMPORT_TOPORT followed by every
+// source of the rule: CIDR blocks, IPv6 CIDR blocks, prefix lists, the source security group or "self".
+func securityGroupRuleImportID(attributes map[string]interface{}) string {
+	securityGroupID, _ := attributes["security_group_id"].(string)
+	ruleType, _ := attributes["type"].(string)
+	protocol, _ := attributes["protocol"].(string)
+	if securityGroupID == "" || ruleType == "" || protocol == "" {
+		return ""
+	}
+	var sources []string
+	sources = append(sources, stringSliceAttribute(attributes, "cidr_blocks")...)
+	sources = append(sources, stringSliceAttribute(attributes, "ipv6_cidr_blocks")...)
+	sources = append(sources, stringSliceAttribute(attributes, "prefix_list_ids")...)
+	if sourceGroupID, _ := attributes["source_security_group_id"].(string); sourceGroupID != "" {
+		sources = append(sources, sourceGroupID)
+	}
+	if self, _ := attributes["self"].(bool); self {
+		sources = append(sources, "self")
+	}
+	if len(sources) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s_%s_%s_%d_%d_%s", securityGroupID, ruleType, protocol,
+		numberAttribute(attributes, "from_port"), numberAttribute(attributes, "to_port"), strings.Join(sources, "_"))
+}
+
+// eventTargetImportID returns BUS/RULE/TARGETID, the bus being "default" for rules on the default event bus.
+func eventTargetImportID(attributes map[string]interface{}) string {
+	ruleName, _ := attributes["rule"].(string)
+	targetID, _ := attributes["target_id"].(string)
+	if ruleName == "" || targetID == "" {
+		return ""
+	}
+	busName, _ := attributes["event_bus_name"].(string)
+	return cmp.Or(busName, "default") + "/" + ruleName + "/" + targetID
+}
+
+// volumeAttachmentImportID returns DEVICENAME:VOLUMEID:INSTANCEID.
+func volumeAttachmentImportID(attributes map[string]interface{}) string {
+	deviceName, _ := attributes["device_name"].(string)
+	volumeID, _ := attributes["volume_id"].(string)
+	instanceID, _ := attributes["instance_id"].(string)
+	if deviceName == "" || volumeID == "" || instanceID == "" {
+		return ""
+	}
+	return deviceName + ":" + volumeID + ":" + instanceID
+}
+
+// networkACLRuleImportID returns NETWORKACLID:RULENUMBER:PROTOCOL:EGRESS.
+func networkACLRuleImportID(attributes map[string]interface{}) string {
+	networkACLID, _ := attributes["network_acl_id"].(string)
+	protocol, _ := attributes["protocol"].(string)
+	if networkACLID == "" || protocol == "" || attributes["rule_number"] == nil {
+		return ""
+	}
+	egress, _ := attributes["egress"].(bool)
+	return fmt.Sprintf("%s:%d:%s:%t", networkACLID, numberAttribute(attributes, "rule_number"), protocol, egress)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestImportID(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType string
+		attributes   string
+		liveID       string
+		want         string
+	}{
+		{"plain", "aws_s3_bucket", `{"id": "logs"}`, "logs", "logs"},
+		{"api gateway stage", "aws_api_gateway_stage", `{"rest_api_id": "a1b2", "stage_name": "prod"}`, "ags-a1b2-prod", "a1b2/prod"},
+		{"api gateway method", "aws_api_gateway_method", `{"rest_api_id": "a1b2", "resource_id": "r3", "http_method": "GET"}`, "agm-a1b2-r3-GET", "a1b2/r3/GET"},
+		{"joined with a missing part", "aws_api_gateway_stage", `{"rest_api_id": "a1b2"}`, "ags-a1b2-prod", "ags-a1b2-prod"},
+		{"wafv2 web acl", "aws_wafv2_web_acl", `{"id": "a1b2", "name": "edge", "scope": "CLOUDFRONT"}`, "a1b2", "a1b2/edge/CLOUDFRONT"},
+		{"route to ipv4", "aws_route", `{"route_table_id": "rtb-1", "destination_cidr_block": "10.0.0.0/16"}`, "r-rtb-1", "rtb-1_10.0.0.0/16"},
+		{"route to ipv6", "aws_route", `{"route_table_id": "rtb-1", "destination_cidr_block": "", "destination_ipv6_cidr_block": "::/0"}`, "r-rtb-1", "rtb-1_::/0"},
+		{"route to prefix list", "aws_route", `{"route_table_id": "rtb-1", "destination_prefix_list_id": "pl-1"}`, "r-rtb-1", "rtb-1_pl-1"},
+		{"route without destination", "aws_route", `{"route_table_id": "rtb-1"}`, "r-rtb-1", "r-rtb-1"},
+		{"subnet association", "aws_route_table_association", `{"route_table_id": "rtb-1", "subnet_id": "subnet-1"}`, "rtbassoc-1", "subnet-1/rtb-1"},
+		{"gateway association", "aws_route_table_association", `{"route_table_id": "rtb-1", "subnet_id": "", "gateway_id": "igw-1"}`, "rtbassoc-1", "igw-1/rtb-1"},
+		{"security group rule with cidr blocks", "aws_security_group_rule",
+			`{"security_group_id": "sg-1", "type": "ingress", "protocol": "tcp", "from_port": 443, "to_port": 443, "cidr_blocks": ["10.0.0.0/8", "192.168.0.0/16"], "ipv6_cidr_blocks": ["::/0"]}`,
+			"sgrule-1", "sg-1_ingress_tcp_443_443_10.0.0.0/8_192.168.0.0/16_::/0"},
+		{"security group rule from a group", "aws_security_group_rule",
+			`{"security_group_id": "sg-1", "type": "egress", "protocol": "-1", "from_port": "0", "to_port": "0", "source_security_group_id": "sg-2"}`,
+			"sgrule-1", "sg-1_egress_-1_0_0_sg-2"},
+		{"security group rule to self", "aws_security_group_rule",
+			`{"security_group_id": "sg-1", "type": "ingress", "protocol": "udp", "from_port": 53, "to_port": 53, "self": true}`,
+			"sgrule-1", "sg-1_ingress_udp_53_53_self"},
+		{"security group rule without source", "aws_security_group_rule",
+			`{"security_group_id": "sg-1", "type": "ingress", "protocol": "tcp", "from_port": 22, "to_port": 22}`,
+			"sgrule-1", "sgrule-1"},
+		{"event target on the default bus", "aws_cloudwatch_event_target", `{"rule": "nightly", "target_id": "lambda"}`, "nightly-lambda", "default/nightly/lambda"},
+		{"event target on a custom bus", "aws_cloudwatch_event_target", `{"rule": "nightly", "target_id": "lambda", "event_bus_name": "jobs"}`, "nightly-lambda", "jobs/nightly/lambda"},
+		{"volume attachment", "aws_volume_attachment", `{"device_name": "/dev/sdh", "volume_id": "vol-1", "instance_id": "i-1"}`, "vai-1", "/dev/sdh:vol-1:i-1"},
+		{"network acl rule", "aws_network_acl_rule", `{"network_acl_id": "acl-1", "rule_number": 100, "protocol": "6", "egress": true}`, "nacl-1", "acl-1:100:6:true"},
+		{"network acl rule without number", "aws_network_acl_rule", `{"network_acl_id": "acl-1", "protocol": "6"}`, "nacl-1", "nacl-1"},
+		{"parent", "aws_api_gateway_resource", `{"rest_api_id": "a1b2"}`, "r3", "a1b2/r3"},
+		{"missing parent", "aws_cognito_user_pool_client", `{}`, "client-1", "client-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attributes map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.attributes), &attributes); err != nil {
+				t.Fatal(err)
+			}
+			if got := importID(tt.resourceType, attributes, tt.liveID); got != tt.want {
+				t.Errorf("importID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// incrementalCategories are the results that stay valid until the resource's attributes change or
+// the TTL passes. Everything else (errors, drift, skips, warnings that need manual verification) is
+// verified again on the next run.
+var incrementalCategories = map[string]bool{
+	"OK":   true,
+	"INFO": true,
+}
+
+// defaultIncrementalPath returns the file holding last-verified results for a state.
+func defaultIncrementalPath(backupsDir, originalFileName string) string {
+	return filepath.Join(backupsDir, fmt.Sprintf("verified.%s.json", stateBaseName(originalFileName)))
+}
+
+// incrementalScope names where resources are verified: the region and the caller's account, or the
+// profile when the account cannot be looked up. Results verified in another scope are not reused.
+func incrementalScope(ctx context.Context, awsClients *AWSClient, config Config) string {
+	if identity, err := awsClients.callerIdentity(ctx); err == nil {
+		return config.AWSRegion + "/" + aws.ToString(identity.Account)
+	}
+	return config.AWSRegion + "/profile:" + config.AWSProfile
+}
+
+// openIncremental loads the last-verified results from path. A missing file starts an empty store.
+// Only results verified in scope are reused.
+func openIncremental(path string, ttl time.Duration, scope string) (*incrementalStore, error) {
+	store := &incrementalStore{entries: make(map[string]incrementalEntry), now: time.Now(), path: path, scope: scope, ttl: ttl}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incremental file '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		log.Printf("WARNING: Ignoring unreadable incremental file '%s', every resource will be verified: %v", path, err)
+		store.entries = make(map[string]incrementalEntry)
+	}
+	return store, nil
+}
+
+// attributesHash fingerprints the attributes a resource instance was verified against, and the scope
+// it was verified in.
+func attributesHash(scope string, resource ResourceStateV4, instance InstanceObjectStateV4) string {
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%d\x00", scope, resource.Type, resource.ProviderConfig, instance.SchemaVersion)
+	if len(instance.AttributesRaw) > 0 {
+		hash.Write(instance.AttributesRaw)
+	} else {
+		keys := make([]string, 0, len(instance.AttributesFlat))
+		for key := range instance.AttributesFlat {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			_, _ = fmt.Fprintf(hash, "%s=%s\x00", key, instance.AttributesFlat[key])
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fresh returns the stored result for address when the attributes are unchanged and the last
+// verification is younger than the TTL.
+func (s *incrementalStore) fresh(address string, resource ResourceStateV4, instance InstanceObjectStateV4) (ResourceStatus, bool) {
+	if s == nil {
+		return ResourceStatus{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[address]
+	if !ok || s.now.Sub(entry.VerifiedAt) > s.ttl || entry.AttributesHash != attributesHash(s.scope, resource, instance) {
+		return ResourceStatus{}, false
+	}
+	s.reused++
+	return entry.Result.toStatus(), true
+}
+
+// record stores a freshly verified result. Results outside incrementalCategories drop any previous
+// entry so the address is checked again next time.
+func (s *incrementalStore) record(resource ResourceStateV4, instance InstanceObjectStateV4, status ResourceStatus) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !incrementalCategories[status.Category] {
+		delete(s.entries, status.TerraformAddress)
+		return
+	}
+	s.entries[status.TerraformAddress] = incrementalEntry{
+		Result:         newCheckpointRecord(status),
+		VerifiedAt:     time.Now(),
+		AttributesHash: attributesHash(s.scope, resource, instance),
+	}
+}
+
+// save writes the store back, dropping addresses that are no longer in the state. The file is
+// replaced atomically so an interrupted write never leaves it half written.
+func (s *incrementalStore) save(tfState *TFStateFile) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inState := make(map[string]bool)
+	for _, resource := range tfState.Resources {
+		for _, instance := range resource.Instances {
+			inState[resourceInstanceAddress(resource, instance)] = true
+		}
+	}
+	for address := range s.entries {
+		if !inState[address] {
+			delete(s.entries, address)
+		}
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode incremental file: %w", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write incremental file '%s': %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace incremental file '%s': %w", s.path, err)
+	}
+	return nil
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -25,29 +27,69 @@ var globalLocalStateFilePath string
 var globalTfStateFile *TFStateFile
 var globalOriginalBaseFileName string
 var globalTimestamp string
+var globalRunStarted time.Time
+var globalRunDir string // The run's directory below --backups-dir, rendered from --backup-layout
 var globalStateFileModified bool
 var globalOriginalStateFileHash string
 
-// main is the entry point of the application.
-func main() {
-	// 1. Parse config first, as it's needed for error reporting and setup
-	config := parseAndValidateConfig()
-	globalConfig = config // Store globally for panic handler
+// globalProgress, when set, is called by processResources as each resource instance finishes.
+var globalProgress func(done, total int, status ResourceStatus)
 
-	if config.ShowVersion {
-		fmt.Println(Version())
-		os.Exit(0)
+// main is the entry point of the application. It dispatches to the subcommand named by the first
+// argument; without one it runs check with every flag, as earlier versions did.
+func main() {
+	cmd, args := selectCommand(os.Args[1:])
+	if err := cmd.run(newCommandFlagSet(cmd), args); err != nil {
+		log.Fatalf("%s: %v", cmd.name, err)
 	}
+}
+
+// prepareRun resets the process-wide state runApplication and the panic handler rely on before a
+// reconciliation of the state described by config.
+func prepareRun(config Config) {
+	globalConfig = config // Store globally for panic handler
+	globalTemps.keep = config.KeepTemp
 
 	// Initialize these here as well for global access
-	globalResults = &categorizedResults{}              // Ensure this is initialized before potentially being used by panic handler
-	globalTimestamp = time.Now().Format("02-15-04-05") // DD-HH-MM-SS
+	globalResults = &categorizedResults{} // Ensure this is initialized before potentially being used by panic handler
 	if config.IsS3State {
 		_, globalOriginalBaseFileName = filepath.Split(config.S3Key)
 	} else {
 		globalOriginalBaseFileName = filepath.Base(config.StateFilePath)
 	}
 
+	now := time.Now()
+	globalRunStarted = now
+	globalTimestamp = now.Format(cmp.Or(config.TimestampFormat, defaultTimestampFormat))
+	runDir, err := renderBackupLayout(cmp.Or(config.BackupLayout, defaultBackupLayout), newBackupLayout(config, now, globalTimestamp, globalOriginalBaseFileName))
+	if err != nil {
+		log.Printf("WARNING: %v. Using the default layout %s.", err, defaultBackupLayout)
+		runDir, _ = renderBackupLayout(defaultBackupLayout, newBackupLayout(config, now, globalTimestamp, globalOriginalBaseFileName))
+	}
+	globalRunDir = runDir
+}
+
+// reconcile verifies the state described by config against AWS, writes backups and reports, and
+// uploads them for S3 states, recovering from crashes to upload whatever is available.
+func reconcile(config Config) {
+	if config.ShowVersion {
+		fmt.Println(Version())
+		os.Exit(0)
+	}
+
+	prepareRun(config)
+
+	if config.Watch {
+		if err := runWatch(config); err != nil {
+			log.Fatalf("FATAL ERROR: %v", err)
+		}
+		return
+	}
+
+	// Temporary downloads and partially written artifacts are removed however the run ends
+	defer cleanupOnSignal()()
+	defer globalTemps.cleanup()
+
 	// Set up the deferred function to handle panics and ensure S3 upload on failure
 	defer func() {
 		if r := recover(); r != nil {
@@ -66,10 +108,10 @@ func main() {
 
 			// Try to upload whatever state/reports we have
 			if globalConfig.IsS3State {
-				originalBackupLocalPath := createBackupPath(globalConfig.BackupsDir, globalOriginalBaseFileName, "original", globalTimestamp, ".tfstate")
-				newLocalStatePathPlaceholder := createBackupPath(globalConfig.BackupsDir, globalOriginalBaseFileName, "new", globalTimestamp, ".tfstate")
-				reportLocalPathMD := createBackupPath(globalConfig.BackupsDir, globalOriginalBaseFileName, "report", globalTimestamp, ".txt")
-				reportLocalPathJSON := createBackupPath(globalConfig.BackupsDir, globalOriginalBaseFileName, "report", globalTimestamp, ".json")
+				originalBackupLocalPath := createBackupPath(globalConfig.BackupsDir, globalOriginalBaseFileName, "original", globalRunDir, ".tfstate")
+				newLocalStatePathPlaceholder := createBackupPath(globalConfig.BackupsDir, globalOriginalBaseFileName, "new", globalRunDir, ".tfstate")
+				reportLocalPathMD := createBackupPath(globalConfig.ReportDir, globalOriginalBaseFileName, "report", globalRunDir, ".txt")
+				reportLocalPathJSON := createBackupPath(globalConfig.ReportDir, globalOriginalBaseFileName, "report", globalRunDir, ".json")
 
 				// Create a dummy TFStateFile if it wasn't populated due to early error
 				if globalTfStateFile == nil {
@@ -86,7 +128,7 @@ func main() {
 				log.Println("Attempting to upload available backups and reports to S3 after crash...")
 				uploadErr := handlePostReconciliationBackupsAndUpload(
 					context.Background(), globalAWSClients, globalConfig, globalResults,
-					globalLocalStateFilePath, globalTfStateFile, globalOriginalBaseFileName, globalTimestamp,
+					globalLocalStateFilePath, globalTfStateFile, globalOriginalBaseFileName, globalRunDir,
 					globalStateFileModified, globalOriginalStateFileHash,
 					originalBackupLocalPath, newLocalStatePathPlaceholder, reportLocalPathMD, reportLocalPathJSON)
 				if uploadErr != nil {
@@ -97,6 +139,9 @@ func main() {
 			} else { // Local only mode, just ensure reports are written
 				log.Println("Application crashed in local-only mode. Reports should be available locally.")
 			}
+			recordRunAudit(context.Background(), globalAWSClients, globalConfig, globalResults,
+				globalTfStateFile, globalLocalStateFilePath, globalStateFileModified)
+			globalTemps.cleanup()
 			os.Exit(1) // Exit with an error code after recovery/cleanup
 		}
 	}()
@@ -107,4 +152,16 @@ func main() {
 		// The panic value will be the error itself
 		panic(appErr)
 	}
+	if denied := deniedPolicies(globalResults.PolicyViolations); len(denied) > 0 {
+		log.Printf("ERROR: %d policy violations denied the results.", len(denied))
+		globalTemps.cleanup()
+		os.Exit(1)
+	}
+	if config.FailUnknownProviders {
+		if unknown := unknownProviders(skippedProviders(globalResults)); len(unknown) > 0 {
+			log.Printf("ERROR: The state has resources of providers that were not verified: %s.", strings.Join(unknown, ", "))
+			globalTemps.cleanup()
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// kmsKeyPrefix marks a --sign-key as a KMS key rather than a local private key file.
+const kmsKeyPrefix = "kms:"
+
+// writeRunManifest writes manifest.<state>.json listing the artifacts of the run that exist, with their
+// hashes and sizes, and with --sign-key its detached signature manifest.<state>.json.sig. It returns the
+// paths written; failures are logged, as for the other artifacts of a run.
+func writeRunManifest(
+	ctx context.Context,
+	awsClients *AWSClient,
+	config Config,
+	tfStateFile *TFStateFile,
+	manifestPath string,
+	artifacts []manifestArtifact,
+) (written []string) {
+	manifest := runManifest{
+		CreatedAt: time.Now().UTC(),
+		Version:   Version(),
+		State:     config.StateFilePath,
+		Lineage:   tfStateFile.Lineage,
+		Serial:    tfStateFile.Serial,
+	}
+	if config.IsS3State {
+		manifest.State = config.S3State
+	}
+	for _, artifact := range artifacts {
+		info, err := os.Stat(artifact.Path)
+		if err != nil {
+			continue // Not written by this run
+		}
+		if artifact.SHA256, err = calculateFileSHA256(artifact.Path); err != nil {
+			log.Printf("WARNING: Leaving %s out of the manifest: %v", artifact.Path, err)
+			continue
+		}
+		artifact.Size = info.Size()
+		manifest.Artifacts = append(manifest.Artifacts, artifact)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		log.Printf("WARNING: Failed to render the manifest: %v", err)
+		return nil
+	}
+	if !config.JsonOutput {
+		fmt.Printf("Writing manifest to %s...\n", manifestPath)
+	}
+	if err := writeReportToFile(manifestPath, string(data)); err != nil {
+		log.Printf("WARNING: Failed to write the manifest: %v", err)
+		return nil
+	}
+	written = append(written, manifestPath)
+	if config.SignKey == "" {
+		return written
+	}
+
+	signature, err := signManifest(ctx, awsClients, config.SignKey, data)
+	if err != nil {
+		log.Printf("ERROR: Failed to sign the manifest with %s: %v", config.SignKey, err)
+		return written
+	}
+	signatureData, err := json.MarshalIndent(signature, "", "\t")
+	if err == nil {
+		err = writeReportToFile(manifestPath+".sig", string(signatureData))
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to write the manifest signature: %v", err)
+		return written
+	}
+	return append(written, manifestPath+".sig")
+}
+
+// signManifest signs data with a KMS asymmetric key (kms:<key ID, ARN or alias>) or with the PKCS #8
+// PEM private key at signKey, which may be an Ed25519, ECDSA or RSA key.
+func signManifest(ctx context.Context, awsClients *AWSClient, signKey string, data []byte) (manifestSignature, error) {
+	digest := sha256.Sum256(data)
+	if keyID, ok := strings.CutPrefix(signKey, kmsKeyPrefix); ok {
+		if awsClients == nil {
+			return manifestSignature{}, errors.New("AWS clients were not initialized")
+		}
+		publicKey, err := awsClients.StateKMSClient.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(keyID)})
+		if err != nil {
+			return manifestSignature{}, fmt.Errorf("failed to describe KMS key: %w", err)
+		}
+		algorithm, err := kmsSigningAlgorithm(publicKey.SigningAlgorithms)
+		if err != nil {
+			return manifestSignature{}, err
+		}
+		out, err := awsClients.StateKMSClient.Sign(ctx, &kms.SignInput{
+			KeyId:            aws.String(keyID),
+			Message:          digest[:],
+			MessageType:      kmstypes.MessageTypeDigest,
+			SigningAlgorithm: algorithm,
+		})
+		if err != nil {
+			return manifestSignature{}, fmt.Errorf("failed to sign with KMS: %w", err)
+		}
+		return manifestSignature{
+			Algorithm: kmsKeyPrefix + string(algorithm),
+			KeyID:     aws.ToString(out.KeyId),
+			Signature: base64.StdEncoding.EncodeToString(out.Signature),
+		}, nil
+	}
+
+	pemData, err := os.ReadFile(signKey)
+	if err != nil {
+		return manifestSignature{}, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return manifestSignature{}, errors.New("signing key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return manifestSignature{}, fmt.Errorf("signing key is not a PKCS #8 private key: %w", err)
+	}
+	var signature []byte
+	var algorithm string
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		algorithm, signature = "ed25519", ed25519.Sign(key, data)
+	case *ecdsa.PrivateKey:
+		algorithm = "ecdsa-sha256"
+		signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:])
+	case *rsa.PrivateKey:
+		algorithm = "rsa-pkcs1v15-sha256"
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	default:
+		return manifestSignature{}, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	if err != nil {
+		return manifestSignature{}, err
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(key.(crypto.Signer).Public())
+	if err != nil {
+		return manifestSignature{}, err
+	}
+	fingerprint := sha256.Sum256(publicKey)
+	return manifestSignature{
+		Algorithm: algorithm,
+		KeyID:     "sha256:" + hex.EncodeToString(fingerprint[:]),
+		Signature: base64.StdEncoding.EncodeToString(signature),
+	}, nil
+}
+
+// kmsSigningAlgorithm picks the SHA-256 signing algorithm of a KMS key.
+func kmsSigningAlgorithm(supported []kmstypes.SigningAlgorithmSpec) (kmstypes.SigningAlgorithmSpec, error) {
+	for _, algorithm := range []kmstypes.SigningAlgorithmSpec{
+		kmstypes.SigningAlgorithmSpecEcdsaSha256,
+		kmstypes.SigningAlgorithmSpecRsassaPssSha256,
+		kmstypes.SigningAlgorithmSpecRsassaPkcs1V15Sha256,
+	} {
+		if slices.Contains(supported, algorithm) {
+			return algorithm, nil
+		}
+	}
+	return "", fmt.Errorf("the KMS key supports none of the SHA-256 signing algorithms (it supports %v)", supported)
+}
+
+// runBackupVerify checks that every artifact listed in a manifest still has its recorded hash and size,
+// and that the manifest's signature, if it has one, is valid.
+func runBackupVerify(flags *flag.FlagSet, args []string) error {
+	manifestPath := flags.String("manifest", "", "Path of the manifest.<state>.json to verify.")
+	publicKeyPath := flags.String("public-key", "", "Optional: PEM public key to check the signature of a manifest signed with a local key.")
+	profile := flags.String("profile", "", "Optional: AWS profile to check the signature of a manifest signed with KMS.")
+	parseFlags(flags, args)
+
+	if *manifestPath == "" {
+		return errors.New("--manifest is required")
+	}
+	data, err := os.ReadFile(*manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest runManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse manifest '%s': %w", *manifestPath, err)
+	}
+	fmt.Printf("Manifest of %s (serial %d, lineage %s), written %s by %s %s\n",
+		manifest.State, manifest.Serial, manifest.Lineage, manifest.CreatedAt.Format(time.RFC3339), programName, manifest.Version)
+
+	failed := 0
+	for _, artifact := range manifest.Artifacts {
+		// Artifacts are found where they were written, or next to the manifest once copied elsewhere
+		path := artifact.Path
+		if _, err := os.Stat(path); err != nil {
+			path = filepath.Join(filepath.Dir(*manifestPath), filepath.Base(artifact.Path))
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Printf("MISSING   %s\n", artifact.Path)
+			failed++
+			continue
+		}
+		hash, err := calculateFileSHA256(path)
+		if err != nil || hash != artifact.SHA256 || info.Size() != artifact.Size {
+			fmt.Printf("MODIFIED  %s\n", path)
+			failed++
+			continue
+		}
+		fmt.Printf("OK        %s\n", path)
+	}
+
+	signatureData, err := os.ReadFile(*manifestPath + ".sig")
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Println("The manifest is not signed.")
+	case err != nil:
+		return fmt.Errorf("failed to read manifest signature: %w", err)
+	default:
+		var signature manifestSignature
+		if err := json.Unmarshal(signatureData, &signature); err != nil {
+			return fmt.Errorf("failed to parse manifest signature: %w", err)
+		}
+		if err := verifyManifestSignature(context.Background(), signature, data, *publicKeyPath, *profile); err != nil {
+			fmt.Printf("INVALID SIGNATURE (%s, key %s): %v\n", signature.Algorithm, signature.KeyID, err)
+			failed++
+		} else {
+			fmt.Printf("Valid signature (%s, key %s)\n", signature.Algorithm, signature.KeyID)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d problems found", failed)
+	}
+	return nil
+}
+
+// verifyManifestSignature checks signature over data, with KMS for KMS signatures and with the PEM
+// public key at publicKeyPath otherwise.
+func verifyManifestSignature(ctx context.Context, signature manifestSignature, data []byte, publicKeyPath, profile string) error {
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("signature is not base64: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	if algorithm, ok := strings.CutPrefix(signature.Algorithm, kmsKeyPrefix); ok {
+		var options []func(*awsconfig.LoadOptions) error
+		if keyARN, err := arn.Parse(signature.KeyID); err == nil {
+			options = append(options, awsconfig.WithRegion(keyARN.Region))
+		}
+		if profile != "" {
+			options = append(options, awsconfig.WithSharedConfigProfile(profile))
+		}
+		cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS SDK config: %w", err)
+		}
+		out, err := kms.NewFromConfig(cfg).Verify(ctx, &kms.VerifyInput{
+			KeyId:            aws.String(signature.KeyID),
+			Message:          digest[:],
+			MessageType:      kmstypes.MessageTypeDigest,
+			Signature:        signatureBytes,
+			SigningAlgorithm: kmstypes.SigningAlgorithmSpec(algorithm),
+		})
+		if err != nil {
+			return err
+		}
+		if !out.SignatureValid {
+			return errors.New("KMS reports the signature as invalid")
+		}
+		return nil
+	}
+
+	if publicKeyPath == "" {
+		return errors.New("--public-key is required to check a signature made with a local key")
+	}
+	pemData, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return errors.New("public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, signatureBytes) {
+			return errors.New("signature does not match")
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], signatureBytes) {
+			return errors.New("signature does not match")
+		}
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signatureBytes)
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// moduleNamePattern matches the name of a module call, which follows Terraform's identifier rules.
+var moduleNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// parseModulePath parses a module instance address as the state records it, e.g.
+// module.network.module.subnets["a"] or module.app[0]. The empty address is the root module.
+func parseModulePath(address string) (modulePath, error) {
+	var path modulePath
+	rest := address
+	for rest != "" {
+		after, ok := strings.CutPrefix(rest, "module.")
+		if !ok {
+			return nil, fmt.Errorf("expected \"module.\" at %q", rest)
+		}
+		end := strings.IndexAny(after, ".[")
+		if end < 0 {
+			end = len(after)
+		}
+		step := moduleStep{Name: after[:end]}
+		if !moduleNamePattern.MatchString(step.Name) {
+			return nil, fmt.Errorf("invalid module name %q", step.Name)
+		}
+		rest = after[end:]
+		if strings.HasPrefix(rest, "[") {
+			key, n, err := parseInstanceKey(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid instance key of module %q: %w", step.Name, err)
+			}
+			step.Key = key
+			rest = rest[n:]
+		}
+		path = append(path, step)
+		if rest == "" {
+			break
+		}
+		next, ok := strings.CutPrefix(rest, ".")
+		if !ok || next == "" {
+			return nil, fmt.Errorf("unexpected %q after module %q", rest, step.Name)
+		}
+		rest = next
+	}
+	return path, nil
+}
+
+// parseInstanceKey parses the instance key at the start of s, [0] or ["key"], returning the key as the
+// state decoder would (float64 or string) and the number of bytes it took.
+func parseInstanceKey(s string) (interface{}, int, error) {
+	if strings.HasPrefix(s, `["`) {
+		// Find the closing quote, skipping escaped characters
+		for i := 2; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				key, err := strconv.Unquote(s[1 : i+1])
+				if err != nil {
+					return nil, 0, err
+				}
+				if i+1 >= len(s) || s[i+1] != ']' {
+					return nil, 0, fmt.Errorf("missing ']' after %s", s[1:i+1])
+				}
+				return key, i + 2, nil
+			}
+		}
+		return nil, 0, fmt.Errorf("unterminated string in %q", s)
+	}
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return nil, 0, fmt.Errorf("missing ']' in %q", s)
+	}
+	index, err := strconv.Atoi(s[1:end])
+	if err != nil || index < 0 {
+		return nil, 0, fmt.Errorf("%q is neither a string nor a non-negative whole number", s[1:end])
+	}
+	return float64(index), end + 1, nil
+}
+
+// String returns the address of the module instance, "" for the root module.
+func (p modulePath) String() string {
+	var builder strings.Builder
+	for i, step := range p {
+		if i > 0 {
+			builder.WriteByte('.')
+		}
+		builder.WriteString("module.")
+		builder.WriteString(step.Name)
+		builder.WriteString(formatInstanceKey(step.Key))
+	}
+	return builder.String()
+}
+
+// formatInstanceKey renders an instance key as it appears in addresses: [0], ["key"], or "" for none.
+func formatInstanceKey(key interface{}) string {
+	switch k := key.(type) {
+	case nil:
+		return ""
+	case string:
+		return "[" + strconv.Quote(k) + "]"
+	case float64:
+		return fmt.Sprintf("[%d]", int(k))
+	default:
+		return fmt.Sprintf("[%v]", k)
+	}
+}
+
+// within reports whether p is module, one of its instances or a module nested below it. Steps of module
+// without a key match every instance of that module call.
+func (p modulePath) within(module modulePath) bool {
+	if len(module) > len(p) {
+		return false
+	}
+	for i, step := range module {
+		if p[i].Name != step.Name || (step.Key != nil && compareIndexKeys(p[i].Key, step.Key) != 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareModulePaths orders module instances as a depth-first walk of the module tree: a module before
+// the modules nested in it, module calls by name and their instances by key.
+func compareModulePaths(a, b modulePath) int {
+	for i := range min(len(a), len(b)) {
+		if c := strings.Compare(a[i].Name, b[i].Name); c != 0 {
+			return c
+		}
+		if c := compareIndexKeys(a[i].Key, b[i].Key); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// renderModuleSummary renders the result counts of every module instance, as a tree indented by nesting
+// depth. It returns "" when every resource is in the root module, where the summary would add nothing.
+func renderModuleSummary(results *categorizedResults) string {
+	categories := []struct {
+		name     string
+		statuses []ResourceStatus
+	}{
+		{"OK", results.OkResults},
+		{"INFO", results.InfoResults},
+		{"WARNING", results.WarningResults},
+		{"ERROR", results.ErrorResults},
+		{"REGION_MISMATCH", results.RegionMismatchResults},
+		{"POTENTIAL_IMPORT", results.PotentialImportResults},
+		{"DANGEROUS", results.DangerousResults},
+		{"STALE", results.StaleResults},
+		{"PENDING_DELETION", results.PendingDeletionResults},
+		{"SKIPPED", results.SkippedResults},
+	}
+	counts := make(map[string][]int)
+	paths := make(map[string]modulePath)
+	for i, category := range categories {
+		for _, status := range category.statuses {
+			if counts[status.Module] == nil {
+				counts[status.Module] = make([]int, len(categories))
+				paths[status.Module], _ = parseModulePath(status.Module)
+			}
+			counts[status.Module][i]++
+		}
+	}
+	if len(counts) == 0 || (len(counts) == 1 && counts[""] != nil) {
+		return ""
+	}
+
+	modules := make([]string, 0, len(counts))
+	for module := range counts {
+		modules = append(modules, module)
+	}
+	slices.SortFunc(modules, func(a, b string) int { return compareModulePaths(paths[a], paths[b]) })
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n--- RESULTS BY MODULE (%d) ---\n", len(modules)))
+	for _, module := range modules {
+		var parts []string
+		for i, count := range counts[module] {
+			if count > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", count, categories[i].name))
+			}
+		}
+		name := module
+		if name == "" {
+			name = "(root module)"
+		}
+		builder.WriteString(fmt.Sprintf("%s%s: %s\n", strings.Repeat("  ", len(paths[module])), name, strings.Join(parts, ", ")))
+	}
+	return builder.String()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseModulePath(t *testing.T) {
+	tests := []struct {
+		address string
+		want    modulePath
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"module.app", modulePath{{Name: "app"}}, false},
+		{"module.app[0]", modulePath{{Name: "app", Key: float64(0)}}, false},
+		{"module.network.module.subnets[12]", modulePath{{Name: "network"}, {Name: "subnets", Key: float64(12)}}, false},
+		{`module.app["eu.west.1"].module.db`, modulePath{{Name: "app", Key: "eu.west.1"}, {Name: "db"}}, false},
+		{`module.app["a]b"]`, modulePath{{Name: "app", Key: "a]b"}}, false},
+		{`module.app["say \"hi\""]`, modulePath{{Name: "app", Key: `say "hi"`}}, false},
+		{`module.app["a\\"].module.b`, modulePath{{Name: "app", Key: `a\`}, {Name: "b"}}, false},
+		{"module.my-app_2", modulePath{{Name: "my-app_2"}}, false},
+		{"app", nil, true},
+		{"module.", nil, true},
+		{"module.2app", nil, true},
+		{"module.app.", nil, true},
+		{"module.app.aws_instance.web", nil, true},
+		{"module.app[-1]", nil, true},
+		{"module.app[x]", nil, true},
+		{"module.app[0", nil, true},
+		{`module.app["eu`, nil, true},
+		{`module.app["eu"`, nil, true},
+		{`module.app["eu"]x`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := parseModulePath(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseModulePath = %#v, want %#v", got, tt.want)
+			}
+			if err == nil && got.String() != tt.address {
+				t.Errorf("String = %q, want %q", got.String(), tt.address)
+			}
+		})
+	}
+}
+
+func TestModulePathWithin(t *testing.T) {
+	tests := []struct {
+		path, module string
+		want         bool
+	}{
+		{"module.app", "module.app", true},
+		{`module.app["eu.west"]`, "module.app", true},
+		{`module.app["eu.west"].module.db`, "module.app", true},
+		{`module.app["eu.west"].module.db`, `module.app["eu.west"]`, true},
+		{`module.app["us.east"]`, `module.app["eu.west"]`, false},
+		{"module.app[0]", "module.app[1]", false},
+		{"module.app_extra", "module.app", false},
+		{"module.app", "module.app.module.db", false},
+		{"", "module.app", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" in "+tt.module, func(t *testing.T) {
+			path, err := parseModulePath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			module, err := parseModulePath(tt.module)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := path.within(module); got != tt.want {
+				t.Errorf("within = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
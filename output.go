@@ -28,6 +28,15 @@ func printDetailedResultsToStdout(results *categorizedResults) {
 	printCategoryToStdout("REGION MISMATCH Results", results.RegionMismatchResults)
 	printCategoryToStdout("POTENTIAL IMPORT Results", results.PotentialImportResults)
 	printCategoryToStdout("DANGEROUS Results", results.DangerousResults)
+	printCategoryToStdout("STALE Results", results.StaleResults)
+	printCategoryToStdout("PENDING DELETION Results", results.PendingDeletionResults)
+	printCategoryToStdout("SKIPPED Results", results.SkippedResults)
+	fmt.Print(renderSkippedProviders(skippedProviders(results)))
+	fmt.Print(renderModuleSummary(results))
+	fmt.Print(renderFailedChecks(results.FailedChecks))
+	fmt.Print(renderRecentChanges(results.RecentChanges))
+	fmt.Print(renderLinkedStates(results.LinkedStates))
+	fmt.Print(renderPolicyViolations(results.PolicyViolations))
 
 	if len(results.RunCommands) > 0 {
 		fmt.Printf("\n--- SUGGESTED REMEDIATION COMMANDS (%d) ---\n", len(results.RunCommands))
@@ -95,6 +104,15 @@ func sortResults(results *categorizedResults) {
 	sort.Slice(results.RegionMismatchResults, func(i, j int) bool {
 		return results.RegionMismatchResults[i].TerraformAddress < results.RegionMismatchResults[j].TerraformAddress
 	})
+	sort.Slice(results.StaleResults, func(i, j int) bool {
+		return results.StaleResults[i].TerraformAddress < results.StaleResults[j].TerraformAddress
+	})
+	sort.Slice(results.PendingDeletionResults, func(i, j int) bool {
+		return results.PendingDeletionResults[i].TerraformAddress < results.PendingDeletionResults[j].TerraformAddress
+	})
+	sort.Slice(results.SkippedResults, func(i, j int) bool {
+		return results.SkippedResults[i].TerraformAddress < results.SkippedResults[j].TerraformAddress
+	})
 	sort.Strings(results.RunCommands)
 	// Sort command execution logs by command string for consistent output
 	sort.Slice(results.CommandExecutionLogs, func(i, j int) bool {
@@ -161,6 +179,15 @@ func renderResultsToString(
 	printCategoryToBuilder(&builder, "REGION MISMATCH Results", results.RegionMismatchResults)
 	printCategoryToBuilder(&builder, "POTENTIAL IMPORT Results", results.PotentialImportResults)
 	printCategoryToBuilder(&builder, "DANGEROUS Results", results.DangerousResults)
+	printCategoryToBuilder(&builder, "STALE Results", results.StaleResults)
+	printCategoryToBuilder(&builder, "PENDING DELETION Results", results.PendingDeletionResults)
+	printCategoryToBuilder(&builder, "SKIPPED Results", results.SkippedResults)
+	builder.WriteString(renderSkippedProviders(skippedProviders(results)))
+	builder.WriteString(renderModuleSummary(results))
+	builder.WriteString(renderFailedChecks(results.FailedChecks))
+	builder.WriteString(renderRecentChanges(results.RecentChanges))
+	builder.WriteString(renderLinkedStates(results.LinkedStates))
+	builder.WriteString(renderPolicyViolations(results.PolicyViolations))
 
 	if len(results.RunCommands) > 0 {
 		builder.WriteString(fmt.Sprintf("\n--- SUGGESTED REMEDIATION COMMANDS (%d) ---\n", len(results.RunCommands)))
@@ -199,13 +226,17 @@ func convertResourceStatusToJSONItem(statuses []ResourceStatus) []JSONResultItem
 	items := make([]JSONResultItem, len(statuses))
 	for i, s := range statuses {
 		items[i] = JSONResultItem{
-			Kind:     s.Kind,
-			Resource: s.TerraformAddress,
-			TFID:     s.StateID,
-			AWSID:    s.LiveID,
-			Command:  s.Command,
-			Stdout:   s.Stdout, // Correctly populate
-			Stderr:   s.Stderr, // Correctly populate
+			Kind:          s.Kind,
+			Module:        s.Module,
+			Provider:      s.Provider,
+			Type:          s.ResourceType,
+			PlannedAction: s.PlannedAction,
+			Resource:      s.TerraformAddress,
+			TFID:          s.StateID,
+			AWSID:         s.LiveID,
+			Command:       s.Command,
+			Stdout:        s.Stdout, // Correctly populate
+			Stderr:        s.Stderr, // Correctly populate
 		}
 	}
 	return items
@@ -287,6 +318,11 @@ func renderResultsToJson(
 		Backup:         jsonBackupPaths,
 		Commands:       results.RunCommands,
 		ExecutionLogs:  results.CommandExecutionLogs,
+		Uploads:        results.Uploads,
+		PlannedWrites:  results.PlannedWrites,
+		LinkedStates:   results.LinkedStates,
+		FailedChecks:   results.FailedChecks,
+		RecentChanges:  results.RecentChanges,
 		Results: JSONResults{
 			InfoResults:            convertResourceStatusToJSONItem(results.InfoResults),
 			OkResults:              convertResourceStatusToJSONItem(results.OkResults),
@@ -295,9 +331,14 @@ func renderResultsToJson(
 			WarningResults:         convertResourceStatusToJSONItem(results.WarningResults),
 			ErrorResults:           convertResourceStatusToJSONItem(results.ErrorResults),
 			DangerousResults:       convertResourceStatusToJSONItem(results.DangerousResults),
+			StaleResults:           convertResourceStatusToJSONItem(results.StaleResults),
+			PendingDeletionResults: convertResourceStatusToJSONItem(results.PendingDeletionResults),
+			SkippedResults:         convertResourceStatusToJSONItem(results.SkippedResults),
 		},
 		ApplicationError: results.ApplicationError,
 	}
+	jsonOutput.SkippedProviders = skippedProviders(results)
+	jsonOutput.PolicyViolations = results.PolicyViolations
 
 	jsonData, err := json.MarshalIndent(jsonOutput, "", "\t")
 	if err != nil {
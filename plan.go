@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readPlan reads a plan in the JSON form `terraform show -json plan.tfplan` prints.
+func readPlan(path string) (*planJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan '%s': %w", path, err)
+	}
+	var plan planJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan '%s': %w", path, err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("'%s' is not a JSON plan; write one with terraform show -json plan.tfplan", path)
+	}
+	return &plan, nil
+}
+
+// plannedAction names the actions of a planned change: create, update or replace. Other changes (no-ops,
+// reads, deletions and forgets) return "", as there is nothing in AWS that could make them fail.
+func plannedAction(actions []string) string {
+	switch strings.Join(actions, ",") {
+	case "create":
+		return "create"
+	case "update":
+		return "update"
+	case "delete,create", "create,delete":
+		return "replace"
+	}
+	return ""
+}
+
+// plannedState returns the managed resource instances plan creates, updates or replaces, as a copy of
+// tfState to verify in place of its own resources, and the change of each by address. Creations carry
+// their planned values, which lack whatever is only known after apply; updates and replacements carry
+// the values of the object they change.
+func plannedState(plan *planJSON, tfState *TFStateFile) (*TFStateFile, map[string]plannedChange, error) {
+	planned := *tfState
+	planned.Resources = nil
+	changes := make(map[string]plannedChange)
+	resources := make(map[string]int)
+	for _, rc := range plan.ResourceChanges {
+		action := plannedAction(rc.Change.Actions)
+		if action == "" || rc.Mode != "managed" || rc.Deposed != "" {
+			continue
+		}
+		values := rc.Change.Before
+		if action == "create" {
+			values = rc.Change.After
+		}
+		var attributes map[string]interface{}
+		if err := json.Unmarshal(values, &attributes); err != nil {
+			return nil, nil, fmt.Errorf("invalid planned values of %s: %w", rc.Address, err)
+		}
+		if _, err := parseModulePath(rc.ModuleAddress); err != nil {
+			return nil, nil, fmt.Errorf("invalid module address of %s: %w", rc.Address, err)
+		}
+
+		key := rc.ModuleAddress + " " + rc.Type + "." + rc.Name
+		i, ok := resources[key]
+		if !ok {
+			i = len(planned.Resources)
+			resources[key] = i
+			planned.Resources = append(planned.Resources, ResourceStateV4{
+				Module:         rc.ModuleAddress,
+				Mode:           rc.Mode,
+				Type:           rc.Type,
+				Name:           rc.Name,
+				ProviderConfig: fmt.Sprintf("provider[%q]", rc.ProviderName),
+			})
+		}
+		instance := InstanceObjectStateV4{AttributesRaw: values, IndexKey: rc.Index}
+		planned.Resources[i].Instances = append(planned.Resources[i].Instances, instance)
+		changes[resourceInstanceAddress(planned.Resources[i], instance)] = plannedChange{Attributes: attributes, Action: action}
+	}
+	return &planned, changes, nil
+}
+
+// reconcilePlan recategorizes the results of verifying a plannedState by what the plan will do to each
+// resource instance.
+func reconcilePlan(results *categorizedResults, changes map[string]plannedChange) *categorizedResults {
+	var statuses []ResourceStatus
+	for _, category := range [][]ResourceStatus{
+		results.InfoResults, results.OkResults, results.WarningResults, results.ErrorResults,
+		results.RegionMismatchResults, results.PotentialImportResults, results.DangerousResults,
+		results.StaleResults, results.PendingDeletionResults, results.SkippedResults,
+	} {
+		statuses = append(statuses, category...)
+	}
+	for i, status := range statuses {
+		if change, ok := changes[status.TerraformAddress]; ok {
+			statuses[i] = plannedStatus(status, change)
+		}
+	}
+	return categorizeResults(statuses)
+}
+
+// plannedStatus turns the result of verifying a planned change into what it means for the apply:
+// a creation whose object already exists needs an import first, and an update or replacement of an
+// object that no longer exists will not do what the plan says.
+func plannedStatus(status ResourceStatus, change plannedChange) ResourceStatus {
+	status.PlannedAction = change.Action
+	address := status.TerraformAddress
+	if change.Action != "create" {
+		if status.Category == "DANGEROUS" {
+			status.Message = fmt.Sprintf("%s is planned to be %sd but NOT FOUND in AWS (ID: %s). Remove it from the state and plan again.", address, change.Action, status.StateID)
+		}
+		return status
+	}
+	switch {
+	case status.Category == "OK" || status.Category == "POTENTIAL_IMPORT":
+		status.Category = "POTENTIAL_IMPORT"
+		status.Message = fmt.Sprintf("%s is planned to be created but already exists in AWS with ID '%s'. Import it before applying.", address, status.LiveID)
+		status.Command = fmt.Sprintf("terraform import %s %s", address, importID(status.ResourceType, change.Attributes, status.LiveID))
+	case status.Category == "DANGEROUS":
+		status.Category = "OK"
+		status.Message = fmt.Sprintf("%s is planned to be created and does not exist in AWS yet.", address)
+		status.Command = ""
+	case status.Category == "ERROR" && status.Error != nil && strings.HasPrefix(status.Error.Error(), "could not find"):
+		// The verifier needs a value, usually the ID, that is only known after apply.
+		status.Category = "INFO"
+		status.Message = fmt.Sprintf("%s is planned to be created; it cannot be looked up before apply (%v).", address, status.Error)
+	}
+	return status
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// policyRules are the rules every --policy package may define, each a set of messages, by the level of
+// the violations they report. Only deny violations fail the run.
+var policyRules = []string{"deny", "warn"}
+
+// loadPolicies parses the Rego files at paths, which are files or directories searched recursively for
+// .rego files other than _test.rego ones, so a broken policy stops the run before anything is verified.
+func loadPolicies(paths []string) ([]*ast.Module, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if file == path && !entry.IsDir() {
+				files = append(files, file)
+			} else if !entry.IsDir() && strings.HasSuffix(file, ".rego") && !strings.HasSuffix(file, "_test.rego") {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policies '%s': %w", path, err)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .rego files in %s", strings.Join(paths, ", "))
+	}
+	modules := make([]*ast.Module, 0, len(files))
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy '%s': %w", file, err)
+		}
+		module, err := ast.ParseModule(file, string(source))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy '%s': %w", file, err)
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// policyInput is the document policies are evaluated against: the state, the region and the results
+// and suggested commands as they appear in the JSON report.
+func policyInput(config Config, tfStateFile *TFStateFile, results *categorizedResults) map[string]interface{} {
+	state := config.StateFilePath
+	if config.IsS3State {
+		state = config.S3State
+	}
+	return map[string]interface{}{
+		"state":    state,
+		"lineage":  tfStateFile.Lineage,
+		"region":   config.AWSRegion,
+		"results":  jsonResults(results),
+		"commands": results.RunCommands,
+	}
+}
+
+// evaluatePolicies evaluates the deny and warn rules of every package of modules against input and
+// returns their messages, ordered by package, level and message.
+func evaluatePolicies(ctx context.Context, modules []*ast.Module, input map[string]interface{}) ([]policyViolation, error) {
+	options := make([]func(*rego.Rego), 0, len(modules)+2)
+	var packages []string
+	for _, module := range modules {
+		options = append(options, rego.ParsedModule(module))
+		if name := module.Package.Path.String(); !slices.Contains(packages, name) {
+			packages = append(packages, name)
+		}
+	}
+	slices.Sort(packages)
+
+	var violations []policyViolation
+	for _, name := range packages {
+		rs, err := rego.New(append(options, rego.Query(name), rego.Input(input))...).Eval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate policy %s: %w", name, err)
+		}
+		if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+			continue
+		}
+		document, _ := rs[0].Expressions[0].Value.(map[string]interface{})
+		for _, level := range policyRules {
+			messages, _ := document[level].([]interface{})
+			for _, message := range messages {
+				violations = append(violations, policyViolation{
+					Policy:  strings.TrimPrefix(name, "data."),
+					Level:   level,
+					Message: policyMessage(message),
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// policyMessage renders a deny or warn message, which is a string or, as conftest allows, an object
+// with a msg.
+func policyMessage(message interface{}) string {
+	switch m := message.(type) {
+	case string:
+		return m
+	case map[string]interface{}:
+		if msg, ok := m["msg"].(string); ok {
+			return msg
+		}
+	}
+	return fmt.Sprint(message)
+}
+
+// deniedPolicies returns the deny violations, which fail the run.
+func deniedPolicies(violations []policyViolation) []policyViolation {
+	var denied []policyViolation
+	for _, violation := range violations {
+		if violation.Level == "deny" {
+			denied = append(denied, violation)
+		}
+	}
+	return denied
+}
+
+// renderPolicyViolations renders the messages of the deny and warn rules of --policy.
+func renderPolicyViolations(violations []policyViolation) string {
+	if len(violations) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n--- POLICY VIOLATIONS (%d) ---\n", len(violations)))
+	for _, violation := range violations {
+		builder.WriteString(fmt.Sprintf("%s [%s]: %s\n", strings.ToUpper(violation.Level), violation.Policy, violation.Message))
+	}
+	return builder.String()
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// requiredActions lists the read-only IAM actions each verifier needs. Add an entry alongside every
+// new case in processResourceInstance so the preflight check covers it.
+var requiredActions = map[string][]string{
+	"aws_s3_bucket":                              {"s3:ListBucket"},
+	"aws_s3_bucket_policy":                       {"s3:GetBucketPolicy"},
+	"aws_s3_bucket_acl":                          {"s3:GetBucketAcl"},
+	"aws_s3_bucket_ownership_controls":           {"s3:GetBucketOwnershipControls"},
+	"aws_s3_bucket_public_access_block":          {"s3:GetBucketPublicAccessBlock"},
+	"aws_s3_bucket_website_configuration":        {"s3:GetBucketWebsite"},
+	"aws_s3_bucket_cors_configuration":           {"s3:GetBucketCORS"},
+	"aws_s3_bucket_notification":                 {"s3:GetBucketNotification"},
+	"aws_s3_object":                              {"s3:GetObject"},
+	"aws_cloudwatch_log_group":                   {"logs:DescribeLogGroups"},
+	"aws_cloudwatch_metric_alarm":                {"cloudwatch:DescribeAlarms"},
+	"aws_key_pair":                               {"ec2:DescribeKeyPairs"},
+	"aws_security_group":                         {"ec2:DescribeSecurityGroups"},
+	"aws_security_group_rule":                    {"ec2:DescribeSecurityGroupRules"},
+	"aws_ami":                                    {"ec2:DescribeImages"},
+	"aws_eip":                                    {"ec2:DescribeAddresses"},
+	"aws_internet_gateway":                       {"ec2:DescribeInternetGateways"},
+	"aws_nat_gateway":                            {"ec2:DescribeNatGateways"},
+	"aws_route":                                  {"ec2:DescribeRouteTables"},
+	"aws_route_table":                            {"ec2:DescribeRouteTables"},
+	"aws_route_table_association":                {"ec2:DescribeRouteTables"},
+	"aws_subnet":                                 {"ec2:DescribeSubnets"},
+	"aws_vpc":                                    {"ec2:DescribeVpcs"},
+	"aws_instance":                               {"ec2:DescribeInstances"},
+	"aws_launch_template":                        {"ec2:DescribeLaunchTemplates"},
+	"aws_route53_zone":                           {"route53:GetHostedZone", "route53:ListHostedZonesByName"},
+	"aws_route53_record":                         {"route53:ListResourceRecordSets"},
+	"aws_lb":                                     {"elasticloadbalancing:DescribeLoadBalancers"},
+	"aws_lb_listener":                            {"elasticloadbalancing:DescribeListeners"},
+	"aws_lb_target_group":                        {"elasticloadbalancing:DescribeTargetGroups"},
+	"aws_lb_listener_rule":                       {"elasticloadbalancing:DescribeRules"},
+	"aws_lb_listener_certificate":                {"elasticloadbalancing:DescribeListenerCertificates"},
+	"aws_acm_certificate":                        {"acm:DescribeCertificate"},
+	"aws_acm_certificate_validation":             {"acm:DescribeCertificate"},
+	"aws_ecs_cluster":                            {"ecs:DescribeClusters"},
+	"aws_ecs_service":                            {"ecs:DescribeServices"},
+	"aws_ecs_task_definition":                    {"ecs:DescribeTaskDefinition"},
+	"aws_ssm_parameter":                          {"ssm:GetParameter"},
+	"aws_secretsmanager_secret":                  {"secretsmanager:DescribeSecret"},
+	"aws_secretsmanager_secret_version":          {"secretsmanager:GetSecretValue"},
+	"aws_autoscaling_group":                      {"autoscaling:DescribeAutoScalingGroups"},
+	"aws_autoscaling_policy":                     {"autoscaling:DescribePolicies"},
+	"aws_iam_instance_profile":                   {"iam:GetInstanceProfile"},
+	"aws_iam_role":                               {"iam:GetRole"},
+	"aws_iam_role_policy":                        {"iam:GetRolePolicy"},
+	"aws_lambda_function":                        {"lambda:GetFunction"},
+	"aws_lambda_permission":                      {"lambda:GetPolicy"},
+	"aws_cloudfront_distribution":                {"cloudfront:GetDistribution"},
+	"aws_cloudfront_origin_access_identity":      {"cloudfront:GetCloudFrontOriginAccessIdentity"},
+	"aws_db_instance":                            {"rds:DescribeDBInstances"},
+	"aws_rds_cluster":                            {"rds:DescribeDBClusters"},
+	"aws_rds_cluster_instance":                   {"rds:DescribeDBInstances"},
+	"aws_dynamodb_table":                         {"dynamodb:DescribeTable"},
+	"aws_dynamodb_table_item":                    {"dynamodb:GetItem"},
+	"aws_dynamodb_global_table":                  {"dynamodb:DescribeGlobalTable"},
+	"aws_sqs_queue":                              {"sqs:GetQueueUrl", "sqs:GetQueueAttributes"},
+	"aws_sqs_queue_policy":                       {"sqs:GetQueueUrl", "sqs:GetQueueAttributes"},
+	"aws_sns_topic":                              {"sns:GetTopicAttributes"},
+	"aws_sns_topic_policy":                       {"sns:GetTopicAttributes"},
+	"aws_sns_topic_subscription":                 {"sns:GetSubscriptionAttributes", "sns:ListSubscriptionsByTopic"},
+	"aws_kms_key":                                {"kms:DescribeKey"},
+	"aws_kms_alias":                              {"kms:ListAliases", "kms:DescribeKey"},
+	"aws_kms_key_policy":                         {"kms:DescribeKey", "kms:GetKeyPolicy"},
+	"aws_eks_cluster":                            {"eks:DescribeCluster"},
+	"aws_eks_node_group":                         {"eks:DescribeNodegroup"},
+	"aws_eks_addon":                              {"eks:DescribeAddon"},
+	"aws_eks_fargate_profile":                    {"eks:DescribeFargateProfile"},
+	"aws_elasticache_cluster":                    {"elasticache:DescribeCacheClusters"},
+	"aws_elasticache_replication_group":          {"elasticache:DescribeReplicationGroups"},
+	"aws_elasticache_subnet_group":               {"elasticache:DescribeCacheSubnetGroups"},
+	"aws_elasticache_parameter_group":            {"elasticache:DescribeCacheParameterGroups"},
+	"aws_ecr_repository":                         {"ecr:DescribeRepositories"},
+	"aws_ecr_lifecycle_policy":                   {"ecr:GetLifecyclePolicy"},
+	"aws_ecr_repository_policy":                  {"ecr:GetRepositoryPolicy"},
+	"aws_api_gateway_rest_api":                   {"apigateway:GET"},
+	"aws_api_gateway_stage":                      {"apigateway:GET"},
+	"aws_api_gateway_deployment":                 {"apigateway:GET"},
+	"aws_api_gateway_resource":                   {"apigateway:GET"},
+	"aws_api_gateway_method":                     {"apigateway:GET"},
+	"aws_api_gateway_integration":                {"apigateway:GET"},
+	"aws_apigatewayv2_api":                       {"apigateway:GET"},
+	"aws_apigatewayv2_stage":                     {"apigateway:GET"},
+	"aws_apigatewayv2_route":                     {"apigateway:GET"},
+	"aws_apigatewayv2_integration":               {"apigateway:GET"},
+	"aws_apigatewayv2_domain_name":               {"apigateway:GET"},
+	"aws_sfn_state_machine":                      {"states:DescribeStateMachine"},
+	"aws_sfn_activity":                           {"states:DescribeActivity"},
+	"aws_cloudwatch_event_bus":                   {"events:DescribeEventBus"},
+	"aws_cloudwatch_event_rule":                  {"events:DescribeRule"},
+	"aws_cloudwatch_event_target":                {"events:ListTargetsByRule"},
+	"aws_kinesis_stream":                         {"kinesis:DescribeStreamSummary"},
+	"aws_kinesis_stream_consumer":                {"kinesis:DescribeStreamConsumer"},
+	"aws_kinesis_firehose_delivery_stream":       {"firehose:DescribeDeliveryStream"},
+	"aws_efs_file_system":                        {"elasticfilesystem:DescribeFileSystems"},
+	"aws_efs_mount_target":                       {"elasticfilesystem:DescribeMountTargets"},
+	"aws_efs_access_point":                       {"elasticfilesystem:DescribeAccessPoints"},
+	"aws_ebs_volume":                             {"ec2:DescribeVolumes"},
+	"aws_volume_attachment":                      {"ec2:DescribeVolumes"},
+	"aws_ebs_snapshot":                           {"ec2:DescribeSnapshots"},
+	"aws_ebs_snapshot_copy":                      {"ec2:DescribeSnapshots"},
+	"aws_dlm_lifecycle_policy":                   {"dlm:GetLifecyclePolicy"},
+	"aws_vpc_endpoint":                           {"ec2:DescribeVpcEndpoints"},
+	"aws_vpc_endpoint_service":                   {"ec2:DescribeVpcEndpointServiceConfigurations"},
+	"aws_vpc_endpoint_route_table_association":   {"ec2:DescribeVpcEndpoints"},
+	"aws_vpc_peering_connection":                 {"ec2:DescribeVpcPeeringConnections"},
+	"aws_vpc_peering_connection_accepter":        {"ec2:DescribeVpcPeeringConnections"},
+	"aws_vpc_peering_connection_options":         {"ec2:DescribeVpcPeeringConnections"},
+	"aws_ec2_transit_gateway":                    {"ec2:DescribeTransitGateways"},
+	"aws_ec2_transit_gateway_vpc_attachment":     {"ec2:DescribeTransitGatewayVpcAttachments"},
+	"aws_ec2_transit_gateway_peering_attachment": {"ec2:DescribeTransitGatewayPeeringAttachments"},
+	"aws_network_acl":                            {"ec2:DescribeNetworkAcls"},
+	"aws_network_acl_rule":                       {"ec2:DescribeNetworkAcls"},
+	"aws_network_acl_association":                {"ec2:DescribeNetworkAcls"},
+	"aws_flow_log":                               {"ec2:DescribeFlowLogs", "s3:ListBucket", "logs:DescribeLogGroups", "firehose:DescribeDeliveryStream"},
+	"aws_vpc_dhcp_options":                       {"ec2:DescribeDhcpOptions"},
+	"aws_vpc_dhcp_options_association":           {"ec2:DescribeVpcs"},
+	"aws_wafv2_web_acl":                          {"wafv2:GetWebACL"},
+	"aws_wafv2_rule_group":                       {"wafv2:GetRuleGroup"},
+	"aws_wafv2_ip_set":                           {"wafv2:GetIPSet"},
+	"aws_wafv2_web_acl_association":              {"wafv2:GetWebACLForResource"},
+	"aws_cloudtrail":                             {"cloudtrail:GetTrail", "cloudtrail:GetTrailStatus"},
+	"aws_config_configuration_recorder":          {"config:DescribeConfigurationRecorders"},
+	"aws_config_delivery_channel":                {"config:DescribeDeliveryChannels"},
+	"aws_config_config_rule":                     {"config:DescribeConfigRules"},
+	"aws_guardduty_detector":                     {"guardduty:ListDetectors", "guardduty:GetDetector"},
+	"aws_guardduty_filter":                       {"guardduty:ListDetectors", "guardduty:GetFilter"},
+	"aws_guardduty_member":                       {"guardduty:ListDetectors", "guardduty:GetMembers"},
+	"aws_ses_domain_identity":                    {"ses:GetEmailIdentity"},
+	"aws_ses_email_identity":                     {"ses:GetEmailIdentity"},
+	"aws_ses_configuration_set":                  {"ses:GetConfigurationSet"},
+	"aws_ses_receipt_rule":                       {"ses:DescribeReceiptRule"},
+	"aws_cognito_user_pool":                      {"cognito-idp:DescribeUserPool"},
+	"aws_cognito_user_pool_client":               {"cognito-idp:DescribeUserPoolClient"},
+	"aws_cognito_user_pool_domain":               {"cognito-idp:DescribeUserPoolDomain"},
+	"aws_cognito_resource_server":                {"cognito-idp:DescribeResourceServer"},
+	"aws_cognito_identity_pool":                  {"cognito-identity:DescribeIdentityPool"},
+	"aws_cognito_identity_pool_roles_attachment": {"cognito-identity:GetIdentityPoolRoles"},
+	"aws_appsync_graphql_api":                    {"appsync:GetGraphqlApi"},
+	"aws_appsync_datasource":                     {"appsync:GetDataSource"},
+	"aws_appsync_resolver":                       {"appsync:GetResolver"},
+	"aws_appsync_api_key":                        {"appsync:ListApiKeys"},
+	"aws_codebuild_project":                      {"codebuild:BatchGetProjects"},
+	"aws_codebuild_source_credential":            {"codebuild:ListSourceCredentials"},
+	"aws_codebuild_webhook":                      {"codebuild:BatchGetProjects"},
+	"aws_codepipeline":                           {"codepipeline:GetPipeline"},
+	"aws_codepipeline_webhook":                   {"codepipeline:ListWebhooks"},
+	"aws_codedeploy_app":                         {"codedeploy:GetApplication"},
+	"aws_codedeploy_deployment_group":            {"codedeploy:GetDeploymentGroup"},
+	"aws_batch_compute_environment":              {"batch:DescribeComputeEnvironments"},
+	"aws_batch_job_queue":                        {"batch:DescribeJobQueues"},
+	"aws_batch_job_definition":                   {"batch:DescribeJobDefinitions"},
+	"aws_msk_cluster":                            {"kafka:DescribeClusterV2"},
+	"aws_msk_configuration":                      {"kafka:DescribeConfiguration"},
+	"aws_mq_broker":                              {"mq:DescribeBroker"},
+	"aws_mq_configuration":                       {"mq:DescribeConfiguration"},
+	"aws_neptune_cluster":                        {"rds:DescribeDBClusters"},
+	"aws_neptune_cluster_instance":               {"rds:DescribeDBInstances"},
+	"aws_neptune_subnet_group":                   {"rds:DescribeDBSubnetGroups"},
+	"aws_docdb_cluster":                          {"rds:DescribeDBClusters"},
+	"aws_docdb_cluster_instance":                 {"rds:DescribeDBInstances"},
+	"aws_docdb_subnet_group":                     {"rds:DescribeDBSubnetGroups"},
+	"aws_opensearch_domain":                      {"es:DescribeDomain"},
+	"aws_opensearch_domain_policy":               {"es:DescribeDomain"},
+	"aws_elasticsearch_domain":                   {"es:DescribeElasticsearchDomain"},
+	"aws_elasticsearch_domain_policy":            {"es:DescribeElasticsearchDomain"},
+	"aws_redshift_cluster":                       {"redshift:DescribeClusters"},
+	"aws_redshift_subnet_group":                  {"redshift:DescribeClusterSubnetGroups"},
+	"aws_redshift_parameter_group":               {"redshift:DescribeClusterParameterGroups"},
+}
+
+// callerIdentity is a cached sts:GetCallerIdentity, shared by the startup diagnostics and the preflight check.
+func (c *AWSClient) callerIdentity(ctx context.Context) (*sts.GetCallerIdentityOutput, error) {
+	return cachedCall(ctx, c, cacheKey("sts", "GetCallerIdentity"), func() (*sts.GetCallerIdentityOutput, error) {
+		return c.STSClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	})
+}
+
+// runPreflight checks, before any resource is verified, that the caller may perform the read-only
+// actions needed for the resource types in the state. Denied actions are logged together with the
+// types they affect and returned keyed by resource type, so those resources can be reported as
+// ERROR without a call each. Problems with the check itself only produce a warning.
+func runPreflight(ctx context.Context, clients *AWSClient, tfState *TFStateFile) map[string][]string {
+	identity, err := clients.callerIdentity(ctx)
+	if err != nil {
+		log.Printf("WARNING: Preflight skipped, could not resolve the caller identity: %v", err)
+		return nil
+	}
+	principalARN, err := simulationPrincipal(ctx, clients, aws.ToString(identity.Arn))
+	if err != nil {
+		log.Printf("WARNING: Preflight skipped: %v", err)
+		return nil
+	}
+
+	typesByAction := make(map[string][]string)
+	for _, resource := range tfState.Resources {
+		if resource.Mode == "data" {
+			continue
+		}
+		for _, action := range requiredActions[resource.Type] {
+			if !slices.Contains(typesByAction[action], resource.Type) {
+				typesByAction[action] = append(typesByAction[action], resource.Type)
+			}
+		}
+	}
+	if len(typesByAction) == 0 {
+		return nil
+	}
+	actions := make([]string, 0, len(typesByAction))
+	for action := range typesByAction {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	denied := make(map[string][]string)
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(clients.IAMClient, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalARN),
+		ActionNames:     actions,
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Printf("WARNING: Preflight skipped, could not simulate permissions for '%s' (this needs iam:SimulatePrincipalPolicy): %v", principalARN, err)
+			return nil
+		}
+		for _, result := range resp.EvaluationResults {
+			if result.EvalDecision == iamtypes.PolicyEvaluationDecisionTypeAllowed {
+				continue
+			}
+			action := aws.ToString(result.EvalActionName)
+			for _, resourceType := range typesByAction[action] {
+				denied[resourceType] = append(denied[resourceType], action)
+			}
+			log.Printf("WARNING: Preflight: %s is not allowed to %s (%s), needed for %s", principalARN, action, result.EvalDecision, strings.Join(typesByAction[action], ", "))
+		}
+	}
+	if len(denied) == 0 {
+		log.Printf("Preflight: %s has every permission needed for the %d actions this state requires", principalARN, len(actions))
+	}
+	return denied
+}
+
+// simulationPrincipal turns a caller identity ARN into the IAM principal SimulatePrincipalPolicy
+// accepts: assumed-role sessions are mapped back to their role, looked up to get its path.
+func simulationPrincipal(ctx context.Context, clients *AWSClient, callerARN string) (string, error) {
+	parts := strings.Split(callerARN, ":")
+	if len(parts) < 6 {
+		return "", fmt.Errorf("unrecognized caller ARN '%s'", callerARN)
+	}
+	resource := parts[5]
+	switch {
+	case strings.HasPrefix(resource, "assumed-role/"):
+		roleName := strings.Split(strings.TrimPrefix(resource, "assumed-role/"), "/")[0]
+		role, err := clients.IAMClient.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+		if err != nil {
+			return "", fmt.Errorf("could not look up role '%s' of the current session: %w", roleName, err)
+		}
+		return aws.ToString(role.Role.Arn), nil
+	case strings.HasPrefix(resource, "user/"), strings.HasPrefix(resource, "role/"):
+		return callerARN, nil
+	default:
+		return "", fmt.Errorf("permissions of '%s' cannot be simulated", callerARN)
+	}
+}
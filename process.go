@@ -1,48 +1,108 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // processResources concurrently processes each resource instance in the Terraform state file
 // and returns categorized results.
-func processResources(ctx context.Context, awsClients *AWSClient, tfState *TFStateFile, awsRegion string, concurrency int) *categorizedResults {
-	resultsChan := make(chan ResourceStatus, concurrency)
-	var wg sync.WaitGroup
+// Results already present in checkpoint are reused instead of being verified again, and every
+// newly finished result is appended to it.
+// A non-zero apiTimeout bounds the AWS calls made for each resource instance.
+func processResources(ctx context.Context, awsClients *AWSClient, tfState *TFStateFile, awsRegion string, concurrency int, apiTimeout time.Duration, checkpoint *checkpointStore, incremental *incrementalStore) *categorizedResults {
 	var regionMismatchErrors atomic.Int64
 
-	if len(tfState.Resources) > 0 {
-		for _, resource := range tfState.Resources {
-			for _, instance := range resource.Instances {
-				wg.Add(1)
-				go func(res ResourceStateV4, inst InstanceObjectStateV4) {
-					defer wg.Done()
-					status := processResourceInstance(ctx, awsClients, res, inst, awsRegion, &regionMismatchErrors)
-					// Determine Kind for JSON output
-					// CORRECTED: Access res.Mode
-					if res.Mode == "data" {
-						status.Kind = "data"
-					} else {
-						status.Kind = "resource" // Default to resource
-					}
-					resultsChan <- status
-				}(resource, instance)
-			}
+	// Resolve batchable resource types with bulk calls up front; verifiers consult the results.
+	prefetchBatches(ctx, awsClients, tfState)
+
+	// Jobs are numbered in state order and scheduled in that order; results are placed back by index
+	// so the outcome does not depend on which worker finished first.
+	var jobs []resourceJob
+	for _, resource := range tfState.Resources {
+		for _, instance := range resource.Instances {
+			jobs = append(jobs, resourceJob{resource: resource, instance: instance, index: len(jobs)})
 		}
 	}
+	statuses := make([]ResourceStatus, len(jobs))
+
+	pending := make(chan resourceJob)
+	finished := make(chan resourceJob, concurrency)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range pending {
+				job.status = runResourceJob(ctx, awsClients, job, awsRegion, &regionMismatchErrors, apiTimeout)
+				finished <- job
+			}
+		}()
+	}
 
 	go func() {
-		wg.Wait()
-		close(resultsChan)
+		defer close(pending)
+		for _, job := range jobs {
+			address := resourceInstanceAddress(job.resource, job.instance)
+			if status, ok := checkpoint.completed(address); ok {
+				job.status = status
+				job.resumed = true
+				finished <- job
+				continue
+			}
+			if status, ok := incremental.fresh(address, job.resource, job.instance); ok {
+				job.status = status
+				job.resumed = true
+				finished <- job
+				continue
+			}
+			if ctx.Err() != nil {
+				// Nothing more will be verified; report the rest without waiting for a worker.
+				job.status = skippedStatus(job.resource, job.instance, ctx.Err())
+				finished <- job
+				continue
+			}
+			select {
+			case pending <- job:
+			case <-ctx.Done():
+				job.status = skippedStatus(job.resource, job.instance, ctx.Err())
+				finished <- job
+			}
+		}
 	}()
 
+	// Every job produces exactly one result, so the collector knows when it is done.
+	for done := range len(jobs) {
+		job := <-finished
+		if !job.resumed && job.status.Category != "SKIPPED" {
+			if job.status.Category != "ERROR" { // Like skipped resources, errors are verified again on --resume
+				checkpoint.record(job.status)
+			}
+			incremental.record(job.resource, job.instance, job.status)
+		}
+		job.status.Module = job.resource.Module
+		job.status.Provider = resourceProvider(job.resource)
+		statuses[job.index] = job.status
+		if globalProgress != nil {
+			globalProgress(done+1, len(jobs), job.status)
+		}
+	}
+	wg.Wait()
+	return categorizeResults(statuses)
+}
+
+// categorizeResults sorts statuses into their categories, collecting the suggested commands in order.
+func categorizeResults(statuses []ResourceStatus) *categorizedResults {
 	results := &categorizedResults{}
-	for status := range resultsChan {
+	for _, status := range statuses {
 		// CORRECTED: Access status.Category
 		switch status.Category {
 		case "INFO":
@@ -68,28 +128,98 @@ func processResources(ctx context.Context, awsClients *AWSClient, tfState *TFSta
 			if status.Command != "" {
 				results.RunCommands = append(results.RunCommands, status.Command)
 			}
+		case "STALE":
+			results.StaleResults = append(results.StaleResults, status)
+			if status.Command != "" {
+				results.RunCommands = append(results.RunCommands, status.Command)
+			}
+		case "PENDING_DELETION":
+			results.PendingDeletionResults = append(results.PendingDeletionResults, status)
+			if status.Command != "" {
+				results.RunCommands = append(results.RunCommands, status.Command)
+			}
+		case "SKIPPED":
+			results.SkippedResults = append(results.SkippedResults, status)
 		}
 	}
 	return results
 }
 
+// runResourceJob verifies one resource instance, reporting it as SKIPPED when the run is stopped
+// before or while it is checked.
+func runResourceJob(ctx context.Context, clients *AWSClient, job resourceJob, awsRegion string, regionMismatchCount *atomic.Int64, apiTimeout time.Duration) ResourceStatus {
+	if ctx.Err() != nil {
+		return skippedStatus(job.resource, job.instance, ctx.Err())
+	}
+	started := time.Now()
+	status := processResourceInstanceWithTimeout(ctx, clients, job.resource, job.instance, awsRegion, regionMismatchCount, apiTimeout)
+	if ctx.Err() != nil && status.Category == "ERROR" {
+		// The run deadline interrupted this resource; its error says nothing about AWS.
+		status = skippedStatus(job.resource, job.instance, ctx.Err())
+	}
+	status.ResourceType = job.resource.Type
+	status.Elapsed = time.Since(started)
+	// Determine Kind for JSON output
+	if job.resource.Mode == "data" {
+		status.Kind = "data"
+	} else {
+		status.Kind = "resource" // Default to resource
+	}
+	return status
+}
+
+// processResourceInstanceWithTimeout runs processResourceInstance with its AWS calls bounded by timeout.
+// A resource whose calls time out is retried once before being reported as ERROR(timeout).
+func processResourceInstanceWithTimeout(ctx context.Context, clients *AWSClient, resource ResourceStateV4, instance InstanceObjectStateV4, currentFlagRegion string, regionMismatchCount *atomic.Int64, timeout time.Duration) ResourceStatus {
+	if timeout <= 0 {
+		return processResourceInstance(ctx, clients, resource, instance, currentFlagRegion, regionMismatchCount)
+	}
+
+	var status ResourceStatus
+	for attempt := 1; attempt <= 2; attempt++ {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		status = processResourceInstance(callCtx, clients, resource, instance, currentFlagRegion, regionMismatchCount)
+		cancel()
+		if !isTimeoutError(status.Error) || ctx.Err() != nil {
+			return status
+		}
+	}
+	status.Message = fmt.Sprintf("ERROR(timeout): %s did not respond within %s after a retry: %v", status.TerraformAddress, timeout, status.Error)
+	return status
+}
+
+// skippedStatus reports a resource instance that was never verified because the run was cut short.
+func skippedStatus(resource ResourceStateV4, instance InstanceObjectStateV4, reason error) ResourceStatus {
+	tfAddress := resourceInstanceAddress(resource, instance)
+	stateID := instanceStringAttribute(instance, "id")
+	kind := "resource"
+	if resource.Mode == "data" {
+		kind = "data"
+	}
+	return ResourceStatus{
+		TerraformAddress: tfAddress,
+		Category:         "SKIPPED",
+		Message:          fmt.Sprintf("%s was not verified before the run was stopped (%v).", tfAddress, reason),
+		Kind:             kind,
+		StateID:          stateID,
+		TFID:             stateID,
+	}
+}
+
+// isTimeoutError reports whether err was caused by a context deadline.
+func isTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), context.DeadlineExceeded.Error())
+}
+
 // processResourceInstance checks a single Terraform resource instance against AWS
 // It now accepts the ResourceStateV4 and InstanceObjectStateV4 from the copied types.
 func processResourceInstance(ctx context.Context, clients *AWSClient, resource ResourceStateV4, instance InstanceObjectStateV4, currentFlagRegion string, regionMismatchCount *atomic.Int64) ResourceStatus {
-	tfAddress := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
-	if resource.Module != "" {
-		tfAddress = fmt.Sprintf("%s.%s", resource.Module, tfAddress)
-	}
-	// For instances with IndexKey (e.g., count, for_each), append it to the address
-	if instance.IndexKey != nil {
-		switch v := instance.IndexKey.(type) {
-		case string:
-			tfAddress = fmt.Sprintf("%s[\"%s\"]", tfAddress, v)
-		case float64: // JSON numbers unmarshal to float64 by default
-			tfAddress = fmt.Sprintf("%s[%d]", tfAddress, int(v))
-		default:
-			tfAddress = fmt.Sprintf("%s[%v]", tfAddress, v) // Fallback for other types
-		}
+	tfAddress := resourceInstanceAddress(resource, instance)
+	if provider := resourceProvider(resource); !verifiedProviders[provider] {
+		return providerSkippedStatus(resource, tfAddress, instanceStringAttribute(instance, "id"), provider)
 	}
 
 	var attributes map[string]interface{}
@@ -115,6 +245,7 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 	stateID, _ := attributes["id"].(string) // Get ID from attributes map
 
 	status := ResourceStatus{TerraformAddress: tfAddress, StateID: stateID}
+	status.ARN, _ = attributes["arn"].(string)
 	status.Kind = resource.Mode // CORRECTED: Access resource.Mode
 
 	// Common ARN attribute for region check (extracted here for all ARN-based resources)
@@ -154,8 +285,8 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 	// --- REGION MISMATCH PRE-CHECK: Centralized Logic ---
 	// If an ARN is present and its region doesn't match the current flagged region,
 	// immediately categorize it as REGION_MISMATCH without making an AWS API call.
-	// This prevents API errors from cross-region calls.
-	if arnInState != "" {
+	// This prevents API errors from cross-region calls. Global WAFv2 resources are not regional.
+	if arnInState != "" && !isGlobalWAFV2ARN(arnInState) {
 		stateRegionFromARN := extractRegionFromARN(arnInState)
 		if stateRegionFromARN != "" && stateRegionFromARN != currentFlagRegion {
 			regionMismatchCount.Add(1)
@@ -168,6 +299,17 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 		}
 	}
 
+	// Resources owned by an account in --account-roles are verified with that account's role.
+	clients = clients.forAccount(resourceAccountID(arnInState, attributes))
+
+	if denied := clients.DeniedActions[resource.Type]; len(denied) > 0 {
+		status.Category = "ERROR"
+		status.Error = fmt.Errorf("missing permission %s", strings.Join(denied, ", "))
+		status.Message = fmt.Sprintf("Cannot verify %s: the preflight check found %s denied.", tfAddress, strings.Join(denied, ", "))
+		status.TFID = stateID
+		return status
+	}
+
 	var liveID string
 	var exists bool
 	var err error
@@ -249,11 +391,32 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 		if sgRuleAWSID, ok := attributes["security_group_rule_id"].(string); ok && sgRuleAWSID != "" {
 			liveID, exists, err = clients.verifySecurityGroupRule(ctx, sgRuleAWSID)
 		} else {
-			status.Category = "WARNING" // CORRECTED: Set Category
-			status.Message = fmt.Sprintf("Resource type '%s' (ID: %s) verification is complex and 'security_group_rule_id' not found in state attributes. Manual verification recommended.", resource.Type, stateID)
-			status.TFID = stateID
-			status.AWSID = liveID
-			return status
+			// Older states lack security_group_rule_id, so match the rule by its contents instead.
+			tuple := securityGroupRuleTuple{
+				CIDRBlocks:     stringSliceAttribute(attributes, "cidr_blocks"),
+				IPv6CIDRBlocks: stringSliceAttribute(attributes, "ipv6_cidr_blocks"),
+				PrefixListIDs:  stringSliceAttribute(attributes, "prefix_list_ids"),
+				FromPort:       int32(numberAttribute(attributes, "from_port")),
+				ToPort:         int32(numberAttribute(attributes, "to_port")),
+			}
+			tuple.SecurityGroupID, _ = attributes["security_group_id"].(string)
+			tuple.Protocol, _ = attributes["protocol"].(string)
+			tuple.SourceSecurityGroupID, _ = attributes["source_security_group_id"].(string)
+			tuple.Self, _ = attributes["self"].(bool)
+			ruleType, _ := attributes["type"].(string)
+			tuple.Egress = ruleType == "egress"
+			if tuple.SecurityGroupID == "" || tuple.Protocol == "" || ruleType == "" {
+				status.Category = "WARNING" // CORRECTED: Set Category
+				status.Message = fmt.Sprintf("Resource type '%s' (ID: %s) has neither 'security_group_rule_id' nor a complete rule definition in state attributes. Manual verification recommended.", resource.Type, stateID)
+				status.TFID = stateID
+				status.AWSID = liveID
+				return status
+			}
+			liveID, exists, err = clients.verifySecurityGroupRuleByTuple(ctx, tuple)
+			if exists {
+				// Terraform's sgrule- ID is a hash of this same tuple, so a full match is the same rule.
+				liveID = stateID
+			}
 		}
 	case "aws_acm_certificate":
 		if certARN, ok := attributes["arn"].(string); ok && certARN != "" {
@@ -271,8 +434,15 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 		zoneID, _ := attributes["zone_id"].(string)
 		recordName, _ := attributes["name"].(string)
 		recordType, _ := attributes["type"].(string)
+		setIdentifier, _ := attributes["set_identifier"].(string)
+		var aliasName string
+		if aliases, ok := attributes["alias"].([]interface{}); ok && len(aliases) > 0 {
+			if alias, ok := aliases[0].(map[string]interface{}); ok {
+				aliasName, _ = alias["name"].(string)
+			}
+		}
 		if zoneID != "" && recordName != "" && recordType != "" {
-			liveID, exists, err = clients.verifyRoute53Record(ctx, zoneID, recordName, recordType)
+			liveID, exists, err = clients.verifyRoute53Record(ctx, zoneID, recordName, recordType, setIdentifier, aliasName)
 		} else {
 			err = fmt.Errorf("could not find 'zone_id', 'name', or 'type' attributes for aws_route53_record")
 		}
@@ -382,8 +552,11 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 		}
 	case "aws_route":
 		routeTableID, _ := attributes["route_table_id"].(string)
-		destinationCIDR, _ := attributes["destination_cidr_block"].(string) // Or ipv6
-		if routeTableID != "" && (destinationCIDR != "" || attributes["destination_ipv6_cidr_block"] != nil) {
+		destinationCIDR, _ := attributes["destination_cidr_block"].(string)
+		if destinationCIDR == "" {
+			destinationCIDR, _ = attributes["destination_ipv6_cidr_block"].(string)
+		}
+		if routeTableID != "" && destinationCIDR != "" {
 			liveID, exists, err = clients.verifyRoute(ctx, routeTableID, destinationCIDR)
 		} else {
 			err = fmt.Errorf("could not find 'route_table_id' or destination CIDR attributes for aws_route")
@@ -586,7 +759,8 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 				Kind:             resource.Mode,
 			}
 		}
-		liveID, exists, err = clients.verifyECSService(ctx, clusterName, serviceName)
+		stateTaskDefinition, _ := attributes["task_definition"].(string)
+		liveID, exists, err = clients.verifyECSService(ctx, clusterName, serviceName, stateTaskDefinition)
 
 	case "aws_ecs_task_definition":
 		val, ok := attributes["arn"]
@@ -618,6 +792,845 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 		} else {
 			err = fmt.Errorf("could not find 'listener_arn' or 'certificate_arn' attributes for aws_lb_listener_certificate")
 		}
+	case "aws_db_instance", "aws_rds_cluster_instance":
+		identifier, _ := attributes["identifier"].(string)
+		if identifier == "" && resource.Type == "aws_rds_cluster_instance" {
+			identifier = stateID
+		}
+		clusterIdentifier, _ := attributes["cluster_identifier"].(string)
+		var deletionProtection *bool
+		if protected, ok := attributes["deletion_protection"].(bool); ok {
+			deletionProtection = &protected
+		}
+		if identifier != "" {
+			liveID, exists, err = clients.verifyDBInstance(ctx, identifier, stateID, clusterIdentifier, deletionProtection)
+		} else {
+			err = fmt.Errorf("could not find 'identifier' attribute for %s", resource.Type)
+		}
+	case "aws_rds_cluster":
+		clusterIdentifier, _ := attributes["cluster_identifier"].(string)
+		var deletionProtection *bool
+		if protected, ok := attributes["deletion_protection"].(bool); ok {
+			deletionProtection = &protected
+		}
+		if clusterIdentifier != "" {
+			liveID, exists, err = clients.verifyRDSCluster(ctx, clusterIdentifier, deletionProtection)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_identifier' attribute for aws_rds_cluster")
+		}
+	case "aws_dynamodb_table":
+		tableName, _ := attributes["name"].(string)
+		if tableName == "" {
+			tableName = arnInState
+		}
+		if tableName != "" {
+			liveID, exists, err = clients.verifyDynamoDBTable(ctx, tableName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'arn' attribute for aws_dynamodb_table")
+		}
+	case "aws_dynamodb_table_item":
+		tableName, _ := attributes["table_name"].(string)
+		hashKey, _ := attributes["hash_key"].(string)
+		rangeKey, _ := attributes["range_key"].(string)
+		item, _ := attributes["item"].(string)
+		if tableName != "" && hashKey != "" && item != "" {
+			liveID, exists, err = clients.verifyDynamoDBTableItem(ctx, tableName, hashKey, rangeKey, item)
+			if exists {
+				// Items have no ID of their own; the state ID is built from the table and the key.
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'table_name', 'hash_key' or 'item' attributes for aws_dynamodb_table_item")
+		}
+	case "aws_dynamodb_global_table":
+		if tableName, ok := attributes["name"].(string); ok && tableName != "" {
+			liveID, exists, err = clients.verifyDynamoDBGlobalTable(ctx, tableName)
+		} else {
+			err = fmt.Errorf("could not find 'name' attribute for aws_dynamodb_global_table")
+		}
+	case "aws_sqs_queue":
+		queueName, _ := attributes["name"].(string)
+		if stateID != "" || queueName != "" {
+			liveID, exists, err = clients.verifySQSQueue(ctx, stateID, queueName)
+		} else {
+			err = fmt.Errorf("could not find 'id' or 'name' attribute for aws_sqs_queue")
+		}
+	case "aws_sqs_queue_policy":
+		if queueURL, ok := attributes["queue_url"].(string); ok && queueURL != "" {
+			liveID, exists, err = clients.verifySQSQueuePolicy(ctx, queueURL)
+		} else {
+			err = fmt.Errorf("could not find 'queue_url' attribute for aws_sqs_queue_policy")
+		}
+	case "aws_sns_topic":
+		if topicARN := cmp.Or(arnInState, stateID); topicARN != "" {
+			liveID, exists, err = clients.verifySNSTopic(ctx, topicARN)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_sns_topic")
+		}
+	case "aws_sns_topic_policy":
+		if topicARN := cmp.Or(arnInState, stateID); topicARN != "" {
+			liveID, exists, err = clients.verifySNSTopicPolicy(ctx, topicARN)
+		} else {
+			err = fmt.Errorf("could not find 'arn' attribute for aws_sns_topic_policy")
+		}
+	case "aws_sns_topic_subscription":
+		topicARN, _ := attributes["topic_arn"].(string)
+		protocol, _ := attributes["protocol"].(string)
+		endpoint, _ := attributes["endpoint"].(string)
+		if stateID != "" {
+			liveID, exists, err = clients.verifySNSTopicSubscription(ctx, stateID, topicARN, protocol, endpoint)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_sns_topic_subscription")
+		}
+	case "aws_kms_key":
+		keyID, _ := attributes["key_id"].(string)
+		isEnabled, _ := attributes["is_enabled"].(bool)
+		if keyID = cmp.Or(keyID, stateID); keyID != "" {
+			liveID, exists, err = clients.verifyKMSKey(ctx, keyID, isEnabled)
+		} else {
+			err = fmt.Errorf("could not find 'key_id' or 'id' attribute for aws_kms_key")
+		}
+	case "aws_kms_alias":
+		aliasName, _ := attributes["name"].(string)
+		targetKeyID, _ := attributes["target_key_id"].(string)
+		if aliasName = cmp.Or(aliasName, stateID); aliasName != "" {
+			liveID, exists, err = clients.verifyKMSAlias(ctx, aliasName, targetKeyID)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_kms_alias")
+		}
+	case "aws_kms_key_policy":
+		if keyID, ok := attributes["key_id"].(string); ok && keyID != "" {
+			liveID, exists, err = clients.verifyKMSKeyPolicy(ctx, keyID)
+		} else {
+			err = fmt.Errorf("could not find 'key_id' attribute for aws_kms_key_policy")
+		}
+	case "aws_eks_cluster":
+		clusterName, _ := attributes["name"].(string)
+		if clusterName = cmp.Or(clusterName, stateID); clusterName != "" {
+			liveID, exists, err = clients.verifyEKSCluster(ctx, clusterName, arnInState)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_eks_cluster")
+		}
+	case "aws_eks_node_group":
+		if clusterName, nodeGroupName := eksClusterScopedNames(attributes, stateID, "node_group_name"); nodeGroupName != "" {
+			liveID, exists, err = clients.verifyEKSNodeGroup(ctx, clusterName, nodeGroupName, arnInState)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_name' and 'node_group_name' attributes for aws_eks_node_group")
+		}
+	case "aws_eks_addon":
+		if clusterName, addonName := eksClusterScopedNames(attributes, stateID, "addon_name"); addonName != "" {
+			liveID, exists, err = clients.verifyEKSAddon(ctx, clusterName, addonName, arnInState)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_name' and 'addon_name' attributes for aws_eks_addon")
+		}
+	case "aws_eks_fargate_profile":
+		if clusterName, profileName := eksClusterScopedNames(attributes, stateID, "fargate_profile_name"); profileName != "" {
+			liveID, exists, err = clients.verifyEKSFargateProfile(ctx, clusterName, profileName, arnInState)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_name' and 'fargate_profile_name' attributes for aws_eks_fargate_profile")
+		}
+	case "aws_elasticache_cluster":
+		clusterID, _ := attributes["cluster_id"].(string)
+		replicationGroupID, _ := attributes["replication_group_id"].(string)
+		if clusterID = cmp.Or(clusterID, stateID); clusterID != "" {
+			liveID, exists, err = clients.verifyElastiCacheCluster(ctx, clusterID, replicationGroupID)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_id' or 'id' attribute for aws_elasticache_cluster")
+		}
+	case "aws_elasticache_replication_group":
+		replicationGroupID, _ := attributes["replication_group_id"].(string)
+		if replicationGroupID = cmp.Or(replicationGroupID, stateID); replicationGroupID != "" {
+			liveID, exists, err = clients.verifyElastiCacheReplicationGroup(ctx, replicationGroupID)
+		} else {
+			err = fmt.Errorf("could not find 'replication_group_id' or 'id' attribute for aws_elasticache_replication_group")
+		}
+	case "aws_elasticache_subnet_group":
+		subnetGroupName, _ := attributes["name"].(string)
+		if subnetGroupName = cmp.Or(subnetGroupName, stateID); subnetGroupName != "" {
+			liveID, exists, err = clients.verifyElastiCacheSubnetGroup(ctx, subnetGroupName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_elasticache_subnet_group")
+		}
+	case "aws_elasticache_parameter_group":
+		parameterGroupName, _ := attributes["name"].(string)
+		if parameterGroupName = cmp.Or(parameterGroupName, stateID); parameterGroupName != "" {
+			liveID, exists, err = clients.verifyElastiCacheParameterGroup(ctx, parameterGroupName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_elasticache_parameter_group")
+		}
+	case "aws_ecr_repository":
+		repositoryName, _ := attributes["name"].(string)
+		registryID, _ := attributes["registry_id"].(string)
+		if repositoryName = cmp.Or(repositoryName, stateID); repositoryName != "" {
+			liveID, exists, err = clients.verifyECRRepository(ctx, repositoryName, registryID)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_ecr_repository")
+		}
+	case "aws_ecr_lifecycle_policy":
+		repositoryName, _ := attributes["repository"].(string)
+		registryID, _ := attributes["registry_id"].(string)
+		if repositoryName = cmp.Or(repositoryName, stateID); repositoryName != "" {
+			liveID, exists, err = clients.verifyECRLifecyclePolicy(ctx, repositoryName, registryID)
+		} else {
+			err = fmt.Errorf("could not find 'repository' or 'id' attribute for aws_ecr_lifecycle_policy")
+		}
+	case "aws_ecr_repository_policy":
+		repositoryName, _ := attributes["repository"].(string)
+		registryID, _ := attributes["registry_id"].(string)
+		if repositoryName = cmp.Or(repositoryName, stateID); repositoryName != "" {
+			liveID, exists, err = clients.verifyECRRepositoryPolicy(ctx, repositoryName, registryID)
+		} else {
+			err = fmt.Errorf("could not find 'repository' or 'id' attribute for aws_ecr_repository_policy")
+		}
+	case "aws_api_gateway_rest_api":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyAPIGatewayRestAPI(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_api_gateway_rest_api")
+		}
+	case "aws_api_gateway_stage":
+		restAPIID, _ := attributes["rest_api_id"].(string)
+		stageName, _ := attributes["stage_name"].(string)
+		deploymentID, _ := attributes["deployment_id"].(string)
+		if restAPIID != "" && stageName != "" {
+			liveID, exists, err = clients.verifyAPIGatewayStage(ctx, restAPIID, stageName, deploymentID)
+		} else {
+			err = fmt.Errorf("could not find 'rest_api_id' or 'stage_name' attribute for aws_api_gateway_stage")
+		}
+	case "aws_api_gateway_deployment":
+		restAPIID, _ := attributes["rest_api_id"].(string)
+		if restAPIID != "" && stateID != "" {
+			liveID, exists, err = clients.verifyAPIGatewayDeployment(ctx, restAPIID, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'rest_api_id' or 'id' attribute for aws_api_gateway_deployment")
+		}
+	case "aws_api_gateway_resource":
+		restAPIID, _ := attributes["rest_api_id"].(string)
+		path, _ := attributes["path"].(string)
+		if restAPIID != "" && (stateID != "" || path != "") {
+			liveID, exists, err = clients.verifyAPIGatewayResource(ctx, restAPIID, stateID, path)
+		} else {
+			err = fmt.Errorf("could not find 'rest_api_id' and 'id' or 'path' attributes for aws_api_gateway_resource")
+		}
+	case "aws_api_gateway_method", "aws_api_gateway_integration":
+		restAPIID, _ := attributes["rest_api_id"].(string)
+		resourceID, _ := attributes["resource_id"].(string)
+		httpMethod, _ := attributes["http_method"].(string)
+		if restAPIID == "" || resourceID == "" || httpMethod == "" {
+			err = fmt.Errorf("could not find 'rest_api_id', 'resource_id' or 'http_method' attribute for %s", resource.Type)
+		} else if resource.Type == "aws_api_gateway_method" {
+			liveID, exists, err = clients.verifyAPIGatewayMethod(ctx, restAPIID, resourceID, httpMethod)
+		} else {
+			liveID, exists, err = clients.verifyAPIGatewayIntegration(ctx, restAPIID, resourceID, httpMethod)
+		}
+	case "aws_apigatewayv2_api":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyAPIGatewayV2API(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_apigatewayv2_api")
+		}
+	case "aws_apigatewayv2_stage":
+		apiID, _ := attributes["api_id"].(string)
+		stageName, _ := attributes["name"].(string)
+		if stageName = cmp.Or(stageName, stateID); apiID != "" && stageName != "" {
+			liveID, exists, err = clients.verifyAPIGatewayV2Stage(ctx, apiID, stageName)
+		} else {
+			err = fmt.Errorf("could not find 'api_id' or 'name' attribute for aws_apigatewayv2_stage")
+		}
+	case "aws_apigatewayv2_route":
+		apiID, _ := attributes["api_id"].(string)
+		routeKey, _ := attributes["route_key"].(string)
+		if apiID != "" && (stateID != "" || routeKey != "") {
+			liveID, exists, err = clients.verifyAPIGatewayV2Route(ctx, apiID, stateID, routeKey)
+		} else {
+			err = fmt.Errorf("could not find 'api_id' and 'id' or 'route_key' attributes for aws_apigatewayv2_route")
+		}
+	case "aws_apigatewayv2_integration":
+		apiID, _ := attributes["api_id"].(string)
+		if apiID != "" && stateID != "" {
+			liveID, exists, err = clients.verifyAPIGatewayV2Integration(ctx, apiID, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'api_id' or 'id' attribute for aws_apigatewayv2_integration")
+		}
+	case "aws_apigatewayv2_domain_name":
+		domainName, _ := attributes["domain_name"].(string)
+		if domainName = cmp.Or(domainName, stateID); domainName != "" {
+			liveID, exists, err = clients.verifyAPIGatewayV2DomainName(ctx, domainName)
+		} else {
+			err = fmt.Errorf("could not find 'domain_name' or 'id' attribute for aws_apigatewayv2_domain_name")
+		}
+	case "aws_sfn_state_machine":
+		if stateMachineARN := cmp.Or(arnInState, stateID); stateMachineARN != "" {
+			liveID, exists, err = clients.verifySFNStateMachine(ctx, stateMachineARN)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_sfn_state_machine")
+		}
+	case "aws_sfn_activity":
+		if activityARN := cmp.Or(arnInState, stateID); activityARN != "" {
+			liveID, exists, err = clients.verifySFNActivity(ctx, activityARN)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_sfn_activity")
+		}
+	case "aws_cloudwatch_event_bus":
+		busName, _ := attributes["name"].(string)
+		if busName = cmp.Or(busName, stateID); busName != "" {
+			liveID, exists, err = clients.verifyEventBus(ctx, busName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_cloudwatch_event_bus")
+		}
+	case "aws_cloudwatch_event_rule":
+		ruleName, _ := attributes["name"].(string)
+		busName, _ := attributes["event_bus_name"].(string)
+		ruleState, _ := attributes["state"].(string)
+		if ruleName != "" {
+			liveID, exists, err = clients.verifyEventRule(ctx, busName, ruleName, ruleState)
+		} else {
+			err = fmt.Errorf("could not find 'name' attribute for aws_cloudwatch_event_rule")
+		}
+	case "aws_cloudwatch_event_target":
+		ruleName, _ := attributes["rule"].(string)
+		busName, _ := attributes["event_bus_name"].(string)
+		targetID, _ := attributes["target_id"].(string)
+		if ruleName != "" && targetID != "" {
+			liveID, exists, err = clients.verifyEventTarget(ctx, busName, ruleName, targetID)
+		} else {
+			err = fmt.Errorf("could not find 'rule' or 'target_id' attribute for aws_cloudwatch_event_target")
+		}
+	case "aws_kinesis_stream":
+		streamName, _ := attributes["name"].(string)
+		if streamID := cmp.Or(arnInState, streamName, stateID); streamID != "" {
+			liveID, exists, err = clients.verifyKinesisStream(ctx, streamID)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'name' attribute for aws_kinesis_stream")
+		}
+	case "aws_kinesis_stream_consumer":
+		streamARN, _ := attributes["stream_arn"].(string)
+		consumerName, _ := attributes["name"].(string)
+		if consumerARN := cmp.Or(arnInState, stateID); consumerARN != "" || (streamARN != "" && consumerName != "") {
+			liveID, exists, err = clients.verifyKinesisStreamConsumer(ctx, consumerARN, streamARN, consumerName)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'stream_arn' and 'name' attributes for aws_kinesis_stream_consumer")
+		}
+	case "aws_kinesis_firehose_delivery_stream":
+		if streamName, ok := attributes["name"].(string); ok && streamName != "" {
+			liveID, exists, err = clients.verifyFirehoseDeliveryStream(ctx, streamName)
+		} else {
+			err = fmt.Errorf("could not find 'name' attribute for aws_kinesis_firehose_delivery_stream")
+		}
+	case "aws_efs_file_system":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyEFSFileSystem(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_efs_file_system")
+		}
+	case "aws_efs_mount_target":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyEFSMountTarget(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_efs_mount_target")
+		}
+	case "aws_efs_access_point":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyEFSAccessPoint(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_efs_access_point")
+		}
+	case "aws_ebs_volume":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyEBSVolume(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_ebs_volume")
+		}
+	case "aws_volume_attachment":
+		deviceName, _ := attributes["device_name"].(string)
+		volumeID, _ := attributes["volume_id"].(string)
+		instanceID, _ := attributes["instance_id"].(string)
+		if deviceName != "" && volumeID != "" && instanceID != "" {
+			liveID, exists, err = clients.verifyVolumeAttachment(ctx, deviceName, volumeID, instanceID)
+		} else {
+			err = fmt.Errorf("could not find 'device_name', 'volume_id' or 'instance_id' attribute for aws_volume_attachment")
+		}
+	case "aws_ebs_snapshot", "aws_ebs_snapshot_copy":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyEBSSnapshot(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for %s", resource.Type)
+		}
+	case "aws_dlm_lifecycle_policy":
+		policyState, _ := attributes["state"].(string)
+		if stateID != "" {
+			liveID, exists, err = clients.verifyDLMLifecyclePolicy(ctx, stateID, policyState)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_dlm_lifecycle_policy")
+		}
+	case "aws_vpc_endpoint":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyVPCEndpoint(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_vpc_endpoint")
+		}
+	case "aws_vpc_endpoint_service":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyVPCEndpointService(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_vpc_endpoint_service")
+		}
+	case "aws_vpc_endpoint_route_table_association":
+		endpointID, _ := attributes["vpc_endpoint_id"].(string)
+		routeTableID, _ := attributes["route_table_id"].(string)
+		if endpointID != "" && routeTableID != "" {
+			liveID, exists, err = clients.verifyVPCEndpointRouteTableAssociation(ctx, endpointID, routeTableID)
+		} else {
+			err = fmt.Errorf("could not find 'vpc_endpoint_id' or 'route_table_id' attribute for aws_vpc_endpoint_route_table_association")
+		}
+	case "aws_vpc_peering_connection", "aws_vpc_peering_connection_accepter", "aws_vpc_peering_connection_options":
+		peeringID, _ := attributes["vpc_peering_connection_id"].(string)
+		if peeringID = cmp.Or(peeringID, stateID); peeringID != "" {
+			liveID, exists, err = clients.verifyVPCPeeringConnection(ctx, peeringID)
+		} else {
+			err = fmt.Errorf("could not find 'vpc_peering_connection_id' or 'id' attribute for %s", resource.Type)
+		}
+	case "aws_ec2_transit_gateway":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyTransitGateway(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_ec2_transit_gateway")
+		}
+	case "aws_ec2_transit_gateway_vpc_attachment":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyTransitGatewayVPCAttachment(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_ec2_transit_gateway_vpc_attachment")
+		}
+	case "aws_ec2_transit_gateway_peering_attachment":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyTransitGatewayPeeringAttachment(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_ec2_transit_gateway_peering_attachment")
+		}
+	case "aws_network_acl":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyNetworkACL(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_network_acl")
+		}
+	case "aws_network_acl_rule":
+		// Terraform's nacl- ID is a hash of the ACL, rule number, direction and protocol, so the
+		// rule is looked up by those instead.
+		networkACLID, _ := attributes["network_acl_id"].(string)
+		protocol, _ := attributes["protocol"].(string)
+		egress, _ := attributes["egress"].(bool)
+		if networkACLID != "" && attributes["rule_number"] != nil {
+			liveID, exists, err = clients.verifyNetworkACLRule(ctx, networkACLID, int32(numberAttribute(attributes, "rule_number")), egress, protocol)
+			if exists {
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'network_acl_id' or 'rule_number' attribute for aws_network_acl_rule")
+		}
+	case "aws_network_acl_association":
+		if stateID != "" {
+			networkACLID, _ := attributes["network_acl_id"].(string)
+			subnetID, _ := attributes["subnet_id"].(string)
+			liveID, exists, err = clients.verifyNetworkACLAssociation(ctx, stateID, networkACLID, subnetID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_network_acl_association")
+		}
+	case "aws_flow_log":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyFlowLog(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_flow_log")
+		}
+	case "aws_vpc_dhcp_options":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyDHCPOptions(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_vpc_dhcp_options")
+		}
+	case "aws_vpc_dhcp_options_association":
+		vpcID, _ := attributes["vpc_id"].(string)
+		dhcpOptionsID, _ := attributes["dhcp_options_id"].(string)
+		if vpcID != "" && dhcpOptionsID != "" {
+			liveID, exists, err = clients.verifyDHCPOptionsAssociation(ctx, vpcID, dhcpOptionsID)
+		} else {
+			err = fmt.Errorf("could not find 'vpc_id' or 'dhcp_options_id' attribute for aws_vpc_dhcp_options_association")
+		}
+	case "aws_wafv2_web_acl", "aws_wafv2_rule_group", "aws_wafv2_ip_set":
+		name, _ := attributes["name"].(string)
+		scope, _ := attributes["scope"].(string)
+		if stateID != "" && name != "" && scope != "" {
+			liveID, exists, err = clients.verifyWAFV2Resource(ctx, resource.Type, stateID, name, scope)
+		} else {
+			err = fmt.Errorf("could not find 'id', 'name' or 'scope' attribute for %s", resource.Type)
+		}
+	case "aws_wafv2_web_acl_association":
+		webACLARN, _ := attributes["web_acl_arn"].(string)
+		resourceARN, _ := attributes["resource_arn"].(string)
+		if webACLARN != "" && resourceARN != "" {
+			liveID, exists, err = clients.verifyWAFV2WebACLAssociation(ctx, webACLARN, resourceARN)
+		} else {
+			err = fmt.Errorf("could not find 'web_acl_arn' or 'resource_arn' attribute for aws_wafv2_web_acl_association")
+		}
+	case "aws_cloudtrail":
+		trailName, _ := attributes["arn"].(string)
+		if trailName == "" {
+			trailName, _ = attributes["name"].(string)
+		}
+		if trailName = cmp.Or(trailName, stateID); trailName != "" {
+			enableLogging, ok := attributes["enable_logging"].(bool)
+			liveID, exists, err = clients.verifyCloudTrail(ctx, trailName, enableLogging || !ok)
+			if exists && !strings.HasPrefix(stateID, "arn:") {
+				// Older states use the trail name as ID
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'arn', 'name' or 'id' attribute for aws_cloudtrail")
+		}
+	case "aws_config_configuration_recorder":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyConfigRecorder(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_config_configuration_recorder")
+		}
+	case "aws_config_delivery_channel":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyConfigDeliveryChannel(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_config_delivery_channel")
+		}
+	case "aws_config_config_rule":
+		if ruleName, ok := attributes["name"].(string); ok && ruleName != "" {
+			liveID, exists, err = clients.verifyConfigRule(ctx, ruleName)
+		} else {
+			err = fmt.Errorf("could not find 'name' attribute for aws_config_config_rule")
+		}
+	case "aws_guardduty_detector":
+		if stateID != "" {
+			enable, ok := attributes["enable"].(bool)
+			liveID, exists, err = clients.verifyGuardDutyDetector(ctx, stateID, enable || !ok)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_guardduty_detector")
+		}
+	case "aws_guardduty_filter":
+		detectorID, _ := attributes["detector_id"].(string)
+		filterName, _ := attributes["name"].(string)
+		if detectorID != "" && filterName != "" {
+			liveID, exists, err = clients.verifyGuardDutyFilter(ctx, detectorID, filterName)
+		} else {
+			err = fmt.Errorf("could not find 'detector_id' or 'name' attribute for aws_guardduty_filter")
+		}
+	case "aws_guardduty_member":
+		detectorID, _ := attributes["detector_id"].(string)
+		accountID, _ := attributes["account_id"].(string)
+		if detectorID != "" && accountID != "" {
+			liveID, exists, err = clients.verifyGuardDutyMember(ctx, detectorID, accountID)
+		} else {
+			err = fmt.Errorf("could not find 'detector_id' or 'account_id' attribute for aws_guardduty_member")
+		}
+	case "aws_ses_domain_identity", "aws_ses_email_identity":
+		identity, _ := attributes["domain"].(string)
+		if identity == "" {
+			identity, _ = attributes["email"].(string)
+		}
+		if identity = cmp.Or(identity, stateID); identity != "" {
+			liveID, exists, err = clients.verifySESIdentity(ctx, identity)
+		} else {
+			err = fmt.Errorf("could not find 'domain', 'email' or 'id' attribute for %s", resource.Type)
+		}
+	case "aws_ses_configuration_set":
+		if setName, ok := attributes["name"].(string); ok && setName != "" {
+			liveID, exists, err = clients.verifySESConfigurationSet(ctx, setName)
+		} else {
+			err = fmt.Errorf("could not find 'name' attribute for aws_ses_configuration_set")
+		}
+	case "aws_ses_receipt_rule":
+		ruleSetName, _ := attributes["rule_set_name"].(string)
+		ruleName, _ := attributes["name"].(string)
+		if ruleSetName != "" && ruleName != "" {
+			liveID, exists, err = clients.verifySESReceiptRule(ctx, ruleSetName, ruleName)
+		} else {
+			err = fmt.Errorf("could not find 'rule_set_name' or 'name' attribute for aws_ses_receipt_rule")
+		}
+	case "aws_cognito_user_pool":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyCognitoUserPool(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_cognito_user_pool")
+		}
+	case "aws_cognito_user_pool_client":
+		if userPoolID, ok := attributes["user_pool_id"].(string); ok && userPoolID != "" && stateID != "" {
+			liveID, exists, err = clients.verifyCognitoUserPoolClient(ctx, userPoolID, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'user_pool_id' or 'id' attribute for aws_cognito_user_pool_client")
+		}
+	case "aws_cognito_user_pool_domain":
+		domain, _ := attributes["domain"].(string)
+		userPoolID, _ := attributes["user_pool_id"].(string)
+		if domain = cmp.Or(domain, stateID); domain != "" {
+			liveID, exists, err = clients.verifyCognitoUserPoolDomain(ctx, domain, userPoolID)
+		} else {
+			err = fmt.Errorf("could not find 'domain' or 'id' attribute for aws_cognito_user_pool_domain")
+		}
+	case "aws_cognito_resource_server":
+		userPoolID, _ := attributes["user_pool_id"].(string)
+		identifier, _ := attributes["identifier"].(string)
+		if userPoolID != "" && identifier != "" {
+			liveID, exists, err = clients.verifyCognitoResourceServer(ctx, userPoolID, identifier)
+		} else {
+			err = fmt.Errorf("could not find 'user_pool_id' or 'identifier' attribute for aws_cognito_resource_server")
+		}
+	case "aws_cognito_identity_pool":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyCognitoIdentityPool(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_cognito_identity_pool")
+		}
+	case "aws_cognito_identity_pool_roles_attachment":
+		if identityPoolID, ok := attributes["identity_pool_id"].(string); ok && identityPoolID != "" {
+			roles := map[string]string{}
+			if stateRoles, ok := attributes["roles"].(map[string]interface{}); ok {
+				for role, roleARN := range stateRoles {
+					roles[role], _ = roleARN.(string)
+				}
+			}
+			liveID, exists, err = clients.verifyCognitoIdentityPoolRoles(ctx, identityPoolID, roles)
+		} else {
+			err = fmt.Errorf("could not find 'identity_pool_id' attribute for aws_cognito_identity_pool_roles_attachment")
+		}
+	case "aws_appsync_graphql_api":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyAppSyncGraphQLAPI(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_appsync_graphql_api")
+		}
+	case "aws_appsync_datasource":
+		apiID, _ := attributes["api_id"].(string)
+		dataSourceName, _ := attributes["name"].(string)
+		if apiID != "" && dataSourceName != "" {
+			liveID, exists, err = clients.verifyAppSyncDataSource(ctx, apiID, dataSourceName)
+		} else {
+			err = fmt.Errorf("could not find 'api_id' or 'name' attribute for aws_appsync_datasource")
+		}
+	case "aws_appsync_resolver":
+		apiID, _ := attributes["api_id"].(string)
+		typeName, _ := attributes["type"].(string)
+		fieldName, _ := attributes["field"].(string)
+		if apiID != "" && typeName != "" && fieldName != "" {
+			liveID, exists, err = clients.verifyAppSyncResolver(ctx, apiID, typeName, fieldName)
+		} else {
+			err = fmt.Errorf("could not find 'api_id', 'type' or 'field' attribute for aws_appsync_resolver")
+		}
+	case "aws_appsync_api_key":
+		// The ID is API_ID:KEY_ID; the key ID is not an attribute of its own.
+		apiID, keyID, found := strings.Cut(stateID, ":")
+		if found && apiID != "" && keyID != "" {
+			liveID, exists, err = clients.verifyAppSyncAPIKey(ctx, apiID, keyID)
+		} else {
+			err = fmt.Errorf("could not parse API and key IDs from 'id' attribute '%s' for aws_appsync_api_key", stateID)
+		}
+	case "aws_codebuild_project":
+		projectName, _ := attributes["arn"].(string)
+		if projectName = cmp.Or(projectName, stateID); projectName != "" {
+			liveID, exists, err = clients.verifyCodeBuildProject(ctx, projectName)
+			if exists && !strings.HasPrefix(stateID, "arn:") {
+				// Projects imported by name keep the name as ID
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_codebuild_project")
+		}
+	case "aws_codebuild_source_credential":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyCodeBuildSourceCredential(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_codebuild_source_credential")
+		}
+	case "aws_codebuild_webhook":
+		projectName, _ := attributes["project_name"].(string)
+		if projectName = cmp.Or(projectName, stateID); projectName != "" {
+			liveID, exists, err = clients.verifyCodeBuildWebhook(ctx, projectName)
+		} else {
+			err = fmt.Errorf("could not find 'project_name' or 'id' attribute for aws_codebuild_webhook")
+		}
+	case "aws_codepipeline":
+		pipelineName, _ := attributes["name"].(string)
+		if pipelineName = cmp.Or(pipelineName, stateID); pipelineName != "" {
+			liveID, exists, err = clients.verifyCodePipeline(ctx, pipelineName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_codepipeline")
+		}
+	case "aws_codepipeline_webhook":
+		if stateID != "" {
+			targetPipeline, _ := attributes["target_pipeline"].(string)
+			liveID, exists, err = clients.verifyCodePipelineWebhook(ctx, stateID, targetPipeline)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_codepipeline_webhook")
+		}
+	case "aws_codedeploy_app":
+		appName, _ := attributes["name"].(string)
+		if appName != "" {
+			appID, _, _ := strings.Cut(stateID, ":")
+			liveID, exists, err = clients.verifyCodeDeployApp(ctx, appName, appID)
+			if exists {
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'name' attribute for aws_codedeploy_app")
+		}
+	case "aws_codedeploy_deployment_group":
+		appName, _ := attributes["app_name"].(string)
+		groupName, _ := attributes["deployment_group_name"].(string)
+		if appName != "" && groupName != "" {
+			liveID, exists, err = clients.verifyCodeDeployDeploymentGroup(ctx, appName, groupName, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'app_name' or 'deployment_group_name' attribute for aws_codedeploy_deployment_group")
+		}
+	case "aws_batch_compute_environment":
+		environmentName, _ := attributes["arn"].(string)
+		if environmentName = cmp.Or(environmentName, stateID); environmentName != "" {
+			state, _ := attributes["state"].(string)
+			liveID, exists, err = clients.verifyBatchComputeEnvironment(ctx, environmentName, state)
+			if exists {
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_batch_compute_environment")
+		}
+	case "aws_batch_job_queue":
+		queueName, _ := attributes["arn"].(string)
+		if queueName = cmp.Or(queueName, stateID); queueName != "" {
+			state, _ := attributes["state"].(string)
+			liveID, exists, err = clients.verifyBatchJobQueue(ctx, queueName, state)
+			if exists {
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_batch_job_queue")
+		}
+	case "aws_batch_job_definition":
+		definitionARN, _ := attributes["arn"].(string)
+		if definitionARN = cmp.Or(definitionARN, stateID); definitionARN != "" {
+			liveID, exists, err = clients.verifyBatchJobDefinition(ctx, definitionARN)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_batch_job_definition")
+		}
+	case "aws_msk_cluster":
+		clusterARN, _ := attributes["arn"].(string)
+		if clusterARN = cmp.Or(clusterARN, stateID); clusterARN != "" {
+			liveID, exists, err = clients.verifyMSKCluster(ctx, clusterARN)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_msk_cluster")
+		}
+	case "aws_msk_configuration":
+		configurationARN, _ := attributes["arn"].(string)
+		if configurationARN = cmp.Or(configurationARN, stateID); configurationARN != "" {
+			liveID, exists, err = clients.verifyMSKConfiguration(ctx, configurationARN)
+		} else {
+			err = fmt.Errorf("could not find 'arn' or 'id' attribute for aws_msk_configuration")
+		}
+	case "aws_mq_broker":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyMQBroker(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_mq_broker")
+		}
+	case "aws_mq_configuration":
+		if stateID != "" {
+			liveID, exists, err = clients.verifyMQConfiguration(ctx, stateID)
+		} else {
+			err = fmt.Errorf("could not find 'id' attribute for aws_mq_configuration")
+		}
+	case "aws_neptune_cluster":
+		clusterIdentifier, _ := attributes["cluster_identifier"].(string)
+		var deletionProtection *bool
+		if protected, ok := attributes["deletion_protection"].(bool); ok {
+			deletionProtection = &protected
+		}
+		if clusterIdentifier = cmp.Or(clusterIdentifier, stateID); clusterIdentifier != "" {
+			liveID, exists, err = clients.verifyNeptuneCluster(ctx, clusterIdentifier, deletionProtection)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_identifier' or 'id' attribute for aws_neptune_cluster")
+		}
+	case "aws_neptune_cluster_instance":
+		identifier, _ := attributes["identifier"].(string)
+		clusterIdentifier, _ := attributes["cluster_identifier"].(string)
+		if identifier = cmp.Or(identifier, stateID); identifier != "" {
+			liveID, exists, err = clients.verifyNeptuneInstance(ctx, identifier, clusterIdentifier)
+		} else {
+			err = fmt.Errorf("could not find 'identifier' or 'id' attribute for aws_neptune_cluster_instance")
+		}
+	case "aws_neptune_subnet_group":
+		groupName, _ := attributes["name"].(string)
+		if groupName = cmp.Or(groupName, stateID); groupName != "" {
+			liveID, exists, err = clients.verifyNeptuneSubnetGroup(ctx, groupName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_neptune_subnet_group")
+		}
+	case "aws_docdb_cluster":
+		clusterIdentifier, _ := attributes["cluster_identifier"].(string)
+		var deletionProtection *bool
+		if protected, ok := attributes["deletion_protection"].(bool); ok {
+			deletionProtection = &protected
+		}
+		if clusterIdentifier = cmp.Or(clusterIdentifier, stateID); clusterIdentifier != "" {
+			liveID, exists, err = clients.verifyDocDBCluster(ctx, clusterIdentifier, deletionProtection)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_identifier' or 'id' attribute for aws_docdb_cluster")
+		}
+	case "aws_docdb_cluster_instance":
+		identifier, _ := attributes["identifier"].(string)
+		clusterIdentifier, _ := attributes["cluster_identifier"].(string)
+		if identifier = cmp.Or(identifier, stateID); identifier != "" {
+			liveID, exists, err = clients.verifyDocDBInstance(ctx, identifier, clusterIdentifier)
+		} else {
+			err = fmt.Errorf("could not find 'identifier' or 'id' attribute for aws_docdb_cluster_instance")
+		}
+	case "aws_docdb_subnet_group":
+		groupName, _ := attributes["name"].(string)
+		if groupName = cmp.Or(groupName, stateID); groupName != "" {
+			liveID, exists, err = clients.verifyDocDBSubnetGroup(ctx, groupName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_docdb_subnet_group")
+		}
+	case "aws_opensearch_domain", "aws_elasticsearch_domain":
+		domainName, _ := attributes["domain_name"].(string)
+		if domainName != "" {
+			liveID, exists, err = clients.verifyOpenSearchDomain(ctx, resource.Type, domainName)
+		} else {
+			err = fmt.Errorf("could not find 'domain_name' attribute for %s", resource.Type)
+		}
+	case "aws_opensearch_domain_policy", "aws_elasticsearch_domain_policy":
+		domainName, _ := attributes["domain_name"].(string)
+		if domainName != "" {
+			liveID, exists, err = clients.verifyOpenSearchDomainPolicy(ctx, resource.Type, domainName)
+			if exists {
+				liveID = stateID
+			}
+		} else {
+			err = fmt.Errorf("could not find 'domain_name' attribute for %s", resource.Type)
+		}
+	case "aws_redshift_cluster":
+		clusterIdentifier, _ := attributes["cluster_identifier"].(string)
+		if clusterIdentifier = cmp.Or(clusterIdentifier, stateID); clusterIdentifier != "" {
+			liveID, exists, err = clients.verifyRedshiftCluster(ctx, clusterIdentifier)
+		} else {
+			err = fmt.Errorf("could not find 'cluster_identifier' or 'id' attribute for aws_redshift_cluster")
+		}
+	case "aws_redshift_subnet_group":
+		groupName, _ := attributes["name"].(string)
+		if groupName = cmp.Or(groupName, stateID); groupName != "" {
+			liveID, exists, err = clients.verifyRedshiftSubnetGroup(ctx, groupName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_redshift_subnet_group")
+		}
+	case "aws_redshift_parameter_group":
+		groupName, _ := attributes["name"].(string)
+		if groupName = cmp.Or(groupName, stateID); groupName != "" {
+			liveID, exists, err = clients.verifyRedshiftParameterGroup(ctx, groupName)
+		} else {
+			err = fmt.Errorf("could not find 'name' or 'id' attribute for aws_redshift_parameter_group")
+		}
 
 	default:
 		status.Category = "WARNING" // CORRECTED: Set Category
@@ -631,13 +1644,33 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 	status.ExistsInAWS = exists
 	status.Error = err
 
+	var stateErr *liveStateError
+	if errors.As(err, &stateErr) {
+		status.Error = nil
+		status.LiveID = stateErr.LiveID
+		status.ExistsInAWS = true
+		status.Category = stateErr.Category
+		status.Message = fmt.Sprintf("%s: %s", tfAddress, stateErr.Message)
+		switch stateErr.Remediation {
+		case "import":
+			status.Command = fmt.Sprintf("terraform import %s %s", tfAddress, importID(resource.Type, attributes, stateErr.LiveID))
+		case "rm":
+			status.Command = fmt.Sprintf("terraform state rm %s", tfAddress)
+		}
+		status.TFID = stateID
+		status.AWSID = stateErr.LiveID
+		return status
+	}
+
 	if err != nil {
 		status.Category = "ERROR" // CORRECTED: Set Category
 		status.Message = fmt.Sprintf("Failed to verify %s: %v", tfAddress, err)
 		status.TFID = stateID // For JSON output
 		status.AWSID = liveID // For JSON output
 	} else if exists {
-		if strings.EqualFold(stateID, liveID) || len(stateID) == 0 {
+		// Composite-ID resources are found by the attributes their import ID is built from, so they are the
+		// object the state tracks even though the verifier's ID differs from the state ID.
+		if strings.EqualFold(stateID, liveID) || len(stateID) == 0 || importIDFormats[resource.Type] != nil {
 			status.Category = "OK" // CORRECTED: Set Category
 			status.Message = fmt.Sprintf("%s (ID: %s) exists in state and AWS.", tfAddress, liveID)
 			status.TFID = stateID // For JSON output
@@ -645,7 +1678,7 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 		} else {
 			status.Category = "POTENTIAL_IMPORT" // CORRECTED: Set Category
 			status.Message = fmt.Sprintf("%s exists in AWS with ID '%s'. State ID: '%s'.", tfAddress, liveID, stateID)
-			status.Command = fmt.Sprintf("terraform import %s %s", tfAddress, liveID)
+			status.Command = fmt.Sprintf("terraform import %s %s", tfAddress, importID(resource.Type, attributes, liveID))
 			status.TFID = stateID // For JSON output
 			status.AWSID = liveID // For JSON output
 		}
@@ -659,3 +1692,63 @@ func processResourceInstance(ctx context.Context, clients *AWSClient, resource R
 
 	return status
 }
+
+// resourceInstanceAddress builds the Terraform address of a resource instance, including module path and index key,
+// with data. before data sources as terraform expects in import, state rm and -target.
+func resourceInstanceAddress(resource ResourceStateV4, instance InstanceObjectStateV4) string {
+	tfAddress := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
+	if resource.Mode == "data" {
+		tfAddress = "data." + tfAddress
+	}
+	if resource.Module != "" {
+		tfAddress = fmt.Sprintf("%s.%s", resource.Module, tfAddress)
+	}
+	// For instances with IndexKey (e.g., count, for_each), append it to the address
+	return tfAddress + formatInstanceKey(instance.IndexKey)
+}
+
+// Error implements the error interface so verifiers can return a liveStateError.
+func (e *liveStateError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Category, e.Message)
+}
+
+// eksClusterScopedNames returns the cluster name and the nameKey name of an EKS node group, addon or
+// Fargate profile, falling back to the CLUSTER:NAME state ID. Both are empty if neither is complete.
+func eksClusterScopedNames(attributes map[string]interface{}, stateID, nameKey string) (string, string) {
+	clusterName, _ := attributes["cluster_name"].(string)
+	name, _ := attributes[nameKey].(string)
+	if clusterName != "" && name != "" {
+		return clusterName, name
+	}
+	if clusterName, name, ok := strings.Cut(stateID, ":"); ok && clusterName != "" && name != "" {
+		return clusterName, name
+	}
+	return "", ""
+}
+
+// stringSliceAttribute returns a list-of-strings attribute, ignoring non-string elements.
+func stringSliceAttribute(attributes map[string]interface{}, key string) []string {
+	raw, ok := attributes[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if str, ok := v.(string); ok && str != "" {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
+// numberAttribute returns a numeric attribute, accepting both JSON numbers and flatmap strings.
+func numberAttribute(attributes map[string]interface{}, key string) int64 {
+	switch v := attributes[key].(type) {
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+)
+
+// testCloudFrontWebACL is a CLOUDFRONT-scope web ACL, whose ARN names us-east-1 although it is global.
+func testCloudFrontWebACL(t *testing.T) (ResourceStateV4, InstanceObjectStateV4) {
+	t.Helper()
+	attributes, err := json.Marshal(map[string]string{
+		"id":    "a1b2c3d4-5678-90ab-cdef-EXAMPLE11111",
+		"name":  "edge",
+		"scope": "CLOUDFRONT",
+		"arn":   "arn:aws:wafv2:us-east-1:123456789012:global/webacl/edge/a1b2c3d4-5678-90ab-cdef-EXAMPLE11111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource := ResourceStateV4{
+		Mode:           "managed",
+		Type:           "aws_wafv2_web_acl",
+		Name:           "edge",
+		ProviderConfig: `provider["registry.terraform.io/hashicorp/aws"]`,
+	}
+	return resource, InstanceObjectStateV4{AttributesRaw: attributes}
+}
+
+func TestProcessResourceInstanceGlobalWAFV2(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if target := r.Header.Get("X-Amz-Target"); target != "AWSWAF_20190729.GetWebACL" {
+			t.Errorf("X-Amz-Target = %q, want GetWebACL", target)
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// Only the us-east-1 client is set: a call through the regional client would panic.
+	clients := &AWSClient{
+		WAFV2GlobalClient: wafv2.New(wafv2.Options{
+			Region:           "us-east-1",
+			BaseEndpoint:     aws.String(server.URL),
+			Credentials:      credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			RetryMaxAttempts: 1,
+		}),
+	}
+	resource, instance := testCloudFrontWebACL(t)
+	var mismatches atomic.Int64
+	status := processResourceInstance(context.Background(), clients, resource, instance, "eu-west-1", &mismatches)
+
+	if status.Category != "OK" {
+		t.Errorf("category = %s (%s), want OK", status.Category, status.Message)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("GetWebACL was called %d times, want 1", calls.Load())
+	}
+	if mismatches.Load() != 0 {
+		t.Errorf("%d region mismatches counted, want 0", mismatches.Load())
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// startProfiler creates dir and starts a CPU profile in it. The returned profiler must be stopped
+// with stop once verification is done.
+func startProfiler(dir string) (*profiler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory '%s': %w", dir, err)
+	}
+	cpuFile, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		_ = cpuFile.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+	return &profiler{started: time.Now(), dir: dir, cpuFile: cpuFile}, nil
+}
+
+// stop ends the CPU profile and writes heap.pprof and timings.txt next to it.
+func (p *profiler) stop(results *categorizedResults) error {
+	if p == nil {
+		return nil
+	}
+	pprof.StopCPUProfile()
+	if err := p.cpuFile.Close(); err != nil {
+		return fmt.Errorf("failed to close CPU profile: %w", err)
+	}
+
+	heapFile, err := os.Create(filepath.Join(p.dir, "heap.pprof"))
+	if err != nil {
+		return fmt.Errorf("failed to create heap profile: %w", err)
+	}
+	defer func() { _ = heapFile.Close() }()
+	runtime.GC() // Get up-to-date statistics for the heap profile.
+	if err := pprof.WriteHeapProfile(heapFile); err != nil {
+		return fmt.Errorf("failed to write heap profile: %w", err)
+	}
+
+	timingsPath := filepath.Join(p.dir, "timings.txt")
+	if err := os.WriteFile(timingsPath, []byte(renderTimings(results, time.Since(p.started))), 0644); err != nil {
+		return fmt.Errorf("failed to write timings '%s': %w", timingsPath, err)
+	}
+	return nil
+}
+
+// resourceTypeTimings totals verification time per resource type, slowest total first.
+// Results restored from a checkpoint carry no timing and are left out.
+func resourceTypeTimings(results *categorizedResults) []resourceTypeTiming {
+	byType := make(map[string]*resourceTypeTiming)
+	for _, category := range [][]ResourceStatus{
+		results.InfoResults, results.OkResults, results.WarningResults, results.ErrorResults,
+		results.PotentialImportResults, results.DangerousResults, results.RegionMismatchResults,
+		results.StaleResults, results.PendingDeletionResults, results.SkippedResults,
+	} {
+		for _, status := range category {
+			if status.Elapsed == 0 || status.ResourceType == "" {
+				continue
+			}
+			timing, ok := byType[status.ResourceType]
+			if !ok {
+				timing = &resourceTypeTiming{resourceType: status.ResourceType}
+				byType[status.ResourceType] = timing
+			}
+			timing.count++
+			timing.total += status.Elapsed
+			timing.max = max(timing.max, status.Elapsed)
+		}
+	}
+
+	timings := make([]resourceTypeTiming, 0, len(byType))
+	for _, timing := range byType {
+		timings = append(timings, *timing)
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].total != timings[j].total {
+			return timings[i].total > timings[j].total
+		}
+		return timings[i].resourceType < timings[j].resourceType
+	})
+	return timings
+}
+
+// renderTimings formats the per-resource-type breakdown as an aligned table. Totals are the sum of
+// per-resource time across workers, so they can exceed the wall-clock time of the run.
+func renderTimings(results *categorizedResults, wall time.Duration) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Wall time: %s\n\n", wall.Round(time.Millisecond))
+	w := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tCOUNT\tTOTAL\tMEAN\tMAX")
+	for _, timing := range resourceTypeTimings(results) {
+		mean := timing.total / time.Duration(timing.count)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", timing.resourceType, timing.count,
+			timing.total.Round(time.Millisecond), mean.Round(time.Millisecond), timing.max.Round(time.Millisecond))
+	}
+	_ = w.Flush()
+	return builder.String()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// verifiedProviders are the providers whose resources are verified against AWS. Resources of every
+// other provider are reported as SKIPPED instead of being checked.
+var verifiedProviders = map[string]bool{
+	"aws": true,
+}
+
+// stateOnlyProviders manage nothing outside the state, so --fail-unknown-providers does not count them.
+var stateOnlyProviders = map[string]bool{
+	"terraform": true,
+	"null":      true,
+	"random":    true,
+	"time":      true,
+	"local":     true,
+	"tls":       true,
+	"archive":   true,
+	"external":  true,
+	"http":      true,
+	"template":  true,
+	"cloudinit": true,
+}
+
+// resourceProvider returns the type of the provider that manages resource, e.g. "aws" or "google", from
+// its provider configuration address: provider["registry.terraform.io/hashicorp/aws"].west in current
+// states, provider.aws.west in those written by Terraform 0.12, both possibly inside a module. Without
+// one, the provider is the prefix of the resource type, as Terraform assumes.
+func resourceProvider(resource ResourceStateV4) string {
+	rest := resource.ProviderConfig
+	for strings.HasPrefix(rest, "module.") {
+		_, rest, _ = strings.Cut(rest[len("module."):], ".")
+	}
+	if source, ok := strings.CutPrefix(rest, "provider["); ok {
+		source, _, _ = strings.Cut(source, "]")
+		source = strings.Trim(source, `"`)
+		return source[strings.LastIndex(source, "/")+1:]
+	}
+	if name, ok := strings.CutPrefix(rest, "provider."); ok {
+		name, _, _ = strings.Cut(name, ".")
+		return name
+	}
+	name, _, _ := strings.Cut(resource.Type, "_")
+	return name
+}
+
+// providerSkippedStatus reports a resource instance of a provider this tool does not verify.
+func providerSkippedStatus(resource ResourceStateV4, tfAddress, stateID, provider string) ResourceStatus {
+	return ResourceStatus{
+		TerraformAddress: tfAddress,
+		Category:         "SKIPPED",
+		Message:          fmt.Sprintf("%s belongs to the %s provider, which this checker does not verify.", tfAddress, provider),
+		Kind:             resource.Mode,
+		StateID:          stateID,
+		TFID:             stateID,
+		Provider:         provider,
+	}
+}
+
+// interruptedResults returns the SKIPPED results of resources the run was stopped before verifying,
+// leaving out those skipped because of their provider.
+func interruptedResults(results *categorizedResults) []ResourceStatus {
+	var interrupted []ResourceStatus
+	for _, status := range results.SkippedResults {
+		if verifiedProviders[status.Provider] {
+			interrupted = append(interrupted, status)
+		}
+	}
+	return interrupted
+}
+
+// skippedProviders counts the SKIPPED results of every provider this tool does not verify.
+func skippedProviders(results *categorizedResults) map[string]int {
+	var counts map[string]int
+	for _, status := range results.SkippedResults {
+		if verifiedProviders[status.Provider] {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		counts[status.Provider]++
+	}
+	return counts
+}
+
+// unknownProviders returns the sorted providers of counts that manage infrastructure, for
+// --fail-unknown-providers.
+func unknownProviders(counts map[string]int) []string {
+	var unknown []string
+	for provider := range counts {
+		if !stateOnlyProviders[provider] {
+			unknown = append(unknown, provider)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
+
+// renderSkippedProviders renders how many resources of each provider were skipped, most first.
+func renderSkippedProviders(counts map[string]int) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	providers := make([]string, 0, len(counts))
+	for provider := range counts {
+		providers = append(providers, provider)
+	}
+	slices.SortFunc(providers, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n--- SKIPPED PROVIDERS (%d) ---\n", len(providers)))
+	for _, provider := range providers {
+		note := ""
+		if stateOnlyProviders[provider] {
+			note = " (state only)"
+		}
+		builder.WriteString(fmt.Sprintf("%s: %d%s\n", provider, counts[provider], note))
+	}
+	return builder.String()
+}
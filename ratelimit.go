@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// newTokenBucket returns a bucket that allows rate requests per second with bursts up to burst.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// newServiceRateLimiter returns a limiter handing out one token bucket per AWS service.
+func newServiceRateLimiter(rate float64, burst int) *serviceRateLimiter {
+	return &serviceRateLimiter{buckets: make(map[string]*tokenBucket), rate: rate, burst: burst}
+}
+
+// bucket returns the token bucket for serviceID, creating it on first use.
+func (l *serviceRateLimiter) bucket(serviceID string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[serviceID]
+	if !ok {
+		b = newTokenBucket(l.rate, l.burst)
+		l.buckets[serviceID] = b
+	}
+	return b
+}
+
+// addToStack registers the limiter in the finalize step, after the retry middleware, so every
+// attempt (including retries) waits for a token from its service's bucket.
+func (l *serviceRateLimiter) addToStack(stack *middleware.Stack) error {
+	return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("ServiceRateLimiter",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if err := l.bucket(awsmiddleware.GetServiceID(ctx)).wait(ctx); err != nil {
+				return middleware.FinalizeOutput{}, middleware.Metadata{}, fmt.Errorf("rate limiter wait aborted: %w", err)
+			}
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+}
@@ -1,96 +1,318 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
 // Read reads a state from the given reader.
+// The state is decoded as a stream, one resource at a time, so the raw file is never held in
+// memory alongside the decoded state.
 func Read(r io.Reader) (*TFStateFile, error) {
 	if f, ok := r.(*os.File); ok && f == nil {
 		return nil, ErrNoState
 	}
 
-	src, err := io.ReadAll(r)
+	br := bufio.NewReaderSize(r, 64*1024)
+	first, err := firstNonSpaceByte(br)
 	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoState
+		}
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
-
-	if len(src) == 0 {
-		return nil, ErrNoState
+	switch first {
+	case '{':
+	case '[':
+		return nil, errors.New("the state file could not be parsed: it is a JSON array, but a state is a JSON object")
+	default:
+		return nil, errors.New("the state is stored in a legacy binary format that is not supported since Terraform v0.7. To continue, first upgrade the state using Terraform 0.6.16 or earlier")
 	}
 
-	state, err := readState(src)
+	state, err := readStateStream(br)
 	if err != nil {
 		return nil, err
 	}
 
 	if state == nil {
-		panic("readState returned nil state with no errors")
+		panic("readStateStream returned nil state with no errors")
 	}
 
 	return state, nil
 }
 
-// readState processes the bytes and returns a *TFStateFile resource
-func readState(src []byte) (*TFStateFile, error) {
-	if looksLikeVersion0(src) {
-		return nil, errors.New("the state is stored in a legacy binary format that is not supported since Terraform v0.7. To continue, first upgrade the state using Terraform 0.6.16 or earlier")
+// readStateStream decodes a state from r without buffering the whole document. Version 4 states are
+// decoded and version 3 states upgraded to version 4 in memory; other versions are refused before their
+// body is decoded. Anything but whitespace after the state object is rejected.
+func readStateStream(r io.Reader) (*TFStateFile, error) {
+	dec := json.NewDecoder(r)
+	f, err := decodeState(dec)
+	if err != nil {
+		return nil, err
 	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the state file could not be parsed as JSON: unexpected data after the state object at byte offset %d", dec.InputOffset())
+	}
+	return f, nil
+}
 
-	version, err := sniffJSONStateVersion(src)
-	if err != nil {
+// heldStateKey is a top-level key that came before "version", kept until the format version is known.
+type heldStateKey struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeState walks the top-level state object token by token. The format version decides how the
+// other keys are decoded, so keys that come before "version" are held back until it is read. The
+// resources array is decoded one element at a time straight into the TFStateFile, avoiding the
+// intermediate StateFileV4 copy.
+func decodeState(dec *json.Decoder) (*TFStateFile, error) {
+	if err := expectDelim(dec, '{'); err != nil {
 		return nil, err
 	}
 
-	switch version {
-	case 0:
-		return nil, errors.New("the state file uses JSON syntax but has a version number of zero. There was never a JSON-based state format zero, so this state file is invalid and cannot be processed")
-	case 1:
-		return nil, errors.New("version1 terraform state files not supported")
-	case 2:
-		return nil, errors.New("version2 terraform state files not supported")
+	f := &TFStateFile{
+		RootOutputs: make(map[string]OutputStateV4),
+	}
+	sawVersion := false
+	var held []heldStateKey
+	var legacyModules []stateModuleV3
+	for dec.More() {
+		keyToken, err := dec.Token()
+		if err != nil {
+			return nil, stateSyntaxError(err)
+		}
+		key, _ := keyToken.(string)
+
+		switch {
+		case key == "version" && sawVersion:
+			return nil, errors.New("the state file has more than one \"version\" attribute")
+		case key == "version":
+			if f.Version, err = decodeStateVersion(dec); err != nil {
+				return nil, err
+			}
+			sawVersion = true
+			for _, k := range held {
+				if err := decodeStateKey(json.NewDecoder(bytes.NewReader(k.value)), k.key, f, &legacyModules); err != nil {
+					return nil, err
+				}
+			}
+			held = nil
+		case !sawVersion:
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, stateSyntaxError(err)
+			}
+			held = append(held, heldStateKey{key, value})
+		default:
+			if err := decodeStateKey(dec, key, f, &legacyModules); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	if !sawVersion {
+		return nil, errors.New("the state file does not have a \"version\" attribute, which is required to identify the format version")
+	}
+	if f.RootOutputs == nil {
+		f.RootOutputs = make(map[string]OutputStateV4)
+	}
+	switch f.Version {
 	case 3:
-		return nil, errors.New("version3 terraform state files not supported")
+		return upgradeStateV3(f, legacyModules)
 	case 4:
-		return readStateV4(src)
+		return f, nil
+	default:
+		return nil, unsupportedStateVersionError(f.Version, validTerraformVersion(f.TerraformVersion))
+	}
+}
+
+// decodeStateVersion reads the value of "version", which must be a bare whole number, and refuses the
+// format versions that are known not to be supported.
+func decodeStateVersion(dec *json.Decoder) (uint64, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return 0, stateSyntaxError(err)
+	}
+	version, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("the version in the state file is %s. A positive whole number is required", raw)
+	}
+	switch version {
+	case 0:
+		return 0, errors.New("the state file uses JSON syntax but has a version number of zero. There was never a JSON-based state format zero, so this state file is invalid and cannot be processed")
+	case 1, 2:
+		return 0, fmt.Errorf("version%d terraform state files not supported", version)
+	}
+	return version, nil
+}
+
+// decodeStateKey decodes the value of the top-level key into f as format version f.Version lays it out.
+// Of a state in a newer format only the Terraform version that wrote it is kept, for the error message.
+func decodeStateKey(dec *json.Decoder, key string, f *TFStateFile, legacyModules *[]stateModuleV3) error {
+	if f.Version > 4 {
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return stateSyntaxError(err)
+		}
+		if key == "terraform_version" {
+			_ = json.Unmarshal(value, &f.TerraformVersion)
+		}
+		return nil
+	}
+
+	var err error
+	switch key {
+	case "terraform_version":
+		err = dec.Decode(&f.TerraformVersion)
+	case "serial":
+		err = dec.Decode(&f.Serial)
+	case "lineage":
+		err = dec.Decode(&f.Lineage)
+	case "outputs":
+		err = dec.Decode(&f.RootOutputs)
+	case "check_results":
+		err = dec.Decode(&f.CheckResults)
+	case "resources":
+		err = decodeResources(dec, f)
+	case "modules":
+		if f.Version == 4 {
+			return errors.New("the state file has format version 4 but uses the \"modules\" layout of format version 3. It may have been edited by hand")
+		}
+		err = dec.Decode(legacyModules)
 	default:
-		creatingVersion := sniffJSONStateTerraformVersion(src)
-		if creatingVersion != "" {
-			return nil, fmt.Errorf("the state file uses format version %d, which is not supported by this program. This state file was created by Terraform %s", version, creatingVersion)
+		var value json.RawMessage
+		if err = dec.Decode(&value); err == nil {
+			if f.Unknown == nil {
+				f.Unknown = make(map[string]json.RawMessage)
+			}
+			f.Unknown[key] = value
 		}
-		return nil, fmt.Errorf("the state file uses format version %d, which is not supported by this program. This state file may have been created by a newer version of Terraform", version)
 	}
+	if err != nil {
+		return fmt.Errorf("failed to parse state file as version %d: %w", f.Version, err)
+	}
+	return nil
 }
 
-// readStateV4 uses json.Unmarshal from the []byte to return a *TFStateFile resource
-func readStateV4(src []byte) (*TFStateFile, error) {
-	var s StateFileV4
-	err := json.Unmarshal(src, &s)
+// decodeResources streams the "resources" array into f.Resources.
+func decodeResources(dec *json.Decoder, f *TFStateFile) error {
+	token, err := dec.Token()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse state file as version 4: %w", err)
+		return err
+	}
+	if token == nil {
+		return nil // "resources": null
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected resources to be an array, got %v", token)
 	}
+	for dec.More() {
+		var resource ResourceStateV4
+		if err := dec.Decode(&resource); err != nil {
+			return err
+		}
+		if _, err := parseModulePath(resource.Module); err != nil {
+			return fmt.Errorf("resource %s.%s has an invalid module address %q: %w", resource.Type, resource.Name, resource.Module, err)
+		}
+		f.Resources = append(f.Resources, resource)
+	}
+	_, err = dec.Token() // closing ']'
+	return err
+}
 
-	// Convert StateFileV4 to the common TFStateFile struct
-	f := &TFStateFile{
-		Version:          4,
-		TerraformVersion: s.TerraformVersion,
-		Serial:           s.Serial,
-		Lineage:          s.Lineage,
-		RootOutputs:      make(map[string]OutputStateV4),
-		Resources:        make([]ResourceStateV4, len(s.Resources)),
-		CheckResults:     s.CheckResults,
+// UnmarshalJSON decodes the resource and keeps the keys this program does not model so Write can write
+// them back.
+func (r *ResourceStateV4) UnmarshalJSON(src []byte) error {
+	type plain ResourceStateV4
+	if err := json.Unmarshal(src, (*plain)(r)); err != nil {
+		return err
+	}
+	unknown, err := unknownKeys(src, reflect.TypeFor[ResourceStateV4]())
+	r.Unknown = unknown
+	return err
+}
+
+// UnmarshalJSON decodes the instance and keeps the keys this program does not model, such as the resource
+// identity Terraform 1.12 records, so Write can write them back.
+func (i *InstanceObjectStateV4) UnmarshalJSON(src []byte) error {
+	type plain InstanceObjectStateV4
+	if err := json.Unmarshal(src, (*plain)(i)); err != nil {
+		return err
+	}
+	unknown, err := unknownKeys(src, reflect.TypeFor[InstanceObjectStateV4]())
+	i.Unknown = unknown
+	return err
+}
+
+// unknownKeys returns the keys of the object src that are not the JSON name of a field of t, or nil.
+func unknownKeys(src []byte, t reflect.Type) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(src, &fields); err != nil {
+		return nil, err
+	}
+	for n := 0; n < t.NumField(); n++ {
+		if name, _, _ := strings.Cut(t.Field(n).Tag.Get("json"), ","); name != "-" {
+			delete(fields, name)
+		}
 	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
 
-	for k, v := range s.RootOutputs {
-		f.RootOutputs[k] = v
+// expectDelim consumes the next token and checks it is the given delimiter.
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return stateSyntaxError(err)
 	}
-	for i, r := range s.Resources {
-		f.Resources[i] = r
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("the state file could not be parsed as JSON: expected '%c', got %v", want, token)
 	}
+	return nil
+}
 
-	return f, nil
+// stateSyntaxError reports a decoder error as a state file that is not valid JSON.
+func stateSyntaxError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("the state file could not be parsed as JSON: syntax error at byte offset %d", syntaxErr.Offset)
+	}
+	return fmt.Errorf("the state file could not be parsed as JSON: %w", err)
+}
+
+// unsupportedStateVersionError describes a state format version this program cannot read.
+func unsupportedStateVersionError(version uint64, creatingVersion string) error {
+	if creatingVersion != "" {
+		return fmt.Errorf("the state file uses format version %d, which is not supported by this program. This state file was created by Terraform %s", version, creatingVersion)
+	}
+	return fmt.Errorf("the state file uses format version %d, which is not supported by this program. This state file may have been created by a newer version of Terraform", version)
+}
+
+// firstNonSpaceByte peeks at the first non-whitespace byte without consuming it.
+func firstNonSpaceByte(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string // Substring of the error; empty when Read must succeed
+	}{
+		{"version first", `{"version": 4, "serial": 2, "lineage": "a", "resources": []}`, ""},
+		{"version after other keys", `{"serial": 2, "lineage": "a", "resources": [], "version": 4}`, ""},
+		{"resources null", `{"version": 4, "serial": 2, "lineage": "a", "resources": null}`, ""},
+		{"trailing whitespace", "{\"version\": 4, \"serial\": 2, \"lineage\": \"a\"}\n\n", ""},
+		{"duplicate version", `{"version": 4, "serial": 2, "version": 4}`, `more than one "version"`},
+		{"quoted version", `{"version": "4"}`, "positive whole number"},
+		{"negative version", `{"version": -1}`, "positive whole number"},
+		{"missing version", `{"serial": 2, "lineage": "a"}`, `does not have a "version"`},
+		{"version 2", `{"version": 2}`, "version2 terraform state files not supported"},
+		{"version 5", `{"version": 5, "terraform_version": "2.0.0", "resources": {"new": "layout"}}`, "format version 5, which is not supported by this program. This state file was created by Terraform 2.0.0"},
+		{"version 5 before its version", `{"resources": {"new": "layout"}, "version": 5}`, "format version 5"},
+		{"version 4 with modules", `{"version": 4, "modules": []}`, `uses the "modules" layout of format version 3`},
+		{"trailing data", `{"version": 4, "serial": 2, "lineage": "a"} {}`, "unexpected data after the state object"},
+		{"array", `[{"version": 4}]`, "it is a JSON array"},
+		{"binary", "\x00\x01terraform", "legacy binary format"},
+		{"empty", "  \n", "no state"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := Read(strings.NewReader(tt.src))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Read: %v", err)
+				}
+				if state.Version != 4 || state.Serial != 2 || state.Lineage != "a" {
+					t.Errorf("Read = version %d serial %d lineage %q, want version 4 serial 2 lineage \"a\"", state.Version, state.Serial, state.Lineage)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadKeepsUnknownKeys(t *testing.T) {
+	src := `{
+  "version": 4,
+  "terraform_version": "1.12.1",
+  "serial": 1,
+  "lineage": "a",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "schema_version": 0,
+          "attributes": {
+            "id": "logs"
+          },
+          "identity": {
+            "bucket": "logs"
+          },
+          "x_instance": [1, 2]
+        }
+      ],
+      "x_resource": true
+    }
+  ],
+  "check_results": null,
+  "x_state": {"kept": "yes"}
+}
+`
+	state := readTestState(t, src)
+	if got := string(state.Unknown["x_state"]); got != `{"kept": "yes"}` {
+		t.Errorf("unknown state key = %s, want {\"kept\": \"yes\"}", got)
+	}
+	if got := string(state.Resources[0].Unknown["x_resource"]); got != "true" {
+		t.Errorf("unknown resource key = %s, want true", got)
+	}
+	instance := state.Resources[0].Instances[0]
+	if _, ok := instance.Unknown["identity"]; !ok {
+		t.Errorf("instance identity was dropped, unknown keys are %v", instance.Unknown)
+	}
+
+	var want, got interface{}
+	if err := json.Unmarshal([]byte(src), &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(writeState(t, state), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Read→Write lost unknown keys:\nwant %v\ngot  %v", want, got)
+	}
+}
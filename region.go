@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// fallbackRegion is used to reach the state (e.g. download it from S3) when neither --region nor the
+// SDK's own configuration names a region. Verification always uses a region chosen from the state.
+const fallbackRegion = "us-east-1"
+
+// regionVotes counts, per region, the resources in the state whose ARN names that region.
+// Global services (IAM, CloudFront, Route53) have no region in their ARNs and are not counted, nor are
+// CLOUDFRONT-scope WAFv2 resources, whose ARNs name us-east-1.
+func regionVotes(tfState *TFStateFile) map[string]int {
+	votes := make(map[string]int)
+	for _, resource := range tfState.Resources {
+		if resource.Mode == "data" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			arn := instanceStringAttribute(instance, "arn")
+			if isGlobalWAFV2ARN(arn) {
+				continue
+			}
+			if region := extractRegionFromARN(arn); region != "" {
+				votes[region]++
+			}
+		}
+	}
+	return votes
+}
+
+// inferRegion picks the region most ARNs in the state belong to, breaking ties alphabetically so the
+// choice is stable. When the state has no regional ARNs, configuredRegion (from AWS_REGION or the
+// shared config) is used; with neither, an error asks for --region.
+func inferRegion(tfState *TFStateFile, configuredRegion string) (string, string, error) {
+	votes := regionVotes(tfState)
+	if len(votes) == 0 {
+		if configuredRegion == "" {
+			return "", "", fmt.Errorf("could not infer the AWS region: the state has no regional ARNs and no region is configured; pass --region")
+		}
+		return configuredRegion, "the AWS SDK configuration (the state has no regional ARNs)", nil
+	}
+
+	regions := make([]string, 0, len(votes))
+	total := 0
+	for region, count := range votes {
+		regions = append(regions, region)
+		total += count
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		if votes[regions[i]] != votes[regions[j]] {
+			return votes[regions[i]] > votes[regions[j]]
+		}
+		return regions[i] < regions[j]
+	})
+	selected := regions[0]
+	reason := fmt.Sprintf("%d of %d regional ARNs in the state", votes[selected], total)
+	if len(regions) > 1 {
+		others, _ := json.Marshal(votes)
+		reason = fmt.Sprintf("%s; ARNs per region: %s", reason, others)
+	}
+	return selected, reason, nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRegionVotes(t *testing.T) {
+	webACL, webACLInstance := testCloudFrontWebACL(t)
+	queue := ResourceStateV4{Mode: "managed", Type: "aws_sqs_queue", Name: "jobs", Instances: []InstanceObjectStateV4{{
+		AttributesRaw: json.RawMessage(`{"arn": "arn:aws:sqs:eu-west-1:123456789012:jobs"}`),
+	}}}
+	role := ResourceStateV4{Mode: "managed", Type: "aws_iam_role", Name: "app", Instances: []InstanceObjectStateV4{{
+		AttributesRaw: json.RawMessage(`{"arn": "arn:aws:iam::123456789012:role/app"}`),
+	}}}
+	webACL.Instances = []InstanceObjectStateV4{webACLInstance}
+
+	votes := regionVotes(&TFStateFile{Resources: []ResourceStateV4{queue, role, webACL}})
+	if want := map[string]int{"eu-west-1": 1}; !reflect.DeepEqual(votes, want) {
+		t.Errorf("regionVotes = %v, want %v", votes, want)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// followRemoteStates verifies the states referenced by the terraform_remote_state data sources of root,
+// and the states those reference in turn, breadth first up to config.RemoteStateDepth hops, for
+// --follow-remote-state. Every state is verified once, however many data sources reference it, so
+// cycles between stacks end where they close. States that cannot be read are reported with their error.
+func followRemoteStates(ctx context.Context, awsClients *AWSClient, config Config, root *TFStateFile) []linkedState {
+	type pending struct {
+		state    *TFStateFile
+		location string
+		depth    int
+	}
+	rootLocation := stateLocation(config)
+	visited := map[string]bool{rootLocation: true}
+	queue := []pending{{state: root, location: rootLocation}}
+	var linked []linkedState
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, ref := range remoteStateRefs(current.state, config.TerraformWorkingDir) {
+			if visited[ref.Location] {
+				continue
+			}
+			if current.depth+1 > config.RemoteStateDepth {
+				log.Printf("Not following %s in %s to %s: --remote-state-depth %d reached.", ref.Address, current.location, ref.Location, config.RemoteStateDepth)
+				continue
+			}
+			visited[ref.Location] = true
+			link := linkedState{State: ref.Location, Parent: current.location, Address: ref.Address, Depth: current.depth + 1}
+			if ctx.Err() != nil {
+				link.Error = fmt.Sprintf("not verified before the run was stopped (%v)", ctx.Err())
+				linked = append(linked, link)
+				continue
+			}
+			if !config.JsonOutput {
+				fmt.Printf("Following %s to %s (depth %d)\n", ref.Address, ref.Location, link.Depth)
+			}
+			state, err := readRemoteState(ctx, awsClients, ref)
+			if err != nil {
+				link.Error = err.Error()
+				linked = append(linked, link)
+				continue
+			}
+			link.Lineage = state.Lineage
+			results := processResources(ctx, awsClients, state, config.AWSRegion, config.Concurrency, config.APITimeout, nil, nil)
+			sortResults(results)
+			link.Results = jsonResults(results)
+			linked = append(linked, link)
+			queue = append(queue, pending{state: state, location: ref.Location, depth: link.Depth})
+		}
+	}
+	return linked
+}
+
+// stateLocation identifies the state of config the way remoteStateRefs identifies referenced states.
+func stateLocation(config Config) string {
+	if config.IsS3State {
+		return "s3://" + config.S3Bucket + "/" + config.S3Key
+	}
+	if abs, err := filepath.Abs(config.StateFilePath); err == nil {
+		return abs
+	}
+	return config.StateFilePath
+}
+
+// remoteStateRefs returns the states referenced by the terraform_remote_state data sources of state that
+// use the s3 or local backend. Relative local paths are resolved against tfDir, as Terraform resolves them
+// against its working directory. Other backends are logged and skipped.
+func remoteStateRefs(state *TFStateFile, tfDir string) []remoteStateRef {
+	var refs []remoteStateRef
+	for _, resource := range state.Resources {
+		if resource.Mode != "data" || resource.Type != "terraform_remote_state" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			address := resourceInstanceAddress(resource, instance)
+			var attributes struct {
+				Backend   string          `json:"backend"`
+				Workspace string          `json:"workspace"`
+				Config    json.RawMessage `json:"config"`
+			}
+			if err := json.Unmarshal(instance.AttributesRaw, &attributes); err != nil {
+				log.Printf("WARNING: Cannot follow %s: %v", address, err)
+				continue
+			}
+			backendConfig := remoteStateConfig(attributes.Config)
+			workspace := attributes.Workspace
+			if workspace == "default" {
+				workspace = ""
+			}
+			ref := remoteStateRef{Address: address, Backend: attributes.Backend}
+			switch attributes.Backend {
+			case "s3":
+				ref.Bucket, ref.Key, ref.Region = backendConfig["bucket"], backendConfig["key"], backendConfig["region"]
+				if ref.Bucket == "" || ref.Key == "" {
+					log.Printf("WARNING: Cannot follow %s: its s3 backend configuration has no bucket or key.", address)
+					continue
+				}
+				if workspace != "" {
+					prefix := backendConfig["workspace_key_prefix"]
+					if prefix == "" {
+						prefix = "env:"
+					}
+					ref.Key = prefix + "/" + workspace + "/" + ref.Key
+				}
+				ref.Location = "s3://" + ref.Bucket + "/" + ref.Key
+			case "local":
+				ref.Path = backendConfig["path"]
+				if ref.Path == "" {
+					ref.Path = "terraform.tfstate"
+				}
+				if workspace != "" {
+					workspaceDir := backendConfig["workspace_dir"]
+					if workspaceDir == "" {
+						workspaceDir = "terraform.tfstate.d"
+					}
+					ref.Path = filepath.Join(workspaceDir, workspace, "terraform.tfstate")
+				}
+				if !filepath.IsAbs(ref.Path) {
+					ref.Path = filepath.Join(tfDir, ref.Path)
+				}
+				if abs, err := filepath.Abs(ref.Path); err == nil {
+					ref.Path = abs
+				}
+				ref.Location = ref.Path
+			default:
+				log.Printf("Not following %s: the %q backend is not supported, only s3 and local.", address, attributes.Backend)
+				continue
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// remoteStateConfig reads the string settings of a terraform_remote_state config attribute. Being of a
+// dynamic type, it is stored either as the object itself or wrapped as {"value": ..., "type": ...}.
+func remoteStateConfig(raw json.RawMessage) map[string]string {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil
+	}
+	if value, ok := object["value"]; ok && object["type"] != nil {
+		object = nil
+		if err := json.Unmarshal(value, &object); err != nil {
+			return nil
+		}
+	}
+	settings := make(map[string]string, len(object))
+	for name, value := range object {
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			settings[name] = s
+		}
+	}
+	return settings
+}
+
+// readRemoteState reads the state ref points to. S3 states are decoded straight from the response body,
+// with a client for the bucket's region when the backend configuration names one.
+func readRemoteState(ctx context.Context, awsClients *AWSClient, ref remoteStateRef) (*TFStateFile, error) {
+	if ref.Backend == "local" {
+		return openAndReadStateFile(ref.Path)
+	}
+	client := awsClients.s3ClientFor(ref.Bucket)
+	if ref.Region != "" && ref.Region != client.Options().Region {
+		client = s3.New(client.Options(), func(o *s3.Options) { o.Region = ref.Region })
+	}
+	resp, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(ref.Bucket), Key: aws.String(ref.Key)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download state from S3: %w", err)
+	}
+	defer resp.Body.Close()
+	state, err := Read(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state file '%s': %w", ref.Location, err)
+	}
+	return state, nil
+}
+
+// jsonResults converts the results of a linked state to their JSON form.
+func jsonResults(results *categorizedResults) JSONResults {
+	return JSONResults{
+		InfoResults:            convertResourceStatusToJSONItem(results.InfoResults),
+		OkResults:              convertResourceStatusToJSONItem(results.OkResults),
+		PotentialImportResults: convertResourceStatusToJSONItem(results.PotentialImportResults),
+		RegionMismatchResults:  convertResourceStatusToJSONItem(results.RegionMismatchResults),
+		WarningResults:         convertResourceStatusToJSONItem(results.WarningResults),
+		ErrorResults:           convertResourceStatusToJSONItem(results.ErrorResults),
+		DangerousResults:       convertResourceStatusToJSONItem(results.DangerousResults),
+		StaleResults:           convertResourceStatusToJSONItem(results.StaleResults),
+		PendingDeletionResults: convertResourceStatusToJSONItem(results.PendingDeletionResults),
+		SkippedResults:         convertResourceStatusToJSONItem(results.SkippedResults),
+	}
+}
+
+// renderLinkedStates renders the states followed with --follow-remote-state: for each, where it was
+// referenced from, its result counts and the resources that need attention.
+func renderLinkedStates(linked []linkedState) string {
+	if len(linked) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n--- LINKED STATES (%d) ---\n", len(linked)))
+	for _, link := range linked {
+		builder.WriteString(fmt.Sprintf("%s (depth %d, via %s in %s)\n", link.State, link.Depth, link.Address, link.Parent))
+		if link.Error != "" {
+			builder.WriteString(fmt.Sprintf("   ERROR: %s\n", link.Error))
+			continue
+		}
+		categories := []struct {
+			name  string
+			items []JSONResultItem
+		}{
+			{"OK", link.Results.OkResults},
+			{"INFO", link.Results.InfoResults},
+			{"WARNING", link.Results.WarningResults},
+			{"ERROR", link.Results.ErrorResults},
+			{"REGION_MISMATCH", link.Results.RegionMismatchResults},
+			{"POTENTIAL_IMPORT", link.Results.PotentialImportResults},
+			{"DANGEROUS", link.Results.DangerousResults},
+			{"STALE", link.Results.StaleResults},
+			{"PENDING_DELETION", link.Results.PendingDeletionResults},
+			{"SKIPPED", link.Results.SkippedResults},
+		}
+		var counts []string
+		for _, category := range categories {
+			if len(category.items) > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", len(category.items), category.name))
+			}
+		}
+		if len(counts) == 0 {
+			counts = append(counts, "no resources")
+		}
+		builder.WriteString("   " + strings.Join(counts, ", ") + "\n")
+		for _, category := range categories {
+			switch category.name {
+			case "OK", "INFO":
+				continue
+			}
+			for _, item := range category.items {
+				builder.WriteString(fmt.Sprintf("   %s: %s", category.name, item.Resource))
+				if item.TFID != "" {
+					builder.WriteString(fmt.Sprintf(" (state: %s)", item.TFID))
+				}
+				builder.WriteString("\n")
+			}
+		}
+	}
+	return builder.String()
+}
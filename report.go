@@ -0,0 +1,140 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runReport prints a JSON report written by an earlier run, as text or as the original JSON.
+func runReport(flags *flag.FlagSet, args []string) error {
+	from := flags.String("from", "", "Optional: Path of the report.<state>.json to print. Defaults to the newest one in --backups-dir.")
+	backupsDir := flags.String("backups-dir", filepath.Join(".", "backups"), "Directory the reports were written to.")
+	reportDir := flags.String("report-dir", "", "Optional: Directory the reports were written to when the run used --report-dir. Overrides --backups-dir.")
+	stateName := flags.String("state", "", "Optional: Only consider reports of this state file (e.g. dev.tfstate or the S3 key's file name).")
+	jsonOutput := flags.Bool("json", false, "If true, print the report as JSON instead of text.")
+	parseFlags(flags, args)
+
+	path := *from
+	if path == "" {
+		var err error
+		if path, err = latestReport(cmp.Or(*reportDir, *backupsDir), *stateName); err != nil {
+			return err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read report '%s': %w", path, err)
+	}
+	if *jsonOutput {
+		fmt.Println(strings.TrimSpace(string(data)))
+		return nil
+	}
+	var report JSONOutput
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("failed to parse report '%s': %w", path, err)
+	}
+	printJSONReport(path, report)
+	return nil
+}
+
+// latestReport returns the most recently written report.<state>.json under backupsDir.
+func latestReport(backupsDir, stateName string) (string, error) {
+	pattern := "report.*.json"
+	if stateName != "" {
+		pattern = fmt.Sprintf("report.%s.json", stateBaseName(filepath.Base(stateName)))
+	}
+	var latest string
+	var latestInfo fs.FileInfo
+	err := filepath.WalkDir(backupsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if matched, _ := filepath.Match(pattern, entry.Name()); entry.IsDir() || !matched {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if latestInfo == nil || info.ModTime().After(latestInfo.ModTime()) {
+			latest, latestInfo = path, info
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search '%s' for reports: %w", backupsDir, err)
+	}
+	if latest == "" {
+		return "", errors.New("no report found in " + backupsDir)
+	}
+	return latest, nil
+}
+
+// printJSONReport prints a report read back from JSON in the layout of the run's own output.
+func printJSONReport(path string, report JSONOutput) {
+	fmt.Println("--- Terraform State Reconciliation Report ---")
+	fmt.Printf("Report: %s\n", path)
+	fmt.Printf("State File: %s (State Version: %d, Terraform Version: %s)\n", report.State, report.StateVersion, report.TFVersion)
+	fmt.Printf("AWS Region: %s\n", report.Region)
+	fmt.Printf("State Checksum: %s\n", report.StateChecksum)
+	fmt.Println("-------------------------------------------")
+
+	printJSONCategory("INFO Results", report.Results.InfoResults)
+	printJSONCategory("OK Results", report.Results.OkResults)
+	printJSONCategory("WARNING Results", report.Results.WarningResults)
+	printJSONCategory("ERROR Results", report.Results.ErrorResults)
+	printJSONCategory("REGION MISMATCH Results", report.Results.RegionMismatchResults)
+	printJSONCategory("POTENTIAL IMPORT Results", report.Results.PotentialImportResults)
+	printJSONCategory("DANGEROUS Results", report.Results.DangerousResults)
+	printJSONCategory("STALE Results", report.Results.StaleResults)
+	printJSONCategory("PENDING DELETION Results", report.Results.PendingDeletionResults)
+	printJSONCategory("SKIPPED Results", report.Results.SkippedResults)
+	fmt.Print(renderSkippedProviders(report.SkippedProviders))
+	fmt.Print(renderLinkedStates(report.LinkedStates))
+	fmt.Print(renderFailedChecks(report.FailedChecks))
+	fmt.Print(renderRecentChanges(report.RecentChanges))
+	fmt.Print(renderPolicyViolations(report.PolicyViolations))
+
+	if len(report.Commands) > 0 {
+		fmt.Printf("\n--- SUGGESTED REMEDIATION COMMANDS (%d) ---\n", len(report.Commands))
+		for _, cmd := range report.Commands {
+			fmt.Printf("   %s\n", cmd)
+		}
+	}
+	if len(report.ExecutionLogs) > 0 {
+		fmt.Printf("\n--- COMMAND EXECUTION LOGS (%d) ---\n", len(report.ExecutionLogs))
+		for _, log := range report.ExecutionLogs {
+			fmt.Printf("Command: %s (exit code %d)\n", log.Command, log.ExitCode)
+		}
+	}
+	if report.ApplicationError != "" {
+		fmt.Printf("\n--- APPLICATION ERROR ---\n%s\n", report.ApplicationError)
+	}
+	fmt.Println("-------------------------------------------")
+}
+
+// printJSONCategory prints the items of one result category of a JSON report.
+func printJSONCategory(title string, items []JSONResultItem) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("\n--- %s (%d) ---\n", title, len(items))
+	for _, item := range items {
+		line := item.Resource
+		if item.TFID != "" {
+			line += fmt.Sprintf(" (state: %s", item.TFID)
+			if item.AWSID != "" && item.AWSID != item.TFID {
+				line += fmt.Sprintf(", live: %s", item.AWSID)
+			}
+			line += ")"
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// runBackupRestore verifies a state backup against its .sha256 file or SHA256SUMS entry and restores it over the state it
+// was taken of, or over --state/--s3-state when given. Over a state of the same lineage it is written as the
+// next snapshot of that state: only its serial is raised, the rest of the backup is restored byte for byte. The state it replaces is backed up first, and
+// the restore is recorded as restore.<state>.json in a new run directory.
+func runBackupRestore(flags *flag.FlagSet, args []string) error {
+	from := flags.String("from", "", "Path of the state backup to restore (e.g. backups/2024/05/21-10-30-00/original.dev.tfstate) or its S3 URI. With --s3-state, a key in the backup bucket also works.")
+	noVerify := flags.Bool("no-verify", false, "If true, restore a backup that has neither a .sha256 file nor a SHA256SUMS entry. A backup that does not match its hash is never restored.")
+	force := flags.Bool("force", false, "If true, restore over a state with a different lineage.")
+	config := parseAndValidateConfig(flags, args)
+
+	if *from == "" {
+		return errors.New("--from is required")
+	}
+	if config.DryRun || config.NoBackups {
+		return errors.New("--dry-run and --no-backups cannot be used with backup restore")
+	}
+	ctx := context.Background()
+	var awsClients *AWSClient
+	clients := func() (*AWSClient, error) {
+		if awsClients == nil {
+			var err error
+			if awsClients, err = NewAWSClient(ctx, config); err != nil {
+				return nil, fmt.Errorf("failed to initialize AWS clients: %w", err)
+			}
+		}
+		return awsClients, nil
+	}
+
+	// 1. Fetch the backup, the hash written next to it and the report of its run
+	var backupBucket, backupKey string
+	if strings.HasPrefix(*from, "s3://") {
+		var err error
+		if backupBucket, backupKey, err = parseS3URI(*from); err != nil {
+			return err
+		}
+	} else if _, err := os.Stat(*from); err != nil && config.IsS3State {
+		backupBucket, backupKey = s3BackupBucket(config), *from
+	}
+	reportName := restoreReportName(*from)
+	backupPath := *from
+	var expectedHash, report []byte
+	var hashErr error
+	if backupKey != "" {
+		s3Clients, err := clients()
+		if err != nil {
+			return err
+		}
+		backupPath = createLocalTempStateFile("restore")
+		defer globalTemps.remove(backupPath)
+		if err := downloadStateFileFromS3(ctx, s3Clients, backupPath, backupBucket, backupKey); err != nil {
+			return fmt.Errorf("failed to download backup '%s': %w", *from, err)
+		}
+		expectedHash, hashErr = readS3Object(ctx, s3Clients, backupBucket, backupKey+".sha256")
+		if hashErr != nil {
+			// Runs with --checksums sums only have the SHA256SUMS of their run directory
+			if sums, err := readS3Object(ctx, s3Clients, backupBucket, path.Join(path.Dir(backupKey), checksumsFileName)); err == nil {
+				expectedHash, hashErr = checksumOf(sums, path.Base(backupKey), hashErr)
+			}
+		}
+		report, _ = readS3Object(ctx, s3Clients, backupBucket, path.Join(path.Dir(backupKey), reportName))
+	} else {
+		expectedHash, hashErr = os.ReadFile(*from + ".sha256")
+		if hashErr != nil {
+			if sums, err := os.ReadFile(filepath.Join(filepath.Dir(*from), checksumsFileName)); err == nil {
+				expectedHash, hashErr = checksumOf(sums, filepath.Base(*from), hashErr)
+			}
+		}
+		report, _ = os.ReadFile(filepath.Join(filepath.Dir(*from), reportName))
+	}
+
+	// 2. Only restore what is verified to be the backup that was written, and is a state at all
+	backupHash, err := calculateFileSHA256(backupPath)
+	if err != nil {
+		return err
+	}
+	switch {
+	case hashErr != nil && !*noVerify:
+		return fmt.Errorf("cannot verify '%s' without its .sha256 file or SHA256SUMS entry: %w. Use --no-verify to restore it anyway", *from, hashErr)
+	case hashErr == nil && strings.TrimSpace(string(expectedHash)) != backupHash:
+		return fmt.Errorf("backup '%s' does not match its .sha256 file (expected %s, got %s); not restoring it", *from, strings.TrimSpace(string(expectedHash)), backupHash)
+	}
+	backupState, err := openAndReadStateFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("backup '%s' is not a valid state file: %w", *from, err)
+	}
+
+	// 3. Without --state or --s3-state, restore to where the backup was taken from
+	explicit := flagGiven(flags, "state") || flagGiven(flags, "s3-state")
+	if config, err = restoreTarget(config, explicit, report, *from); err != nil {
+		return err
+	}
+	target := config.StateFilePath
+	if config.IsS3State {
+		target = config.S3State
+	}
+	prepareRun(config) // The restore gets its own run directory, named like those of reconciliations
+	defer cleanupOnSignal()()
+	record := restoreRecord{
+		RestoredAt: time.Now(),
+		From:       *from,
+		SHA256:     backupHash,
+		To:         target,
+		Lineage:    backupState.Lineage,
+		Serial:     backupState.Serial,
+		Verified:   hashErr == nil,
+	}
+
+	// 4. Back up the state about to be replaced, refusing to replace another lineage's state
+	currentPath := config.StateFilePath
+	if config.IsS3State {
+		s3Clients, err := clients()
+		if err != nil {
+			return err
+		}
+		currentPath = createLocalTempStateFile(tfState)
+		defer globalTemps.remove(currentPath)
+		if err := downloadStateFileFromS3(ctx, s3Clients, currentPath, config.S3Bucket, config.S3Key); err != nil {
+			var noSuchKey *s3types.NoSuchKey
+			if !errors.As(err, &noSuchKey) {
+				return err
+			}
+			currentPath = ""
+		}
+	} else if _, err := os.Stat(currentPath); errors.Is(err, os.ErrNotExist) {
+		currentPath = ""
+	}
+	var previousState *TFStateFile // The state the backup is written as the next snapshot of
+	if currentPath != "" {
+		currentState, err := openAndReadStateFile(currentPath)
+		switch {
+		case err != nil:
+			log.Printf("WARNING: The state being replaced cannot be read, its lineage is not checked: %v", err)
+		case currentState.Lineage != backupState.Lineage && !*force:
+			return fmt.Errorf("%s has lineage %s, the backup %s; use --force to replace it anyway", target, currentState.Lineage, backupState.Lineage)
+		case currentState.Lineage != backupState.Lineage:
+			record.PreviousSerial = currentState.Serial
+		default:
+			record.PreviousSerial = currentState.Serial
+			previousState = currentState
+		}
+		record.PreviousBackup = createBackupPath(config.BackupsDir, globalOriginalBaseFileName, "original", globalRunDir, ".tfstate")
+		if err := copyFile(currentPath, record.PreviousBackup); err != nil {
+			return fmt.Errorf("failed to back up %s before restoring: %w", target, err)
+		}
+		if record.PreviousSHA256, err = calculateFileSHA256(record.PreviousBackup); err != nil {
+			return err
+		}
+		if err := writeHashFile(config, record.PreviousBackup, record.PreviousSHA256); err != nil {
+			log.Printf("WARNING: Failed to write SHA256 for the backup of %s: %v", target, err)
+		}
+		fmt.Printf("Backed up %s to %s\n", target, record.PreviousBackup)
+	}
+
+	// 5. Restore, as the next snapshot of the replaced state so Terraform does not refuse an older serial.
+	// Only the serial of the backup is edited; every other byte is restored as it was backed up.
+	restored, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup '%s': %w", *from, err)
+	}
+	if previousState != nil {
+		serial, err := nextSerial(backupState, previousState)
+		if err != nil {
+			return fmt.Errorf("failed to restore '%s': %w", *from, err)
+		}
+		if serial != backupState.Serial {
+			if restored, err = setStateSerial(restored, serial); err != nil {
+				return fmt.Errorf("failed to restore '%s': %w", *from, err)
+			}
+		}
+		record.Serial = serial
+	}
+	digest := sha256.Sum256(restored)
+	record.SHA256 = hex.EncodeToString(digest[:])
+	if config.IsS3State {
+		s3Clients, err := clients()
+		if err != nil {
+			return err
+		}
+		upload := uploadArtifact(ctx, s3Clients, config.S3Bucket, s3Artifact{name: "state", content: string(restored), key: config.S3Key})
+		if !upload.Uploaded {
+			return fmt.Errorf("failed to upload the backup to %s after %d attempts: %s", target, upload.Attempts, upload.Error)
+		}
+	} else if err := replaceStateFile(config.StateFilePath, restored); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s (serial %d, lineage %s) to %s\n", *from, record.Serial, backupState.Lineage, target)
+
+	// 6. Record the restore next to the backup of the replaced state
+	data, err := json.MarshalIndent(record, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to render the restore record: %w", err)
+	}
+	recordPath := createBackupPath(config.ReportDir, globalOriginalBaseFileName, "restore", globalRunDir, ".json")
+	if err := writeReportToFile(recordPath, string(data)); err != nil {
+		return fmt.Errorf("failed to write the restore record: %w", err)
+	}
+	if hash, err := calculateFileSHA256(recordPath); err == nil {
+		if err := writeHashFile(config, recordPath, hash); err != nil {
+			log.Printf("WARNING: Failed to write SHA256 for the restore record: %v", err)
+		}
+	}
+	checksummed := []string{recordPath}
+	if record.PreviousBackup != "" {
+		checksummed = append(checksummed, record.PreviousBackup)
+	}
+	if err := writeChecksumsFile(config, checksummed...); err != nil {
+		log.Printf("WARNING: Failed to write %s: %v", checksumsFileName, err)
+	}
+	fmt.Printf("Recorded the restore in %s\n", recordPath)
+	if !config.IsS3State {
+		return nil
+	}
+	prefix := s3BackupPrefix(config, globalRunDir)
+	artifacts := []s3Artifact{{name: "restore record", localPath: recordPath, key: prefix + "restore." + globalOriginalBaseFileName + ".json"}}
+	if record.PreviousBackup != "" {
+		artifacts = append(artifacts,
+			s3Artifact{name: "original state backup", localPath: record.PreviousBackup, key: prefix + "original." + globalOriginalBaseFileName + ".tfstate"},
+			s3Artifact{name: "original state hash", content: record.PreviousSHA256, key: prefix + "original." + globalOriginalBaseFileName + ".tfstate.sha256"})
+	}
+	artifacts = s3ChecksumArtifacts(config, prefix, artifacts)
+	for _, upload := range uploadArtifacts(ctx, awsClients, s3BackupBucket(config), artifacts, false) {
+		if !upload.Uploaded {
+			return fmt.Errorf("restored %s, but failed to upload the %s: %s", target, upload.Artifact, upload.Error)
+		}
+	}
+	return nil
+}
+
+// checksumOf returns the SHA256 of name listed in the SHA256SUMS content sums, or notFound if it is not listed.
+func checksumOf(sums []byte, name string, notFound error) ([]byte, error) {
+	if hash, ok := parseChecksums(sums)[name]; ok {
+		return []byte(hash), nil
+	}
+	return nil, notFound
+}
+
+// restoreReportName returns the name of the report written by the run a backup belongs to:
+// original.dev.tfstate was written with report.dev.json, and in S3 original.terraform.tfstate.tfstate
+// with report.terraform.tfstate.json.
+func restoreReportName(backup string) string {
+	name := filepath.Base(backup)
+	for _, prefix := range backupArtifactPrefixes {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return "report." + strings.TrimSuffix(name, ".tfstate") + ".json"
+}
+
+// restoreTarget returns config pointed at the state the backup's run reconciled, as report records it,
+// unless --state or --s3-state named the target explicitly. Without a report naming the state the restore
+// is refused rather than sent to the default state.
+func restoreTarget(config Config, explicit bool, report []byte, from string) (Config, error) {
+	if explicit {
+		return config, nil
+	}
+	var run JSONOutput
+	if len(report) == 0 || json.Unmarshal(report, &run) != nil || run.State == "" {
+		return config, fmt.Errorf("no report next to '%s' records the state it was taken of; name the state to restore to with --state or --s3-state", from)
+	}
+	return retargetConfig(config, run.State)
+}
+
+// retargetConfig points config at state, a local path or S3 URI as recorded in a report.
+func retargetConfig(config Config, state string) (Config, error) {
+	if !strings.HasPrefix(state, "s3://") {
+		config.IsS3State, config.S3State, config.S3Bucket, config.S3Key = false, "", "", ""
+		config.StateFilePath = state
+		return config, nil
+	}
+	bucket, key, err := parseS3URI(state)
+	if err != nil {
+		return config, err
+	}
+	config.IsS3State, config.S3State, config.S3Bucket, config.S3Key = true, state, bucket, key
+	return config, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRestoreTarget(t *testing.T) {
+	base := Config{StateFilePath: "terraform.tfstate"}
+	explicit := Config{IsS3State: true, S3State: "s3://other/key", S3Bucket: "other", S3Key: "key"}
+	tests := []struct {
+		name      string
+		config    Config
+		explicit  bool
+		report    string
+		wantState string // Local path or S3 URI restored to; empty when the restore must be refused
+	}{
+		{"local state from the report", base, false, `{"state": "envs/dev.tfstate"}`, "envs/dev.tfstate"},
+		{"S3 state from the report", base, false, `{"state": "s3://acme/state/terraform.tfstate"}`, "s3://acme/state/terraform.tfstate"},
+		{"explicit target wins over the report", explicit, true, `{"state": "envs/dev.tfstate"}`, "s3://other/key"},
+		{"explicit target without a report", explicit, true, "", "s3://other/key"},
+		{"no report", base, false, "", ""},
+		{"unreadable report", base, false, `{"state": `, ""},
+		{"report without a state", base, false, `{"lineage": "a"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := restoreTarget(tt.config, tt.explicit, []byte(tt.report), "backups/original.dev.tfstate")
+			if tt.wantState == "" {
+				if err == nil || !strings.Contains(err.Error(), "--state or --s3-state") {
+					t.Errorf("restoreTarget error = %v, want one asking for --state or --s3-state", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("restoreTarget: %v", err)
+			}
+			got := config.StateFilePath
+			if config.IsS3State {
+				got = config.S3State
+			}
+			if got != tt.wantState {
+				t.Errorf("restoring to %s, want %s", got, tt.wantState)
+			}
+		})
+	}
+}
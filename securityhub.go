@@ -0,0 +1,251 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	securityhubtypes "github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+)
+
+// securityHubBatchSize is the most findings BatchImportFindings accepts per call.
+const securityHubBatchSize = 100
+
+// securityHubSeverities are the categories exported with --security-hub and the severity of their findings:
+// resources gone from AWS are a likely outage on the next apply, untracked ones a likely failed create.
+var securityHubSeverities = map[string]securityhubtypes.SeverityLabel{
+	"DANGEROUS":        securityhubtypes.SeverityLabelHigh,
+	"POTENTIAL_IMPORT": securityhubtypes.SeverityLabelMedium,
+	"ERROR":            securityhubtypes.SeverityLabelLow,
+}
+
+// securityHubResourceTypes maps resource types to their ASFF resource type. Others are exported as Other.
+var securityHubResourceTypes = map[string]string{
+	"aws_mq_broker":              "AwsAmazonMqBroker",
+	"aws_api_gateway_rest_api":   "AwsApiGatewayRestApi",
+	"aws_api_gateway_stage":      "AwsApiGatewayStage",
+	"aws_dynamodb_table":         "AwsDynamoDbTable",
+	"aws_ebs_volume":             "AwsEc2Volume",
+	"aws_instance":               "AwsEc2Instance",
+	"aws_security_group":         "AwsEc2SecurityGroup",
+	"aws_subnet":                 "AwsEc2Subnet",
+	"aws_ec2_transit_gateway":    "AwsEc2TransitGateway",
+	"aws_vpc":                    "AwsEc2Vpc",
+	"aws_vpc_peering_connection": "AwsEc2VpcPeeringConnection",
+	"aws_ecr_repository":         "AwsEcrRepository",
+	"aws_eks_cluster":            "AwsEksCluster",
+	"aws_efs_access_point":       "AwsEfsAccessPoint",
+	"aws_elasticsearch_domain":   "AwsElasticsearchDomain",
+	"aws_iam_policy":             "AwsIamPolicy",
+	"aws_iam_role":               "AwsIamRole",
+	"aws_kinesis_stream":         "AwsKinesisStream",
+	"aws_kms_key":                "AwsKmsKey",
+	"aws_lambda_function":        "AwsLambdaFunction",
+	"aws_msk_cluster":            "AwsMskCluster",
+	"aws_opensearch_domain":      "AwsOpenSearchServiceDomain",
+	"aws_lb":                     "AwsElbv2LoadBalancer",
+	"aws_db_instance":            "AwsRdsDbInstance",
+	"aws_rds_cluster":            "AwsRdsDbCluster",
+	"aws_redshift_cluster":       "AwsRedshiftCluster",
+	"aws_s3_bucket":              "AwsS3Bucket",
+	"aws_sqs_queue":              "AwsSqsQueue",
+	"aws_sfn_state_machine":      "AwsStepFunctionStateMachine",
+}
+
+// securityHubFindings converts the DANGEROUS, POTENTIAL_IMPORT and ERROR results into ASFF findings of
+// account, first observed now. Finding IDs are derived from the state and the resource address, so every
+// run updates the findings of the previous one instead of adding to them.
+func securityHubFindings(config Config, results *categorizedResults, account, partition string, now time.Time) []securityhubtypes.AwsSecurityFinding {
+	state := securityHubState(config)
+	productARN := securityHubProductARN(config, account, partition)
+	timestamp := now.UTC().Format(time.RFC3339)
+
+	var findings []securityhubtypes.AwsSecurityFinding
+	for _, category := range []struct {
+		name     string
+		title    string
+		statuses []ResourceStatus
+	}{
+		{"DANGEROUS", "%s is in the Terraform state but not in AWS", results.DangerousResults},
+		{"POTENTIAL_IMPORT", "%s exists in AWS under another ID than the Terraform state records", results.PotentialImportResults},
+		{"ERROR", "%s could not be verified against AWS", results.ErrorResults},
+	} {
+		for _, status := range category.statuses {
+			resourceType := securityHubResourceTypes[status.ResourceType]
+			resourceID := status.ARN
+			if resourceID == "" || resourceType == "" {
+				resourceType = "Other"
+				resourceID = cmp.Or(status.ARN, status.LiveID, status.StateID, status.TerraformAddress)
+			}
+			productFields := map[string]string{
+				"reconcile-tfstate/State":    state,
+				"reconcile-tfstate/Address":  status.TerraformAddress,
+				"reconcile-tfstate/Category": category.name,
+				"reconcile-tfstate/Type":     status.ResourceType,
+			}
+			if status.Command != "" {
+				productFields["reconcile-tfstate/Command"] = status.Command
+			}
+			findings = append(findings, securityhubtypes.AwsSecurityFinding{
+				SchemaVersion:   aws.String("2018-10-08"),
+				Id:              aws.String(truncate(state+"/"+status.TerraformAddress, 512)),
+				ProductArn:      aws.String(productARN),
+				ProductName:     aws.String(programName),
+				CompanyName:     aws.String(programName),
+				GeneratorId:     aws.String(programName + "/" + category.name),
+				AwsAccountId:    aws.String(account),
+				Region:          aws.String(config.AWSRegion),
+				Types:           []string{"Software and Configuration Checks/Terraform State Drift"},
+				CreatedAt:       aws.String(timestamp),
+				FirstObservedAt: aws.String(timestamp),
+				UpdatedAt:       aws.String(timestamp),
+				Severity:        &securityhubtypes.Severity{Label: securityHubSeverities[category.name]},
+				Title:           aws.String(truncate(fmt.Sprintf(category.title, status.TerraformAddress), 256)),
+				Description:     aws.String(truncate(status.Message, 1024)),
+				RecordState:     securityhubtypes.RecordStateActive,
+				ProductFields:   productFields,
+				Resources: []securityhubtypes.Resource{{
+					Type:      aws.String(resourceType),
+					Id:        aws.String(resourceID),
+					Partition: securityhubtypes.Partition(partition),
+					Region:    aws.String(config.AWSRegion),
+				}},
+			})
+		}
+	}
+	return findings
+}
+
+// exportToSecurityHub imports the findings of results into Security Hub in --region, in the account of
+// the credentials, and returns how many were imported. Findings of earlier runs keep the time they were
+// first seen, and those of the same state that are no longer in results are archived. Findings Security
+// Hub rejects are logged.
+func exportToSecurityHub(ctx context.Context, awsClients *AWSClient, config Config, results *categorizedResults) (int, error) {
+	identity, err := awsClients.callerIdentity(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up the account to report findings in: %w", err)
+	}
+	partition := "aws"
+	if callerARN, err := arn.Parse(aws.ToString(identity.Arn)); err == nil {
+		partition = callerARN.Partition
+	}
+	now := time.Now()
+	findings := securityHubFindings(config, results, aws.ToString(identity.Account), partition, now)
+	previous, err := activeSecurityHubFindings(ctx, awsClients, config, aws.ToString(identity.Account), partition)
+	if err != nil {
+		log.Printf("WARNING: %v; findings of earlier runs are neither carried over nor archived", err)
+	}
+	archived := carryOverSecurityHubFindings(findings, previous, now)
+
+	imported, err := importSecurityHubFindings(ctx, awsClients, findings)
+	if err != nil {
+		return imported, err
+	}
+	if _, err := importSecurityHubFindings(ctx, awsClients, archived); err != nil {
+		return imported, fmt.Errorf("failed to archive resolved findings: %w", err)
+	}
+	if len(archived) > 0 {
+		log.Printf("Archived %d Security Hub findings that no longer apply to %s.", len(archived), securityHubState(config))
+	}
+	return imported, nil
+}
+
+// activeSecurityHubFindings returns the active findings this program imported for the state in earlier runs.
+func activeSecurityHubFindings(ctx context.Context, awsClients *AWSClient, config Config, account, partition string) ([]securityhubtypes.AwsSecurityFinding, error) {
+	equals := func(value string) []securityhubtypes.StringFilter {
+		return []securityhubtypes.StringFilter{{Comparison: securityhubtypes.StringFilterComparisonEquals, Value: aws.String(value)}}
+	}
+	paginator := securityhub.NewGetFindingsPaginator(awsClients.SecurityHubClient, &securityhub.GetFindingsInput{
+		Filters: &securityhubtypes.AwsSecurityFindingFilters{
+			ProductArn:  equals(securityHubProductARN(config, account, partition)),
+			RecordState: equals(string(securityhubtypes.RecordStateActive)),
+			ProductFields: []securityhubtypes.MapFilter{{
+				Comparison: securityhubtypes.MapFilterComparisonEquals,
+				Key:        aws.String("reconcile-tfstate/State"),
+				Value:      aws.String(securityHubState(config)),
+			}},
+		},
+	})
+	var findings []securityhubtypes.AwsSecurityFinding
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up the findings of earlier runs in Security Hub: %w", err)
+		}
+		findings = append(findings, page.Findings...)
+	}
+	return findings, nil
+}
+
+// carryOverSecurityHubFindings gives findings that were already active in previous the time they were
+// first seen, and returns the previous findings missing from findings, archived as of now.
+func carryOverSecurityHubFindings(findings, previous []securityhubtypes.AwsSecurityFinding, now time.Time) []securityhubtypes.AwsSecurityFinding {
+	byID := make(map[string]securityhubtypes.AwsSecurityFinding, len(previous))
+	for _, finding := range previous {
+		byID[aws.ToString(finding.Id)] = finding
+	}
+	for i := range findings {
+		earlier, ok := byID[aws.ToString(findings[i].Id)]
+		if !ok {
+			continue
+		}
+		delete(byID, aws.ToString(findings[i].Id))
+		findings[i].CreatedAt = earlier.CreatedAt
+		findings[i].FirstObservedAt = cmp.Or(earlier.FirstObservedAt, earlier.CreatedAt)
+	}
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	var archived []securityhubtypes.AwsSecurityFinding
+	for _, finding := range previous {
+		if _, ok := byID[aws.ToString(finding.Id)]; !ok {
+			continue
+		}
+		finding.RecordState = securityhubtypes.RecordStateArchived
+		finding.UpdatedAt = aws.String(timestamp)
+		archived = append(archived, finding)
+	}
+	return archived
+}
+
+// importSecurityHubFindings imports findings in batches and returns how many Security Hub accepted.
+func importSecurityHubFindings(ctx context.Context, awsClients *AWSClient, findings []securityhubtypes.AwsSecurityFinding) (int, error) {
+	imported := 0
+	for start := 0; start < len(findings); start += securityHubBatchSize {
+		batch := findings[start:min(start+securityHubBatchSize, len(findings))]
+		out, err := awsClients.SecurityHubClient.BatchImportFindings(ctx, &securityhub.BatchImportFindingsInput{Findings: batch})
+		if err != nil {
+			return imported, fmt.Errorf("failed to import findings into Security Hub: %w", err)
+		}
+		imported += int(aws.ToInt32(out.SuccessCount))
+		for _, failed := range out.FailedFindings {
+			log.Printf("WARNING: Security Hub rejected finding %s: %s %s", aws.ToString(failed.Id), aws.ToString(failed.ErrorCode), aws.ToString(failed.ErrorMessage))
+		}
+	}
+	return imported, nil
+}
+
+// securityHubState names the state in finding IDs and the reconcile-tfstate/State product field.
+func securityHubState(config Config) string {
+	if config.IsS3State {
+		return config.S3State
+	}
+	return config.StateFilePath
+}
+
+// securityHubProductARN is the ARN of the default product of account, which findings are imported as.
+func securityHubProductARN(config Config, account, partition string) string {
+	return fmt.Sprintf("arn:%s:securityhub:%s:%s:product/%s/default", partition, config.AWSRegion, account, account)
+}
+
+// truncate shortens s to at most n bytes, for ASFF fields with a maximum length.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// apiQueueSize is how many reconciliations can wait for the one in progress before the API rejects new ones.
+const apiQueueSize = 32
+
+// apiRunTTL is how long a finished run is kept after it finished, and apiMaxRuns how many finished runs are
+// kept at most. The last finished run of each state is kept regardless, for GET /results.
+const (
+	apiRunTTL  = time.Hour
+	apiMaxRuns = 100
+)
+
+// runServe serves the REST API until the process is interrupted.
+func runServe(fs *flag.FlagSet, args []string) error {
+	listen := fs.String("listen", "127.0.0.1:8080", "Address to serve the API on. Listening on other than a loopback address requires --api-token.")
+	allowLocalState := fs.Bool("allow-local-state", false, "If true, accept local state file paths in requests, not only s3:// URIs.")
+	token := fs.String("api-token", "", "Bearer token every request but GET /healthz must send as 'Authorization: Bearer <token>'. Set it through "+flagEnvName("api-token")+" to keep it out of the process list.")
+	config := parseAndValidateConfig(fs, args)
+	if config.Watch || config.ExecuteCommands {
+		return errors.New("--watch and --should-execute cannot be used with serve")
+	}
+	if *token == "" && !isLoopbackAddress(*listen) {
+		return fmt.Errorf("--listen %s is not a loopback address; set --api-token to require a token from clients", *listen)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	api := &apiServer{
+		config: config,
+		runs:   make(map[string]*apiRun),
+		latest: make(map[string]*apiRun),
+		queue:  make(chan *apiRun, apiQueueSize),
+	}
+	go api.work(ctx)
+
+	server := &http.Server{Addr: *listen, Handler: api.handler(*allowLocalState, *token), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	log.Printf("Serving the reconciliation API on %s", *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handler routes the API:
+//
+//	POST /reconcile          {"state": "s3://bucket/key"} queues a reconciliation and returns the run
+//	GET  /runs/{id}          the run, with its result once finished
+//	GET  /runs/{id}/events   server-sent progress events of the run, ending with a "finished" event
+//	GET  /results?state=URI  the result of the last finished run of a state
+//	GET  /healthz            liveness
+//
+// With a token, every route but /healthz requires it as a bearer token.
+func (a *apiServer) handler(allowLocalState bool, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(rw, "ok")
+	})
+	mux.HandleFunc("POST /reconcile", func(rw http.ResponseWriter, r *http.Request) {
+		var request struct {
+			State string `json:"state"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.State == "" {
+			http.Error(rw, `expected a JSON body like {"state": "s3://bucket/key"}`, http.StatusBadRequest)
+			return
+		}
+		if !strings.HasPrefix(request.State, "s3://") && !allowLocalState {
+			http.Error(rw, "only s3:// state URIs are accepted; start the server with --allow-local-state for local paths", http.StatusBadRequest)
+			return
+		}
+		if strings.HasPrefix(request.State, "s3://") {
+			if _, _, err := parseS3URI(request.State); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		run, err := a.enqueue(request.State)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		rw.Header().Set("Location", "/runs/"+run.ID)
+		writeJSON(rw, http.StatusAccepted, run)
+	})
+	mux.HandleFunc("GET /runs/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		run := a.run(r.PathValue("id"))
+		if run == nil {
+			http.NotFound(rw, r)
+			return
+		}
+		writeJSON(rw, http.StatusOK, run)
+	})
+	mux.HandleFunc("GET /runs/{id}/events", func(rw http.ResponseWriter, r *http.Request) {
+		run := a.run(r.PathValue("id"))
+		if run == nil {
+			http.NotFound(rw, r)
+			return
+		}
+		streamEvents(rw, r, run)
+	})
+	mux.HandleFunc("GET /results", func(rw http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		run := a.latest[r.URL.Query().Get("state")]
+		a.mu.Unlock()
+		if run == nil {
+			http.Error(rw, "no finished reconciliation for this state", http.StatusNotFound)
+			return
+		}
+		writeJSON(rw, http.StatusOK, run)
+	})
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if r.URL.Path != "/healthz" && (!ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1) {
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="reconcile-tfstate"`)
+			http.Error(rw, "a valid bearer token is required", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(rw, r)
+	})
+}
+
+// isLoopbackAddress reports whether the listen address only accepts connections from this host.
+func isLoopbackAddress(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// enqueue records a new run of state and queues it.
+func (a *apiServer) enqueue(state string) (*apiRun, error) {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	run := &apiRun{
+		changed:  make(chan struct{}),
+		QueuedAt: time.Now(),
+		ID:       hex.EncodeToString(id),
+		State:    state,
+		Status:   "queued",
+	}
+	select {
+	case a.queue <- run:
+	default:
+		return nil, fmt.Errorf("%d reconciliations are already queued, try again later", apiQueueSize)
+	}
+	a.mu.Lock()
+	a.runs[run.ID] = run
+	a.evict(time.Now())
+	a.mu.Unlock()
+	return run, nil
+}
+
+// evict forgets finished runs older than apiRunTTL, then the oldest finished runs beyond apiMaxRuns,
+// keeping the last finished run of each state. The caller holds a.mu.
+func (a *apiServer) evict(now time.Time) {
+	var finished []*apiRun
+	for id, run := range a.runs {
+		if run.finishedAt.IsZero() || a.latest[run.State] == run {
+			continue
+		}
+		if now.Sub(run.finishedAt) > apiRunTTL {
+			delete(a.runs, id)
+			continue
+		}
+		finished = append(finished, run)
+	}
+	excess := min(len(finished), len(a.runs)-apiMaxRuns)
+	if excess <= 0 {
+		return
+	}
+	slices.SortFunc(finished, func(x, y *apiRun) int { return x.finishedAt.Compare(y.finishedAt) })
+	for _, run := range finished[:excess] {
+		delete(a.runs, run.ID)
+	}
+}
+
+// run returns the run with id, or nil.
+func (a *apiServer) run(id string) *apiRun {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.runs[id]
+}
+
+// work executes queued runs one after another until ctx is done.
+func (a *apiServer) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case run := <-a.queue:
+			a.execute(run)
+		}
+	}
+}
+
+// execute reconciles the run's state with the server's configuration and records the result.
+func (a *apiServer) execute(run *apiRun) {
+	config := a.config
+	config.IsS3State = strings.HasPrefix(run.State, "s3://")
+	if config.IsS3State {
+		config.S3State = run.State
+		config.S3Bucket, config.S3Key, _ = parseS3URI(run.State)
+	} else {
+		config.S3State = ""
+		config.StateFilePath = run.State
+	}
+
+	run.mu.Lock()
+	run.Status = "running"
+	run.mu.Unlock()
+	run.publish("started", map[string]string{"state": run.State})
+
+	started := time.Now()
+	prepareRun(config)
+	globalProgress = func(done, total int, status ResourceStatus) {
+		run.publish("progress", apiProgress{Resource: status.TerraformAddress, Category: status.Category, Done: done, Total: total})
+	}
+	err := runApplicationRecovered(config)
+	globalProgress = nil
+	if err != nil {
+		log.Printf("ERROR: Reconciliation of %s failed: %v", run.State, err)
+	}
+	snapshot := newRunSnapshot(globalResults, started, "api", err)
+
+	run.mu.Lock()
+	run.Result = &snapshot
+	run.Status = "finished"
+	if err != nil {
+		run.Status = "failed"
+	}
+	run.mu.Unlock()
+	a.mu.Lock()
+	a.latest[run.State] = run
+	run.finishedAt = time.Now()
+	a.evict(run.finishedAt)
+	a.mu.Unlock()
+	run.publish("finished", run)
+}
+
+// publish adds an event and wakes up the progress streams waiting for it. Once the run finished and
+// no stream is reading them, its events are dropped.
+func (r *apiRun) publish(name string, data any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, apiEvent{data: data, name: name})
+	r.published++
+	r.finished = r.finished || name == "finished"
+	close(r.changed)
+	r.changed = make(chan struct{})
+	r.dropDrainedEvents()
+}
+
+// dropDrainedEvents releases the events of a finished run no stream is reading. Streams opened later
+// only get the "finished" event. The caller holds r.mu.
+func (r *apiRun) dropDrainedEvents() {
+	if r.finished && r.streams == 0 {
+		r.events = nil
+	}
+}
+
+// MarshalJSON encodes the run under its lock, since the worker updates it while it is served.
+func (r *apiRun) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return json.Marshal(struct {
+		ID       string         `json:"id"`
+		State    string         `json:"state"`
+		Status   string         `json:"status"`
+		QueuedAt time.Time      `json:"queued_at"`
+		Events   int            `json:"events"`
+		Result   *watchSnapshot `json:"result,omitempty"`
+	}{r.ID, r.State, r.Status, r.QueuedAt, r.published, r.Result})
+}
+
+// streamEvents writes the run's events as server-sent events, from the first one until it finished
+// or the client goes away.
+func streamEvents(rw http.ResponseWriter, r *http.Request, run *apiRun) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+
+	run.mu.Lock()
+	run.streams++
+	run.mu.Unlock()
+	defer func() {
+		run.mu.Lock()
+		run.streams--
+		run.dropDrainedEvents()
+		run.mu.Unlock()
+	}()
+
+	sent := 0
+	for {
+		run.mu.Lock()
+		pending := run.events[sent:]
+		changed := run.changed
+		if run.finished && len(run.events) == 0 {
+			pending = []apiEvent{{data: run, name: "finished"}}
+		}
+		run.mu.Unlock()
+
+		for _, event := range pending {
+			data, err := json.Marshal(event.data)
+			if err != nil {
+				data = []byte(fmt.Sprintf("%q", err.Error()))
+			}
+			_, _ = fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event.name, data)
+			sent++
+			if event.name == "finished" {
+				flusher.Flush()
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+// writeJSON writes value as an indented JSON response with status.
+func writeJSON(rw http.ResponseWriter, status int, value any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	encoder := json.NewEncoder(rw)
+	encoder.SetIndent("", "\t")
+	_ = encoder.Encode(value)
+}
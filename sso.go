@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// activeProfile returns the shared config profile the SDK will use: --profile, then AWS_PROFILE, then "default".
+func activeProfile(appConfig Config) string {
+	if appConfig.AWSProfile != "" {
+		return appConfig.AWSProfile
+	}
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// isSSOProfile reports whether profile gets its credentials from AWS IAM Identity Center (SSO),
+// either through an sso-session section or the legacy sso_start_url key.
+func isSSOProfile(ctx context.Context, appConfig Config, profile string) bool {
+	shared, err := config.LoadSharedConfigProfile(ctx, profile, func(o *config.LoadSharedConfigOptions) {
+		if appConfig.SharedConfigFile != "" {
+			o.ConfigFiles = []string{appConfig.SharedConfigFile}
+		}
+		if appConfig.SharedCredentialsFile != "" {
+			o.CredentialsFiles = []string{appConfig.SharedCredentialsFile}
+		}
+	})
+	if err != nil {
+		return false
+	}
+	return shared.SSOSessionName != "" || shared.SSOStartURL != ""
+}
+
+// ssoLogin runs `aws sso login` for profile so an expired session can be renewed without leaving the
+// tool. Its output goes to stderr to keep --json output on stdout clean.
+func ssoLogin(ctx context.Context, appConfig Config, profile string) error {
+	cmd := exec.CommandContext(ctx, "aws", "sso", "login", "--profile", profile)
+	cmd.Env = terraformEnvironment(appConfig)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	log.Printf("Running: aws sso login --profile %s", profile)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("aws sso login --profile %s failed: %w", profile, err)
+	}
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"time" // Added for time.Now().Format for S3 paths
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // setupStateFileForProcessing handles downloading/copying the state file and initial local backup/hashing.
@@ -15,7 +17,7 @@ func setupStateFileForProcessing(
 	awsClients *AWSClient,
 	config Config,
 	originalBaseFileName string,
-	timestamp string,
+	runDir string,
 ) (localPath string, originalHash string, err error) {
 	var fileToHashPath string // The path of the file we will backup and hash
 
@@ -36,7 +38,15 @@ func setupStateFileForProcessing(
 	}
 
 	// Backup original local file
-	originalBackupLocalPath := createBackupPath(config.BackupsDir, originalBaseFileName, "original", timestamp, ".tfstate") // Use .tfstate extension explicitly
+	originalBackupLocalPath := createBackupPath(config.BackupsDir, originalBaseFileName, "original", runDir, ".tfstate") // Use .tfstate extension explicitly
+	if config.DryRun || config.NoBackups {
+		// Nothing is written; the hash still tells later steps whether the state changed.
+		originalHash, err = calculateFileSHA256(fileToHashPath)
+		if err != nil {
+			log.Printf("WARNING: Failed to calculate SHA256 for original state: %v", err)
+		}
+		return localPath, originalHash, nil
+	}
 	if !config.JsonOutput {                                                                                                 // Only print backup message in non-JSON mode
 		fmt.Printf("Backing up original state to %s...\n", originalBackupLocalPath)
 	}
@@ -47,7 +57,7 @@ func setupStateFileForProcessing(
 		if hashErr != nil {
 			log.Printf("WARNING: Failed to calculate SHA256 for original backup: %v", hashErr)
 		} else {
-			if err := os.WriteFile(originalBackupLocalPath+".sha256", []byte(hash), 0644); err != nil {
+			if err := writeHashFile(config, originalBackupLocalPath, hash); err != nil {
 				log.Printf("WARNING: Failed to write SHA256 for original backup: %v", err)
 			}
 			originalHash = hash
@@ -65,7 +75,7 @@ func handlePostReconciliationBackupsAndUpload(
 	localStateFilePath string,
 	tfStateFile *TFStateFile,
 	originalBaseFileName string,
-	timestamp string,
+	runDir string,
 	stateFileModified bool, // This is true if `executeCommands` ran and potentially modified.
 	originalStateFileHash string,
 	originalBackupLocalPath string, // Pass actual path from main
@@ -73,6 +83,16 @@ func handlePostReconciliationBackupsAndUpload(
 	reportLocalPathMD string,       // Pass actual path from main
 	reportLocalPathJSON string,     // Pass actual path from main
 ) error {
+	if config.DryRun {
+		results.PlannedWrites = append(results.PlannedWrites, planPostReconciliationWrites(ctx, awsClients, config, results, originalBaseFileName, runDir,
+			originalBackupLocalPath, newLocalStatePath, reportLocalPathMD, reportLocalPathJSON)...)
+		return nil
+	}
+	if config.NoBackups && config.ReportDir == config.BackupsDir {
+		// The results are printed by the caller; without --should-execute the state is unchanged, so there is nothing to upload.
+		return nil
+	}
+
 	// Calculate newStateFileHash first, as it's needed for both text and JSON outputs
 	var newStateFileHash string
 	// Always attempt to get the hash of the current local state file (which is the result after commands or no changes)
@@ -99,29 +119,31 @@ func handlePostReconciliationBackupsAndUpload(
 		if hashErr != nil {
 			log.Printf("WARNING: Failed to calculate SHA256 for Markdown report: %v", hashErr)
 		} else {
-			if err := os.WriteFile(reportLocalPathMD+".sha256", []byte(hash), 0644); err != nil {
+			if err := writeHashFile(config, reportLocalPathMD, hash); err != nil {
 				log.Printf("WARNING: Failed to write SHA256 for Markdown report: %v", err)
 			}
 		}
 	}
 
-	// --- Always create the 'new' state backup locally ---
-	if _, err := os.Stat(localStateFilePath); err == nil { // Double check source exists
-		if !config.JsonOutput {
-			fmt.Printf("Copying final state to new backup path: %s...\n", newLocalStatePath)
-		}
-		if err := copyFile(localStateFilePath, newLocalStatePath); err != nil {
-			log.Printf("WARNING: Failed to copy final state to new backup path: %v", err)
-		} else {
-			// Write the hash for the 'new' local backup
-			if newStateFileHash != "" { // Only write if we successfully calculated a hash
-				if err := os.WriteFile(newLocalStatePath+".sha256", []byte(newStateFileHash), 0644); err != nil {
-					log.Printf("WARNING: Failed to write SHA256 for new backup: %v", err)
+	// --- Always create the 'new' state backup locally, unless --no-backups is set ---
+	if !config.NoBackups {
+		if _, err := os.Stat(localStateFilePath); err == nil { // Double check source exists
+			if !config.JsonOutput {
+				fmt.Printf("Copying final state to new backup path: %s...\n", newLocalStatePath)
+			}
+			if err := copyFile(localStateFilePath, newLocalStatePath); err != nil {
+				log.Printf("WARNING: Failed to copy final state to new backup path: %v", err)
+			} else {
+				// Write the hash for the 'new' local backup
+				if newStateFileHash != "" { // Only write if we successfully calculated a hash
+					if err := writeHashFile(config, newLocalStatePath, newStateFileHash); err != nil {
+						log.Printf("WARNING: Failed to write SHA256 for new backup: %v", err)
+					}
 				}
 			}
+		} else {
+			log.Printf("WARNING: Skipping creation of 'new' backup as local state file source '%s' was not found: %v", localStateFilePath, err)
 		}
-	} else {
-		log.Printf("WARNING: Skipping creation of 'new' backup as local state file source '%s' was not found: %v", localStateFilePath, err)
 	}
 
 	// --- Save JSON Report (Always) ---
@@ -150,104 +172,162 @@ func handlePostReconciliationBackupsAndUpload(
 			if hashErr != nil {
 				log.Printf("WARNING: Failed to calculate SHA256 for JSON report: %v", hashErr)
 			} else {
-				if err := os.WriteFile(reportLocalPathJSON+".sha256", []byte(hash), 0644); err != nil {
+				if err := writeHashFile(config, reportLocalPathJSON, hash); err != nil {
 					log.Printf("WARNING: Failed to write SHA256 for JSON report: %v", err)
 				}
 			}
 		}
 	}
 
+	// --- Save Manifest (Always) ---
+	manifestLocalPath := createBackupPath(config.ReportDir, originalBaseFileName, "manifest", runDir, ".json")
+	manifestFiles := writeRunManifest(ctx, awsClients, config, tfStateFile, manifestLocalPath, []manifestArtifact{
+		{Name: "original state backup", Path: originalBackupLocalPath},
+		{Name: "new state backup", Path: newLocalStatePath},
+		{Name: "Markdown report", Path: reportLocalPathMD},
+		{Name: "JSON report", Path: reportLocalPathJSON},
+	})
+	var manifestHash string
+	if len(manifestFiles) > 0 {
+		if hash, hashErr := calculateFileSHA256(manifestLocalPath); hashErr == nil {
+			manifestHash = hash
+			if err := writeHashFile(config, manifestLocalPath, hash); err != nil {
+				log.Printf("WARNING: Failed to write SHA256 for manifest: %v", err)
+			}
+		}
+	}
+
+	// --- Save Checksums (Always) ---
+	checksummed := append([]string{reportLocalPathMD, reportLocalPathJSON}, manifestFiles...)
+	if !config.NoBackups {
+		checksummed = append(checksummed, originalBackupLocalPath, newLocalStatePath)
+	}
+	if err := writeChecksumsFile(config, checksummed...); err != nil {
+		log.Printf("WARNING: Failed to write %s: %v", checksumsFileName, err)
+	}
+
+	// --- Save Archive (--archive) ---
+	archiveLocalPath := createBackupPath(config.BackupsDir, originalBaseFileName, "archive", runDir, ".tar.gz")
+	var archiveHash string
+	if config.Archive {
+		var archived []string
+		for _, artifact := range checksummed {
+			archived = append(archived, artifact, artifact+".sha256")
+		}
+		archived = append(archived, filepath.Join(filepath.Dir(archiveLocalPath), checksumsFileName), filepath.Join(filepath.Dir(reportLocalPathJSON), checksumsFileName))
+		if !config.JsonOutput {
+			fmt.Printf("Writing archive to %s...\n", archiveLocalPath)
+		}
+		if err := writeRunArchive(archiveLocalPath, slices.Compact(archived)); err != nil {
+			log.Printf("ERROR: Failed to write archive: %v", err)
+		} else if hash, hashErr := calculateFileSHA256(archiveLocalPath); hashErr == nil {
+			archiveHash = hash
+			if err := writeHashFile(config, archiveLocalPath, hash); err != nil {
+				log.Printf("WARNING: Failed to write SHA256 for archive: %v", err)
+			}
+			if err := writeChecksumsFile(config, archiveLocalPath); err != nil {
+				log.Printf("WARNING: Failed to write %s: %v", checksumsFileName, err)
+			}
+		}
+	}
+
 	// S3-specific post-processing for backups and final upload
-	if config.IsS3State && (contentChanged || stateFileModified || (results.ApplicationError != "")) { // Upload if modified, commands run, or app crashed
+	stateChanged := contentChanged || stateFileModified || (results.ApplicationError != "") // Upload if modified, commands run, or app crashed
+	if config.IsS3State && !config.NoBackups && (stateChanged || config.AlwaysUpload) {
 		if !config.JsonOutput { // Only print upload status in non-JSON mode
 			fmt.Println("\n--- PERFORMING S3 BACKUP AND FINAL UPLOAD ---")
 		}
-		// yearMonth must be derived consistently with createBackupPath
-		yearMonth := time.Now().Format("2006/01")
-		s3BackupPrefix := fmt.Sprintf("state-backups/%s/%s/", yearMonth, timestamp)
+		s3BackupPrefix := s3BackupPrefix(config, runDir)
 
-		// Upload original.tfstate + hash to S3 backup path
+		// Collect every backup and report artifact that exists locally, then upload them together
+		var artifacts []s3Artifact
 		originalS3BackupKey := s3BackupPrefix + "original." + originalBaseFileName + ".tfstate"
-		originalS3HashKey := originalS3BackupKey + ".sha256"
 		if _, err := os.Stat(originalBackupLocalPath); err == nil { // Check if file exists locally
-			if !config.JsonOutput {
-				fmt.Printf("Uploading original local backup to s3://%s/%s...\n", config.S3Bucket, originalS3BackupKey)
-			}
-			if err := uploadFileToS3(ctx, awsClients, originalBackupLocalPath, config.S3Bucket, originalS3BackupKey); err != nil {
-				log.Printf("ERROR: Failed to upload original local backup to S3: %v", err)
-			}
+			artifacts = append(artifacts, s3Artifact{name: "original state backup", localPath: originalBackupLocalPath, key: originalS3BackupKey})
 			if originalStateFileHash != "" {
-				if err := uploadStringContentToS3(ctx, awsClients, originalStateFileHash, config.S3Bucket, originalS3HashKey); err != nil {
-					log.Printf("ERROR: Failed to upload original hash to S3: %v", err)
-				}
+				artifacts = append(artifacts, s3Artifact{name: "original state hash", content: originalStateFileHash, key: originalS3BackupKey + ".sha256"})
 			}
 		} else {
 			log.Printf("WARNING: Skipping S3 upload of 'original' state backup as local file '%s' was not found: %v\n", originalBackupLocalPath, err)
 		}
 
-		// Upload new.tfstate + hash to S3 backup path
 		if _, err := os.Stat(newLocalStatePath); err == nil { // Only attempt S3 upload if local new.tfstate was successfully created
 			newS3BackupKey := s3BackupPrefix + "new." + originalBaseFileName + ".tfstate"
-			newS3HashKey := newS3BackupKey + ".sha256"
-			if !config.JsonOutput {
-				fmt.Printf("Uploading modified local state to s3://%s/%s...\n", config.S3Bucket, newS3BackupKey)
-			}
-			if err := uploadFileToS3(ctx, awsClients, newLocalStatePath, config.S3Bucket, newS3BackupKey); err != nil {
-				log.Printf("ERROR: Failed to upload modified state to S3: %v", err)
-			}
+			artifacts = append(artifacts, s3Artifact{name: "new state backup", localPath: newLocalStatePath, key: newS3BackupKey})
 			if newStateFileHash != "" {
-				if err := uploadStringContentToS3(ctx, awsClients, newStateFileHash, config.S3Bucket, newS3HashKey); err != nil {
-					log.Printf("ERROR: Failed to upload new hash to S3: %v", err)
-				}
+				artifacts = append(artifacts, s3Artifact{name: "new state hash", content: newStateFileHash, key: newS3BackupKey + ".sha256"})
 			}
 		} else {
 			log.Printf("WARNING: Skipping S3 upload of 'new' state as local 'new' backup file '%s' was not found: %v\n", newLocalStatePath, err)
 		}
 
-		// Upload MD report to S3
 		reportS3KeyMD := s3BackupPrefix + "report." + originalBaseFileName + ".txt"
-		reportHashS3KeyMD := reportS3KeyMD + ".sha256"
 		if _, err := os.Stat(reportLocalPathMD); err == nil { // Check if file exists locally
-			if !config.JsonOutput {
-				fmt.Printf("Uploading Markdown report to s3://%s/%s...\n", config.S3Bucket, reportS3KeyMD)
-			}
-			if err := uploadFileToS3(ctx, awsClients, reportLocalPathMD, config.S3Bucket, reportS3KeyMD); err != nil {
-				log.Printf("ERROR: Failed to upload Markdown report to S3: %v", err)
-			}
+			artifacts = append(artifacts, s3Artifact{name: "Markdown report", localPath: reportLocalPathMD, key: reportS3KeyMD})
 			if hash, hashErr := calculateFileSHA256(reportLocalPathMD); hashErr == nil {
-				if err := uploadStringContentToS3(ctx, awsClients, hash, config.S3Bucket, reportHashS3KeyMD); err != nil {
-					log.Printf("ERROR: Failed to upload Markdown report hash to S3: %v", err)
-				}
+				artifacts = append(artifacts, s3Artifact{name: "Markdown report hash", content: hash, key: reportS3KeyMD + ".sha256"})
 			}
 		} else {
 			log.Printf("WARNING: Skipping S3 upload of Markdown report as local file '%s' was not found: %v\n", reportLocalPathMD, err)
 		}
 
-		// Upload JSON report to S3
 		reportS3KeyJSON := s3BackupPrefix + "report." + originalBaseFileName + ".json"
-		reportHashS3KeyJSON := reportS3KeyJSON + ".sha256"
 		if _, err := os.Stat(reportLocalPathJSON); err == nil { // Check if file exists locally
-			if !config.JsonOutput {
-				fmt.Printf("Uploading JSON report to s3://%s/%s...\n", config.S3Bucket, reportS3KeyJSON)
-			}
-			if err := uploadFileToS3(ctx, awsClients, reportLocalPathJSON, config.S3Bucket, reportS3KeyJSON); err != nil {
-				log.Printf("ERROR: Failed to upload JSON report to S3: %v", err)
-			}
+			artifacts = append(artifacts, s3Artifact{name: "JSON report", localPath: reportLocalPathJSON, key: reportS3KeyJSON})
 			if hash, hashErr := calculateFileSHA256(reportLocalPathJSON); hashErr == nil {
-				if err := uploadStringContentToS3(ctx, awsClients, hash, config.S3Bucket, reportHashS3KeyJSON); err != nil {
-					log.Printf("ERROR: Failed to upload JSON report hash to S3: %v", err)
-				}
+				artifacts = append(artifacts, s3Artifact{name: "JSON report hash", content: hash, key: reportS3KeyJSON + ".sha256"})
 			}
 		} else {
 			log.Printf("WARNING: Skipping S3 upload of JSON report as local file '%s' was not found: %v\n", reportLocalPathJSON, err)
 		}
 
+		manifestS3Key := s3BackupPrefix + "manifest." + originalBaseFileName + ".json"
+		for _, manifestFile := range manifestFiles {
+			if strings.HasSuffix(manifestFile, ".sig") {
+				artifacts = append(artifacts, s3Artifact{name: "manifest signature", localPath: manifestFile, key: manifestS3Key + ".sig"})
+				continue
+			}
+			artifacts = append(artifacts, s3Artifact{name: "manifest", localPath: manifestFile, key: manifestS3Key})
+			if manifestHash != "" {
+				artifacts = append(artifacts, s3Artifact{name: "manifest hash", content: manifestHash, key: manifestS3Key + ".sha256"})
+			}
+		}
+
+		if archiveHash != "" {
+			archiveS3Key := s3BackupPrefix + "archive." + originalBaseFileName + ".tar.gz"
+			artifacts = append(artifacts,
+				s3Artifact{name: "archive", localPath: archiveLocalPath, key: archiveS3Key},
+				s3Artifact{name: "archive hash", content: archiveHash, key: archiveS3Key + ".sha256"})
+		}
+
+		artifacts = s3ChecksumArtifacts(config, s3BackupPrefix, artifacts)
+		results.Uploads = uploadArtifacts(ctx, awsClients, s3BackupBucket(config), artifacts, config.JsonOutput)
+		if !stateChanged {
+			if !config.JsonOutput {
+				fmt.Println("\nNo changes to the state file detected. Its backups and reports were uploaded (--always-upload).")
+			}
+			return nil
+		}
+
+		// The live state is only replaced once the backup of the original is in the backup bucket, not just on this machine
+		if !slices.ContainsFunc(results.Uploads, func(upload ArtifactUpload) bool {
+			return upload.Artifact == "original state backup" && upload.Uploaded
+		}) {
+			err := fmt.Errorf("not replacing s3://%s/%s because the original state backup was not uploaded. The original is in '%s' and the modified state in '%s'", config.S3Bucket, config.S3Key, originalBackupLocalPath, newLocalStatePath)
+			log.Printf("ERROR: %v", err)
+			return err
+		}
+
 		// Finally, upload the modified local state back to the original S3 location
 		if !config.JsonOutput {
 			fmt.Printf("Uploading FINAL modified state to original s3://%s/%s...\n", config.S3Bucket, config.S3Key)
 		}
-		uploadErr := uploadStateFileToS3(ctx, awsClients, localStateFilePath, config.S3Bucket, config.S3Key)
-		if uploadErr != nil {
+		// The state itself goes last so it is only replaced once its backups have been attempted
+		finalUpload := uploadArtifact(ctx, awsClients, config.S3Bucket, s3Artifact{name: "state", localPath: localStateFilePath, key: config.S3Key})
+		results.Uploads = append(results.Uploads, finalUpload)
+		var uploadErr error
+		if !finalUpload.Uploaded {
+			uploadErr = fmt.Errorf("failed to upload state to S3 after %d attempts: %s", finalUpload.Attempts, finalUpload.Error)
 			log.Printf("ERROR: Final upload of state file to original S3 location failed: %v", uploadErr)
 		}
 		return uploadErr // Return the error from the final upload
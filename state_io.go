@@ -7,14 +7,15 @@ import (
 	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
 // openAndReadStateFile opens the specified state file and reads its content.
-func openAndReadStateFile(filePath string) *TFStateFile {
+func openAndReadStateFile(filePath string) (*TFStateFile, error) {
 	stateFile, err := os.Open(filePath)
 	if err != nil {
-		log.Fatalf("Failed to open state file '%s': %v", filePath, err)
+		return nil, fmt.Errorf("failed to open state file '%s': %w", filePath, err)
 	}
 	defer func() {
 		_ = stateFile.Close()
@@ -22,24 +23,28 @@ func openAndReadStateFile(filePath string) *TFStateFile {
 
 	tfState, err := Read(stateFile)
 	if err != nil {
-		log.Fatalf("Failed to parse state file: %v", err)
+		return nil, fmt.Errorf("failed to parse state file '%s': %w", filePath, err)
 	}
-	return tfState
+	return tfState, nil
 }
 
-// createLocalTempStateFile creates a local temporary file for S3 download.
+// createLocalTempStateFile creates a local temporary file for S3 download. It is removed by
+// globalTemps when the run ends.
 func createLocalTempStateFile(prefix string) string {
 	tempFile, err := os.CreateTemp("", fmt.Sprintf("%s-download-*.%s", prefix, tfState))
 	if err != nil {
 		log.Fatalf("Failed to create temporary file for S3 state: %v", err)
 	}
 	localPath := tempFile.Name()
+	globalTemps.track(localPath)
 	_ = tempFile.Close() // Close immediately, downloader will open it
 	return localPath
 }
 
 // downloadStateFileFromS3 downloads the state file from S3 to a local path.
-func downloadStateFileFromS3(ctx context.Context, awsClients *AWSClient, localPath, bucket, key string) error {
+func downloadStateFileFromS3(ctx context.Context, awsClients *AWSClient, localPath, bucket, key string) (err error) {
+	done := globalTemps.trackPartial(localPath)
+	defer func() { done(err) }()
 	file, err := os.Create(localPath)
 	if err != nil {
 		return fmt.Errorf("failed to create local file for S3 download: %w", err)
@@ -48,7 +53,11 @@ func downloadStateFileFromS3(ctx context.Context, awsClients *AWSClient, localPa
 		_ = file.Close()
 	}()
 
-	_, err = awsClients.S3Downloader.Download(ctx, file, &s3.GetObjectInput{
+	downloader := awsClients.S3Downloader
+	if client := awsClients.s3ClientFor(bucket); client != awsClients.StateS3Client {
+		downloader = manager.NewDownloader(client)
+	}
+	_, err = downloader.Download(ctx, file, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
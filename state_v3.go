@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// upgradeStateV3 converts the modules of a version 3 state into the version 4 model of f, in memory.
+// Resource keys such as "module path" + "data.aws_ami.ubuntu.1" become resources with index keys, and
+// the flatmap attributes are expanded into nested JSON so verifiers read them like version 4 attributes.
+// Without provider schemas every leaf value stays a string. The state on disk is left untouched.
+func upgradeStateV3(f *TFStateFile, modules []stateModuleV3) (*TFStateFile, error) {
+	resources := make(map[string]*ResourceStateV4)
+	var order []string
+	for _, module := range modules {
+		modulePath, err := moduleAddressV3(module.Path)
+		if err != nil {
+			return nil, err
+		}
+		if modulePath == "" {
+			for name, output := range module.Outputs {
+				f.RootOutputs[name] = upgradeOutputV3(output)
+			}
+		}
+
+		keys := make([]string, 0, len(module.Resources))
+		for key := range module.Resources {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessResourceKeyV3(keys[i], keys[j]) })
+		for _, key := range keys {
+			rs := module.Resources[key]
+			mode, resourceType, name, index, err := parseResourceKeyV3(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to upgrade resource %q in module %q: %w", key, strings.Join(module.Path, "."), err)
+			}
+			if rs.Type != "" {
+				resourceType = rs.Type
+			}
+			id := strings.Join([]string{modulePath, mode, resourceType, name}, "\x00")
+			resource, ok := resources[id]
+			if !ok {
+				resource = &ResourceStateV4{
+					Module:         modulePath,
+					Mode:           mode,
+					Type:           resourceType,
+					Name:           name,
+					ProviderConfig: upgradeProviderConfigV3(rs.Provider, resourceType, modulePath),
+				}
+				resources[id] = resource
+				order = append(order, id)
+			}
+			var indexKey interface{}
+			if index >= 0 {
+				indexKey = float64(index) // As a JSON-decoded version 4 state would have it
+				resource.EachMode = "list"
+			}
+
+			if rs.Primary != nil {
+				instance, err := upgradeInstanceV3(rs.Primary, rs.DependsOn, indexKey, "")
+				if err != nil {
+					return nil, fmt.Errorf("failed to upgrade resource %q: %w", key, err)
+				}
+				resource.Instances = append(resource.Instances, instance)
+			}
+			for i, deposed := range rs.Deposed {
+				instance, err := upgradeInstanceV3(deposed, rs.DependsOn, indexKey, fmt.Sprintf("%08x", i+1))
+				if err != nil {
+					return nil, fmt.Errorf("failed to upgrade deposed object %d of resource %q: %w", i, key, err)
+				}
+				resource.Instances = append(resource.Instances, instance)
+			}
+		}
+	}
+
+	f.Resources = make([]ResourceStateV4, 0, len(order))
+	for _, id := range order {
+		f.Resources = append(f.Resources, *resources[id])
+	}
+	f.UpgradedFrom = 3
+	f.Version = 4
+	return f, nil
+}
+
+// moduleAddressV3 turns a version 3 module path such as ["root", "network", "subnets"] into the
+// version 4 address module.network.module.subnets, and the root module into "".
+func moduleAddressV3(path []string) (string, error) {
+	if len(path) == 0 || path[0] != "root" {
+		return "", fmt.Errorf("invalid module path %q in version 3 state: it must start with \"root\"", path)
+	}
+	var address []string
+	for _, name := range path[1:] {
+		address = append(address, "module."+name)
+	}
+	return strings.Join(address, "."), nil
+}
+
+// parseResourceKeyV3 splits a version 3 resource key, "[data.]TYPE.NAME[.INDEX]", returning an index
+// of -1 for resources without count.
+func parseResourceKeyV3(key string) (mode, resourceType, name string, index int, err error) {
+	mode = "managed"
+	if rest, ok := strings.CutPrefix(key, "data."); ok {
+		mode, key = "data", rest
+	}
+	parts := strings.Split(key, ".")
+	switch len(parts) {
+	case 2:
+		return mode, parts[0], parts[1], -1, nil
+	case 3:
+		index, err = strconv.Atoi(parts[2])
+		if err != nil || index < 0 {
+			return "", "", "", 0, fmt.Errorf("invalid index %q", parts[2])
+		}
+		return mode, parts[0], parts[1], index, nil
+	default:
+		return "", "", "", 0, fmt.Errorf("expected [data.]TYPE.NAME[.INDEX]")
+	}
+}
+
+// lessResourceKeyV3 orders version 3 resource keys by resource, then by count index as a number, so
+// aws_instance.web.2 comes before aws_instance.web.10.
+func lessResourceKeyV3(a, b string) bool {
+	baseA, indexA := splitResourceKeyIndexV3(a)
+	baseB, indexB := splitResourceKeyIndexV3(b)
+	if baseA != baseB {
+		return baseA < baseB
+	}
+	return indexA < indexB
+}
+
+// splitResourceKeyIndexV3 splits the count index off a version 3 resource key, returning -1 without one.
+func splitResourceKeyIndexV3(key string) (string, int) {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		if index, err := strconv.Atoi(key[i+1:]); err == nil {
+			return key[:i], index
+		}
+	}
+	return key, -1
+}
+
+// upgradeProviderConfigV3 turns a version 3 provider reference such as "provider.aws.west" into the
+// version 4 form provider["registry.terraform.io/hashicorp/aws"].west, deriving it from the resource
+// type when the state does not record one.
+func upgradeProviderConfigV3(provider, resourceType, modulePath string) string {
+	name, alias := "", ""
+	if rest, ok := strings.CutPrefix(provider, "provider."); ok {
+		name, alias, _ = strings.Cut(rest, ".")
+	} else if provider != "" {
+		name, alias, _ = strings.Cut(provider, ".")
+	} else {
+		name, _, _ = strings.Cut(resourceType, "_")
+	}
+	config := fmt.Sprintf("provider[\"registry.terraform.io/hashicorp/%s\"]", name)
+	if alias != "" {
+		config += "." + alias
+	}
+	if modulePath != "" {
+		config = modulePath + "." + config
+	}
+	return config
+}
+
+// upgradeInstanceV3 converts the primary or a deposed object of a version 3 resource.
+func upgradeInstanceV3(is *instanceStateV3, dependsOn []string, indexKey interface{}, deposed string) (InstanceObjectStateV4, error) {
+	attributes := expandFlatmap(is.Attributes)
+	if _, ok := attributes["id"]; !ok && is.ID != "" {
+		attributes["id"] = is.ID
+	}
+	raw, err := json.Marshal(attributes)
+	if err != nil {
+		return InstanceObjectStateV4{}, err
+	}
+	instance := InstanceObjectStateV4{
+		AttributesRaw: raw,
+		Dependencies:  dependsOn,
+		IndexKey:      indexKey,
+		Deposed:       deposed,
+	}
+	if is.Tainted {
+		instance.Status = "tainted"
+	}
+	switch version := is.Meta["schema_version"].(type) {
+	case string:
+		instance.SchemaVersion, _ = strconv.ParseUint(version, 10, 64)
+	case float64:
+		instance.SchemaVersion = uint64(version)
+	}
+	return instance, nil
+}
+
+// upgradeOutputV3 converts a root module output. Version 3 only records "string", "list" or "map".
+func upgradeOutputV3(output outputStateV3) OutputStateV4 {
+	valueType := `"dynamic"`
+	if output.Type == "string" {
+		valueType = `"string"`
+	}
+	return OutputStateV4{ValueRaw: output.Value, ValueTypeRaw: json.RawMessage(valueType), Sensitive: output.Sensitive}
+}
+
+// expandFlatmap expands flatmap attributes into nested values: "tags.%" and "tags.Name" become the map
+// tags, "subnet_ids.#" and "subnet_ids.0" the list subnet_ids, and "ingress.0.from_port" a list of maps.
+// Sets, whose flatmap keys are element hashes, become lists in the order of their keys.
+func expandFlatmap(flat map[string]string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key := range flat {
+		name, _, _ := strings.Cut(key, ".")
+		if _, done := result[name]; !done {
+			result[name] = expandFlatmapValue(flat, name)
+		}
+	}
+	return result
+}
+
+// expandFlatmapValue expands the value at prefix: a list if prefix.# is set, a map if prefix.% is set,
+// otherwise the string at prefix, or an object assembled from prefix.* keys.
+func expandFlatmapValue(flat map[string]string, prefix string) interface{} {
+	if count, ok := flat[prefix+".#"]; ok {
+		n, _ := strconv.Atoi(count)
+		list := make([]interface{}, 0, n)
+		for _, key := range flatmapChildKeys(flat, prefix, "#") {
+			list = append(list, expandFlatmapValue(flat, prefix+"."+key))
+		}
+		return list
+	}
+	if value, ok := flat[prefix]; ok {
+		return value
+	}
+	object := make(map[string]interface{})
+	for _, key := range flatmapChildKeys(flat, prefix, "%") {
+		object[key] = expandFlatmapValue(flat, prefix+"."+key)
+	}
+	return object
+}
+
+// flatmapChildKeys returns the distinct keys directly below prefix, other than the count key skip,
+// with numeric keys in numeric order. Map keys containing dots are kept whole when the map has a count.
+func flatmapChildKeys(flat map[string]string, prefix, skip string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	_, isMap := flat[prefix+".%"]
+	for key := range flat {
+		rest, ok := strings.CutPrefix(key, prefix+".")
+		if !ok || rest == skip || rest == "#" || rest == "%" {
+			continue
+		}
+		child, _, nested := strings.Cut(rest, ".")
+		if isMap && nested && !flatmapHasCount(flat, prefix+"."+child) {
+			child = rest
+		}
+		if !seen[child] {
+			seen[child] = true
+			keys = append(keys, child)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// flatmapHasCount reports whether key holds a nested list or map rather than a string.
+func flatmapHasCount(flat map[string]string, key string) bool {
+	_, list := flat[key+".#"]
+	_, object := flat[key+".%"]
+	return list || object
+}
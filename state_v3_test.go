@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testStateV3 = `{
+  "version": 3,
+  "terraform_version": "0.11.14",
+  "serial": 5,
+  "lineage": "6c1f2a3b-4d5e-4f60-8a7b-9c0d1e2f3a4b",
+  "modules": [
+    {
+      "path": ["root"],
+      "outputs": {
+        "ip": {"sensitive": false, "type": "string", "value": "10.0.0.1"}
+      },
+      "resources": {
+        "aws_instance.web.10": {"type": "aws_instance", "provider": "provider.aws", "primary": {"id": "i-10", "attributes": {"id": "i-10"}}},
+        "aws_instance.web.2": {"type": "aws_instance", "provider": "provider.aws", "primary": {"id": "i-2", "attributes": {"id": "i-2"}}},
+        "aws_instance.web.0": {"type": "aws_instance", "provider": "provider.aws", "primary": {"id": "i-0", "attributes": {"id": "i-0"}, "tainted": true}},
+        "data.aws_ami.ubuntu": {"type": "aws_ami", "provider": "provider.aws", "primary": {"id": "ami-1", "attributes": {"id": "ami-1"}}},
+        "aws_s3_bucket.logs": {
+          "type": "aws_s3_bucket",
+          "provider": "provider.aws.west",
+          "depends_on": ["aws_instance.web"],
+          "primary": {"id": "logs", "attributes": {"id": "logs"}, "meta": {"schema_version": "1"}},
+          "deposed": [{"id": "old-logs", "attributes": {"id": "old-logs"}}]
+        }
+      }
+    },
+    {
+      "path": ["root", "network", "subnets"],
+      "outputs": {},
+      "resources": {
+        "aws_subnet.private": {"type": "aws_subnet", "primary": {"id": "subnet-1", "attributes": {}}}
+      }
+    }
+  ]
+}
+`
+
+// upgradedInstance is the part of an upgraded instance the tests compare.
+type upgradedInstance struct {
+	IndexKey      interface{}
+	Deposed       string
+	Status        string
+	ID            string
+	SchemaVersion uint64
+}
+
+// upgradedResource is the part of an upgraded resource the tests compare.
+type upgradedResource struct {
+	Module, Mode, Type, Name, EachMode, ProviderConfig string
+	Instances                                          []upgradedInstance
+}
+
+func TestUpgradeStateV3(t *testing.T) {
+	state := readTestState(t, testStateV3)
+	if state.Version != 4 || state.UpgradedFrom != 3 {
+		t.Fatalf("version = %d, upgraded from %d; want 4 upgraded from 3", state.Version, state.UpgradedFrom)
+	}
+
+	aws := `provider["registry.terraform.io/hashicorp/aws"]`
+	want := []upgradedResource{
+		{"", "managed", "aws_instance", "web", "list", aws, []upgradedInstance{
+			{float64(0), "", "tainted", "i-0", 0},
+			{float64(2), "", "", "i-2", 0},
+			{float64(10), "", "", "i-10", 0},
+		}},
+		{"", "managed", "aws_s3_bucket", "logs", "", aws + ".west", []upgradedInstance{
+			{nil, "", "", "logs", 1},
+			{nil, "00000001", "", "old-logs", 0},
+		}},
+		{"", "data", "aws_ami", "ubuntu", "", aws, []upgradedInstance{
+			{nil, "", "", "ami-1", 0},
+		}},
+		{"module.network.module.subnets", "managed", "aws_subnet", "private", "", "module.network.module.subnets." + aws, []upgradedInstance{
+			{nil, "", "", "subnet-1", 0},
+		}},
+	}
+
+	var got []upgradedResource
+	for _, resource := range state.Resources {
+		r := upgradedResource{resource.Module, resource.Mode, resource.Type, resource.Name, resource.EachMode, resource.ProviderConfig, nil}
+		for _, instance := range resource.Instances {
+			r.Instances = append(r.Instances, upgradedInstance{
+				instance.IndexKey, instance.Deposed, instance.Status, instanceStringAttribute(instance, "id"), instance.SchemaVersion,
+			})
+		}
+		got = append(got, r)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("upgraded resources:\ngot  %+v\nwant %+v", got, want)
+	}
+	if deps := state.Resources[1].Instances[0].Dependencies; !reflect.DeepEqual(deps, []string{"aws_instance.web"}) {
+		t.Errorf("dependencies = %q, want [aws_instance.web]", deps)
+	}
+	if output := state.RootOutputs["ip"]; string(output.ValueRaw) != `"10.0.0.1"` || string(output.ValueTypeRaw) != `"string"` {
+		t.Errorf("output ip = %s of type %s, want \"10.0.0.1\" of type \"string\"", output.ValueRaw, output.ValueTypeRaw)
+	}
+}
+
+func TestExpandFlatmap(t *testing.T) {
+	tests := []struct {
+		name string
+		flat map[string]string
+		want string
+	}{
+		{"string", map[string]string{"id": "i-1"}, `{"id": "i-1"}`},
+		{"list", map[string]string{"ids.#": "3", "ids.0": "a", "ids.1": "b", "ids.2": "c"}, `{"ids": ["a", "b", "c"]}`},
+		{"empty list", map[string]string{"ids.#": "0"}, `{"ids": []}`},
+		{"list in numeric order", map[string]string{
+			"ids.#": "11", "ids.0": "0", "ids.1": "1", "ids.2": "2", "ids.3": "3", "ids.4": "4", "ids.5": "5",
+			"ids.6": "6", "ids.7": "7", "ids.8": "8", "ids.9": "9", "ids.10": "10",
+		}, `{"ids": ["0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"]}`},
+		{"map", map[string]string{"tags.%": "2", "tags.Name": "web", "tags.team": "platform"}, `{"tags": {"Name": "web", "team": "platform"}}`},
+		{"map key with dots", map[string]string{"tags.%": "1", "tags.kubernetes.io/role": "elb"}, `{"tags": {"kubernetes.io/role": "elb"}}`},
+		{"nested list of maps", map[string]string{
+			"ingress.#": "1", "ingress.0.from_port": "80",
+			"ingress.0.cidr_blocks.#": "2", "ingress.0.cidr_blocks.0": "10.0.0.0/8", "ingress.0.cidr_blocks.1": "192.168.0.0/16",
+		}, `{"ingress": [{"from_port": "80", "cidr_blocks": ["10.0.0.0/8", "192.168.0.0/16"]}]}`},
+		{"set hashes", map[string]string{
+			"ingress.#": "2", "ingress.3456789012.port": "443", "ingress.123.port": "80",
+		}, `{"ingress": [{"port": "80"}, {"port": "443"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			raw, err := json.Marshal(expandFlatmap(tt.flat))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got interface{}
+			if err := json.Unmarshal(raw, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expandFlatmap = %s, want %s", raw, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseResourceKeyV3(t *testing.T) {
+	tests := []struct {
+		key             string
+		mode, typ, name string
+		index           int
+		wantErr         bool
+	}{
+		{"aws_instance.web", "managed", "aws_instance", "web", -1, false},
+		{"aws_instance.web.3", "managed", "aws_instance", "web", 3, false},
+		{"data.aws_ami.ubuntu", "data", "aws_ami", "ubuntu", -1, false},
+		{"data.aws_ami.ubuntu.1", "data", "aws_ami", "ubuntu", 1, false},
+		{"aws_instance.web.x", "", "", "", 0, true},
+		{"aws_instance", "", "", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			mode, typ, name, index, err := parseResourceKeyV3(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if mode != tt.mode || typ != tt.typ || name != tt.name || index != tt.index {
+				t.Errorf("got %s %s %s %d, want %s %s %s %d", mode, typ, name, index, tt.mode, tt.typ, tt.name, tt.index)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// globalTemps tracks the temporary files of the current run and the artifacts it is still writing.
+var globalTemps = &tempRegistry{paths: map[string]bool{}}
+
+// track registers a temporary file to be removed by cleanup.
+func (r *tempRegistry) track(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths[path] = true
+}
+
+// remove removes a tracked temporary file now, unless --keep-temp is set.
+func (r *tempRegistry) remove(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removeLocked(path)
+}
+
+// trackPartial registers an artifact about to be written to path. Until the returned function is called
+// with the outcome of the write, cleanup removes the artifact as partially written; a failed write
+// removes it right away. Existing files are never tracked, so a failed write cannot delete a state
+// that was being overwritten.
+func (r *tempRegistry) trackPartial(path string) (done func(err error)) {
+	if _, err := os.Lstat(path); err == nil {
+		return func(error) {}
+	}
+	r.track(path)
+	return func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err != nil {
+			r.removeLocked(path)
+			return
+		}
+		delete(r.paths, path)
+	}
+}
+
+// cleanup removes every tracked file, unless --keep-temp is set. It is called when a run ends, crashes
+// or is interrupted.
+func (r *tempRegistry) cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for path := range r.paths {
+		r.removeLocked(path)
+	}
+}
+
+// removeLocked removes path, or with --keep-temp logs that it is kept. r.mu must be held.
+func (r *tempRegistry) removeLocked(path string) {
+	delete(r.paths, path)
+	if r.keep {
+		log.Printf("Keeping temporary file %s (--keep-temp).", path)
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("WARNING: Failed to remove temporary file %s: %v", path, err)
+	}
+}
+
+// cleanupOnSignal removes the tracked files and exits when the process is interrupted or terminated
+// during a single run. The returned function stops handling the signals.
+func cleanupOnSignal() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Printf("Received %v, removing temporary files and partially written artifacts.", sig)
+			globalTemps.cleanup()
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -2,58 +2,176 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
+	"os"
+	"regexp"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/acm"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/appsync"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/codepipeline"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/dlm"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticsearchservice"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/mq"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
 )
 
 type (
 	// Config holds the application's runtime configuration.
 	// Order: string (16) > int (8) > bool (1)
 	Config struct {
-		StateFilePath       string
-		S3State             string
-		S3Bucket            string
-		S3Key               string
-		BackupsDir          string
-		AWSRegion           string
-		TerraformWorkingDir string // NEW: Field for Terraform's working directory
-		Concurrency         int
-		ExecuteCommands     bool
-		ShowVersion         bool
-		IsS3State           bool
-		JsonOutput          bool
+		StateFilePath         string
+		PlanPath              string // terraform show -json output whose changes are verified instead of the state
+		ExpectedLineage       string // Lineage the state must have to be reconciled and uploaded
+		ReportGitRepo         string // Git repository the reports are committed to
+		ReportGitBranch       string // Branch of ReportGitRepo; its default branch when empty
+		DatadogAPIKey         string // Enables sending metrics and events to Datadog
+		DatadogSite           string // e.g. datadoghq.com or datadoghq.eu
+		DatadogEnv            string // env tag of the metrics and events
+		S3State               string
+		S3Bucket              string
+		S3Key                 string
+		BackupsDir            string
+		ReportDir             string
+		SSE                   string
+		SSEKMSKeyID           string
+		ObjectLockMode        string
+		Checksums             string
+		BackupBucket          string
+		BackupPrefix          string
+		SignKey               string
+		AuditTable            string
+		BackupLayout          string
+		TimestampFormat       string
+		AWSRegion             string
+		TerraformWorkingDir   string // NEW: Field for Terraform's working directory
+		CheckpointPath        string
+		ProfileDir            string
+		WatchListen           string
+		WatchQueueURL         string
+		EndpointURL           string
+		ServiceEndpoints      map[string]string // service name (e.g. "s3", "ec2") -> endpoint URL
+		AccountRoles          map[string]string // account ID -> role ARN to assume for resources in that account
+		IncludeTypes          []string
+		ExcludeTypes          []string
+		IncludeAddresses      []string
+		ExcludeModules        []string
+		Targets               []string
+		Policies              []string // Rego files or directories evaluated against the results
+		DatadogTags           []string // Extra key:value tags of the metrics and events
+		AWSProfile            string
+		RoleARN               string
+		StateProfile          string
+		StateRoleARN          string
+		StateRegion           string
+		WebIdentityTokenFile  string
+		SharedConfigFile      string
+		SharedCredentialsFile string
+		RateLimit             float64
+		APITimeout            time.Duration
+		Deadline              time.Duration
+		IncrementalTTL        time.Duration
+		WatchInterval         time.Duration
+		ObjectLockRetention   time.Duration
+		Concurrency           int
+		RateBurst             int
+		MaxAttempts           int
+		RemoteStateDepth      int
+		ExecuteCommands       bool
+		ShowVersion           bool
+		IsS3State             bool
+		JsonOutput            bool
+		Resume                bool
+		Incremental           bool
+		SkipPreflight         bool
+		SSOLogin              bool
+		UseFIPSEndpoints      bool
+		UseDualStack          bool
+		Watch                 bool
+		DryRun                bool
+		NoBackups             bool
+		NoHashFiles           bool
+		ObjectLockLegalHold   bool
+		KeepTemp              bool
+		Archive               bool
+		AlwaysUpload          bool
+		FollowRemoteState     bool
+		CompareBackup         bool
+		FailUnknownProviders  bool
+		SecurityHub           bool
 	}
 
 	// ResourceStatus represents the status of a resource after checking AWS
-	// Order: error (16) > string (16) > bool (1)
+	// Order: error (16) > string (16) > time.Duration (8) > bool (1)
 	ResourceStatus struct {
-		Error            error  // interface (16 bytes)
-		TerraformAddress string // (16 bytes)
-		Message          string // (16 bytes)
-		Command          string // (16 bytes)
-		Kind             string // (16 bytes)
-		StateID          string // (16 bytes)
-		LiveID           string // (16 bytes)
-		TFID             string // (16 bytes)
-		AWSID            string // (16 bytes)
-		Stdout           string // (16 bytes)
-		Stderr           string // (16 bytes)
-		Category         string // RE-ADDED: (16 bytes)
-		ExistsInAWS      bool   // (1 byte)
+		Error            error         // interface (16 bytes)
+		TerraformAddress string        // (16 bytes)
+		Message          string        // (16 bytes)
+		Command          string        // (16 bytes)
+		Kind             string        // (16 bytes)
+		StateID          string        // (16 bytes)
+		LiveID           string        // (16 bytes)
+		TFID             string        // (16 bytes)
+		AWSID            string        // (16 bytes)
+		Stdout           string        // (16 bytes)
+		Stderr           string        // (16 bytes)
+		Category         string        // RE-ADDED: (16 bytes)
+		ResourceType     string        // (16 bytes)
+		Module           string        // Module instance address of the resource; "" in the root module (16 bytes)
+		Provider         string        // Type of the provider managing the resource, e.g. aws (16 bytes)
+		PlannedAction    string        // create, update or replace when verifying a --plan (16 bytes)
+		ARN              string        // ARN of the resource as the state records it, if it has one (16 bytes)
+		Elapsed          time.Duration // Time spent verifying; zero for resumed results (8 bytes)
+		ExistsInAWS      bool          // (1 byte)
 	}
 
 	// AWSClient holds all necessary AWS service clients
@@ -73,19 +191,481 @@ type (
 		IAMClient            *iam.Client
 		LambdaClient         *lambda.Client
 		CloudFrontClient     *cloudfront.Client
+		RDSClient            *rds.Client
+		DynamoDBClient       *dynamodb.Client
+		SQSClient            *sqs.Client
+		SNSClient            *sns.Client
+		KMSClient            *kms.Client
+		EKSClient            *eks.Client
+		ElastiCacheClient    *elasticache.Client
+		ECRClient            *ecr.Client
+		APIGatewayClient     *apigateway.Client
+		APIGatewayV2Client   *apigatewayv2.Client
+		SFNClient            *sfn.Client
+		EventBridgeClient    *eventbridge.Client
+		KinesisClient        *kinesis.Client
+		FirehoseClient       *firehose.Client
+		EFSClient            *efs.Client
+		DLMClient            *dlm.Client
+		WAFV2Client          *wafv2.Client
+		WAFV2GlobalClient    *wafv2.Client // CLOUDFRONT scope web ACLs, rule groups and IP sets, which only exist in us-east-1
+		CloudTrailClient     *cloudtrail.Client
+		ConfigServiceClient  *configservice.Client
+		GuardDutyClient      *guardduty.Client
+		SESClient            *ses.Client
+		SESV2Client          *sesv2.Client
+		CognitoIDPClient     *cognitoidentityprovider.Client
+		CognitoIDClient      *cognitoidentity.Client
+		AppSyncClient        *appsync.Client
+		CodeBuildClient      *codebuild.Client
+		CodePipelineClient   *codepipeline.Client
+		CodeDeployClient     *codedeploy.Client
+		BatchClient          *batch.Client
+		MSKClient            *kafka.Client
+		MQClient             *mq.Client
+		NeptuneClient        *neptune.Client
+		DocDBClient          *docdb.Client
+		OpenSearchClient     *opensearch.Client
+		ElasticsearchClient  *elasticsearchservice.Client
+		RedshiftClient       *redshift.Client
+		STSClient            *sts.Client
+		SecurityHubClient    *securityhub.Client // Receives --security-hub findings
+		StateS3Client        *s3.Client          // State file, backups and reports; differs from S3Client with --state-profile/--state-role-arn
+		StateSQSClient       *sqs.Client         // Receives --watch-queue-url notifications with the state bucket's credentials
+		BackupS3Client       *s3.Client          // --backup-bucket, in its own region; StateS3Client without it
+		StateKMSClient       *kms.Client         // Signs run manifests with --sign-key kms:<key>
+		StateDynamoDBClient  *dynamodb.Client    // Writes --audit-table items with the state bucket's credentials
 		S3Downloader         *manager.Downloader // This is a struct pointer itself, so effectively 8 bytes here
+		Batch                *batchLookup        // Results of bulk describe calls made before verification
+		Cache                *callCache          // De-duplicates identical API calls within a run
+		Accounts             *accountClients     // Clients for other accounts reached through --account-roles
+		DeniedActions        map[string][]string // Resource type -> actions the preflight check found denied
+		ConfiguredRegion     string              // Region from --region, AWS_REGION or the shared config; empty if none
+		SSE                  string              // --sse applied to every upload; empty for the bucket default
+		SSEKMSKeyID          string              // --sse-kms-key-id
+		BackupBucket         string              // --backup-bucket; empty when backups go to the state bucket
+		ObjectLockMode       string              // --object-lock-mode of uploaded backups and reports; empty for the bucket default
+		ObjectLockRetention  time.Duration       // --object-lock-retention
+		ObjectLockLegalHold  bool                // --object-lock-legal-hold
+	}
+
+	// accountClients lazily builds an AWSClient per account listed in --account-roles, using
+	// credentials from assuming the mapped role with the base configuration.
+	// Order: aws.Config > Config > maps (8) > sync.Mutex (8)
+	accountClients struct {
+		base      aws.Config
+		appConfig Config
+		roles     map[string]string     // account ID -> role ARN
+		clients   map[string]*AWSClient // account ID -> clients using the assumed role
+		mu        sync.Mutex
+	}
+
+	// callCache remembers the outcome of AWS API calls keyed by service, operation and parameters
+	// so identical lookups made by different resources are performed only once per run.
+	// Order: map (8) > sync.Mutex (8)
+	callCache struct {
+		entries map[string]*callCacheEntry
+		mu      sync.Mutex
+	}
+
+	// callCacheEntry is a single cached call; done is closed once value and err are set. A failed entry
+	// was removed from the cache because its error is not shared.
+	// Order: interface{} (16) > error (16) > chan (8) > bool (1)
+	callCacheEntry struct {
+		value  interface{}
+		err    error
+		done   chan struct{}
+		failed bool
+	}
+
+	// tempRegistry tracks files to remove when a run ends, crashes or is interrupted: temporary
+	// downloads, and artifacts whose write has not finished. keep is --keep-temp.
+	// Order: map (8) > sync.Mutex (8) > bool (1)
+	tempRegistry struct {
+		paths map[string]bool
+		mu    sync.Mutex
+		keep  bool
+	}
+
+	// command is a CLI subcommand. run receives a flag set already named and documented for it.
+	// Order: func (8) > string (16)
+	command struct {
+		run     func(fs *flag.FlagSet, args []string) error
+		name    string
+		usage   string
+		summary string
+	}
+
+	// backupLayout is the data a --backup-layout template is rendered with.
+	// Order: string (16)
+	backupLayout struct {
+		Year      string
+		Month     string
+		Day       string
+		Timestamp string
+		State     string
+		Workspace string
+		Region    string
+	}
+
+	// restoreRecord is written as restore.<state>.json by backup restore.
+	// Order: time.Time (24) > string (16) > int64 (8) > bool (1)
+	restoreRecord struct {
+		RestoredAt     time.Time `json:"restored_at"`
+		From           string    `json:"from"`
+		SHA256         string    `json:"sha256"`
+		To             string    `json:"to"`
+		Lineage        string    `json:"lineage"`
+		PreviousBackup string    `json:"previous_backup,omitempty"`
+		PreviousSHA256 string    `json:"previous_sha256,omitempty"`
+		Serial         uint64    `json:"serial"`
+		PreviousSerial uint64    `json:"previous_serial,omitempty"`
+		Verified       bool      `json:"verified"`
+	}
+
+	// runManifest is written as manifest.<state>.json at the end of every run.
+	// Order: slice (24) > time.Time (24) > string (16) > uint64 (8)
+	runManifest struct {
+		Artifacts []manifestArtifact `json:"artifacts"`
+		CreatedAt time.Time          `json:"created_at"`
+		Version   string             `json:"version"`
+		State     string             `json:"state"`
+		Lineage   string             `json:"lineage"`
+		Serial    uint64             `json:"serial"`
+	}
+
+	// manifestArtifact is one file listed in a runManifest.
+	// Order: string (16) > int64 (8)
+	manifestArtifact struct {
+		Name   string `json:"name"`
+		Path   string `json:"path"`
+		SHA256 string `json:"sha256"`
+		Size   int64  `json:"size"`
+	}
+
+	// manifestSignature is written as manifest.<state>.json.sig next to a manifest signed with --sign-key.
+	manifestSignature struct {
+		Algorithm string `json:"algorithm"`
+		KeyID     string `json:"key_id"`
+		Signature string `json:"signature"`
+	}
+
+	// backupRun is one run directory under --backups-dir with the artifacts written into it.
+	// Order: slice (24) > time.Time (24) > string (16) > int64 (8)
+	backupRun struct {
+		files    []string
+		modified time.Time
+		dir      string
+		size     int64
+	}
+
+	// resourceFilter selects the resource instances to verify from --include-types, --exclude-types,
+	// --include-address, --exclude-module and --target. Type and address patterns are compiled to regexps.
+	// Order: slices (24)
+	resourceFilter struct {
+		includeTypes     []*regexp.Regexp
+		excludeTypes     []*regexp.Regexp
+		includeAddresses []*regexp.Regexp
+		excludeModules   []modulePath
+		targets          []string
+	}
+
+	// moduleStep is one step of a module instance address: module.NAME, with KEY when the module call uses
+	// count (a float64, as decoded from state) or for_each (a string). A nil Key in a filter matches every
+	// instance of the call.
+	// Order: interface{} (16) > string (16)
+	moduleStep struct {
+		Key  interface{}
+		Name string
+	}
+
+	// modulePath is a parsed module instance address such as module.network.module.subnets["a"], from the
+	// root module down. The root module is the empty path.
+	modulePath []moduleStep
+
+	// stringList is a flag.Value collecting every occurrence of a repeatable flag.
+	stringList []string
+
+	// watcher runs the reconciliation on a schedule for --watch and keeps the outcome of the last run.
+	// Order: watchSnapshot > sync.RWMutex (24)
+	watcher struct {
+		last watchSnapshot
+		mu   sync.RWMutex
+	}
+
+	// watchSnapshot is the outcome of one --watch run, served from /results and /metrics.
+	// Order: JSONResults > map (8) > slice (24) > time.Time (24) > string (16) > float64 (8) > int (8)
+	watchSnapshot struct {
+		Results         JSONResults    `json:"results"`
+		Counts          map[string]int `json:"counts"`
+		Commands        []string       `json:"commands"`
+		StartedAt       time.Time      `json:"started_at"`
+		FinishedAt      time.Time      `json:"finished_at"`
+		Trigger         string         `json:"trigger"`
+		Error           string         `json:"error,omitempty"`
+		DurationSeconds float64        `json:"duration_seconds"`
+		Runs            int            `json:"runs"`
+	}
+
+	// apiServer is the REST API of the serve command. Runs are queued and executed one at a time
+	// because a reconciliation uses process-wide state.
+	// Order: Config > maps (8) > chan (8) > sync.Mutex (8)
+	apiServer struct {
+		config Config
+		runs   map[string]*apiRun
+		latest map[string]*apiRun // state URI -> last finished run
+		queue  chan *apiRun
+		mu     sync.Mutex
+	}
+
+	// apiRun is one reconciliation requested through the API. changed is closed and replaced whenever
+	// an event is added so progress streams can wait for the next one. finishedAt is guarded by
+	// apiServer.mu, the other fields by mu.
+	// Order: pointer (8) > slice (24) > chan (8) > time.Time (24) > string (16) > int (8) > sync.Mutex (8) > bool (1)
+	apiRun struct {
+		Result     *watchSnapshot
+		events     []apiEvent
+		changed    chan struct{}
+		QueuedAt   time.Time
+		finishedAt time.Time
+		ID         string
+		State      string
+		Status     string // queued, running, finished or failed
+		published  int    // Events published, including those already dropped
+		streams    int    // Progress streams reading the events
+		mu         sync.Mutex
+		finished   bool // The "finished" event was published
+	}
+
+	// apiEvent is one server-sent event of a run's progress stream.
+	// Order: interface{} (16) > string (16)
+	apiEvent struct {
+		data any
+		name string
+	}
+
+	// apiProgress is the data of a progress event, sent as each resource instance finishes.
+	// Order: string (16) > int (8)
+	apiProgress struct {
+		Resource string `json:"resource"`
+		Category string `json:"category"`
+		Done     int    `json:"done"`
+		Total    int    `json:"total"`
+	}
+
+	// s3EventNotification is the part of an S3 event notification that --watch-queue-url needs. The
+	// notification arrives in the SQS message body directly, or as the Message of an SNS envelope.
+	// Order: slice (24) > string (16)
+	s3EventNotification struct {
+		Records []struct {
+			EventName string `json:"eventName"`
+			S3        struct {
+				Bucket struct {
+					Name string `json:"name"`
+				} `json:"bucket"`
+				Object struct {
+					Key string `json:"key"`
+				} `json:"object"`
+			} `json:"s3"`
+		} `json:"Records"`
+		Message string `json:"Message"`
+	}
+
+	// versionInfo is what the version command reports.
+	// Order: slice (24) > string (16) > bool (1)
+	versionInfo struct {
+		StateVersions []uint64 `json:"supported_state_versions"`
+		Version       string   `json:"version"`
+		Commit        string   `json:"commit"`
+		BuildDate     string   `json:"build_date"`
+		GoVersion     string   `json:"go_version"`
+		Platform      string   `json:"platform"`
+		LatestVersion string   `json:"latest_version,omitempty"`
+		Modified      bool     `json:"modified"`
+	}
+
+	// stateDiff lists the resource instance addresses that differ between two state files.
+	// Order: slices (24)
+	stateDiff struct {
+		Added   []string `json:"added"`
+		Removed []string `json:"removed"`
+		Changed []string `json:"changed"`
+	}
+
+	// recentChanges is the difference between a local state and the .backup Terraform wrote next to it
+	// before the last apply, for --compare-backup.
+	// Order: struct (72) > string (16) > uint64 (8) > bool (1)
+	recentChanges struct {
+		Diff           stateDiff `json:"diff"`
+		Backup         string    `json:"backup"`
+		BackupSerial   uint64    `json:"backup_serial"`
+		StateSerial    uint64    `json:"state_serial"`
+		LineageDiffers bool      `json:"lineage_differs,omitempty"`
+	}
+
+	// profiler writes pprof profiles and the per-resource-type timing breakdown for --profile-dir.
+	// Order: time.Time (24) > string (16) > *os.File (8)
+	profiler struct {
+		started time.Time
+		dir     string
+		cpuFile *os.File
+	}
+
+	// resourceTypeTiming aggregates verification time for one Terraform resource type.
+	// Order: string (16) > time.Duration (8) > int (8)
+	resourceTypeTiming struct {
+		resourceType string
+		total        time.Duration
+		max          time.Duration
+		count        int
+	}
+
+	// resourceJob is one resource instance scheduled for verification; index is its position in state order.
+	// Order: ResourceStateV4 > InstanceObjectStateV4 > ResourceStatus > int (8) > bool (1)
+	resourceJob struct {
+		resource ResourceStateV4
+		instance InstanceObjectStateV4
+		status   ResourceStatus
+		index    int
+		resumed  bool // status came from the checkpoint or incremental store rather than a fresh check
+	}
+
+	// routeTableIndex is every route table in the region, listed once per run and indexed for the
+	// route and route table association checks.
+	// Order: maps (8 each)
+	routeTableIndex struct {
+		tables       map[string]ec2types.RouteTable // route table ID -> route table with routes and associations
+		associations map[string]string              // association ID -> route table ID
+	}
+
+	// batchLookup holds the results of bulk describe calls keyed by Terraform resource type, then ID.
+	// Order: map (8) > sync.RWMutex (24)
+	batchLookup struct {
+		found   map[string]map[string]string // type -> requested ID -> live ID
+		checked map[string]map[string]bool   // type -> requested ID -> covered by a successful bulk call
+		mu      sync.RWMutex
+	}
+
+	// securityGroupRuleTuple identifies an aws_security_group_rule by its contents rather than
+	// by security_group_rule_id, for states written before that attribute existed.
+	// Order: slice (24) > string (16) > int32 (4) > bool (1)
+	securityGroupRuleTuple struct {
+		CIDRBlocks            []string
+		IPv6CIDRBlocks        []string
+		PrefixListIDs         []string
+		SecurityGroupID       string
+		Protocol              string
+		SourceSecurityGroupID string
+		FromPort              int32
+		ToPort                int32
+		Egress                bool
+		Self                  bool
+	}
+
+	// tokenBucket is a simple client-side rate limiter refilled at rate tokens per second.
+	// Order: time.Time (24) > float64 (8) > sync.Mutex (8)
+	tokenBucket struct {
+		last   time.Time
+		rate   float64
+		burst  float64
+		tokens float64
+		mu     sync.Mutex
+	}
+
+	// serviceRateLimiter keeps one tokenBucket per AWS service ID.
+	// Order: map (8) > float64 (8) > int (8) > sync.Mutex (8)
+	serviceRateLimiter struct {
+		buckets map[string]*tokenBucket
+		rate    float64
+		burst   int
+		mu      sync.Mutex
+	}
+
+	// liveStateError is returned by a verifier when the resource exists in AWS but is in a
+	// lifecycle state that should be reported in its own category instead of OK or DANGEROUS.
+	// Order: string (16)
+	liveStateError struct {
+		Category    string
+		LiveID      string
+		Message     string
+		Remediation string // "import" suggests importing LiveID, "rm" suggests removing from state
+	}
+
+	// openSearchDomainStatus is the part of an OpenSearch or legacy Elasticsearch domain's status that
+	// the domain and domain policy checks use, whichever API described it.
+	// Order: string (16) > bool (1)
+	openSearchDomainStatus struct {
+		kind              string
+		name              string
+		arn               string
+		accessPolicies    string
+		deleted           bool
+		processing        bool
+		upgradeProcessing bool
+	}
+
+	// checkpointStore persists finished results so an interrupted run can be resumed.
+	// Order: map (8) > pointers (8) > string (16)
+	checkpointStore struct {
+		done    map[string]ResourceStatus
+		file    *os.File
+		encoder *json.Encoder
+		path    string
+	}
+
+	// checkpointRecord is one line of a checkpoint file. The first line only carries the
+	// lineage and serial of the state the checkpoint belongs to.
+	// Order: string (16) > uint64 (8) > bool (1)
+	checkpointRecord struct {
+		Lineage  string `json:"lineage,omitempty"`
+		Address  string `json:"address,omitempty"`
+		Category string `json:"category,omitempty"`
+		Message  string `json:"message,omitempty"`
+		Command  string `json:"command,omitempty"`
+		Kind     string `json:"kind,omitempty"`
+		StateID  string `json:"state_id,omitempty"`
+		LiveID   string `json:"live_id,omitempty"`
+		TFID     string `json:"tf_id,omitempty"`
+		AWSID    string `json:"aws_id,omitempty"`
+		Error    string `json:"error,omitempty"`
+		Serial   uint64 `json:"serial,omitempty"`
+		Exists   bool   `json:"exists,omitempty"`
+	}
+
+	// incrementalStore remembers when each address was last verified so --incremental runs can skip
+	// resources whose attributes are unchanged and whose last check is younger than ttl.
+	// Order: map (8) > time.Time (24) > string (16) > time.Duration (8) > int (8) > sync.Mutex (8)
+	incrementalStore struct {
+		entries map[string]incrementalEntry
+		now     time.Time
+		path    string
+		scope   string // region and account the results were verified in
+		ttl     time.Duration
+		reused  int
+		mu      sync.Mutex
+	}
+
+	// incrementalEntry is the last verified result for one address, keyed to a hash of the
+	// instance attributes it was verified against.
+	// Order: checkpointRecord > time.Time (24) > string (16)
+	incrementalEntry struct {
+		Result         checkpointRecord `json:"result"`
+		VerifiedAt     time.Time        `json:"verified_at"`
+		AttributesHash string           `json:"attributes_hash"`
 	}
 
 	// TFStateFile represents the contents of a Terraform state file.
 	// Order: map (8) / slice (24) > uint64 (8) > string (16)
 	TFStateFile struct {
-		RootOutputs      map[string]OutputStateV4 `json:"outputs"`                     // (8 bytes for map header)
-		Resources        []ResourceStateV4        `json:"resources"`                   // (24 bytes for slice header)
-		CheckResults     []CheckResultsV4         `json:"check_results,omitempty"`     // (24 bytes for slice header)
-		Version          uint64                   `json:"version"`                     // (8 bytes)
-		Serial           uint64                   `json:"serial"`                      // (8 bytes)
-		TerraformVersion string                   `json:"terraform_version,omitempty"` // (16 bytes)
-		Lineage          string                   `json:"lineage"`                     // (16 bytes)
+		RootOutputs      map[string]OutputStateV4   `json:"outputs"`                     // (8 bytes for map header)
+		Resources        []ResourceStateV4          `json:"resources"`                   // (24 bytes for slice header)
+		CheckResults     []CheckResultsV4           `json:"check_results,omitempty"`     // (24 bytes for slice header)
+		Version          uint64                     `json:"version"`                     // (8 bytes)
+		Serial           uint64                     `json:"serial"`                      // (8 bytes)
+		Unknown          map[string]json.RawMessage `json:"-"`                           // Top-level keys this program does not model, written back as read
+		UpgradedFrom     uint64                     `json:"-"`                           // Format version read before the in-memory upgrade to 4; 0 if read as 4
+		TerraformVersion string                     `json:"terraform_version,omitempty"` // (16 bytes)
+		Lineage          string                     `json:"lineage"`                     // (16 bytes)
 	}
 
 	// OutputStateV4 is the state of a single output variable.
@@ -99,28 +679,30 @@ type (
 	// ResourceStateV4 is the state of a single resource.
 	// Order: slice (24) > string (16)
 	ResourceStateV4 struct {
-		Instances      []InstanceObjectStateV4 `json:"instances"` // (24 bytes for slice header)
-		Module         string                  `json:"module,omitempty"`
-		Type           string                  `json:"type"`
-		Name           string                  `json:"name"`
-		EachMode       string                  `json:"each,omitempty"`
-		ProviderConfig string                  `json:"provider"`
-		Mode           string                  `json:"mode"` // RE-ADDED: (16 bytes)
+		Instances      []InstanceObjectStateV4    `json:"instances"` // (24 bytes for slice header)
+		Unknown        map[string]json.RawMessage `json:"-"`         // Keys this program does not model, written back as read
+		Module         string                     `json:"module,omitempty"`
+		Type           string                     `json:"type"`
+		Name           string                     `json:"name"`
+		EachMode       string                     `json:"each,omitempty"`
+		ProviderConfig string                     `json:"provider"`
+		Mode           string                     `json:"mode"` // RE-ADDED: (16 bytes)
 	}
 
 	// InstanceObjectStateV4 is the state of a single instance of a resource.
 	// Order: json.RawMessage (24) > []byte (24) > map (8) > interface{} (16) > uint64 (8) > string (16) > bool (1)
 	InstanceObjectStateV4 struct {
-		AttributesRaw           json.RawMessage   `json:"attributes,omitempty"`            // (24 bytes)
-		AttributeSensitivePaths json.RawMessage   `json:"sensitive_attributes,omitempty"`  // (24 bytes)
-		PrivateRaw              []byte            `json:"private,omitempty"`               // (24 bytes)
-		Dependencies            []string          `json:"dependencies,omitempty"`          // (24 bytes)
-		IndexKey                interface{}       `json:"index_key,omitempty"`             // (16 bytes)
-		Status                  string            `json:"status,omitempty"`                // (16 bytes)
-		Deposed                 string            `json:"deposed,omitempty"`               // (16 bytes)
-		AttributesFlat          map[string]string `json:"attributes_flat,omitempty"`       // (8 bytes for map header)
-		SchemaVersion           uint64            `json:"schema_version"`                  // (8 bytes)
-		CreateBeforeDestroy     bool              `json:"create_before_destroy,omitempty"` // (1 byte)
+		AttributesRaw           json.RawMessage            `json:"attributes,omitempty"`            // (24 bytes)
+		AttributeSensitivePaths json.RawMessage            `json:"sensitive_attributes,omitempty"`  // (24 bytes)
+		PrivateRaw              []byte                     `json:"private,omitempty"`               // (24 bytes)
+		Dependencies            []string                   `json:"dependencies,omitempty"`          // (24 bytes)
+		IndexKey                interface{}                `json:"index_key,omitempty"`             // (16 bytes)
+		Status                  string                     `json:"status,omitempty"`                // (16 bytes)
+		Deposed                 string                     `json:"deposed,omitempty"`               // (16 bytes)
+		AttributesFlat          map[string]string          `json:"attributes_flat,omitempty"`       // (8 bytes for map header)
+		Unknown                 map[string]json.RawMessage `json:"-"`                               // Keys this program does not model, such as identity, written back as read
+		SchemaVersion           uint64                     `json:"schema_version"`                  // (8 bytes)
+		CreateBeforeDestroy     bool                       `json:"create_before_destroy,omitempty"` // (1 byte)
 	}
 
 	// CheckResultsV4 is the results of a single check block.
@@ -140,6 +722,114 @@ type (
 		Status          string   `json:"status"`
 	}
 
+	// failedCheck is an object whose check block, precondition or postcondition failed or could not be
+	// evaluated at the last apply, from the check_results of the state.
+	// Order: slice (24) > string (16)
+	failedCheck struct {
+		FailureMessages []string `json:"failure_messages,omitempty"`
+		ObjectAddr      string   `json:"object_addr"`
+		ConfigAddr      string   `json:"config_addr"`
+		ObjectKind      string   `json:"object_kind"`
+		Status          string   `json:"status"`
+	}
+
+	// stateModuleV3 is one module of a version 3 state, as written by Terraform 0.7 to 0.11.
+	// Order: slice (24) > map (8)
+	stateModuleV3 struct {
+		Path      []string                   `json:"path"`
+		Outputs   map[string]outputStateV3   `json:"outputs"`
+		Resources map[string]resourceStateV3 `json:"resources"`
+	}
+
+	// outputStateV3 is an output of a version 3 state module.
+	// Order: json.RawMessage (24) > string (16) > bool (1)
+	outputStateV3 struct {
+		Value     json.RawMessage `json:"value"`
+		Type      string          `json:"type"`
+		Sensitive bool            `json:"sensitive"`
+	}
+
+	// resourceStateV3 is a resource instance of a version 3 state module, keyed by e.g. "aws_instance.web.1".
+	// Order: slice (24) > pointer (8) > string (16)
+	resourceStateV3 struct {
+		DependsOn []string           `json:"depends_on"`
+		Deposed   []*instanceStateV3 `json:"deposed"`
+		Primary   *instanceStateV3   `json:"primary"`
+		Type      string             `json:"type"`
+		Provider  string             `json:"provider"`
+	}
+
+	// instanceStateV3 is the primary or a deposed object of a version 3 resource, with flatmap attributes.
+	// Order: map (8) > string (16) > bool (1)
+	instanceStateV3 struct {
+		Attributes map[string]string      `json:"attributes"`
+		Meta       map[string]interface{} `json:"meta"`
+		ID         string                 `json:"id"`
+		Tainted    bool                   `json:"tainted"`
+	}
+
+	// planJSON is the part of a `terraform show -json` plan that --plan reconciles.
+	// Order: slice (24) > string (16)
+	planJSON struct {
+		ResourceChanges  []planResourceChange `json:"resource_changes"`
+		FormatVersion    string               `json:"format_version"`
+		TerraformVersion string               `json:"terraform_version"`
+	}
+
+	// planResourceChange is the planned change of one resource instance.
+	// Order: struct > interface{} (16) > string (16)
+	planResourceChange struct {
+		Change        planChangeValues `json:"change"`
+		Index         interface{}      `json:"index,omitempty"`
+		Address       string           `json:"address"`
+		ModuleAddress string           `json:"module_address,omitempty"`
+		Mode          string           `json:"mode"`
+		Type          string           `json:"type"`
+		Name          string           `json:"name"`
+		ProviderName  string           `json:"provider_name"`
+		Deposed       string           `json:"deposed,omitempty"`
+	}
+
+	// planChangeValues is the planned actions of a resource instance with its values before and after them.
+	// Order: slice (24) > json.RawMessage (24)
+	planChangeValues struct {
+		Actions []string        `json:"actions"`
+		Before  json.RawMessage `json:"before"`
+		After   json.RawMessage `json:"after"`
+	}
+
+	// plannedChange is a create, update or replacement of a plan that --plan verifies.
+	// Order: map (8) > string (16)
+	plannedChange struct {
+		Attributes map[string]interface{} // Planned values for creations, current ones otherwise
+		Action     string                 // create, update or replace
+	}
+
+	// remoteStateRef is a state referenced by a terraform_remote_state data source.
+	// Order: string (16)
+	remoteStateRef struct {
+		Address  string // Address of the data source in the referencing state
+		Location string // s3://bucket/key or a local path; identifies the state for cycle detection
+		Backend  string
+		Bucket   string
+		Key      string
+		Region   string
+		Path     string
+	}
+
+	// linkedState is a state reached through terraform_remote_state data sources with --follow-remote-state,
+	// and the outcome of verifying it.
+	// Order: struct (large) > string (16) > int (8)
+	linkedState struct {
+		Results JSONResults `json:"results"`
+		State   string      `json:"state"`
+		Parent  string      `json:"parent"`  // The state holding the data source
+		Address string      `json:"address"` // The terraform_remote_state data source in Parent
+		Lineage string      `json:"lineage,omitempty"`
+		Error   string      `json:"error,omitempty"` // Why the state could not be read or verified
+		Depth   int         `json:"depth"`
+	}
+
 	// StateVersionV4 is a weird special type we use to produce our hard-coded
 	// "version": 4 in the JSON serialization. (No fields to sort)
 	StateVersionV4 struct{}
@@ -147,13 +837,14 @@ type (
 	// StateFileV4 is the internal representation of a state file at version 4.
 	// Order: maps/slices > uint64 > string
 	StateFileV4 struct {
-		RootOutputs      map[string]OutputStateV4 `json:"outputs"`
-		Resources        []ResourceStateV4        `json:"resources"`
-		CheckResults     []CheckResultsV4         `json:"check_results"`
-		Serial           uint64                   `json:"serial"`
-		Version          StateVersionV4           `json:"version"` // StateVersionV4 is a struct, but effectively small
-		TerraformVersion string                   `json:"terraform_version"`
-		Lineage          string                   `json:"lineage"`
+		RootOutputs      map[string]OutputStateV4   `json:"outputs"`
+		Resources        []ResourceStateV4          `json:"resources"`
+		CheckResults     []CheckResultsV4           `json:"check_results"`
+		Unknown          map[string]json.RawMessage `json:"-"`
+		Serial           uint64                     `json:"serial"`
+		Version          StateVersionV4             `json:"version"` // StateVersionV4 is a struct, but effectively small
+		TerraformVersion string                     `json:"terraform_version"`
+		Lineage          string                     `json:"lineage"`
 	}
 
 	// categorizedResults holds slices of ResourceStatus for each category.
@@ -166,11 +857,87 @@ type (
 		PotentialImportResults []ResourceStatus      // (24 bytes)
 		DangerousResults       []ResourceStatus      // (24 bytes)
 		RegionMismatchResults  []ResourceStatus      // (24 bytes)
+		StaleResults           []ResourceStatus      // (24 bytes)
+		PendingDeletionResults []ResourceStatus      // (24 bytes)
+		SkippedResults         []ResourceStatus      // (24 bytes)
 		RunCommands            []string              // (24 bytes)
 		CommandExecutionLogs   []CommandExecutionLog // (24 bytes)
+		Uploads                []ArtifactUpload      // (24 bytes)
+		PlannedWrites          []plannedWrite        // Files and objects --dry-run did not write (24 bytes)
+		LinkedStates           []linkedState         // States followed through terraform_remote_state (24 bytes)
+		FailedChecks           []failedCheck         // Checks that failed or errored at the last apply (24 bytes)
+		PolicyViolations       []policyViolation     // Messages of the deny and warn rules of --policy (24 bytes)
+		RecentChanges          *recentChanges        // What the last apply changed, from --compare-backup (8 bytes)
 		ApplicationError       string                `json:"application_error,omitempty"` // (16 bytes)
 	}
 
+	// policyViolation is a message of a deny or warn rule of a --policy package.
+	// Order: string (16)
+	policyViolation struct {
+		Policy  string `json:"policy"` // Package of the rule, e.g. reconcile.iam
+		Level   string `json:"level"`  // deny or warn
+		Message string `json:"message"`
+	}
+
+	// datadogSeries is a metric of the Datadog v2 series API.
+	// Order: slice (24) > string (16) > int (8)
+	datadogSeries struct {
+		Points []datadogPoint `json:"points"`
+		Tags   []string       `json:"tags"`
+		Metric string         `json:"metric"`
+		Unit   string         `json:"unit,omitempty"`
+		Type   int            `json:"type"` // 3 is a gauge
+	}
+
+	// datadogPoint is a value of a datadogSeries at a time.
+	// Order: int64 (8) > float64 (8)
+	datadogPoint struct {
+		Timestamp int64   `json:"timestamp"`
+		Value     float64 `json:"value"`
+	}
+
+	// datadogEvent is an event of the Datadog v1 events API.
+	// Order: slice (24) > string (16) > int64 (8)
+	datadogEvent struct {
+		Tags           []string `json:"tags"`
+		Title          string   `json:"title"`
+		Text           string   `json:"text"`
+		AlertType      string   `json:"alert_type"` // error, warning, info or success
+		AggregationKey string   `json:"aggregation_key,omitempty"`
+		SourceTypeName string   `json:"source_type_name,omitempty"`
+		DateHappened   int64    `json:"date_happened"`
+	}
+
+	// plannedWrite is a local file or S3 object that a --dry-run run would have created or overwritten.
+	// Order: string (16)
+	plannedWrite struct {
+		Target      string `json:"target"` // Local path or s3:// URI
+		Action      string `json:"action"` // create, overwrite or write when S3 could not tell
+		Description string `json:"description"`
+	}
+
+	// s3Artifact is one backup or report object to upload. Exactly one of localPath and content is set.
+	// Order: string (16) > bool (1)
+	s3Artifact struct {
+		name      string
+		localPath string
+		content   string
+		key       string
+		locked    bool // Set by uploadArtifacts: backups and reports get --object-lock-mode retention
+	}
+
+	// ArtifactUpload is the outcome of uploading one artifact to S3.
+	// Order: string (16) > int (8) > bool (1)
+	ArtifactUpload struct {
+		Artifact    string `json:"artifact"`
+		Bucket      string `json:"bucket"`
+		Key         string `json:"key"`
+		Error       string `json:"error,omitempty"`
+		RetainUntil string `json:"retain_until,omitempty"` // End of the Object Lock retention, if locked
+		Attempts    int    `json:"attempts"`
+		Uploaded    bool   `json:"uploaded"`
+	}
+
 	// CommandExecutionLog
 	// Order: string (16) > int (8)
 	CommandExecutionLog struct {
@@ -197,13 +964,17 @@ type (
 	// JSONResultItem
 	// Order: string (16)
 	JSONResultItem struct {
-		Resource string `json:"resource"`
-		Command  string `json:"command"`
-		Kind     string `json:"kind"`
-		TFID     string `json:"tf_id"`
-		AWSID    string `json:"aws_id"`
-		Stdout   string `json:"stdout"`
-		Stderr   string `json:"stderr"`
+		Resource      string `json:"resource"`
+		Module        string `json:"module,omitempty"`
+		Provider      string `json:"provider,omitempty"`
+		Type          string `json:"type,omitempty"`
+		PlannedAction string `json:"planned_action,omitempty"`
+		Command       string `json:"command"`
+		Kind          string `json:"kind"`
+		TFID          string `json:"tf_id"`
+		AWSID         string `json:"aws_id"`
+		Stdout        string `json:"stdout"`
+		Stderr        string `json:"stderr"`
 	}
 
 	// JSONResults
@@ -216,14 +987,24 @@ type (
 		WarningResults         []JSONResultItem `json:"WARNING"`
 		ErrorResults           []JSONResultItem `json:"ERROR"`
 		DangerousResults       []JSONResultItem `json:"DANGEROUS"`
+		StaleResults           []JSONResultItem `json:"STALE"`
+		PendingDeletionResults []JSONResultItem `json:"PENDING_DELETION"`
+		SkippedResults         []JSONResultItem `json:"SKIPPED"`
 	}
 
 	// JSONOutput
 	// Order: slices (24) > maps (8) > string (16) > uint64 (8) > int (8)
 	JSONOutput struct {
-		ExecutionLogs    []CommandExecutionLog `json:"execution_logs"` // (24 bytes)
-		Commands         []string              `json:"commands"`       // (24 bytes)
-		Results          JSONResults           `json:"results"`        // (struct containing slices, effectively large)
+		ExecutionLogs    []CommandExecutionLog `json:"execution_logs"`              // (24 bytes)
+		Commands         []string              `json:"commands"`                    // (24 bytes)
+		Uploads          []ArtifactUpload      `json:"uploads,omitempty"`           // (24 bytes)
+		PlannedWrites    []plannedWrite        `json:"dry_run,omitempty"`           // (24 bytes)
+		LinkedStates     []linkedState         `json:"linked_states,omitempty"`     // (24 bytes)
+		FailedChecks     []failedCheck         `json:"failed_checks,omitempty"`     // (24 bytes)
+		PolicyViolations []policyViolation     `json:"policy_violations,omitempty"` // (24 bytes)
+		RecentChanges    *recentChanges        `json:"recent_changes,omitempty"`    // (8 bytes)
+		SkippedProviders map[string]int        `json:"skipped_providers,omitempty"` // Skipped resources per unverified provider (8 bytes)
+		Results          JSONResults           `json:"results"`                     // (struct containing slices, effectively large)
 		State            string                `json:"state"`
 		StateChecksum    string                `json:"state_checksum"`
 		Region           string                `json:"region"`
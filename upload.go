@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
+	"sync"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
@@ -21,17 +23,97 @@ func uploadStateFileToS3(ctx context.Context, awsClients *AWSClient, filePath, b
 		_ = file.Close()
 	}()
 
-	uploader := manager.NewUploader(awsClients.S3Client) // Use the existing S3Client
+	uploader := manager.NewUploader(awsClients.StateS3Client) // Use the existing S3Client
 
-	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   file,
-		// Removed explicit ACL and MetadataDirective to mimic `aws s3 cp` default behavior
-		// and respect existing bucket configurations (like default ACLs, versioning, object locking).
-	})
+	// No explicit ACL or MetadataDirective, to mimic `aws s3 cp` default behavior
+	// and respect existing bucket configurations (like default ACLs, versioning, object locking).
+	_, err = uploader.Upload(ctx, newPutObjectInput(awsClients, bucket, key, file))
 	if err != nil {
 		return fmt.Errorf("failed to upload state to S3: %w", err)
 	}
 	return nil
 }
+
+// checkStateLineage returns an error unless the state at filePath has lineage expected, so that a state
+// is never uploaded over the key of another configuration or environment.
+func checkStateLineage(filePath, expected string) error {
+	state, err := openAndReadStateFile(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot check its lineage: %w", err)
+	}
+	if state.Lineage != expected {
+		return fmt.Errorf("it has lineage %q, but %q was expected", state.Lineage, expected)
+	}
+	return nil
+}
+
+// artifactUploadAttempts is how many times an artifact upload is tried before it is reported as failed.
+// Each attempt already includes the SDK's own retries; these cover failures that outlast them.
+const artifactUploadAttempts = 3
+
+// uploadArtifacts uploads every artifact to bucket concurrently, retrying each failed upload with a
+// growing pause, and returns one ArtifactUpload per artifact in the order given. The artifacts are
+// backups and reports, so they are locked with --object-lock-mode and --object-lock-legal-hold.
+func uploadArtifacts(ctx context.Context, awsClients *AWSClient, bucket string, artifacts []s3Artifact, quiet bool) []ArtifactUpload {
+	uploads := make([]ArtifactUpload, len(artifacts))
+	var wg sync.WaitGroup
+	for i, artifact := range artifacts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !quiet {
+				fmt.Printf("Uploading %s to s3://%s/%s...\n", artifact.name, bucket, artifact.key)
+			}
+			artifact.locked = true
+			uploads[i] = uploadArtifact(ctx, awsClients, bucket, artifact)
+			if !uploads[i].Uploaded {
+				log.Printf("ERROR: Failed to upload %s to S3 after %d attempts: %s", artifact.name, uploads[i].Attempts, uploads[i].Error)
+			}
+		}()
+	}
+	wg.Wait()
+	return uploads
+}
+
+// uploadArtifact uploads a single artifact, retrying up to artifactUploadAttempts times.
+func uploadArtifact(ctx context.Context, awsClients *AWSClient, bucket string, artifact s3Artifact) ArtifactUpload {
+	upload := ArtifactUpload{Artifact: artifact.name, Bucket: bucket, Key: artifact.key}
+	var options []func(*s3.PutObjectInput)
+	locked := artifact.locked && awsClients.objectLocked()
+	if locked {
+		options = append(options, awsClients.withObjectLock)
+	}
+	var err error
+	for upload.Attempts < artifactUploadAttempts {
+		if upload.Attempts > 0 {
+			select {
+			case <-time.After(time.Duration(upload.Attempts) * time.Second):
+			case <-ctx.Done():
+				upload.Error = ctx.Err().Error()
+				return upload
+			}
+		}
+		upload.Attempts++
+		if artifact.localPath != "" {
+			err = uploadFileToS3(ctx, awsClients, artifact.localPath, bucket, artifact.key, options...)
+		} else {
+			err = uploadStringContentToS3(ctx, awsClients, artifact.content, bucket, artifact.key, options...)
+		}
+		if err == nil {
+			upload.Uploaded = true
+			break
+		}
+	}
+	if !upload.Uploaded {
+		upload.Error = err.Error()
+		return upload
+	}
+	if locked {
+		// An object the bucket accepted without its lock must not pass for a locked backup
+		if upload.RetainUntil, err = confirmObjectLock(ctx, awsClients, bucket, artifact.key); err != nil {
+			upload.Uploaded = false
+			upload.Error = err.Error()
+		}
+	}
+	return upload
+}
@@ -1,36 +1,96 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/acm"
 	"github.com/aws/aws-sdk-go-v2/service/acm/types"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/appsync"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	codebuildtypes "github.com/aws/aws-sdk-go-v2/service/codebuild/types"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/codepipeline"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	cognitoidptypes "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/dlm"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	efstypes "github.com/aws/aws-sdk-go-v2/service/efs/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticsearchservice"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	firehosetypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	guarddutytypes "github.com/aws/aws-sdk-go-v2/service/guardduty/types"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesistypes "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/mq"
+	mqtypes "github.com/aws/aws-sdk-go-v2/service/mq/types"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	sesv2types "github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	sfntypes "github.com/aws/aws-sdk-go-v2/service/sfn/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	wafv2types "github.com/aws/aws-sdk-go-v2/service/wafv2/types"
 )
 
 // verifyS3Bucket checks if an S3 bucket exists in AWS
 func (c *AWSClient) verifyS3Bucket(ctx context.Context, bucketName string) (string, bool, error) {
-	_, err := c.S3Client.HeadBucket(ctx, &s3.HeadBucketInput{
-		Bucket: aws.String(bucketName),
+	_, err := cachedCall(ctx, c, cacheKey("s3", "HeadBucket", bucketName), func() (*s3.HeadBucketOutput, error) {
+		return c.S3Client.HeadBucket(ctx, &s3.HeadBucketInput{
+			Bucket: aws.String(bucketName),
+		})
 	})
 	if err != nil {
 		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "NoSuchBucket") {
@@ -82,6 +142,9 @@ func (c *AWSClient) verifyKeyPair(ctx context.Context, keyName string) (string,
 
 // verifySecurityGroup checks if an EC2 Security Group exists in AWS
 func (c *AWSClient) verifySecurityGroup(ctx context.Context, sgID, sgName string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_security_group", sgID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeSecurityGroupsInput{}
 	if sgID != "" {
 		input.GroupIds = []string{sgID}
@@ -108,8 +171,10 @@ func (c *AWSClient) verifySecurityGroup(ctx context.Context, sgID, sgName string
 // verifyRoute53Zone checks if a Route53 Hosted Zone exists in AWS
 func (c *AWSClient) verifyRoute53Zone(ctx context.Context, zoneID, zoneName string) (string, bool, error) {
 	if zoneID != "" {
-		resp, err := c.Route53Client.GetHostedZone(ctx, &route53.GetHostedZoneInput{
-			Id: aws.String(zoneID),
+		resp, err := cachedCall(ctx, c, cacheKey("route53", "GetHostedZone", normalizeRoute53ZoneID(zoneID)), func() (*route53.GetHostedZoneOutput, error) {
+			return c.Route53Client.GetHostedZone(ctx, &route53.GetHostedZoneInput{
+				Id: aws.String(zoneID),
+			})
 		})
 		if err != nil {
 			if strings.Contains(err.Error(), "NoSuchHostedZone") {
@@ -119,8 +184,10 @@ func (c *AWSClient) verifyRoute53Zone(ctx context.Context, zoneID, zoneName stri
 		}
 		return *resp.HostedZone.Id, true, nil
 	} else if zoneName != "" {
-		resp, err := c.Route53Client.ListHostedZonesByName(ctx, &route53.ListHostedZonesByNameInput{
-			DNSName: aws.String(zoneName),
+		resp, err := cachedCall(ctx, c, cacheKey("route53", "ListHostedZonesByName", zoneName), func() (*route53.ListHostedZonesByNameOutput, error) {
+			return c.Route53Client.ListHostedZonesByName(ctx, &route53.ListHostedZonesByNameInput{
+				DNSName: aws.String(zoneName),
+			})
 		})
 		if err != nil {
 			return "", false, fmt.Errorf("failed to list Route53 Hosted Zones by name '%s': %w", zoneName, err)
@@ -255,12 +322,101 @@ func (c *AWSClient) verifySecurityGroupRule(ctx context.Context, sgRuleAWSID str
 	return "", false, nil // Rule not found
 }
 
+// verifySecurityGroupRuleByTuple checks if every AWS rule that an aws_security_group_rule expands to
+// (one per CIDR, IPv6 CIDR, prefix list or source group) exists on the security group.
+// It returns the matching AWS rule IDs joined by commas.
+func (c *AWSClient) verifySecurityGroupRuleByTuple(ctx context.Context, tuple securityGroupRuleTuple) (string, bool, error) {
+	if tuple.SecurityGroupID == "" {
+		return "", false, fmt.Errorf("security group ID must be provided for security group rule verification")
+	}
+
+	var liveRules []ec2types.SecurityGroupRule
+	paginator := ec2.NewDescribeSecurityGroupRulesPaginator(c.EC2Client, &ec2.DescribeSecurityGroupRulesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("group-id"), Values: []string{tuple.SecurityGroupID}}},
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			if strings.Contains(err.Error(), "InvalidGroup.NotFound") {
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("failed to describe rules for Security Group '%s': %w", tuple.SecurityGroupID, err)
+		}
+		liveRules = append(liveRules, resp.SecurityGroupRules...)
+	}
+
+	// Each source of the Terraform rule becomes its own AWS rule.
+	type ruleSource struct{ cidr, ipv6, prefixList, group string }
+	var sources []ruleSource
+	for _, cidr := range tuple.CIDRBlocks {
+		sources = append(sources, ruleSource{cidr: cidr})
+	}
+	for _, cidr := range tuple.IPv6CIDRBlocks {
+		sources = append(sources, ruleSource{ipv6: cidr})
+	}
+	for _, pl := range tuple.PrefixListIDs {
+		sources = append(sources, ruleSource{prefixList: pl})
+	}
+	if tuple.SourceSecurityGroupID != "" {
+		sources = append(sources, ruleSource{group: tuple.SourceSecurityGroupID})
+	}
+	if tuple.Self {
+		sources = append(sources, ruleSource{group: tuple.SecurityGroupID})
+	}
+	if len(sources) == 0 {
+		return "", false, fmt.Errorf("security group rule on '%s' has no CIDR, prefix list or source security group to match", tuple.SecurityGroupID)
+	}
+
+	protocol := normalizeIPProtocol(tuple.Protocol)
+	var matchedIDs []string
+	for _, src := range sources {
+		found := false
+		for _, rule := range liveRules {
+			if aws.ToBool(rule.IsEgress) != tuple.Egress || normalizeIPProtocol(aws.ToString(rule.IpProtocol)) != protocol {
+				continue
+			}
+			// Ports are meaningless (-1 in AWS, 0 in Terraform) for the "all traffic" protocol.
+			if protocol != "-1" && (aws.ToInt32(rule.FromPort) != tuple.FromPort || aws.ToInt32(rule.ToPort) != tuple.ToPort) {
+				continue
+			}
+			var ruleGroup string
+			if rule.ReferencedGroupInfo != nil {
+				ruleGroup = aws.ToString(rule.ReferencedGroupInfo.GroupId)
+			}
+			if aws.ToString(rule.CidrIpv4) == src.cidr && aws.ToString(rule.CidrIpv6) == src.ipv6 &&
+				aws.ToString(rule.PrefixListId) == src.prefixList && ruleGroup == src.group {
+				matchedIDs = append(matchedIDs, aws.ToString(rule.SecurityGroupRuleId))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false, nil // At least one part of the rule is missing in AWS
+		}
+	}
+	return strings.Join(matchedIDs, ","), true, nil
+}
+
+// normalizeIPProtocol maps protocol names and numbers to the form EC2 returns for rules.
+func normalizeIPProtocol(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "6", "tcp":
+		return "tcp"
+	case "17", "udp":
+		return "udp"
+	case "1", "icmp":
+		return "icmp"
+	case "58", "icmpv6":
+		return "icmpv6"
+	case "all", "-1":
+		return "-1"
+	}
+	return strings.ToLower(protocol)
+}
+
 // verifyACMCertificate checks if an ACM Certificate exists in AWS.
 func (c *AWSClient) verifyACMCertificate(ctx context.Context, certARN string) (string, bool, error) {
-	input := &acm.DescribeCertificateInput{
-		CertificateArn: aws.String(certARN),
-	}
-	resp, err := c.ACMClient.DescribeCertificate(ctx, input)
+	resp, err := c.describeACMCertificate(ctx, certARN)
 	if err != nil {
 		if strings.Contains(err.Error(), "ResourceNotFoundException") {
 			return "", false, nil // Certificate not found
@@ -274,10 +430,7 @@ func (c *AWSClient) verifyACMCertificate(ctx context.Context, certARN string) (s
 func (c *AWSClient) verifyACMCertificateValidation(ctx context.Context, certARN string) (string, bool, error) {
 	// Certificate validation is implicitly checked by the certificate status.
 	// If the certificate exists and its status is ISSUED, it's validated.
-	input := &acm.DescribeCertificateInput{
-		CertificateArn: aws.String(certARN),
-	}
-	resp, err := c.ACMClient.DescribeCertificate(ctx, input)
+	resp, err := c.describeACMCertificate(ctx, certARN)
 	if err != nil {
 		if strings.Contains(err.Error(), "ResourceNotFoundException") {
 			return "", false, nil // Certificate not found, thus not validated
@@ -292,15 +445,15 @@ func (c *AWSClient) verifyACMCertificateValidation(ctx context.Context, certARN
 }
 
 // verifyRoute53Record checks if a Route53 Record exists in AWS.
-func (c *AWSClient) verifyRoute53Record(ctx context.Context, zoneID, recordName, recordType string) (string, bool, error) {
-	input := &route53.ListResourceRecordSetsInput{
-		HostedZoneId:    aws.String(zoneID),
-		StartRecordName: aws.String(recordName),
-		StartRecordType: route53types.RRType(recordType),
-		MaxItems:        aws.Int32(1), // Fetch only one record for efficiency
-	}
+// The zone's records are listed once per run and shared by every record in it, so large zones
+// and weighted/latency/failover record sets sharing a name are all considered. When
+// setIdentifier is non-empty only the record set with that identifier matches, and when
+// aliasName is non-empty the live record must be an alias pointing at that target.
+func (c *AWSClient) verifyRoute53Record(ctx context.Context, zoneID, recordName, recordType, setIdentifier, aliasName string) (string, bool, error) {
+	zoneID = normalizeRoute53ZoneID(zoneID)
+	wantType := route53types.RRType(strings.ToUpper(recordType))
 
-	resp, err := c.Route53Client.ListResourceRecordSets(ctx, input)
+	records, err := c.route53ZoneRecords(ctx, zoneID)
 	if err != nil {
 		if strings.Contains(err.Error(), "NoSuchHostedZone") {
 			return "", false, fmt.Errorf("route53 Hosted Zone '%s' not found for record check: %w", zoneID, err)
@@ -308,18 +461,90 @@ func (c *AWSClient) verifyRoute53Record(ctx context.Context, zoneID, recordName,
 		return "", false, fmt.Errorf("failed to list Route53 record sets for '%s' in zone '%s': %w", recordName, zoneID, err)
 	}
 
-	for _, record := range resp.ResourceRecordSets {
-		normalizedRecordName := *record.Name
-		if strings.HasSuffix(normalizedRecordName, ".") {
-			normalizedRecordName = strings.TrimSuffix(normalizedRecordName, ".")
+	for _, record := range records[route53RecordKey(recordName, string(wantType))] {
+		if setIdentifier != "" && aws.ToString(record.SetIdentifier) != setIdentifier {
+			continue
 		}
-		if normalizedRecordName == recordName && record.Type == route53types.RRType(recordType) {
-			return fmt.Sprintf("%s_%s_%s", zoneID, recordName, recordType), true, nil
+		if aliasName != "" {
+			if record.AliasTarget == nil || normalizeRoute53Name(aws.ToString(record.AliasTarget.DNSName)) != normalizeRoute53Name(aliasName) {
+				continue
+			}
 		}
+		return route53RecordID(zoneID, recordName, string(wantType), setIdentifier), true, nil
 	}
 	return "", false, nil // Record not found
 }
 
+// route53ZoneRecords lists every record set in a hosted zone once per run, grouped by
+// route53RecordKey, so all aws_route53_record entries of the zone are verified from one listing.
+func (c *AWSClient) route53ZoneRecords(ctx context.Context, zoneID string) (map[string][]route53types.ResourceRecordSet, error) {
+	return cachedCall(ctx, c, cacheKey("route53", "ListResourceRecordSets", zoneID), func() (map[string][]route53types.ResourceRecordSet, error) {
+		records := make(map[string][]route53types.ResourceRecordSet)
+		paginator := route53.NewListResourceRecordSetsPaginator(c.Route53Client, &route53.ListResourceRecordSetsInput{
+			HostedZoneId: aws.String(zoneID),
+		})
+		for paginator.HasMorePages() {
+			resp, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range resp.ResourceRecordSets {
+				key := route53RecordKey(aws.ToString(record.Name), string(record.Type))
+				records[key] = append(records[key], record)
+			}
+		}
+		return records, nil
+	})
+}
+
+// route53RecordKey identifies the record sets sharing a name and type within a zone.
+func route53RecordKey(recordName, recordType string) string {
+	return normalizeRoute53Name(recordName) + " " + strings.ToUpper(recordType)
+}
+
+// route53RecordID builds the ID Terraform uses for aws_route53_record: ZONEID_NAME_TYPE[_SETIDENTIFIER].
+func route53RecordID(zoneID, recordName, recordType, setIdentifier string) string {
+	id := fmt.Sprintf("%s_%s_%s", zoneID, strings.TrimSuffix(recordName, "."), recordType)
+	if setIdentifier != "" {
+		id = fmt.Sprintf("%s_%s", id, setIdentifier)
+	}
+	return id
+}
+
+// normalizeRoute53ZoneID strips the "/hostedzone/" prefix Route53 returns on zone IDs.
+func normalizeRoute53ZoneID(zoneID string) string {
+	return strings.TrimPrefix(zoneID, "/hostedzone/")
+}
+
+// normalizeRoute53Name lowercases a DNS name, removes the trailing dot and decodes the
+// octal escapes (e.g. "\052" for "*") Route53 uses in record names.
+func normalizeRoute53Name(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if !strings.Contains(name, "\\") {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+3 < len(name) && isOctalDigits(name[i+1:i+4]) {
+			b.WriteByte((name[i+1]-'0')*64 + (name[i+2]-'0')*8 + (name[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// isOctalDigits reports whether s consists solely of octal digits.
+func isOctalDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '7' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
 // verifyAMI checks if an EC2 AMI exists in AWS.
 func (c *AWSClient) verifyAMI(ctx context.Context, imageID string) (string, bool, error) {
 	input := &ec2.DescribeImagesInput{
@@ -407,6 +632,9 @@ func (c *AWSClient) verifySecretsManagerSecretVersion(ctx context.Context, secre
 
 // verifyEIP checks if an EC2 Elastic IP exists in AWS.
 func (c *AWSClient) verifyEIP(ctx context.Context, allocationID string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_eip", allocationID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeAddressesInput{
 		AllocationIds: []string{allocationID},
 	}
@@ -425,6 +653,9 @@ func (c *AWSClient) verifyEIP(ctx context.Context, allocationID string) (string,
 
 // verifyInternetGateway checks if an EC2 Internet Gateway exists in AWS.
 func (c *AWSClient) verifyInternetGateway(ctx context.Context, igwID string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_internet_gateway", igwID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeInternetGatewaysInput{
 		InternetGatewayIds: []string{igwID},
 	}
@@ -443,6 +674,9 @@ func (c *AWSClient) verifyInternetGateway(ctx context.Context, igwID string) (st
 
 // verifyNatGateway checks if an EC2 NAT Gateway exists in AWS.
 func (c *AWSClient) verifyNatGateway(ctx context.Context, natGatewayID string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_nat_gateway", natGatewayID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeNatGatewaysInput{
 		NatGatewayIds: []string{natGatewayID},
 	}
@@ -461,25 +695,21 @@ func (c *AWSClient) verifyNatGateway(ctx context.Context, natGatewayID string) (
 
 // verifyRoute checks if an EC2 Route exists in AWS.
 func (c *AWSClient) verifyRoute(ctx context.Context, routeTableID, destinationCIDR string) (string, bool, error) {
-	input := &ec2.DescribeRouteTablesInput{
-		RouteTableIds: []string{routeTableID},
-	}
-	resp, err := c.EC2Client.DescribeRouteTables(ctx, input)
+	index, err := c.routeTables(ctx)
 	if err != nil {
-		if strings.Contains(err.Error(), "InvalidRouteTableID.NotFound") {
-			return "", false, fmt.Errorf("route Table '%s' not found for route verification: %w", routeTableID, err)
-		}
 		return "", false, fmt.Errorf("failed to describe Route Table '%s' for route verification: %w", routeTableID, err)
 	}
+	rt, ok := index.tables[routeTableID]
+	if !ok {
+		return "", false, fmt.Errorf("route Table '%s' not found for route verification", routeTableID)
+	}
 
-	if len(resp.RouteTables) > 0 && resp.RouteTables[0].Routes != nil {
-		for _, route := range resp.RouteTables[0].Routes {
-			if route.DestinationCidrBlock != nil && *route.DestinationCidrBlock == destinationCIDR && route.State == ec2types.RouteStateActive {
-				return fmt.Sprintf("%s_%s", routeTableID, destinationCIDR), true, nil
-			}
-			if route.DestinationIpv6CidrBlock != nil && *route.DestinationIpv6CidrBlock == destinationCIDR && route.State == ec2types.RouteStateActive {
-				return fmt.Sprintf("%s_%s", routeTableID, destinationCIDR), true, nil
-			}
+	for _, route := range rt.Routes {
+		if route.DestinationCidrBlock != nil && *route.DestinationCidrBlock == destinationCIDR && route.State == ec2types.RouteStateActive {
+			return fmt.Sprintf("%s_%s", routeTableID, destinationCIDR), true, nil
+		}
+		if route.DestinationIpv6CidrBlock != nil && *route.DestinationIpv6CidrBlock == destinationCIDR && route.State == ec2types.RouteStateActive {
+			return fmt.Sprintf("%s_%s", routeTableID, destinationCIDR), true, nil
 		}
 	}
 	return "", false, nil
@@ -487,6 +717,9 @@ func (c *AWSClient) verifyRoute(ctx context.Context, routeTableID, destinationCI
 
 // verifyRouteTable checks if an EC2 Route Table exists in AWS.
 func (c *AWSClient) verifyRouteTable(ctx context.Context, routeTableID string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_route_table", routeTableID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeRouteTablesInput{
 		RouteTableIds: []string{routeTableID},
 	}
@@ -505,25 +738,21 @@ func (c *AWSClient) verifyRouteTable(ctx context.Context, routeTableID string) (
 
 // verifyRouteTableAssociation checks if an EC2 Route Table Association exists in AWS.
 func (c *AWSClient) verifyRouteTableAssociation(ctx context.Context, associationID string) (string, bool, error) {
-	resp, err := c.EC2Client.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{})
+	index, err := c.routeTables(ctx)
 	if err != nil {
 		return "", false, fmt.Errorf("failed to list route tables for association check: %w", err)
 	}
-
-	for _, rt := range resp.RouteTables {
-		if rt.Associations != nil {
-			for _, assoc := range rt.Associations {
-				if assoc.RouteTableAssociationId != nil && *assoc.RouteTableAssociationId == associationID {
-					return *assoc.RouteTableAssociationId, true, nil
-				}
-			}
-		}
+	if _, ok := index.associations[associationID]; ok {
+		return associationID, true, nil
 	}
 	return "", false, nil
 }
 
 // verifySubnet checks if an EC2 Subnet exists in AWS.
 func (c *AWSClient) verifySubnet(ctx context.Context, subnetID string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_subnet", subnetID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeSubnetsInput{
 		SubnetIds: []string{subnetID},
 	}
@@ -542,6 +771,9 @@ func (c *AWSClient) verifySubnet(ctx context.Context, subnetID string) (string,
 
 // verifyVPC checks if an EC2 VPC exists in AWS.
 func (c *AWSClient) verifyVPC(ctx context.Context, vpcID string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_vpc", vpcID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeVpcsInput{
 		VpcIds: []string{vpcID},
 	}
@@ -558,9 +790,11 @@ func (c *AWSClient) verifyVPC(ctx context.Context, vpcID string) (string, bool,
 	return "", false, nil
 }
 
-// verifyInstance checks if an EC2 Instance exists in AWS.
 // verifyInstance checks if an EC2 Instance exists in AWS.
 func (c *AWSClient) verifyInstance(ctx context.Context, instanceID string) (string, bool, error) {
+	if liveID, exists, ok := c.Batch.lookup("aws_instance", instanceID); ok {
+		return liveID, exists, nil
+	}
 	input := &ec2.DescribeInstancesInput{
 		InstanceIds: []string{instanceID},
 	}
@@ -817,10 +1051,11 @@ func (c *AWSClient) verifyLambdaPermission(ctx context.Context, functionName, st
 		return "", false, fmt.Errorf("both function name and statement ID must be provided for Lambda permission verification")
 	}
 
-	input := &lambda.GetPolicyInput{
-		FunctionName: aws.String(functionName),
-	}
-	resp, err := c.LambdaClient.GetPolicy(ctx, input)
+	resp, err := cachedCall(ctx, c, cacheKey("lambda", "GetPolicy", functionName), func() (*lambda.GetPolicyOutput, error) {
+		return c.LambdaClient.GetPolicy(ctx, &lambda.GetPolicyInput{
+			FunctionName: aws.String(functionName),
+		})
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "ResourceNotFoundException") {
 			return "", false, nil // Policy (and thus permission) not found
@@ -1045,7 +1280,9 @@ func (c *AWSClient) verifyS3Object(ctx context.Context, bucketName, key string)
 }
 
 // verifyECS_Service checks if an ECS Service exists in AWS.
-func (c *AWSClient) verifyECSService(ctx context.Context, clusterName, serviceName string) (string, bool, error) {
+// When stateTaskDefinition is set and the live service runs a different task definition,
+// a STALE liveStateError is returned so the drift is reported distinctly.
+func (c *AWSClient) verifyECSService(ctx context.Context, clusterName, serviceName, stateTaskDefinition string) (string, bool, error) {
 	if clusterName == "" || serviceName == "" {
 		return "", false, fmt.Errorf("both cluster name and service name must be provided for ECS service verification")
 	}
@@ -1063,22 +1300,32 @@ func (c *AWSClient) verifyECSService(ctx context.Context, clusterName, serviceNa
 		return "", false, fmt.Errorf("failed to describe ECS Service '%s' in cluster '%s': %w", serviceName, clusterName, err)
 	}
 
-	if len(resp.Services) > 0 && resp.Services[0].ServiceArn != nil {
-		return *resp.Services[0].ServiceArn, true, nil
+	for _, svc := range resp.Services {
+		if svc.ServiceArn == nil || aws.ToString(svc.Status) == "INACTIVE" {
+			continue // Deleted services linger as INACTIVE for a while
+		}
+		liveTaskDefinition := aws.ToString(svc.TaskDefinition)
+		if stateTaskDefinition != "" && liveTaskDefinition != "" && !sameTaskDefinition(stateTaskDefinition, liveTaskDefinition) {
+			return "", false, &liveStateError{
+				Category: "STALE",
+				LiveID:   *svc.ServiceArn,
+				Message:  fmt.Sprintf("service runs task definition '%s' but state references '%s'. Run `terraform apply -refresh-only` to record the running one, or apply the configuration to deploy the one in state.", liveTaskDefinition, stateTaskDefinition),
+			}
+		}
+		return *svc.ServiceArn, true, nil
 	}
 	return "", false, nil // Service not found
 }
 
 // verifyECS_TaskDefinition checks if an ECS Task Definition exists in AWS.
+// Revisions that exist but are INACTIVE or superseded by a newer ACTIVE revision of the same
+// family are returned as STALE liveStateErrors naming the current revision.
 func (c *AWSClient) verifyECSTaskDefinition(ctx context.Context, taskDefinitionARN string) (string, bool, error) {
 	if taskDefinitionARN == "" {
 		return "", false, fmt.Errorf("task definition ARN must be provided for ECS task definition verification")
 	}
 
-	input := &ecs.DescribeTaskDefinitionInput{
-		TaskDefinition: aws.String(taskDefinitionARN),
-	}
-	resp, err := c.ECSClient.DescribeTaskDefinition(ctx, input)
+	resp, err := c.describeECSTaskDefinition(ctx, taskDefinitionARN)
 	if err != nil {
 		if strings.Contains(err.Error(), "ClientException") && strings.Contains(err.Error(), "No task definition found") {
 			return "", false, nil // Task definition not found
@@ -1086,10 +1333,61 @@ func (c *AWSClient) verifyECSTaskDefinition(ctx context.Context, taskDefinitionA
 		return "", false, fmt.Errorf("failed to describe ECS Task Definition '%s': %w", taskDefinitionARN, err)
 	}
 
-	if resp.TaskDefinition != nil && resp.TaskDefinition.TaskDefinitionArn != nil {
-		return *resp.TaskDefinition.TaskDefinitionArn, true, nil
+	if resp.TaskDefinition == nil || resp.TaskDefinition.TaskDefinitionArn == nil {
+		return "", false, nil // Task definition not found or incomplete response
+	}
+	liveARN := *resp.TaskDefinition.TaskDefinitionArn
+	family := aws.ToString(resp.TaskDefinition.Family)
+	if family == "" {
+		return liveARN, true, nil
+	}
+
+	// Describing by family alone resolves to the latest ACTIVE revision.
+	latest, err := c.describeECSTaskDefinition(ctx, family)
+	if err != nil {
+		if strings.Contains(err.Error(), "ClientException") {
+			if resp.TaskDefinition.Status != ecstypes.TaskDefinitionStatusActive {
+				return "", false, &liveStateError{
+					Category: "STALE",
+					LiveID:   liveARN,
+					Message:  fmt.Sprintf("task definition revision %d is %s and family '%s' has no ACTIVE revision.", resp.TaskDefinition.Revision, resp.TaskDefinition.Status, family),
+				}
+			}
+			return liveARN, true, nil
+		}
+		return "", false, fmt.Errorf("failed to describe latest revision of ECS Task Definition family '%s': %w", family, err)
+	}
+	if latest.TaskDefinition == nil || latest.TaskDefinition.TaskDefinitionArn == nil {
+		return liveARN, true, nil
+	}
+	latestARN := *latest.TaskDefinition.TaskDefinitionArn
+
+	if latestARN != liveARN {
+		reason := "superseded"
+		if resp.TaskDefinition.Status != ecstypes.TaskDefinitionStatusActive {
+			reason = strings.ToLower(string(resp.TaskDefinition.Status))
+		}
+		// The address is already managed, so importing the latest revision would be refused; moving the
+		// address to it takes a state rm first, which is left to the operator rather than run by fix.
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   latestARN,
+			Message: fmt.Sprintf("task definition revision %d is %s; the latest ACTIVE revision of family '%s' is %d. Apply the configuration to register a new revision, or run `terraform state rm` followed by `terraform import` of '%s' to track the latest one.",
+				resp.TaskDefinition.Revision, reason, family, latest.TaskDefinition.Revision, latestARN),
+		}
+	}
+	return liveARN, true, nil
+}
+
+// sameTaskDefinition compares task definition references that may be full ARNs or family:revision strings.
+func sameTaskDefinition(a, b string) bool {
+	trim := func(ref string) string {
+		if idx := strings.LastIndex(ref, "task-definition/"); idx != -1 {
+			return ref[idx+len("task-definition/"):]
+		}
+		return ref
 	}
-	return "", false, nil // Task definition not found or incomplete response
+	return trim(a) == trim(b)
 }
 
 // verifyLBLIstenerCertificate checks if an ELBv2 Listener Certificate exists in AWS.
@@ -1098,10 +1396,11 @@ func (c *AWSClient) verifyLBListenerCertificate(ctx context.Context, listenerARN
 		return "", false, fmt.Errorf("both listener ARN and certificate ARN must be provided for LB listener certificate verification")
 	}
 
-	input := &elasticloadbalancingv2.DescribeListenerCertificatesInput{
-		ListenerArn: aws.String(listenerARN),
-	}
-	resp, err := c.ELBV2Client.DescribeListenerCertificates(ctx, input)
+	resp, err := cachedCall(ctx, c, cacheKey("elbv2", "DescribeListenerCertificates", listenerARN), func() (*elasticloadbalancingv2.DescribeListenerCertificatesOutput, error) {
+		return c.ELBV2Client.DescribeListenerCertificates(ctx, &elasticloadbalancingv2.DescribeListenerCertificatesInput{
+			ListenerArn: aws.String(listenerARN),
+		})
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "ListenerNotFound") {
 			return "", false, fmt.Errorf("ELB listener '%s' not found for certificate verification: %w", listenerARN, err)
@@ -1119,3 +1418,3149 @@ func (c *AWSClient) verifyLBListenerCertificate(ctx context.Context, listenerARN
 
 	return "", false, nil // Listener certificate not found
 }
+
+// rdsUnhealthyStatuses are the RDS instance and cluster statuses in which Terraform cannot modify the
+// resource until it recovers, reported as WARNING instead of OK.
+var rdsUnhealthyStatuses = map[string]bool{
+	"failed":                              true,
+	"inaccessible-encryption-credentials": true,
+	"inaccessible-encryption-credentials-recoverable": true,
+	"incompatible-network":                            true,
+	"incompatible-option-group":                       true,
+	"incompatible-parameters":                         true,
+	"incompatible-restore":                            true,
+	"insufficient-capacity":                           true,
+	"restore-error":                                   true,
+	"storage-full":                                    true,
+}
+
+// verifyDBInstance checks if an RDS DB instance exists in AWS. Since v5 of the AWS provider the state ID
+// of aws_db_instance is the instance's resource ID (db-...) rather than its identifier, so the live ID
+// returned is whichever of the two the state uses. An instance recreated under the same identifier has
+// a new resource ID and is reported as POTENTIAL_IMPORT.
+func (c *AWSClient) verifyDBInstance(ctx context.Context, identifier, stateID, clusterIdentifier string, deletionProtection *bool) (string, bool, error) {
+	resp, err := c.RDSClient.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(identifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBInstanceNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe RDS DB instance '%s': %w", identifier, err)
+	}
+
+	for _, instance := range resp.DBInstances {
+		if !strings.EqualFold(aws.ToString(instance.DBInstanceIdentifier), identifier) {
+			continue
+		}
+		liveID := aws.ToString(instance.DBInstanceIdentifier)
+		resourceID := aws.ToString(instance.DbiResourceId)
+		if strings.HasPrefix(stateID, "db-") && !strings.EqualFold(stateID, liveID) {
+			if stateID != resourceID {
+				return "", false, &liveStateError{
+					Category:    "POTENTIAL_IMPORT",
+					LiveID:      liveID,
+					Message:     fmt.Sprintf("DB instance '%s' was recreated: its resource ID is '%s' but state references '%s'.", identifier, resourceID, stateID),
+					Remediation: "import",
+				}
+			}
+			liveID = resourceID
+		}
+		liveCluster := aws.ToString(instance.DBClusterIdentifier)
+		if clusterIdentifier != "" && !strings.EqualFold(liveCluster, clusterIdentifier) {
+			return "", false, &liveStateError{
+				Category: "STALE",
+				LiveID:   liveID,
+				Message:  fmt.Sprintf("DB instance '%s' belongs to cluster '%s' but state references '%s'.", identifier, liveCluster, clusterIdentifier),
+			}
+		}
+		if err := rdsStatusError("DB instance", identifier, liveID, aws.ToString(instance.DBInstanceStatus), deletionProtection, aws.ToBool(instance.DeletionProtection)); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // DB instance not found
+}
+
+// verifyRDSCluster checks if an RDS (Aurora or Multi-AZ) DB cluster exists in AWS.
+func (c *AWSClient) verifyRDSCluster(ctx context.Context, clusterIdentifier string, deletionProtection *bool) (string, bool, error) {
+	resp, err := c.RDSClient.DescribeDBClusters(ctx, &rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(clusterIdentifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBClusterNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe RDS DB cluster '%s': %w", clusterIdentifier, err)
+	}
+
+	for _, cluster := range resp.DBClusters {
+		if !strings.EqualFold(aws.ToString(cluster.DBClusterIdentifier), clusterIdentifier) {
+			continue
+		}
+		liveID := aws.ToString(cluster.DBClusterIdentifier)
+		if err := rdsStatusError("DB cluster", clusterIdentifier, liveID, aws.ToString(cluster.Status), deletionProtection, aws.ToBool(cluster.DeletionProtection)); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // DB cluster not found
+}
+
+// rdsStatusError returns a liveStateError for an RDS instance or cluster that exists but is being
+// deleted (DANGEROUS, as it will be gone by the next apply), is in a status Terraform cannot modify it
+// in (WARNING), or whose deletion protection differs from the state (STALE), and nil otherwise.
+// stateDeletionProtection is nil for resources whose state does not track deletion protection.
+func rdsStatusError(kind, identifier, liveID, status string, stateDeletionProtection *bool, liveDeletionProtection bool) error {
+	switch {
+	case status == "deleting":
+		return beingDeletedError(kind, identifier, liveID)
+	case rdsUnhealthyStatuses[status]:
+		return &liveStateError{
+			Category: "WARNING",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("%s '%s' exists but is %s; Terraform cannot modify it until it recovers.", kind, identifier, status),
+		}
+	case stateDeletionProtection != nil && *stateDeletionProtection != liveDeletionProtection:
+		return &liveStateError{
+			Category: "STALE",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("%s '%s' has deletion protection %s in AWS but %s in state.", kind, identifier, onOff(liveDeletionProtection), onOff(*stateDeletionProtection)),
+		}
+	}
+	return nil
+}
+
+// beingDeletedError reports a resource that still exists but is being deleted as DANGEROUS, since it
+// will be gone by the next apply, suggesting its removal from the state.
+func beingDeletedError(kind, identifier, liveID string) error {
+	return &liveStateError{
+		Category:    "DANGEROUS",
+		LiveID:      liveID,
+		Message:     fmt.Sprintf("%s '%s' is being deleted.", kind, identifier),
+		Remediation: "rm",
+	}
+}
+
+// verifyDynamoDBTable checks if a DynamoDB table exists in AWS. tableName may also be the table's ARN.
+// Tables being deleted are DANGEROUS; tables whose KMS key is inaccessible or that were archived
+// because of it are reported as WARNING.
+func (c *AWSClient) verifyDynamoDBTable(ctx context.Context, tableName string) (string, bool, error) {
+	resp, err := c.describeDynamoDBTable(ctx, tableName)
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe DynamoDB table '%s': %w", tableName, err)
+	}
+	if resp.Table == nil {
+		return "", false, nil
+	}
+	liveID := aws.ToString(resp.Table.TableName)
+	switch resp.Table.TableStatus {
+	case dynamodbtypes.TableStatusDeleting:
+		return "", false, beingDeletedError("DynamoDB table", liveID, liveID)
+	case dynamodbtypes.TableStatusArchiving, dynamodbtypes.TableStatusArchived, dynamodbtypes.TableStatusInaccessibleEncryptionCredentials:
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("DynamoDB table '%s' exists but is %s; its KMS key is inaccessible.", liveID, resp.Table.TableStatus),
+		}
+	}
+	return liveID, true, nil
+}
+
+// verifyDynamoDBTableItem checks if the item of an aws_dynamodb_table_item exists in its table. item is
+// the item's attributes in DynamoDB JSON as the state stores them; only its key attributes are looked up.
+// The item of a table that no longer exists does not exist either.
+func (c *AWSClient) verifyDynamoDBTableItem(ctx context.Context, tableName, hashKey, rangeKey, item string) (string, bool, error) {
+	if _, err := c.describeDynamoDBTable(ctx, tableName); err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe DynamoDB table '%s' of item: %w", tableName, err)
+	}
+
+	var attributes map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(item), &attributes); err != nil {
+		return "", false, fmt.Errorf("failed to parse the item of DynamoDB table '%s': %w", tableName, err)
+	}
+	key := make(map[string]dynamodbtypes.AttributeValue)
+	for _, name := range []string{hashKey, rangeKey} {
+		if name == "" {
+			continue
+		}
+		value, err := dynamoDBKeyValue(attributes[name])
+		if err != nil {
+			return "", false, fmt.Errorf("invalid key attribute '%s' of item in DynamoDB table '%s': %w", name, tableName, err)
+		}
+		key[name] = value
+	}
+
+	resp, err := c.DynamoDBClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(tableName),
+		Key:                  key,
+		ProjectionExpression: aws.String("#k"),
+		ExpressionAttributeNames: map[string]string{
+			"#k": hashKey,
+		},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil // The table was deleted since it was described
+		}
+		return "", false, fmt.Errorf("failed to get item from DynamoDB table '%s': %w", tableName, err)
+	}
+	if len(resp.Item) == 0 {
+		return "", false, nil // Item not found
+	}
+	return tableName, true, nil
+}
+
+// dynamoDBKeyValue converts a key attribute in DynamoDB JSON, e.g. {"S": "id-1"}, to an AttributeValue.
+// Key attributes can only be strings, numbers or binary.
+func dynamoDBKeyValue(value map[string]interface{}) (dynamodbtypes.AttributeValue, error) {
+	if s, ok := value["S"].(string); ok {
+		return &dynamodbtypes.AttributeValueMemberS{Value: s}, nil
+	}
+	if n, ok := value["N"].(string); ok {
+		return &dynamodbtypes.AttributeValueMemberN{Value: n}, nil
+	}
+	if b, ok := value["B"].(string); ok {
+		decoded, err := base64.StdEncoding.DecodeString(b)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamodbtypes.AttributeValueMemberB{Value: decoded}, nil
+	}
+	return nil, fmt.Errorf("missing from the item or not a string, number or binary")
+}
+
+// verifyDynamoDBGlobalTable checks if a DynamoDB global table (version 2017.11.29) exists in AWS.
+func (c *AWSClient) verifyDynamoDBGlobalTable(ctx context.Context, tableName string) (string, bool, error) {
+	resp, err := c.DynamoDBClient.DescribeGlobalTable(ctx, &dynamodb.DescribeGlobalTableInput{
+		GlobalTableName: aws.String(tableName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "GlobalTableNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe DynamoDB global table '%s': %w", tableName, err)
+	}
+	if resp.GlobalTableDescription == nil {
+		return "", false, nil
+	}
+	liveID := aws.ToString(resp.GlobalTableDescription.GlobalTableName)
+	if resp.GlobalTableDescription.GlobalTableStatus == dynamodbtypes.GlobalTableStatusDeleting {
+		return "", false, beingDeletedError("DynamoDB global table", liveID, liveID)
+	}
+	return liveID, true, nil
+}
+
+// verifySQSQueue checks if an SQS queue exists in AWS. queueID is the state ID, normally the queue URL
+// but the queue ARN in some imported states; the live ID is returned in the same form so either matches.
+func (c *AWSClient) verifySQSQueue(ctx context.Context, queueID, queueName string) (string, bool, error) {
+	name, owner := sqsQueueNameAndOwner(queueID)
+	name = cmp.Or(queueName, name)
+	if name == "" {
+		return "", false, fmt.Errorf("could not determine the SQS queue name from '%s'", queueID)
+	}
+	queueURL, exists, err := c.sqsQueueURL(ctx, name, owner)
+	if err != nil || !exists {
+		return "", false, err
+	}
+	if !strings.HasPrefix(queueID, "arn:") {
+		return sameSQSQueueURL(queueID, queueURL), true, nil
+	}
+
+	resp, err := c.SQSClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameQueueArn},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get the ARN of SQS queue '%s': %w", queueURL, err)
+	}
+	return resp.Attributes[string(sqstypes.QueueAttributeNameQueueArn)], true, nil
+}
+
+// verifySQSQueuePolicy checks if the SQS queue at queueURL exists in AWS and has an access policy.
+func (c *AWSClient) verifySQSQueuePolicy(ctx context.Context, queueURL string) (string, bool, error) {
+	name, owner := sqsQueueNameAndOwner(queueURL)
+	liveURL, exists, err := c.sqsQueueURL(ctx, name, owner)
+	if err != nil || !exists {
+		return "", false, err
+	}
+	resp, err := c.SQSClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(liveURL),
+		AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNamePolicy},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get the policy of SQS queue '%s': %w", liveURL, err)
+	}
+	if resp.Attributes[string(sqstypes.QueueAttributeNamePolicy)] == "" {
+		return "", false, nil // Queue has no policy
+	}
+	return sameSQSQueueURL(queueURL, liveURL), true, nil
+}
+
+// sqsQueueURL resolves the URL of the queue named name owned by account, or by the caller's account when
+// owner is empty.
+func (c *AWSClient) sqsQueueURL(ctx context.Context, name, owner string) (string, bool, error) {
+	input := &sqs.GetQueueUrlInput{QueueName: aws.String(name)}
+	if owner != "" {
+		input.QueueOwnerAWSAccountId = aws.String(owner)
+	}
+	resp, err := cachedCall(ctx, c, cacheKey("sqs", "GetQueueUrl", owner, name), func() (*sqs.GetQueueUrlOutput, error) {
+		return c.SQSClient.GetQueueUrl(ctx, input)
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "QueueDoesNotExist") || strings.Contains(err.Error(), "NonExistentQueue") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get the URL of SQS queue '%s': %w", name, err)
+	}
+	return aws.ToString(resp.QueueUrl), true, nil
+}
+
+// sqsQueueNameAndOwner returns the queue name and owning account of an SQS queue URL
+// (https://sqs.<region>.amazonaws.com/<account>/<name>) or ARN (arn:aws:sqs:<region>:<account>:<name>).
+func sqsQueueNameAndOwner(queueID string) (string, string) {
+	if strings.HasPrefix(queueID, "arn:") {
+		parts := strings.Split(queueID, ":")
+		if len(parts) == 6 {
+			return parts[5], parts[4]
+		}
+		return "", ""
+	}
+	parts := strings.Split(strings.TrimSuffix(queueID, "/"), "/")
+	if len(parts) < 2 {
+		return queueID, ""
+	}
+	return parts[len(parts)-1], parts[len(parts)-2]
+}
+
+// sameSQSQueueURL returns stateURL when it names the same queue as liveURL, which differs from it only
+// in the host for queues created before SQS moved to regional endpoints (queue.amazonaws.com,
+// <region>.queue.amazonaws.com), and liveURL otherwise.
+func sameSQSQueueURL(stateURL, liveURL string) string {
+	stateName, stateOwner := sqsQueueNameAndOwner(stateURL)
+	liveName, liveOwner := sqsQueueNameAndOwner(liveURL)
+	if stateName == liveName && stateOwner == liveOwner {
+		return stateURL
+	}
+	return liveURL
+}
+
+// verifySNSTopic checks if an SNS topic exists in AWS.
+func (c *AWSClient) verifySNSTopic(ctx context.Context, topicARN string) (string, bool, error) {
+	_, err := c.getSNSTopicAttributes(ctx, topicARN)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get attributes of SNS topic '%s': %w", topicARN, err)
+	}
+	return topicARN, true, nil
+}
+
+// verifySNSTopicPolicy checks if the SNS topic of an aws_sns_topic_policy exists in AWS and has a policy.
+func (c *AWSClient) verifySNSTopicPolicy(ctx context.Context, topicARN string) (string, bool, error) {
+	resp, err := c.getSNSTopicAttributes(ctx, topicARN)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get attributes of SNS topic '%s': %w", topicARN, err)
+	}
+	if resp.Attributes["Policy"] == "" {
+		return "", false, nil // Topic has no policy
+	}
+	return topicARN, true, nil
+}
+
+// verifySNSTopicSubscription checks if an SNS subscription exists in AWS. Subscriptions whose endpoint
+// has not confirmed them yet are reported as WARNING: they exist but deliver nothing. The state ID of
+// a subscription created before it was confirmed is "pending confirmation" rather than an ARN, so it is
+// looked up among the topic's subscriptions by protocol and endpoint instead.
+func (c *AWSClient) verifySNSTopicSubscription(ctx context.Context, subscriptionARN, topicARN, protocol, endpoint string) (string, bool, error) {
+	if !strings.HasPrefix(subscriptionARN, "arn:") {
+		return c.findSNSTopicSubscription(ctx, topicARN, protocol, endpoint)
+	}
+
+	resp, err := c.SNSClient.GetSubscriptionAttributes(ctx, &sns.GetSubscriptionAttributesInput{
+		SubscriptionArn: aws.String(subscriptionARN),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get attributes of SNS subscription '%s': %w", subscriptionARN, err)
+	}
+	if resp.Attributes["PendingConfirmation"] == "true" {
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   subscriptionARN,
+			Message:  fmt.Sprintf("SNS subscription of %s endpoint '%s' is pending confirmation.", protocol, endpoint),
+		}
+	}
+	return subscriptionARN, true, nil
+}
+
+// findSNSTopicSubscription looks up the subscription of topicARN with protocol and endpoint.
+func (c *AWSClient) findSNSTopicSubscription(ctx context.Context, topicARN, protocol, endpoint string) (string, bool, error) {
+	if topicARN == "" || protocol == "" || endpoint == "" {
+		return "", false, fmt.Errorf("topic ARN, protocol and endpoint are required to find an unconfirmed SNS subscription")
+	}
+	paginator := sns.NewListSubscriptionsByTopicPaginator(c.SNSClient, &sns.ListSubscriptionsByTopicInput{
+		TopicArn: aws.String(topicARN),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			if strings.Contains(err.Error(), "NotFound") {
+				return "", false, nil // Topic not found, so neither is the subscription
+			}
+			return "", false, fmt.Errorf("failed to list subscriptions of SNS topic '%s': %w", topicARN, err)
+		}
+		for _, subscription := range page.Subscriptions {
+			if aws.ToString(subscription.Protocol) != protocol || aws.ToString(subscription.Endpoint) != endpoint {
+				continue
+			}
+			liveID := aws.ToString(subscription.SubscriptionArn)
+			if !strings.HasPrefix(liveID, "arn:") {
+				return "", false, &liveStateError{
+					Category: "WARNING",
+					LiveID:   liveID,
+					Message:  fmt.Sprintf("SNS subscription of %s endpoint '%s' is pending confirmation.", protocol, endpoint),
+				}
+			}
+			return liveID, true, nil // Confirmed since it was created; its ARN is to be imported
+		}
+	}
+	return "", false, nil // Subscription not found
+}
+
+// verifyKMSKey checks if a KMS key exists in AWS. Keys scheduled for deletion are reported as
+// PENDING_DELETION: they still exist and can be recovered until their deletion date, but nothing can
+// use them. A key disabled outside Terraform while the state has it enabled is reported as STALE.
+func (c *AWSClient) verifyKMSKey(ctx context.Context, keyID string, stateEnabled bool) (string, bool, error) {
+	resp, err := c.describeKMSKey(ctx, keyID)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe KMS key '%s': %w", keyID, err)
+	}
+	if err := kmsKeyStateError(fmt.Sprintf("KMS key '%s'", keyID), aws.ToString(resp.KeyMetadata.KeyId), resp.KeyMetadata); err != nil {
+		return "", false, err
+	}
+	liveID := aws.ToString(resp.KeyMetadata.KeyId)
+	if stateEnabled && resp.KeyMetadata.KeyState == kmstypes.KeyStateDisabled {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("KMS key '%s' is disabled in AWS but enabled in state.", keyID),
+		}
+	}
+	return liveID, true, nil
+}
+
+// verifyKMSAlias checks if a KMS alias exists in AWS and points at the key the state records. The
+// aliases of the target key are listed first; an alias found only by name points at another key and is
+// reported as STALE. Aliases of a key pending deletion are reported as PENDING_DELETION, as they go with it.
+func (c *AWSClient) verifyKMSAlias(ctx context.Context, aliasName, targetKeyID string) (string, bool, error) {
+	if targetKeyID != "" {
+		paginator := kms.NewListAliasesPaginator(c.KMSClient, &kms.ListAliasesInput{
+			KeyId: aws.String(targetKeyID),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				if strings.Contains(err.Error(), "NotFoundException") {
+					break // Target key not found; the alias may still point elsewhere
+				}
+				return "", false, fmt.Errorf("failed to list aliases of KMS key '%s': %w", targetKeyID, err)
+			}
+			for _, alias := range page.Aliases {
+				if aws.ToString(alias.AliasName) != aliasName {
+					continue
+				}
+				resp, err := c.describeKMSKey(ctx, targetKeyID)
+				if err != nil {
+					return "", false, fmt.Errorf("failed to describe KMS key '%s' of alias '%s': %w", targetKeyID, aliasName, err)
+				}
+				if err := kmsKeyStateError(fmt.Sprintf("KMS key '%s' of alias '%s'", targetKeyID, aliasName), aliasName, resp.KeyMetadata); err != nil {
+					return "", false, err
+				}
+				return aliasName, true, nil
+			}
+		}
+	}
+
+	resp, err := c.describeKMSKey(ctx, aliasName)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // Alias not found
+		}
+		return "", false, fmt.Errorf("failed to describe KMS alias '%s': %w", aliasName, err)
+	}
+	liveKeyID := aws.ToString(resp.KeyMetadata.KeyId)
+	if targetKeyID == "" || targetKeyID == liveKeyID || targetKeyID == aws.ToString(resp.KeyMetadata.Arn) {
+		return aliasName, true, nil
+	}
+	return "", false, &liveStateError{
+		Category: "STALE",
+		LiveID:   aliasName,
+		Message:  fmt.Sprintf("KMS alias '%s' points at key '%s' in AWS but at '%s' in state.", aliasName, liveKeyID, targetKeyID),
+	}
+}
+
+// verifyKMSKeyPolicy checks if the KMS key of an aws_kms_key_policy exists in AWS and has its default policy.
+func (c *AWSClient) verifyKMSKeyPolicy(ctx context.Context, keyID string) (string, bool, error) {
+	resp, err := c.describeKMSKey(ctx, keyID)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe KMS key '%s': %w", keyID, err)
+	}
+	if err := kmsKeyStateError(fmt.Sprintf("KMS key '%s'", keyID), aws.ToString(resp.KeyMetadata.KeyId), resp.KeyMetadata); err != nil {
+		return "", false, err
+	}
+	policy, err := c.KMSClient.GetKeyPolicy(ctx, &kms.GetKeyPolicyInput{
+		KeyId:      aws.String(keyID),
+		PolicyName: aws.String("default"),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get policy of KMS key '%s': %w", keyID, err)
+	}
+	if aws.ToString(policy.Policy) == "" {
+		return "", false, nil // Key has no policy
+	}
+	return keyID, true, nil
+}
+
+// kmsKeyStateError reports a key scheduled for deletion as PENDING_DELETION and an unavailable key,
+// whose custom key store is disconnected, as WARNING. It returns nil for usable keys.
+func kmsKeyStateError(subject, liveID string, metadata *kmstypes.KeyMetadata) error {
+	switch metadata.KeyState {
+	case kmstypes.KeyStatePendingDeletion, kmstypes.KeyStatePendingReplicaDeletion:
+		deletion := "once its replicas are deleted"
+		if metadata.DeletionDate != nil {
+			deletion = "on " + metadata.DeletionDate.UTC().Format(time.RFC3339)
+		}
+		return &liveStateError{
+			Category: "PENDING_DELETION",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("%s is scheduled for deletion %s; cancel the deletion to keep it.", subject, deletion),
+		}
+	case kmstypes.KeyStateUnavailable:
+		return &liveStateError{
+			Category: "WARNING",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("%s is unavailable; its custom key store is disconnected.", subject),
+		}
+	}
+	return nil
+}
+
+// eksUnhealthyStatuses are the EKS cluster, node group, addon and Fargate profile statuses in which the
+// resource exists but does not work or cannot be changed, reported as WARNING instead of OK.
+var eksUnhealthyStatuses = map[string]bool{
+	"FAILED":        true,
+	"CREATE_FAILED": true,
+	"DELETE_FAILED": true,
+	"UPDATE_FAILED": true,
+	"DEGRADED":      true,
+}
+
+// verifyEKSCluster checks if an EKS cluster exists in AWS.
+func (c *AWSClient) verifyEKSCluster(ctx context.Context, clusterName, stateARN string) (string, bool, error) {
+	resp, err := c.EKSClient.DescribeCluster(ctx, &eks.DescribeClusterInput{
+		Name: aws.String(clusterName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe EKS cluster '%s': %w", clusterName, err)
+	}
+	cluster := resp.Cluster
+	if err := eksStatusError("EKS cluster", clusterName, clusterName, string(cluster.Status), stateARN, aws.ToString(cluster.Arn)); err != nil {
+		return "", false, err
+	}
+	return clusterName, true, nil
+}
+
+// verifyEKSNodeGroup checks if a managed node group of an EKS cluster exists in AWS. Its ID is CLUSTER:NAME.
+func (c *AWSClient) verifyEKSNodeGroup(ctx context.Context, clusterName, nodeGroupName, stateARN string) (string, bool, error) {
+	resp, err := c.EKSClient.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: aws.String(nodeGroupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil // Node group or its cluster not found
+		}
+		return "", false, fmt.Errorf("failed to describe EKS node group '%s' of cluster '%s': %w", nodeGroupName, clusterName, err)
+	}
+	liveID := clusterName + ":" + nodeGroupName
+	nodeGroup := resp.Nodegroup
+	if err := eksStatusError("EKS node group", liveID, liveID, string(nodeGroup.Status), stateARN, aws.ToString(nodeGroup.NodegroupArn)); err != nil {
+		return "", false, err
+	}
+	return liveID, true, nil
+}
+
+// verifyEKSAddon checks if an addon of an EKS cluster exists in AWS. Its ID is CLUSTER:ADDON.
+func (c *AWSClient) verifyEKSAddon(ctx context.Context, clusterName, addonName, stateARN string) (string, bool, error) {
+	resp, err := c.EKSClient.DescribeAddon(ctx, &eks.DescribeAddonInput{
+		ClusterName: aws.String(clusterName),
+		AddonName:   aws.String(addonName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil // Addon or its cluster not found
+		}
+		return "", false, fmt.Errorf("failed to describe EKS addon '%s' of cluster '%s': %w", addonName, clusterName, err)
+	}
+	liveID := clusterName + ":" + addonName
+	addon := resp.Addon
+	if err := eksStatusError("EKS addon", liveID, liveID, string(addon.Status), stateARN, aws.ToString(addon.AddonArn)); err != nil {
+		return "", false, err
+	}
+	return liveID, true, nil
+}
+
+// verifyEKSFargateProfile checks if a Fargate profile of an EKS cluster exists in AWS. Its ID is CLUSTER:NAME.
+func (c *AWSClient) verifyEKSFargateProfile(ctx context.Context, clusterName, profileName, stateARN string) (string, bool, error) {
+	resp, err := c.EKSClient.DescribeFargateProfile(ctx, &eks.DescribeFargateProfileInput{
+		ClusterName:        aws.String(clusterName),
+		FargateProfileName: aws.String(profileName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil // Fargate profile or its cluster not found
+		}
+		return "", false, fmt.Errorf("failed to describe EKS Fargate profile '%s' of cluster '%s': %w", profileName, clusterName, err)
+	}
+	liveID := clusterName + ":" + profileName
+	profile := resp.FargateProfile
+	if err := eksStatusError("EKS Fargate profile", liveID, liveID, string(profile.Status), stateARN, aws.ToString(profile.FargateProfileArn)); err != nil {
+		return "", false, err
+	}
+	return liveID, true, nil
+}
+
+// eksStatusError returns a liveStateError for an EKS resource that is being deleted (DANGEROUS), is in one
+// of eksUnhealthyStatuses (WARNING), or was recreated outside Terraform under the same name, so its ARN
+// differs from the state's (STALE), and nil otherwise. Node group, addon and Fargate profile ARNs end in
+// an ID generated at creation, which is how a recreated one is told apart.
+func eksStatusError(kind, identifier, liveID, status, stateARN, liveARN string) error {
+	switch {
+	case status == "DELETING":
+		return beingDeletedError(kind, identifier, liveID)
+	case eksUnhealthyStatuses[status]:
+		return &liveStateError{
+			Category: "WARNING",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("%s '%s' exists but is %s.", kind, identifier, status),
+		}
+	case stateARN != "" && liveARN != "" && stateARN != liveARN:
+		return &liveStateError{
+			Category: "STALE",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("%s '%s' was recreated outside Terraform: its ARN is '%s' but state references '%s'.", kind, identifier, liveARN, stateARN),
+		}
+	}
+	return nil
+}
+
+// elastiCacheUnhealthyStatuses are the ElastiCache cluster and replication group statuses in which the
+// resource exists but does not work, reported as WARNING instead of OK.
+var elastiCacheUnhealthyStatuses = map[string]bool{
+	"create-failed":        true,
+	"incompatible-network": true,
+	"restore-failed":       true,
+}
+
+// verifyElastiCacheCluster checks if an ElastiCache cluster exists in AWS. A cluster that belongs to
+// another replication group than the state's is reported as STALE.
+func (c *AWSClient) verifyElastiCacheCluster(ctx context.Context, clusterID, replicationGroupID string) (string, bool, error) {
+	resp, err := c.ElastiCacheClient.DescribeCacheClusters(ctx, &elasticache.DescribeCacheClustersInput{
+		CacheClusterId: aws.String(clusterID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "CacheClusterNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe ElastiCache cluster '%s': %w", clusterID, err)
+	}
+
+	for _, cluster := range resp.CacheClusters {
+		if !strings.EqualFold(aws.ToString(cluster.CacheClusterId), clusterID) {
+			continue
+		}
+		liveID := aws.ToString(cluster.CacheClusterId)
+		liveGroup := aws.ToString(cluster.ReplicationGroupId)
+		if replicationGroupID != "" && !strings.EqualFold(liveGroup, replicationGroupID) {
+			return "", false, &liveStateError{
+				Category: "STALE",
+				LiveID:   liveID,
+				Message:  fmt.Sprintf("ElastiCache cluster '%s' belongs to replication group '%s' but state references '%s'.", clusterID, liveGroup, replicationGroupID),
+			}
+		}
+		if err := elastiCacheStatusError("ElastiCache cluster", clusterID, liveID, aws.ToString(cluster.CacheClusterStatus)); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // Cluster not found
+}
+
+// verifyElastiCacheReplicationGroup checks if an ElastiCache replication group exists in AWS.
+func (c *AWSClient) verifyElastiCacheReplicationGroup(ctx context.Context, replicationGroupID string) (string, bool, error) {
+	resp, err := c.ElastiCacheClient.DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
+		ReplicationGroupId: aws.String(replicationGroupID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ReplicationGroupNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe ElastiCache replication group '%s': %w", replicationGroupID, err)
+	}
+
+	for _, group := range resp.ReplicationGroups {
+		if !strings.EqualFold(aws.ToString(group.ReplicationGroupId), replicationGroupID) {
+			continue
+		}
+		liveID := aws.ToString(group.ReplicationGroupId)
+		if err := elastiCacheStatusError("ElastiCache replication group", replicationGroupID, liveID, aws.ToString(group.Status)); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // Replication group not found
+}
+
+// verifyElastiCacheSubnetGroup checks if an ElastiCache subnet group exists in AWS.
+func (c *AWSClient) verifyElastiCacheSubnetGroup(ctx context.Context, subnetGroupName string) (string, bool, error) {
+	resp, err := c.ElastiCacheClient.DescribeCacheSubnetGroups(ctx, &elasticache.DescribeCacheSubnetGroupsInput{
+		CacheSubnetGroupName: aws.String(subnetGroupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "CacheSubnetGroupNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe ElastiCache subnet group '%s': %w", subnetGroupName, err)
+	}
+
+	for _, group := range resp.CacheSubnetGroups {
+		if strings.EqualFold(aws.ToString(group.CacheSubnetGroupName), subnetGroupName) {
+			return aws.ToString(group.CacheSubnetGroupName), true, nil
+		}
+	}
+	return "", false, nil // Subnet group not found
+}
+
+// verifyElastiCacheParameterGroup checks if an ElastiCache parameter group exists in AWS.
+func (c *AWSClient) verifyElastiCacheParameterGroup(ctx context.Context, parameterGroupName string) (string, bool, error) {
+	resp, err := c.ElastiCacheClient.DescribeCacheParameterGroups(ctx, &elasticache.DescribeCacheParameterGroupsInput{
+		CacheParameterGroupName: aws.String(parameterGroupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "CacheParameterGroupNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe ElastiCache parameter group '%s': %w", parameterGroupName, err)
+	}
+
+	for _, group := range resp.CacheParameterGroups {
+		if strings.EqualFold(aws.ToString(group.CacheParameterGroupName), parameterGroupName) {
+			return aws.ToString(group.CacheParameterGroupName), true, nil
+		}
+	}
+	return "", false, nil // Parameter group not found
+}
+
+// elastiCacheStatusError returns a liveStateError for an ElastiCache cluster or replication group that is
+// being deleted (DANGEROUS) or in one of elastiCacheUnhealthyStatuses (WARNING), and nil otherwise.
+func elastiCacheStatusError(kind, identifier, liveID, status string) error {
+	switch {
+	case status == "deleting":
+		return beingDeletedError(kind, identifier, liveID)
+	case elastiCacheUnhealthyStatuses[status]:
+		return &liveStateError{
+			Category: "WARNING",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("%s '%s' exists but is %s.", kind, identifier, status),
+		}
+	}
+	return nil
+}
+
+// verifyECRRepository checks if an ECR repository exists in AWS, in the registry registryID when the
+// state records one and in the account's default registry otherwise.
+func (c *AWSClient) verifyECRRepository(ctx context.Context, repositoryName, registryID string) (string, bool, error) {
+	input := &ecr.DescribeRepositoriesInput{
+		RepositoryNames: []string{repositoryName},
+	}
+	if registryID != "" {
+		input.RegistryId = aws.String(registryID)
+	}
+	resp, err := c.ECRClient.DescribeRepositories(ctx, input)
+	if err != nil {
+		if strings.Contains(err.Error(), "RepositoryNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe ECR repository '%s': %w", repositoryName, err)
+	}
+
+	for _, repository := range resp.Repositories {
+		if aws.ToString(repository.RepositoryName) == repositoryName {
+			return repositoryName, true, nil
+		}
+	}
+	return "", false, nil // Repository not found
+}
+
+// verifyECRLifecyclePolicy checks if an ECR repository exists in AWS and has a lifecycle policy.
+func (c *AWSClient) verifyECRLifecyclePolicy(ctx context.Context, repositoryName, registryID string) (string, bool, error) {
+	input := &ecr.GetLifecyclePolicyInput{
+		RepositoryName: aws.String(repositoryName),
+	}
+	if registryID != "" {
+		input.RegistryId = aws.String(registryID)
+	}
+	_, err := c.ECRClient.GetLifecyclePolicy(ctx, input)
+	if err != nil {
+		if strings.Contains(err.Error(), "RepositoryNotFoundException") || strings.Contains(err.Error(), "LifecyclePolicyNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get lifecycle policy of ECR repository '%s': %w", repositoryName, err)
+	}
+	return repositoryName, true, nil
+}
+
+// verifyECRRepositoryPolicy checks if an ECR repository exists in AWS and has a repository policy.
+func (c *AWSClient) verifyECRRepositoryPolicy(ctx context.Context, repositoryName, registryID string) (string, bool, error) {
+	input := &ecr.GetRepositoryPolicyInput{
+		RepositoryName: aws.String(repositoryName),
+	}
+	if registryID != "" {
+		input.RegistryId = aws.String(registryID)
+	}
+	_, err := c.ECRClient.GetRepositoryPolicy(ctx, input)
+	if err != nil {
+		if strings.Contains(err.Error(), "RepositoryNotFoundException") || strings.Contains(err.Error(), "RepositoryPolicyNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get policy of ECR repository '%s': %w", repositoryName, err)
+	}
+	return repositoryName, true, nil
+}
+
+// verifyAPIGatewayRestAPI checks if an API Gateway REST API exists in AWS.
+func (c *AWSClient) verifyAPIGatewayRestAPI(ctx context.Context, restAPIID string) (string, bool, error) {
+	_, err := c.APIGatewayClient.GetRestApi(ctx, &apigateway.GetRestApiInput{
+		RestApiId: aws.String(restAPIID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get API Gateway REST API '%s': %w", restAPIID, err)
+	}
+	return restAPIID, true, nil
+}
+
+// verifyAPIGatewayStage checks if a stage of an API Gateway REST API exists in AWS. A stage deployed
+// outside Terraform, pointing at another deployment than the state's, is reported as STALE.
+func (c *AWSClient) verifyAPIGatewayStage(ctx context.Context, restAPIID, stageName, deploymentID string) (string, bool, error) {
+	resp, err := c.APIGatewayClient.GetStage(ctx, &apigateway.GetStageInput{
+		RestApiId: aws.String(restAPIID),
+		StageName: aws.String(stageName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // Stage or its REST API not found
+		}
+		return "", false, fmt.Errorf("failed to get stage '%s' of API Gateway REST API '%s': %w", stageName, restAPIID, err)
+	}
+	liveID := restAPIID + "/" + stageName
+	if liveDeployment := aws.ToString(resp.DeploymentId); deploymentID != "" && liveDeployment != deploymentID {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("API Gateway stage '%s' points at deployment '%s' but state references '%s'.", liveID, liveDeployment, deploymentID),
+		}
+	}
+	return liveID, true, nil
+}
+
+// verifyAPIGatewayDeployment checks if a deployment of an API Gateway REST API exists in AWS.
+func (c *AWSClient) verifyAPIGatewayDeployment(ctx context.Context, restAPIID, deploymentID string) (string, bool, error) {
+	_, err := c.APIGatewayClient.GetDeployment(ctx, &apigateway.GetDeploymentInput{
+		RestApiId:    aws.String(restAPIID),
+		DeploymentId: aws.String(deploymentID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // Deployment or its REST API not found
+		}
+		return "", false, fmt.Errorf("failed to get deployment '%s' of API Gateway REST API '%s': %w", deploymentID, restAPIID, err)
+	}
+	return deploymentID, true, nil
+}
+
+// verifyAPIGatewayResource checks if a resource of an API Gateway REST API exists in AWS. A resource
+// whose ID is not found, or that is not known yet, is looked up by path, so one recreated outside
+// Terraform is reported as POTENTIAL_IMPORT.
+func (c *AWSClient) verifyAPIGatewayResource(ctx context.Context, restAPIID, resourceID, path string) (string, bool, error) {
+	if resourceID != "" {
+		_, err := c.APIGatewayClient.GetResource(ctx, &apigateway.GetResourceInput{
+			RestApiId:  aws.String(restAPIID),
+			ResourceId: aws.String(resourceID),
+		})
+		if err == nil {
+			return resourceID, true, nil
+		}
+		if !strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, fmt.Errorf("failed to get resource '%s' of API Gateway REST API '%s': %w", resourceID, restAPIID, err)
+		}
+	}
+	if path == "" {
+		return "", false, nil // Resource not found
+	}
+
+	paginator := apigateway.NewGetResourcesPaginator(c.APIGatewayClient, &apigateway.GetResourcesInput{
+		RestApiId: aws.String(restAPIID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			if strings.Contains(err.Error(), "NotFoundException") {
+				return "", false, nil // REST API not found, so neither is the resource
+			}
+			return "", false, fmt.Errorf("failed to get resources of API Gateway REST API '%s': %w", restAPIID, err)
+		}
+		for _, item := range page.Items {
+			if aws.ToString(item.Path) == path {
+				return aws.ToString(item.Id), true, nil
+			}
+		}
+	}
+	return "", false, nil // Resource not found
+}
+
+// verifyAPIGatewayMethod checks if a method of an API Gateway resource exists in AWS.
+func (c *AWSClient) verifyAPIGatewayMethod(ctx context.Context, restAPIID, resourceID, httpMethod string) (string, bool, error) {
+	_, err := c.APIGatewayClient.GetMethod(ctx, &apigateway.GetMethodInput{
+		RestApiId:  aws.String(restAPIID),
+		ResourceId: aws.String(resourceID),
+		HttpMethod: aws.String(httpMethod),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // Method, its resource or its REST API not found
+		}
+		return "", false, fmt.Errorf("failed to get method %s of API Gateway resource '%s' of REST API '%s': %w", httpMethod, resourceID, restAPIID, err)
+	}
+	return restAPIID + "/" + resourceID + "/" + httpMethod, true, nil
+}
+
+// verifyAPIGatewayIntegration checks if the integration of an API Gateway method exists in AWS.
+func (c *AWSClient) verifyAPIGatewayIntegration(ctx context.Context, restAPIID, resourceID, httpMethod string) (string, bool, error) {
+	_, err := c.APIGatewayClient.GetIntegration(ctx, &apigateway.GetIntegrationInput{
+		RestApiId:  aws.String(restAPIID),
+		ResourceId: aws.String(resourceID),
+		HttpMethod: aws.String(httpMethod),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // Integration, its method, resource or REST API not found
+		}
+		return "", false, fmt.Errorf("failed to get integration of method %s of API Gateway resource '%s' of REST API '%s': %w", httpMethod, resourceID, restAPIID, err)
+	}
+	return restAPIID + "/" + resourceID + "/" + httpMethod, true, nil
+}
+
+// verifyAPIGatewayV2API checks if an API Gateway v2 (HTTP or WebSocket) API exists in AWS.
+func (c *AWSClient) verifyAPIGatewayV2API(ctx context.Context, apiID string) (string, bool, error) {
+	_, err := c.APIGatewayV2Client.GetApi(ctx, &apigatewayv2.GetApiInput{
+		ApiId: aws.String(apiID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get API Gateway v2 API '%s': %w", apiID, err)
+	}
+	return apiID, true, nil
+}
+
+// verifyAPIGatewayV2Stage checks if a stage of an API Gateway v2 API exists in AWS.
+func (c *AWSClient) verifyAPIGatewayV2Stage(ctx context.Context, apiID, stageName string) (string, bool, error) {
+	_, err := c.APIGatewayV2Client.GetStage(ctx, &apigatewayv2.GetStageInput{
+		ApiId:     aws.String(apiID),
+		StageName: aws.String(stageName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // Stage or its API not found
+		}
+		return "", false, fmt.Errorf("failed to get stage '%s' of API Gateway v2 API '%s': %w", stageName, apiID, err)
+	}
+	return stageName, true, nil
+}
+
+// verifyAPIGatewayV2Route checks if a route of an API Gateway v2 API exists in AWS. A route whose ID is
+// not found, or that is not known yet, is looked up by route key, so one recreated outside Terraform is
+// reported as POTENTIAL_IMPORT.
+func (c *AWSClient) verifyAPIGatewayV2Route(ctx context.Context, apiID, routeID, routeKey string) (string, bool, error) {
+	if routeID != "" {
+		_, err := c.APIGatewayV2Client.GetRoute(ctx, &apigatewayv2.GetRouteInput{
+			ApiId:   aws.String(apiID),
+			RouteId: aws.String(routeID),
+		})
+		if err == nil {
+			return routeID, true, nil
+		}
+		if !strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, fmt.Errorf("failed to get route '%s' of API Gateway v2 API '%s': %w", routeID, apiID, err)
+		}
+	}
+	if routeKey == "" {
+		return "", false, nil // Route not found
+	}
+
+	input := &apigatewayv2.GetRoutesInput{ApiId: aws.String(apiID)}
+	for {
+		page, err := c.APIGatewayV2Client.GetRoutes(ctx, input)
+		if err != nil {
+			if strings.Contains(err.Error(), "NotFoundException") {
+				return "", false, nil // API not found, so neither is the route
+			}
+			return "", false, fmt.Errorf("failed to get routes of API Gateway v2 API '%s': %w", apiID, err)
+		}
+		for _, route := range page.Items {
+			if aws.ToString(route.RouteKey) == routeKey {
+				return aws.ToString(route.RouteId), true, nil
+			}
+		}
+		if aws.ToString(page.NextToken) == "" {
+			return "", false, nil // Route not found
+		}
+		input.NextToken = page.NextToken
+	}
+}
+
+// verifyAPIGatewayV2Integration checks if an integration of an API Gateway v2 API exists in AWS.
+func (c *AWSClient) verifyAPIGatewayV2Integration(ctx context.Context, apiID, integrationID string) (string, bool, error) {
+	_, err := c.APIGatewayV2Client.GetIntegration(ctx, &apigatewayv2.GetIntegrationInput{
+		ApiId:         aws.String(apiID),
+		IntegrationId: aws.String(integrationID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // Integration or its API not found
+		}
+		return "", false, fmt.Errorf("failed to get integration '%s' of API Gateway v2 API '%s': %w", integrationID, apiID, err)
+	}
+	return integrationID, true, nil
+}
+
+// verifyAPIGatewayV2DomainName checks if an API Gateway v2 custom domain name exists in AWS. Domains
+// pending a certificate reimport or ownership verification are reported as WARNING, as they do not
+// serve traffic.
+func (c *AWSClient) verifyAPIGatewayV2DomainName(ctx context.Context, domainName string) (string, bool, error) {
+	resp, err := c.APIGatewayV2Client.GetDomainName(ctx, &apigatewayv2.GetDomainNameInput{
+		DomainName: aws.String(domainName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get API Gateway v2 domain name '%s': %w", domainName, err)
+	}
+	for _, configuration := range resp.DomainNameConfigurations {
+		if status := string(configuration.DomainNameStatus); strings.HasPrefix(status, "PENDING_") {
+			return "", false, &liveStateError{
+				Category: "WARNING",
+				LiveID:   domainName,
+				Message:  fmt.Sprintf("API Gateway v2 domain name '%s' is %s and does not serve traffic.", domainName, status),
+			}
+		}
+	}
+	return domainName, true, nil
+}
+
+// verifySFNStateMachine checks if a Step Functions state machine exists in AWS. State machines being
+// deleted are DANGEROUS.
+func (c *AWSClient) verifySFNStateMachine(ctx context.Context, stateMachineARN string) (string, bool, error) {
+	resp, err := c.SFNClient.DescribeStateMachine(ctx, &sfn.DescribeStateMachineInput{
+		StateMachineArn: aws.String(stateMachineARN),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "StateMachineDoesNotExist") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Step Functions state machine '%s': %w", stateMachineARN, err)
+	}
+	if resp.Status == sfntypes.StateMachineStatusDeleting {
+		return "", false, beingDeletedError("Step Functions state machine", stateMachineARN, stateMachineARN)
+	}
+	return stateMachineARN, true, nil
+}
+
+// verifySFNActivity checks if a Step Functions activity exists in AWS.
+func (c *AWSClient) verifySFNActivity(ctx context.Context, activityARN string) (string, bool, error) {
+	_, err := c.SFNClient.DescribeActivity(ctx, &sfn.DescribeActivityInput{
+		ActivityArn: aws.String(activityARN),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ActivityDoesNotExist") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Step Functions activity '%s': %w", activityARN, err)
+	}
+	return activityARN, true, nil
+}
+
+// verifyEventBus checks if an EventBridge event bus exists in AWS.
+func (c *AWSClient) verifyEventBus(ctx context.Context, busName string) (string, bool, error) {
+	_, err := c.EventBridgeClient.DescribeEventBus(ctx, &eventbridge.DescribeEventBusInput{
+		Name: aws.String(busName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe EventBridge event bus '%s': %w", busName, err)
+	}
+	return busName, true, nil
+}
+
+// verifyEventRule checks if an EventBridge rule exists on its event bus in AWS. Its ID is the rule name
+// on the default bus and BUS/RULE on others. A rule enabled or disabled outside Terraform is reported as
+// STALE.
+func (c *AWSClient) verifyEventRule(ctx context.Context, busName, ruleName, stateRuleState string) (string, bool, error) {
+	resp, err := c.EventBridgeClient.DescribeRule(ctx, &eventbridge.DescribeRuleInput{
+		Name:         aws.String(ruleName),
+		EventBusName: eventBusNameInput(busName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil // Rule or its event bus not found
+		}
+		return "", false, fmt.Errorf("failed to describe EventBridge rule '%s' on bus '%s': %w", ruleName, cmp.Or(busName, "default"), err)
+	}
+	liveID := ruleName
+	if busName != "" && busName != "default" {
+		liveID = busName + "/" + ruleName
+	}
+	if liveState := string(resp.State); stateRuleState != "" && liveState != stateRuleState {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("EventBridge rule '%s' is %s in AWS but %s in state.", liveID, liveState, stateRuleState),
+		}
+	}
+	return liveID, true, nil
+}
+
+// verifyEventTarget checks if a target of an EventBridge rule exists in AWS. Its ID is RULE-TARGET on the
+// default bus and BUS-RULE-TARGET on others.
+func (c *AWSClient) verifyEventTarget(ctx context.Context, busName, ruleName, targetID string) (string, bool, error) {
+	input := &eventbridge.ListTargetsByRuleInput{
+		Rule:         aws.String(ruleName),
+		EventBusName: eventBusNameInput(busName),
+	}
+	for {
+		page, err := c.EventBridgeClient.ListTargetsByRule(ctx, input)
+		if err != nil {
+			if strings.Contains(err.Error(), "ResourceNotFoundException") {
+				return "", false, nil // Rule or its event bus not found, so neither is the target
+			}
+			return "", false, fmt.Errorf("failed to list targets of EventBridge rule '%s' on bus '%s': %w", ruleName, cmp.Or(busName, "default"), err)
+		}
+		for _, target := range page.Targets {
+			if aws.ToString(target.Id) != targetID {
+				continue
+			}
+			if busName != "" && busName != "default" {
+				return busName + "-" + ruleName + "-" + targetID, true, nil
+			}
+			return ruleName + "-" + targetID, true, nil
+		}
+		if aws.ToString(page.NextToken) == "" {
+			return "", false, nil // Target not found
+		}
+		input.NextToken = page.NextToken
+	}
+}
+
+// eventBusNameInput returns the EventBusName of a request for a rule on busName, nil for the default bus.
+func eventBusNameInput(busName string) *string {
+	if busName == "" || busName == "default" {
+		return nil
+	}
+	return aws.String(busName)
+}
+
+// verifyKinesisStream checks if a Kinesis data stream exists in AWS. streamID is its ARN or its name.
+// Streams being deleted are DANGEROUS.
+func (c *AWSClient) verifyKinesisStream(ctx context.Context, streamID string) (string, bool, error) {
+	input := &kinesis.DescribeStreamSummaryInput{}
+	if strings.HasPrefix(streamID, "arn:") {
+		input.StreamARN = aws.String(streamID)
+	} else {
+		input.StreamName = aws.String(streamID)
+	}
+	resp, err := c.KinesisClient.DescribeStreamSummary(ctx, input)
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Kinesis stream '%s': %w", streamID, err)
+	}
+	liveID := aws.ToString(resp.StreamDescriptionSummary.StreamARN)
+	if resp.StreamDescriptionSummary.StreamStatus == kinesistypes.StreamStatusDeleting {
+		return "", false, beingDeletedError("Kinesis stream", streamID, liveID)
+	}
+	return liveID, true, nil
+}
+
+// verifyKinesisStreamConsumer checks if an enhanced fan-out consumer of a Kinesis stream exists in AWS.
+// Consumer ARNs end in their creation time, so a consumer not found by ARN is looked up by name on its
+// stream, and one registered again outside Terraform is reported as POTENTIAL_IMPORT.
+func (c *AWSClient) verifyKinesisStreamConsumer(ctx context.Context, consumerARN, streamARN, consumerName string) (string, bool, error) {
+	var lookups []*kinesis.DescribeStreamConsumerInput
+	if consumerARN != "" {
+		lookups = append(lookups, &kinesis.DescribeStreamConsumerInput{ConsumerARN: aws.String(consumerARN)})
+	}
+	if streamARN != "" && consumerName != "" {
+		lookups = append(lookups, &kinesis.DescribeStreamConsumerInput{StreamARN: aws.String(streamARN), ConsumerName: aws.String(consumerName)})
+	}
+	for _, input := range lookups {
+		resp, err := c.KinesisClient.DescribeStreamConsumer(ctx, input)
+		if err != nil {
+			if strings.Contains(err.Error(), "ResourceNotFoundException") {
+				continue
+			}
+			return "", false, fmt.Errorf("failed to describe Kinesis stream consumer '%s': %w", cmp.Or(consumerARN, consumerName), err)
+		}
+		consumer := resp.ConsumerDescription
+		liveID := aws.ToString(consumer.ConsumerARN)
+		if consumer.ConsumerStatus == kinesistypes.ConsumerStatusDeleting {
+			return "", false, beingDeletedError("Kinesis stream consumer", cmp.Or(consumerARN, consumerName), liveID)
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // Consumer not found
+}
+
+// verifyFirehoseDeliveryStream checks if a Kinesis Firehose delivery stream exists in AWS. Streams being
+// deleted are DANGEROUS; streams still being created, or whose creation or deletion failed, are reported
+// as WARNING, as they do not deliver records.
+func (c *AWSClient) verifyFirehoseDeliveryStream(ctx context.Context, streamName string) (string, bool, error) {
+	resp, err := c.FirehoseClient.DescribeDeliveryStream(ctx, &firehose.DescribeDeliveryStreamInput{
+		DeliveryStreamName: aws.String(streamName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Firehose delivery stream '%s': %w", streamName, err)
+	}
+	stream := resp.DeliveryStreamDescription
+	liveID := aws.ToString(stream.DeliveryStreamARN)
+	switch stream.DeliveryStreamStatus {
+	case firehosetypes.DeliveryStreamStatusDeleting:
+		return "", false, beingDeletedError("Firehose delivery stream", streamName, liveID)
+	case firehosetypes.DeliveryStreamStatusCreating:
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   liveID,
+			Message:  fmt.Sprintf("Firehose delivery stream '%s' is still being created and does not accept records yet.", streamName),
+		}
+	case firehosetypes.DeliveryStreamStatusCreatingFailed, firehosetypes.DeliveryStreamStatusDeletingFailed:
+		message := fmt.Sprintf("Firehose delivery stream '%s' is %s.", streamName, stream.DeliveryStreamStatus)
+		if stream.FailureDescription != nil {
+			message = fmt.Sprintf("Firehose delivery stream '%s' is %s: %s", streamName, stream.DeliveryStreamStatus, aws.ToString(stream.FailureDescription.Details))
+		}
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   liveID,
+			Message:  message,
+		}
+	}
+	return liveID, true, nil
+}
+
+// verifyEFSFileSystem checks if an EFS file system exists in AWS.
+func (c *AWSClient) verifyEFSFileSystem(ctx context.Context, fileSystemID string) (string, bool, error) {
+	resp, err := c.EFSClient.DescribeFileSystems(ctx, &efs.DescribeFileSystemsInput{
+		FileSystemId: aws.String(fileSystemID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "FileSystemNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe EFS file system '%s': %w", fileSystemID, err)
+	}
+	for _, fileSystem := range resp.FileSystems {
+		if aws.ToString(fileSystem.FileSystemId) == fileSystemID {
+			return efsLifeCycleResult("EFS file system", fileSystemID, fileSystem.LifeCycleState)
+		}
+	}
+	return "", false, nil // File system not found
+}
+
+// verifyEFSMountTarget checks if an EFS mount target exists in AWS.
+func (c *AWSClient) verifyEFSMountTarget(ctx context.Context, mountTargetID string) (string, bool, error) {
+	resp, err := c.EFSClient.DescribeMountTargets(ctx, &efs.DescribeMountTargetsInput{
+		MountTargetId: aws.String(mountTargetID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "MountTargetNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe EFS mount target '%s': %w", mountTargetID, err)
+	}
+	for _, mountTarget := range resp.MountTargets {
+		if aws.ToString(mountTarget.MountTargetId) == mountTargetID {
+			return efsLifeCycleResult("EFS mount target", mountTargetID, mountTarget.LifeCycleState)
+		}
+	}
+	return "", false, nil // Mount target not found
+}
+
+// verifyEFSAccessPoint checks if an EFS access point exists in AWS.
+func (c *AWSClient) verifyEFSAccessPoint(ctx context.Context, accessPointID string) (string, bool, error) {
+	resp, err := c.EFSClient.DescribeAccessPoints(ctx, &efs.DescribeAccessPointsInput{
+		AccessPointId: aws.String(accessPointID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "AccessPointNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe EFS access point '%s': %w", accessPointID, err)
+	}
+	for _, accessPoint := range resp.AccessPoints {
+		if aws.ToString(accessPoint.AccessPointId) == accessPointID {
+			return efsLifeCycleResult("EFS access point", accessPointID, accessPoint.LifeCycleState)
+		}
+	}
+	return "", false, nil // Access point not found
+}
+
+// efsLifeCycleResult returns the verification result of an EFS resource by its life cycle state: deleted
+// resources are not found, those being deleted are DANGEROUS and those in error are reported as WARNING.
+func efsLifeCycleResult(kind, id string, state efstypes.LifeCycleState) (string, bool, error) {
+	switch state {
+	case efstypes.LifeCycleStateDeleted:
+		return "", false, nil
+	case efstypes.LifeCycleStateDeleting:
+		return "", false, beingDeletedError(kind, id, id)
+	case efstypes.LifeCycleStateError:
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   id,
+			Message:  fmt.Sprintf("%s '%s' exists but is in the error state.", kind, id),
+		}
+	}
+	return id, true, nil
+}
+
+// verifyEBSVolume checks if an EBS volume exists in AWS. Deleted volumes are not found, volumes being
+// deleted are DANGEROUS and volumes in error are reported as WARNING.
+func (c *AWSClient) verifyEBSVolume(ctx context.Context, volumeID string) (string, bool, error) {
+	volume, err := c.describeEBSVolume(ctx, volumeID)
+	if err != nil {
+		return "", false, err
+	}
+	if volume == nil {
+		return "", false, nil // Volume not found
+	}
+	switch volume.State {
+	case ec2types.VolumeStateDeleted:
+		return "", false, nil
+	case ec2types.VolumeStateDeleting:
+		return "", false, beingDeletedError("EBS volume", volumeID, volumeID)
+	case ec2types.VolumeStateError:
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   volumeID,
+			Message:  fmt.Sprintf("EBS volume '%s' exists but is in the error state.", volumeID),
+		}
+	}
+	return volumeID, true, nil
+}
+
+// verifyVolumeAttachment checks if an EBS volume is attached to an instance as deviceName in AWS. Its
+// import ID is DEVICE:VOLUME:INSTANCE. Attachments being detached are DANGEROUS.
+func (c *AWSClient) verifyVolumeAttachment(ctx context.Context, deviceName, volumeID, instanceID string) (string, bool, error) {
+	volume, err := c.describeEBSVolume(ctx, volumeID)
+	if err != nil {
+		return "", false, err
+	}
+	if volume == nil {
+		return "", false, nil // Volume not found, so neither is the attachment
+	}
+	liveID := deviceName + ":" + volumeID + ":" + instanceID
+	for _, attachment := range volume.Attachments {
+		if aws.ToString(attachment.InstanceId) != instanceID || aws.ToString(attachment.Device) != deviceName {
+			continue
+		}
+		switch attachment.State {
+		case ec2types.VolumeAttachmentStateDetached:
+			return "", false, nil
+		case ec2types.VolumeAttachmentStateDetaching:
+			return "", false, beingDeletedError("EBS volume attachment", liveID, liveID)
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // Attachment not found
+}
+
+// verifyEBSSnapshot checks if an EBS snapshot exists in AWS. Snapshots that failed are reported as WARNING.
+func (c *AWSClient) verifyEBSSnapshot(ctx context.Context, snapshotID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{
+		SnapshotIds: []string{snapshotID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidSnapshot.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe EBS snapshot '%s': %w", snapshotID, err)
+	}
+	for _, snapshot := range resp.Snapshots {
+		if aws.ToString(snapshot.SnapshotId) != snapshotID {
+			continue
+		}
+		if snapshot.State == ec2types.SnapshotStateError {
+			return "", false, &liveStateError{
+				Category: "WARNING",
+				LiveID:   snapshotID,
+				Message:  fmt.Sprintf("EBS snapshot '%s' failed: %s", snapshotID, aws.ToString(snapshot.StateMessage)),
+			}
+		}
+		return snapshotID, true, nil
+	}
+	return "", false, nil // Snapshot not found
+}
+
+// verifyDLMLifecyclePolicy checks if a Data Lifecycle Manager policy exists in AWS. Policies in error are
+// reported as WARNING, and policies enabled or disabled outside Terraform as STALE.
+func (c *AWSClient) verifyDLMLifecyclePolicy(ctx context.Context, policyID, statePolicyState string) (string, bool, error) {
+	resp, err := c.DLMClient.GetLifecyclePolicy(ctx, &dlm.GetLifecyclePolicyInput{
+		PolicyId: aws.String(policyID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get DLM lifecycle policy '%s': %w", policyID, err)
+	}
+	switch liveState := string(resp.Policy.State); {
+	case liveState == "ERROR":
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   policyID,
+			Message:  fmt.Sprintf("DLM lifecycle policy '%s' is in error: %s", policyID, aws.ToString(resp.Policy.StatusMessage)),
+		}
+	case statePolicyState != "" && liveState != statePolicyState:
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   policyID,
+			Message:  fmt.Sprintf("DLM lifecycle policy '%s' is %s in AWS but %s in state.", policyID, liveState, statePolicyState),
+		}
+	}
+	return policyID, true, nil
+}
+
+// verifyVPCEndpoint checks if a VPC endpoint exists in AWS. Deleted endpoints are not found, endpoints
+// being deleted are DANGEROUS, and endpoints pending acceptance, rejected, failed or expired are reported
+// as WARNING, as they carry no traffic.
+func (c *AWSClient) verifyVPCEndpoint(ctx context.Context, endpointID string) (string, bool, error) {
+	endpoint, err := c.describeVPCEndpoint(ctx, endpointID)
+	if err != nil {
+		return "", false, err
+	}
+	if endpoint == nil {
+		return "", false, nil // Endpoint not found
+	}
+	switch strings.ToLower(string(endpoint.State)) {
+	case "deleted":
+		return "", false, nil
+	case "deleting":
+		return "", false, beingDeletedError("VPC endpoint", endpointID, endpointID)
+	case "pendingacceptance", "rejected", "failed", "expired":
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   endpointID,
+			Message:  fmt.Sprintf("VPC endpoint '%s' exists but is %s.", endpointID, endpoint.State),
+		}
+	}
+	return endpointID, true, nil
+}
+
+// verifyVPCEndpointService checks if a VPC endpoint service (PrivateLink) exists in AWS. Deleted services
+// are not found, services being deleted are DANGEROUS and failed ones are reported as WARNING.
+func (c *AWSClient) verifyVPCEndpointService(ctx context.Context, serviceID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeVpcEndpointServiceConfigurations(ctx, &ec2.DescribeVpcEndpointServiceConfigurationsInput{
+		ServiceIds: []string{serviceID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidVpcEndpointServiceId.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe VPC endpoint service '%s': %w", serviceID, err)
+	}
+	for _, service := range resp.ServiceConfigurations {
+		if aws.ToString(service.ServiceId) != serviceID {
+			continue
+		}
+		switch strings.ToLower(string(service.ServiceState)) {
+		case "deleted":
+			return "", false, nil
+		case "deleting":
+			return "", false, beingDeletedError("VPC endpoint service", serviceID, serviceID)
+		case "failed":
+			return "", false, &liveStateError{
+				Category: "WARNING",
+				LiveID:   serviceID,
+				Message:  fmt.Sprintf("VPC endpoint service '%s' exists but is %s.", serviceID, service.ServiceState),
+			}
+		}
+		return serviceID, true, nil
+	}
+	return "", false, nil // Endpoint service not found
+}
+
+// verifyVPCEndpointRouteTableAssociation checks if a gateway VPC endpoint is associated with a route
+// table in AWS. Its import ID is ENDPOINTID/ROUTETABLEID.
+func (c *AWSClient) verifyVPCEndpointRouteTableAssociation(ctx context.Context, endpointID, routeTableID string) (string, bool, error) {
+	endpoint, err := c.describeVPCEndpoint(ctx, endpointID)
+	if err != nil {
+		return "", false, err
+	}
+	if endpoint == nil || strings.EqualFold(string(endpoint.State), "deleted") {
+		return "", false, nil // Endpoint not found, so neither is the association
+	}
+	if slices.Contains(endpoint.RouteTableIds, routeTableID) {
+		return endpointID + "/" + routeTableID, true, nil
+	}
+	return "", false, nil // Association not found
+}
+
+// verifyVPCPeeringConnection checks if a VPC peering connection exists in AWS, for the connection itself
+// and for its accepter and options resources. Connections pending acceptance are reported as WARNING,
+// as they carry no traffic until the peer accepts them. Deleted, rejected, failed and expired connections
+// stay visible for a while but can never become active again, so they are DANGEROUS like missing ones,
+// with their status in the message.
+func (c *AWSClient) verifyVPCPeeringConnection(ctx context.Context, peeringID string) (string, bool, error) {
+	resp, err := cachedCall(ctx, c, cacheKey("ec2", "DescribeVpcPeeringConnections", peeringID), func() (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+		return c.EC2Client.DescribeVpcPeeringConnections(ctx, &ec2.DescribeVpcPeeringConnectionsInput{
+			VpcPeeringConnectionIds: []string{peeringID},
+		})
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidVpcPeeringConnectionID.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe VPC peering connection '%s': %w", peeringID, err)
+	}
+	for _, peering := range resp.VpcPeeringConnections {
+		if aws.ToString(peering.VpcPeeringConnectionId) != peeringID || peering.Status == nil {
+			continue
+		}
+		switch status := peering.Status.Code; status {
+		case ec2types.VpcPeeringConnectionStateReasonCodeDeleting:
+			return "", false, beingDeletedError("VPC peering connection", peeringID, peeringID)
+		case ec2types.VpcPeeringConnectionStateReasonCodeDeleted, ec2types.VpcPeeringConnectionStateReasonCodeRejected,
+			ec2types.VpcPeeringConnectionStateReasonCodeFailed, ec2types.VpcPeeringConnectionStateReasonCodeExpired:
+			return "", false, &liveStateError{
+				Category:    "DANGEROUS",
+				LiveID:      peeringID,
+				Message:     fmt.Sprintf("VPC peering connection '%s' is %s: %s", peeringID, status, aws.ToString(peering.Status.Message)),
+				Remediation: "rm",
+			}
+		case ec2types.VpcPeeringConnectionStateReasonCodePendingAcceptance:
+			return "", false, &liveStateError{
+				Category: "WARNING",
+				LiveID:   peeringID,
+				Message:  fmt.Sprintf("VPC peering connection '%s' is pending acceptance by the peer.", peeringID),
+			}
+		}
+		return peeringID, true, nil
+	}
+	return "", false, nil // Peering connection not found
+}
+
+// verifyTransitGateway checks if a transit gateway exists in AWS.
+func (c *AWSClient) verifyTransitGateway(ctx context.Context, transitGatewayID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeTransitGateways(ctx, &ec2.DescribeTransitGatewaysInput{
+		TransitGatewayIds: []string{transitGatewayID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidTransitGatewayID.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe transit gateway '%s': %w", transitGatewayID, err)
+	}
+	for _, transitGateway := range resp.TransitGateways {
+		if aws.ToString(transitGateway.TransitGatewayId) == transitGatewayID {
+			return transitGatewayStateResult("Transit gateway", transitGatewayID, string(transitGateway.State))
+		}
+	}
+	return "", false, nil // Transit gateway not found
+}
+
+// verifyTransitGatewayVPCAttachment checks if a transit gateway VPC attachment exists in AWS.
+func (c *AWSClient) verifyTransitGatewayVPCAttachment(ctx context.Context, attachmentID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeTransitGatewayVpcAttachments(ctx, &ec2.DescribeTransitGatewayVpcAttachmentsInput{
+		TransitGatewayAttachmentIds: []string{attachmentID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidTransitGatewayAttachmentID.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe transit gateway VPC attachment '%s': %w", attachmentID, err)
+	}
+	for _, attachment := range resp.TransitGatewayVpcAttachments {
+		if aws.ToString(attachment.TransitGatewayAttachmentId) == attachmentID {
+			return transitGatewayStateResult("Transit gateway VPC attachment", attachmentID, string(attachment.State))
+		}
+	}
+	return "", false, nil // Attachment not found
+}
+
+// verifyTransitGatewayPeeringAttachment checks if a transit gateway peering attachment exists in AWS.
+func (c *AWSClient) verifyTransitGatewayPeeringAttachment(ctx context.Context, attachmentID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeTransitGatewayPeeringAttachments(ctx, &ec2.DescribeTransitGatewayPeeringAttachmentsInput{
+		TransitGatewayAttachmentIds: []string{attachmentID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidTransitGatewayAttachmentID.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe transit gateway peering attachment '%s': %w", attachmentID, err)
+	}
+	for _, attachment := range resp.TransitGatewayPeeringAttachments {
+		if aws.ToString(attachment.TransitGatewayAttachmentId) == attachmentID {
+			return transitGatewayStateResult("Transit gateway peering attachment", attachmentID, string(attachment.State))
+		}
+	}
+	return "", false, nil // Attachment not found
+}
+
+// transitGatewayStateResult returns the verification result of a transit gateway or attachment by its
+// state: deleted ones are not found, those being deleted are DANGEROUS, failed or rejected attachments
+// are DANGEROUS with their state, as they can never become available, and attachments pending
+// acceptance by the peer account are reported as WARNING.
+func transitGatewayStateResult(kind, id, state string) (string, bool, error) {
+	switch state {
+	case "deleted":
+		return "", false, nil
+	case "deleting":
+		return "", false, beingDeletedError(kind, id, id)
+	case "failed", "failing", "rejected", "rejecting":
+		return "", false, &liveStateError{
+			Category:    "DANGEROUS",
+			LiveID:      id,
+			Message:     fmt.Sprintf("%s '%s' is %s.", kind, id, state),
+			Remediation: "rm",
+		}
+	case "pendingAcceptance":
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   id,
+			Message:  fmt.Sprintf("%s '%s' is pending acceptance by the peer account.", kind, id),
+		}
+	}
+	return id, true, nil
+}
+
+// verifyNetworkACL checks if a network ACL exists in AWS.
+func (c *AWSClient) verifyNetworkACL(ctx context.Context, networkACLID string) (string, bool, error) {
+	networkACL, err := c.describeNetworkACL(ctx, networkACLID)
+	if err != nil {
+		return "", false, err
+	}
+	if networkACL == nil {
+		return "", false, nil // Network ACL not found
+	}
+	return networkACLID, true, nil
+}
+
+// verifyNetworkACLRule checks if a network ACL has an entry with ruleNumber in the direction of egress.
+// An entry of another protocol under the same number is a different rule, reported as STALE.
+func (c *AWSClient) verifyNetworkACLRule(ctx context.Context, networkACLID string, ruleNumber int32, egress bool, protocol string) (string, bool, error) {
+	networkACL, err := c.describeNetworkACL(ctx, networkACLID)
+	if err != nil {
+		return "", false, err
+	}
+	if networkACL == nil {
+		return "", false, nil // Network ACL not found, so neither is the rule
+	}
+	direction := "ingress"
+	if egress {
+		direction = "egress"
+	}
+	for _, entry := range networkACL.Entries {
+		if aws.ToInt32(entry.RuleNumber) != ruleNumber || aws.ToBool(entry.Egress) != egress {
+			continue
+		}
+		liveProtocol := aws.ToString(entry.Protocol)
+		if protocol != "" && normalizeIPProtocol(liveProtocol) != normalizeIPProtocol(protocol) {
+			return "", false, &liveStateError{
+				Category: "STALE",
+				LiveID:   fmt.Sprintf("%s:%d:%s:%t", networkACLID, ruleNumber, liveProtocol, egress),
+				Message: fmt.Sprintf("Network ACL '%s' %s rule %d is for protocol '%s', but the state records '%s'.",
+					networkACLID, direction, ruleNumber, liveProtocol, protocol),
+			}
+		}
+		return fmt.Sprintf("%s:%d:%s:%t", networkACLID, ruleNumber, liveProtocol, egress), true, nil
+	}
+	return "", false, nil // Rule not found
+}
+
+// verifyNetworkACLAssociation checks if a network ACL association exists in AWS. An association of
+// another network ACL or subnet than the state records is reported as STALE.
+func (c *AWSClient) verifyNetworkACLAssociation(ctx context.Context, associationID, networkACLID, subnetID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeNetworkAcls(ctx, &ec2.DescribeNetworkAclsInput{
+		Filters: []ec2types.Filter{{Name: aws.String("association.association-id"), Values: []string{associationID}}},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to describe network ACL association '%s': %w", associationID, err)
+	}
+	for _, networkACL := range resp.NetworkAcls {
+		for _, association := range networkACL.Associations {
+			if aws.ToString(association.NetworkAclAssociationId) != associationID {
+				continue
+			}
+			liveACLID := aws.ToString(association.NetworkAclId)
+			liveSubnetID := aws.ToString(association.SubnetId)
+			if (networkACLID != "" && liveACLID != networkACLID) || (subnetID != "" && liveSubnetID != subnetID) {
+				return "", false, &liveStateError{
+					Category: "STALE",
+					LiveID:   associationID,
+					Message: fmt.Sprintf("Network ACL association '%s' associates network ACL '%s' with subnet '%s', but the state records network ACL '%s' and subnet '%s'.",
+						associationID, liveACLID, liveSubnetID, networkACLID, subnetID),
+				}
+			}
+			return associationID, true, nil
+		}
+	}
+	return "", false, nil // Association not found
+}
+
+// verifyFlowLog checks if a VPC flow log exists in AWS. Flow logs delivering to an S3 bucket, log group
+// or Firehose delivery stream that no longer exists, or failing to deliver, are reported as WARNING.
+func (c *AWSClient) verifyFlowLog(ctx context.Context, flowLogID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeFlowLogs(ctx, &ec2.DescribeFlowLogsInput{
+		FlowLogIds: []string{flowLogID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidFlowLogId.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe flow log '%s': %w", flowLogID, err)
+	}
+	for _, flowLog := range resp.FlowLogs {
+		if aws.ToString(flowLog.FlowLogId) != flowLogID {
+			continue
+		}
+		destination, exists, err := c.flowLogDestinationExists(ctx, flowLog)
+		if err != nil {
+			log.Printf("WARNING: Could not verify the destination of flow log '%s': %v", flowLogID, err)
+		} else if !exists {
+			return "", false, &liveStateError{
+				Category: "WARNING",
+				LiveID:   flowLogID,
+				Message:  fmt.Sprintf("Flow log '%s' exists but delivers to %s, which does not exist.", flowLogID, destination),
+			}
+		}
+		if aws.ToString(flowLog.DeliverLogsStatus) == "FAILED" {
+			return "", false, &liveStateError{
+				Category: "WARNING",
+				LiveID:   flowLogID,
+				Message:  fmt.Sprintf("Flow log '%s' exists but fails to deliver logs: %s", flowLogID, aws.ToString(flowLog.DeliverLogsErrorMessage)),
+			}
+		}
+		return flowLogID, true, nil
+	}
+	return "", false, nil // Flow log not found
+}
+
+// flowLogDestinationExists returns the destination of a flow log and whether it exists: its S3 bucket,
+// CloudWatch Logs log group or Firehose delivery stream.
+func (c *AWSClient) flowLogDestinationExists(ctx context.Context, flowLog ec2types.FlowLog) (string, bool, error) {
+	destination := aws.ToString(flowLog.LogDestination)
+	switch flowLog.LogDestinationType {
+	case ec2types.LogDestinationTypeS3:
+		destinationARN, err := arn.Parse(destination)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid S3 destination '%s': %w", destination, err)
+		}
+		bucket, _, _ := strings.Cut(destinationARN.Resource, "/")
+		_, exists, err := c.verifyS3Bucket(ctx, bucket)
+		return fmt.Sprintf("S3 bucket '%s'", bucket), exists, err
+	case ec2types.LogDestinationTypeKinesisDataFirehose:
+		destinationARN, err := arn.Parse(destination)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid Firehose destination '%s': %w", destination, err)
+		}
+		streamName := strings.TrimPrefix(destinationARN.Resource, "deliverystream/")
+		_, exists, err := c.verifyFirehoseDeliveryStream(ctx, streamName)
+		var stateErr *liveStateError
+		if errors.As(err, &stateErr) {
+			// A delivery stream being created or failing still exists.
+			return fmt.Sprintf("Firehose delivery stream '%s'", streamName), true, nil
+		}
+		return fmt.Sprintf("Firehose delivery stream '%s'", streamName), exists, err
+	default:
+		logGroupName := aws.ToString(flowLog.LogGroupName)
+		if logGroupName == "" {
+			destinationARN, err := arn.Parse(destination)
+			if err != nil {
+				return "", false, fmt.Errorf("invalid CloudWatch Logs destination '%s': %w", destination, err)
+			}
+			logGroupName = strings.TrimSuffix(strings.TrimPrefix(destinationARN.Resource, "log-group:"), ":*")
+		}
+		_, exists, err := c.verifyCloudWatchLogGroup(ctx, logGroupName)
+		return fmt.Sprintf("CloudWatch Logs log group '%s'", logGroupName), exists, err
+	}
+}
+
+// verifyDHCPOptions checks if a DHCP option set exists in AWS.
+func (c *AWSClient) verifyDHCPOptions(ctx context.Context, dhcpOptionsID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeDhcpOptions(ctx, &ec2.DescribeDhcpOptionsInput{
+		DhcpOptionsIds: []string{dhcpOptionsID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidDhcpOptionID.NotFound") || strings.Contains(err.Error(), "InvalidDhcpOptionsID.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe DHCP option set '%s': %w", dhcpOptionsID, err)
+	}
+	for _, dhcpOptions := range resp.DhcpOptions {
+		if aws.ToString(dhcpOptions.DhcpOptionsId) == dhcpOptionsID {
+			return dhcpOptionsID, true, nil
+		}
+	}
+	return "", false, nil // DHCP option set not found
+}
+
+// verifyDHCPOptionsAssociation checks if a VPC uses a DHCP option set. The association is not found if the
+// VPC is gone or back on the default option set, and STALE if the VPC uses another option set.
+func (c *AWSClient) verifyDHCPOptionsAssociation(ctx context.Context, vpcID, dhcpOptionsID string) (string, bool, error) {
+	resp, err := c.EC2Client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
+		VpcIds: []string{vpcID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidVpcID.NotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe VPC '%s': %w", vpcID, err)
+	}
+	for _, vpc := range resp.Vpcs {
+		if aws.ToString(vpc.VpcId) != vpcID {
+			continue
+		}
+		liveOptionsID := aws.ToString(vpc.DhcpOptionsId)
+		switch liveOptionsID {
+		case dhcpOptionsID:
+			return dhcpOptionsID + "-" + vpcID, true, nil
+		case "", "default":
+			return "", false, nil // The VPC has no DHCP option set, so the association is gone
+		}
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   liveOptionsID + "-" + vpcID,
+			Message: fmt.Sprintf("VPC '%s' uses DHCP option set '%s', but the state associates it with '%s'.",
+				vpcID, liveOptionsID, dhcpOptionsID),
+		}
+	}
+	return "", false, nil // VPC not found
+}
+
+// verifyWAFV2Resource checks if a WAFv2 web ACL, rule group or IP set exists in AWS. Those of the
+// CLOUDFRONT scope are looked up in us-east-1, where all of them live.
+func (c *AWSClient) verifyWAFV2Resource(ctx context.Context, resourceType, id, name, scope string) (string, bool, error) {
+	client := c.WAFV2Client
+	if strings.EqualFold(scope, string(wafv2types.ScopeCloudfront)) {
+		client = c.WAFV2GlobalClient
+	}
+	var err error
+	switch resourceType {
+	case "aws_wafv2_web_acl":
+		_, err = client.GetWebACL(ctx, &wafv2.GetWebACLInput{Id: aws.String(id), Name: aws.String(name), Scope: wafv2types.Scope(strings.ToUpper(scope))})
+	case "aws_wafv2_rule_group":
+		_, err = client.GetRuleGroup(ctx, &wafv2.GetRuleGroupInput{Id: aws.String(id), Name: aws.String(name), Scope: wafv2types.Scope(strings.ToUpper(scope))})
+	case "aws_wafv2_ip_set":
+		_, err = client.GetIPSet(ctx, &wafv2.GetIPSetInput{Id: aws.String(id), Name: aws.String(name), Scope: wafv2types.Scope(strings.ToUpper(scope))})
+	default:
+		return "", false, fmt.Errorf("unsupported WAFv2 resource type '%s'", resourceType)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "WAFNonexistentItemException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get %s '%s' (%s scope): %w", resourceType, name, scope, err)
+	}
+	return id, true, nil
+}
+
+// verifyWAFV2WebACLAssociation checks if a regional resource, such as a load balancer or API Gateway stage,
+// is protected by a WAFv2 web ACL. A resource protected by another web ACL is reported as STALE.
+func (c *AWSClient) verifyWAFV2WebACLAssociation(ctx context.Context, webACLARN, resourceARN string) (string, bool, error) {
+	resp, err := c.WAFV2Client.GetWebACLForResource(ctx, &wafv2.GetWebACLForResourceInput{
+		ResourceArn: aws.String(resourceARN),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "WAFNonexistentItemException") {
+			return "", false, nil // The resource is gone, and the association with it
+		}
+		return "", false, fmt.Errorf("failed to get the web ACL of '%s': %w", resourceARN, err)
+	}
+	if resp.WebACL == nil {
+		return "", false, nil // Association not found
+	}
+	if liveARN := aws.ToString(resp.WebACL.ARN); liveARN != webACLARN {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   liveARN + "," + resourceARN,
+			Message:  fmt.Sprintf("Resource '%s' is protected by web ACL '%s', but the state associates it with '%s'.", resourceARN, liveARN, webACLARN),
+		}
+	}
+	return webACLARN + "," + resourceARN, true, nil
+}
+
+// verifyCloudTrail checks if a CloudTrail trail, given by name or ARN, exists in AWS and returns its ARN.
+// A trail whose logging is stopped while the state enables it, or the other way around, is STALE.
+func (c *AWSClient) verifyCloudTrail(ctx context.Context, trailName string, stateLogging bool) (string, bool, error) {
+	resp, err := c.CloudTrailClient.GetTrail(ctx, &cloudtrail.GetTrailInput{
+		Name: aws.String(trailName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "TrailNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get CloudTrail trail '%s': %w", trailName, err)
+	}
+	if resp.Trail == nil {
+		return "", false, nil // Trail not found
+	}
+	trailARN := aws.ToString(resp.Trail.TrailARN)
+	status, err := c.CloudTrailClient.GetTrailStatus(ctx, &cloudtrail.GetTrailStatusInput{
+		Name: aws.String(trailARN),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get the status of CloudTrail trail '%s': %w", trailName, err)
+	}
+	if logging := aws.ToBool(status.IsLogging); logging != stateLogging {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   trailARN,
+			Message:  fmt.Sprintf("CloudTrail trail '%s' exists but logging is %s, while the state has enable_logging = %t.", aws.ToString(resp.Trail.Name), onOff(logging), stateLogging),
+		}
+	}
+	return trailARN, true, nil
+}
+
+// verifyConfigRecorder checks if an AWS Config configuration recorder exists in AWS.
+func (c *AWSClient) verifyConfigRecorder(ctx context.Context, recorderName string) (string, bool, error) {
+	resp, err := c.ConfigServiceClient.DescribeConfigurationRecorders(ctx, &configservice.DescribeConfigurationRecordersInput{
+		ConfigurationRecorderNames: []string{recorderName},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NoSuchConfigurationRecorderException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Config configuration recorder '%s': %w", recorderName, err)
+	}
+	for _, recorder := range resp.ConfigurationRecorders {
+		if aws.ToString(recorder.Name) == recorderName {
+			return recorderName, true, nil
+		}
+	}
+	return "", false, nil // Recorder not found
+}
+
+// verifyConfigDeliveryChannel checks if an AWS Config delivery channel exists in AWS.
+func (c *AWSClient) verifyConfigDeliveryChannel(ctx context.Context, channelName string) (string, bool, error) {
+	resp, err := c.ConfigServiceClient.DescribeDeliveryChannels(ctx, &configservice.DescribeDeliveryChannelsInput{
+		DeliveryChannelNames: []string{channelName},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NoSuchDeliveryChannelException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Config delivery channel '%s': %w", channelName, err)
+	}
+	for _, channel := range resp.DeliveryChannels {
+		if aws.ToString(channel.Name) == channelName {
+			return channelName, true, nil
+		}
+	}
+	return "", false, nil // Delivery channel not found
+}
+
+// verifyConfigRule checks if an AWS Config rule exists in AWS. Rules being deleted are DANGEROUS.
+func (c *AWSClient) verifyConfigRule(ctx context.Context, ruleName string) (string, bool, error) {
+	resp, err := c.ConfigServiceClient.DescribeConfigRules(ctx, &configservice.DescribeConfigRulesInput{
+		ConfigRuleNames: []string{ruleName},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NoSuchConfigRuleException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Config rule '%s': %w", ruleName, err)
+	}
+	for _, rule := range resp.ConfigRules {
+		if aws.ToString(rule.ConfigRuleName) != ruleName {
+			continue
+		}
+		if strings.HasPrefix(string(rule.ConfigRuleState), "DELETING") {
+			return "", false, beingDeletedError("Config rule", ruleName, ruleName)
+		}
+		return ruleName, true, nil
+	}
+	return "", false, nil // Rule not found
+}
+
+// verifyGuardDutyDetector checks if a GuardDuty detector exists in AWS. A detector that is disabled while
+// the state enables it, or the other way around, is STALE.
+func (c *AWSClient) verifyGuardDutyDetector(ctx context.Context, detectorID string, stateEnabled bool) (string, bool, error) {
+	found, err := c.guardDutyDetectorExists(ctx, detectorID)
+	if err != nil || !found {
+		return "", false, err
+	}
+	resp, err := c.GuardDutyClient.GetDetector(ctx, &guardduty.GetDetectorInput{
+		DetectorId: aws.String(detectorID),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get GuardDuty detector '%s': %w", detectorID, err)
+	}
+	if enabled := resp.Status == guarddutytypes.DetectorStatusEnabled; enabled != stateEnabled {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   detectorID,
+			Message:  fmt.Sprintf("GuardDuty detector '%s' exists but is %s, while the state has enable = %t.", detectorID, onOff(enabled), stateEnabled),
+		}
+	}
+	return detectorID, true, nil
+}
+
+// verifyGuardDutyFilter checks if a GuardDuty findings filter exists on its detector in AWS.
+func (c *AWSClient) verifyGuardDutyFilter(ctx context.Context, detectorID, filterName string) (string, bool, error) {
+	found, err := c.guardDutyDetectorExists(ctx, detectorID)
+	if err != nil || !found {
+		return "", false, err // Detector not found, so neither is the filter
+	}
+	_, err = c.GuardDutyClient.GetFilter(ctx, &guardduty.GetFilterInput{
+		DetectorId: aws.String(detectorID),
+		FilterName: aws.String(filterName),
+	})
+	if err != nil {
+		// GuardDuty reports missing filters as a bad request.
+		if strings.Contains(err.Error(), "BadRequestException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get GuardDuty filter '%s' of detector '%s': %w", filterName, detectorID, err)
+	}
+	return detectorID + ":" + filterName, true, nil
+}
+
+// verifyGuardDutyMember checks if an account is a member of a GuardDuty administrator detector in AWS.
+func (c *AWSClient) verifyGuardDutyMember(ctx context.Context, detectorID, accountID string) (string, bool, error) {
+	found, err := c.guardDutyDetectorExists(ctx, detectorID)
+	if err != nil || !found {
+		return "", false, err // Detector not found, so neither is the member
+	}
+	resp, err := c.GuardDutyClient.GetMembers(ctx, &guardduty.GetMembersInput{
+		DetectorId: aws.String(detectorID),
+		AccountIds: []string{accountID},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get GuardDuty member '%s' of detector '%s': %w", accountID, detectorID, err)
+	}
+	for _, member := range resp.Members {
+		if aws.ToString(member.AccountId) == accountID {
+			return detectorID + ":" + accountID, true, nil
+		}
+	}
+	return "", false, nil // Member not found
+}
+
+// verifySESIdentity checks if an SES domain or email identity exists in AWS. Identities that are not
+// verified, whether still pending or failed, cannot send and are reported as WARNING.
+func (c *AWSClient) verifySESIdentity(ctx context.Context, identity string) (string, bool, error) {
+	resp, err := c.SESV2Client.GetEmailIdentity(ctx, &sesv2.GetEmailIdentityInput{
+		EmailIdentity: aws.String(identity),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get SES identity '%s': %w", identity, err)
+	}
+	if resp.VerificationStatus != sesv2types.VerificationStatusSuccess {
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   identity,
+			Message:  fmt.Sprintf("SES identity '%s' exists but its verification status is %s.", identity, resp.VerificationStatus),
+		}
+	}
+	return identity, true, nil
+}
+
+// verifySESConfigurationSet checks if an SES configuration set exists in AWS.
+func (c *AWSClient) verifySESConfigurationSet(ctx context.Context, setName string) (string, bool, error) {
+	_, err := c.SESV2Client.GetConfigurationSet(ctx, &sesv2.GetConfigurationSetInput{
+		ConfigurationSetName: aws.String(setName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get SES configuration set '%s': %w", setName, err)
+	}
+	return setName, true, nil
+}
+
+// verifySESReceiptRule checks if an SES receipt rule exists in its rule set in AWS. Receipt rules are
+// only served by the SES v1 API.
+func (c *AWSClient) verifySESReceiptRule(ctx context.Context, ruleSetName, ruleName string) (string, bool, error) {
+	_, err := c.SESClient.DescribeReceiptRule(ctx, &ses.DescribeReceiptRuleInput{
+		RuleSetName: aws.String(ruleSetName),
+		RuleName:    aws.String(ruleName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "RuleDoesNotExist") || strings.Contains(err.Error(), "RuleSetDoesNotExist") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe SES receipt rule '%s' of rule set '%s': %w", ruleName, ruleSetName, err)
+	}
+	return ruleName, true, nil
+}
+
+// verifyCognitoUserPool checks if a Cognito user pool exists in AWS.
+func (c *AWSClient) verifyCognitoUserPool(ctx context.Context, userPoolID string) (string, bool, error) {
+	_, err := c.CognitoIDPClient.DescribeUserPool(ctx, &cognitoidentityprovider.DescribeUserPoolInput{
+		UserPoolId: aws.String(userPoolID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Cognito user pool '%s': %w", userPoolID, err)
+	}
+	return userPoolID, true, nil
+}
+
+// verifyCognitoUserPoolClient checks if an app client exists in its Cognito user pool in AWS.
+func (c *AWSClient) verifyCognitoUserPoolClient(ctx context.Context, userPoolID, clientID string) (string, bool, error) {
+	_, err := c.CognitoIDPClient.DescribeUserPoolClient(ctx, &cognitoidentityprovider.DescribeUserPoolClientInput{
+		UserPoolId: aws.String(userPoolID),
+		ClientId:   aws.String(clientID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil // The client or its user pool is gone
+		}
+		return "", false, fmt.Errorf("failed to describe Cognito user pool client '%s' of '%s': %w", clientID, userPoolID, err)
+	}
+	return clientID, true, nil
+}
+
+// verifyCognitoUserPoolDomain checks if a Cognito user pool domain exists in AWS. Domains being deleted are
+// DANGEROUS, failed ones are reported as WARNING, and a domain of another user pool than the state
+// records is STALE.
+func (c *AWSClient) verifyCognitoUserPoolDomain(ctx context.Context, domain, userPoolID string) (string, bool, error) {
+	resp, err := c.CognitoIDPClient.DescribeUserPoolDomain(ctx, &cognitoidentityprovider.DescribeUserPoolDomainInput{
+		Domain: aws.String(domain),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Cognito user pool domain '%s': %w", domain, err)
+	}
+	// Unknown domains are described without a user pool rather than as an error.
+	if resp.DomainDescription == nil || aws.ToString(resp.DomainDescription.UserPoolId) == "" {
+		return "", false, nil
+	}
+	description := resp.DomainDescription
+	if liveUserPoolID := aws.ToString(description.UserPoolId); userPoolID != "" && liveUserPoolID != userPoolID {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   domain,
+			Message:  fmt.Sprintf("Cognito user pool domain '%s' belongs to user pool '%s', but the state records '%s'.", domain, liveUserPoolID, userPoolID),
+		}
+	}
+	switch description.Status {
+	case cognitoidptypes.DomainStatusTypeDeleting:
+		return "", false, beingDeletedError("Cognito user pool domain", domain, domain)
+	case cognitoidptypes.DomainStatusTypeFailed:
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   domain,
+			Message:  fmt.Sprintf("Cognito user pool domain '%s' exists but is FAILED.", domain),
+		}
+	}
+	return domain, true, nil
+}
+
+// verifyCognitoResourceServer checks if a resource server exists in its Cognito user pool in AWS.
+func (c *AWSClient) verifyCognitoResourceServer(ctx context.Context, userPoolID, identifier string) (string, bool, error) {
+	_, err := c.CognitoIDPClient.DescribeResourceServer(ctx, &cognitoidentityprovider.DescribeResourceServerInput{
+		UserPoolId: aws.String(userPoolID),
+		Identifier: aws.String(identifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil // The resource server or its user pool is gone
+		}
+		return "", false, fmt.Errorf("failed to describe Cognito resource server '%s' of '%s': %w", identifier, userPoolID, err)
+	}
+	return userPoolID + "|" + identifier, true, nil
+}
+
+// verifyCognitoIdentityPool checks if a Cognito identity pool exists in AWS.
+func (c *AWSClient) verifyCognitoIdentityPool(ctx context.Context, identityPoolID string) (string, bool, error) {
+	_, err := c.CognitoIDClient.DescribeIdentityPool(ctx, &cognitoidentity.DescribeIdentityPoolInput{
+		IdentityPoolId: aws.String(identityPoolID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Cognito identity pool '%s': %w", identityPoolID, err)
+	}
+	return identityPoolID, true, nil
+}
+
+// verifyCognitoIdentityPoolRoles checks if a Cognito identity pool has roles attached in AWS. Roles that
+// differ from those of the state, such as another authenticated role, are reported as STALE.
+func (c *AWSClient) verifyCognitoIdentityPoolRoles(ctx context.Context, identityPoolID string, roles map[string]string) (string, bool, error) {
+	resp, err := c.CognitoIDClient.GetIdentityPoolRoles(ctx, &cognitoidentity.GetIdentityPoolRolesInput{
+		IdentityPoolId: aws.String(identityPoolID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get the roles of Cognito identity pool '%s': %w", identityPoolID, err)
+	}
+	if len(resp.Roles) == 0 {
+		return "", false, nil // No roles attached
+	}
+	var differences []string
+	for _, role := range slices.Sorted(maps.Keys(roles)) {
+		if live := resp.Roles[role]; live != roles[role] {
+			differences = append(differences, fmt.Sprintf("%s role is '%s' instead of '%s'", role, live, roles[role]))
+		}
+	}
+	if len(differences) > 0 {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   identityPoolID,
+			Message:  fmt.Sprintf("Cognito identity pool '%s' has other roles than the state records: %s.", identityPoolID, strings.Join(differences, ", ")),
+		}
+	}
+	return identityPoolID, true, nil
+}
+
+// verifyAppSyncGraphQLAPI checks if an AppSync GraphQL API exists in AWS.
+func (c *AWSClient) verifyAppSyncGraphQLAPI(ctx context.Context, apiID string) (string, bool, error) {
+	_, err := c.AppSyncClient.GetGraphqlApi(ctx, &appsync.GetGraphqlApiInput{
+		ApiId: aws.String(apiID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get AppSync GraphQL API '%s': %w", apiID, err)
+	}
+	return apiID, true, nil
+}
+
+// verifyAppSyncDataSource checks if a data source exists on its AppSync API in AWS and returns
+// API_ID-NAME, the ID Terraform gives it.
+func (c *AWSClient) verifyAppSyncDataSource(ctx context.Context, apiID, dataSourceName string) (string, bool, error) {
+	_, err := c.AppSyncClient.GetDataSource(ctx, &appsync.GetDataSourceInput{
+		ApiId: aws.String(apiID),
+		Name:  aws.String(dataSourceName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // The data source or its API is gone
+		}
+		return "", false, fmt.Errorf("failed to get AppSync data source '%s' of API '%s': %w", dataSourceName, apiID, err)
+	}
+	return apiID + "-" + dataSourceName, true, nil
+}
+
+// verifyAppSyncResolver checks if a resolver of a type's field exists on its AppSync API in AWS and
+// returns API_ID-TYPE-FIELD, the ID Terraform gives it.
+func (c *AWSClient) verifyAppSyncResolver(ctx context.Context, apiID, typeName, fieldName string) (string, bool, error) {
+	_, err := c.AppSyncClient.GetResolver(ctx, &appsync.GetResolverInput{
+		ApiId:     aws.String(apiID),
+		TypeName:  aws.String(typeName),
+		FieldName: aws.String(fieldName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil // The resolver, its type or its API is gone
+		}
+		return "", false, fmt.Errorf("failed to get AppSync resolver '%s.%s' of API '%s': %w", typeName, fieldName, apiID, err)
+	}
+	return apiID + "-" + typeName + "-" + fieldName, true, nil
+}
+
+// verifyAppSyncAPIKey checks if an API key exists on its AppSync API in AWS. Keys past their expiry
+// no longer authorize requests and are reported as WARNING until AppSync deletes them.
+func (c *AWSClient) verifyAppSyncAPIKey(ctx context.Context, apiID, keyID string) (string, bool, error) {
+	paginator := appsync.NewListApiKeysPaginator(c.AppSyncClient, &appsync.ListApiKeysInput{
+		ApiId: aws.String(apiID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			if strings.Contains(err.Error(), "NotFoundException") {
+				return "", false, nil // The API is gone, and its keys with it
+			}
+			return "", false, fmt.Errorf("failed to list API keys of AppSync API '%s': %w", apiID, err)
+		}
+		for _, apiKey := range page.ApiKeys {
+			if aws.ToString(apiKey.Id) != keyID {
+				continue
+			}
+			if expires := time.Unix(apiKey.Expires, 0); apiKey.Expires > 0 && expires.Before(time.Now()) {
+				return "", false, &liveStateError{
+					Category: "WARNING",
+					LiveID:   apiID + ":" + keyID,
+					Message:  fmt.Sprintf("AppSync API key '%s' of API '%s' expired on %s.", keyID, apiID, expires.UTC().Format(time.RFC3339)),
+				}
+			}
+			return apiID + ":" + keyID, true, nil
+		}
+	}
+	return "", false, nil // API key not found
+}
+
+// verifyCodeBuildProject checks if a CodeBuild project, given by name or ARN, exists in AWS and returns its ARN.
+func (c *AWSClient) verifyCodeBuildProject(ctx context.Context, projectName string) (string, bool, error) {
+	project, err := c.describeCodeBuildProject(ctx, projectName)
+	if err != nil || project == nil {
+		return "", false, err
+	}
+	return aws.ToString(project.Arn), true, nil
+}
+
+// verifyCodeBuildSourceCredential checks if CodeBuild source credentials exist in AWS by their ARN.
+func (c *AWSClient) verifyCodeBuildSourceCredential(ctx context.Context, credentialARN string) (string, bool, error) {
+	resp, err := c.CodeBuildClient.ListSourceCredentials(ctx, &codebuild.ListSourceCredentialsInput{})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list CodeBuild source credentials: %w", err)
+	}
+	for _, credential := range resp.SourceCredentialsInfos {
+		if aws.ToString(credential.Arn) == credentialARN {
+			return credentialARN, true, nil
+		}
+	}
+	return "", false, nil // Source credentials not found
+}
+
+// verifyCodeBuildWebhook checks if a CodeBuild project has a webhook in AWS.
+func (c *AWSClient) verifyCodeBuildWebhook(ctx context.Context, projectName string) (string, bool, error) {
+	project, err := c.describeCodeBuildProject(ctx, projectName)
+	if err != nil || project == nil {
+		return "", false, err // Project not found, so neither is its webhook
+	}
+	if project.Webhook == nil {
+		return "", false, nil // Webhook not found
+	}
+	return aws.ToString(project.Name), true, nil
+}
+
+// verifyCodePipeline checks if a CodePipeline pipeline exists in AWS.
+func (c *AWSClient) verifyCodePipeline(ctx context.Context, pipelineName string) (string, bool, error) {
+	_, err := c.CodePipelineClient.GetPipeline(ctx, &codepipeline.GetPipelineInput{
+		Name: aws.String(pipelineName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "PipelineNotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get CodePipeline pipeline '%s': %w", pipelineName, err)
+	}
+	return pipelineName, true, nil
+}
+
+// verifyCodePipelineWebhook checks if a CodePipeline webhook exists in AWS by its ARN. A webhook that
+// triggers another pipeline than the state records is STALE.
+func (c *AWSClient) verifyCodePipelineWebhook(ctx context.Context, webhookARN, targetPipeline string) (string, bool, error) {
+	paginator := codepipeline.NewListWebhooksPaginator(c.CodePipelineClient, &codepipeline.ListWebhooksInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to list CodePipeline webhooks: %w", err)
+		}
+		for _, webhook := range page.Webhooks {
+			if aws.ToString(webhook.Arn) != webhookARN {
+				continue
+			}
+			if webhook.Definition != nil && targetPipeline != "" {
+				if livePipeline := aws.ToString(webhook.Definition.TargetPipeline); livePipeline != targetPipeline {
+					return "", false, &liveStateError{
+						Category: "STALE",
+						LiveID:   webhookARN,
+						Message: fmt.Sprintf("CodePipeline webhook '%s' triggers pipeline '%s', but the state records '%s'.",
+							aws.ToString(webhook.Definition.Name), livePipeline, targetPipeline),
+					}
+				}
+			}
+			return webhookARN, true, nil
+		}
+	}
+	return "", false, nil // Webhook not found
+}
+
+// verifyCodeDeployApp checks if a CodeDeploy application exists in AWS by name. An application recreated
+// under the same name, with another ID than appID from the state, is a POTENTIAL_IMPORT.
+func (c *AWSClient) verifyCodeDeployApp(ctx context.Context, appName, appID string) (string, bool, error) {
+	resp, err := c.CodeDeployClient.GetApplication(ctx, &codedeploy.GetApplicationInput{
+		ApplicationName: aws.String(appName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ApplicationDoesNotExistException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get CodeDeploy application '%s': %w", appName, err)
+	}
+	if resp.Application == nil {
+		return "", false, nil // Application not found
+	}
+	if liveAppID := aws.ToString(resp.Application.ApplicationId); appID != "" && liveAppID != appID {
+		return "", false, &liveStateError{
+			Category:    "POTENTIAL_IMPORT",
+			LiveID:      appName,
+			Message:     fmt.Sprintf("CodeDeploy application '%s' exists in AWS with ID '%s'. State ID: '%s'.", appName, liveAppID, appID),
+			Remediation: "import",
+		}
+	}
+	return appName, true, nil
+}
+
+// verifyCodeDeployDeploymentGroup checks if a CodeDeploy deployment group exists in its application in AWS.
+// A group recreated under the same name, with another ID than groupID from the state, is a POTENTIAL_IMPORT.
+func (c *AWSClient) verifyCodeDeployDeploymentGroup(ctx context.Context, appName, groupName, groupID string) (string, bool, error) {
+	resp, err := c.CodeDeployClient.GetDeploymentGroup(ctx, &codedeploy.GetDeploymentGroupInput{
+		ApplicationName:     aws.String(appName),
+		DeploymentGroupName: aws.String(groupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DeploymentGroupDoesNotExistException") || strings.Contains(err.Error(), "ApplicationDoesNotExistException") {
+			return "", false, nil // The group or its application is gone
+		}
+		return "", false, fmt.Errorf("failed to get CodeDeploy deployment group '%s' of application '%s': %w", groupName, appName, err)
+	}
+	if resp.DeploymentGroupInfo == nil {
+		return "", false, nil // Deployment group not found
+	}
+	liveGroupID := aws.ToString(resp.DeploymentGroupInfo.DeploymentGroupId)
+	if groupID != "" && liveGroupID != groupID {
+		return "", false, &liveStateError{
+			Category:    "POTENTIAL_IMPORT",
+			LiveID:      appName + ":" + groupName,
+			Message:     fmt.Sprintf("CodeDeploy deployment group '%s' of application '%s' exists in AWS with ID '%s'. State ID: '%s'.", groupName, appName, liveGroupID, groupID),
+			Remediation: "import",
+		}
+	}
+	return liveGroupID, true, nil
+}
+
+// verifyBatchComputeEnvironment checks if an AWS Batch compute environment, given by name or ARN, exists
+// in AWS and returns its ARN. See batchStatusResult for how its status and state are reported.
+func (c *AWSClient) verifyBatchComputeEnvironment(ctx context.Context, environmentName, state string) (string, bool, error) {
+	resp, err := c.BatchClient.DescribeComputeEnvironments(ctx, &batch.DescribeComputeEnvironmentsInput{
+		ComputeEnvironments: []string{environmentName},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to describe Batch compute environment '%s': %w", environmentName, err)
+	}
+	for _, environment := range resp.ComputeEnvironments {
+		if aws.ToString(environment.ComputeEnvironmentName) != environmentName && aws.ToString(environment.ComputeEnvironmentArn) != environmentName {
+			continue
+		}
+		return batchStatusResult("Batch compute environment", aws.ToString(environment.ComputeEnvironmentArn), string(environment.Status),
+			aws.ToString(environment.StatusReason), string(environment.State), state)
+	}
+	return "", false, nil // Compute environment not found
+}
+
+// verifyBatchJobQueue checks if an AWS Batch job queue, given by name or ARN, exists in AWS and returns its
+// ARN. See batchStatusResult for how its status and state are reported.
+func (c *AWSClient) verifyBatchJobQueue(ctx context.Context, queueName, state string) (string, bool, error) {
+	resp, err := c.BatchClient.DescribeJobQueues(ctx, &batch.DescribeJobQueuesInput{
+		JobQueues: []string{queueName},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to describe Batch job queue '%s': %w", queueName, err)
+	}
+	for _, queue := range resp.JobQueues {
+		if aws.ToString(queue.JobQueueName) != queueName && aws.ToString(queue.JobQueueArn) != queueName {
+			continue
+		}
+		return batchStatusResult("Batch job queue", aws.ToString(queue.JobQueueArn), string(queue.Status),
+			aws.ToString(queue.StatusReason), string(queue.State), state)
+	}
+	return "", false, nil // Job queue not found
+}
+
+// verifyBatchJobDefinition checks if an AWS Batch job definition revision exists in AWS by its ARN.
+// Deregistered revisions stay visible as INACTIVE, which Terraform treats as deleted.
+func (c *AWSClient) verifyBatchJobDefinition(ctx context.Context, definitionARN string) (string, bool, error) {
+	resp, err := c.BatchClient.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
+		JobDefinitions: []string{definitionARN},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to describe Batch job definition '%s': %w", definitionARN, err)
+	}
+	for _, definition := range resp.JobDefinitions {
+		if aws.ToString(definition.JobDefinitionArn) == definitionARN && aws.ToString(definition.Status) != "INACTIVE" {
+			return definitionARN, true, nil
+		}
+	}
+	return "", false, nil // Job definition not found or deregistered
+}
+
+// batchStatusResult returns the verification result of a Batch compute environment or job queue by its
+// status: deleted ones are not found, those being deleted are DANGEROUS and invalid ones are reported as
+// WARNING with the reason. One ENABLED while the state has it DISABLED, or the other way around, is STALE.
+func batchStatusResult(kind, resourceARN, status, reason, liveState, state string) (string, bool, error) {
+	switch status {
+	case "DELETED":
+		return "", false, nil
+	case "DELETING":
+		return "", false, beingDeletedError(kind, resourceARN, resourceARN)
+	case "INVALID":
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   resourceARN,
+			Message:  fmt.Sprintf("%s '%s' exists but is INVALID: %s", kind, resourceARN, reason),
+		}
+	}
+	if state != "" && !strings.EqualFold(liveState, state) {
+		return "", false, &liveStateError{
+			Category: "STALE",
+			LiveID:   resourceARN,
+			Message:  fmt.Sprintf("%s '%s' is %s, but the state records %s.", kind, resourceARN, liveState, strings.ToUpper(state)),
+		}
+	}
+	return resourceARN, true, nil
+}
+
+// verifyMSKCluster checks if an MSK cluster exists in AWS by its ARN. Clusters being deleted are DANGEROUS
+// and failed ones are reported as WARNING with the reason.
+func (c *AWSClient) verifyMSKCluster(ctx context.Context, clusterARN string) (string, bool, error) {
+	resp, err := c.MSKClient.DescribeClusterV2(ctx, &kafka.DescribeClusterV2Input{
+		ClusterArn: aws.String(clusterARN),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe MSK cluster '%s': %w", clusterARN, err)
+	}
+	if resp.ClusterInfo == nil {
+		return "", false, nil // Cluster not found
+	}
+	switch resp.ClusterInfo.State {
+	case kafkatypes.ClusterStateDeleting:
+		return "", false, beingDeletedError("MSK cluster", aws.ToString(resp.ClusterInfo.ClusterName), clusterARN)
+	case kafkatypes.ClusterStateFailed:
+		var reason string
+		if resp.ClusterInfo.StateInfo != nil {
+			reason = aws.ToString(resp.ClusterInfo.StateInfo.Message)
+		}
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   clusterARN,
+			Message:  fmt.Sprintf("MSK cluster '%s' exists but is FAILED: %s", aws.ToString(resp.ClusterInfo.ClusterName), reason),
+		}
+	}
+	return clusterARN, true, nil
+}
+
+// verifyMSKConfiguration checks if an MSK configuration exists in AWS by its ARN. Configurations being
+// deleted are DANGEROUS and those that failed to delete are reported as WARNING.
+func (c *AWSClient) verifyMSKConfiguration(ctx context.Context, configurationARN string) (string, bool, error) {
+	resp, err := c.MSKClient.DescribeConfiguration(ctx, &kafka.DescribeConfigurationInput{
+		Arn: aws.String(configurationARN),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe MSK configuration '%s': %w", configurationARN, err)
+	}
+	switch resp.State {
+	case kafkatypes.ConfigurationStateDeleting:
+		return "", false, beingDeletedError("MSK configuration", aws.ToString(resp.Name), configurationARN)
+	case kafkatypes.ConfigurationStateDeleteFailed:
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   configurationARN,
+			Message:  fmt.Sprintf("MSK configuration '%s' exists but failed to delete.", aws.ToString(resp.Name)),
+		}
+	}
+	return configurationARN, true, nil
+}
+
+// verifyMQBroker checks if an Amazon MQ broker exists in AWS by its ID. Brokers being deleted are
+// DANGEROUS; brokers that failed to create or require a critical action are reported as WARNING.
+func (c *AWSClient) verifyMQBroker(ctx context.Context, brokerID string) (string, bool, error) {
+	resp, err := c.MQClient.DescribeBroker(ctx, &mq.DescribeBrokerInput{
+		BrokerId: aws.String(brokerID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe MQ broker '%s': %w", brokerID, err)
+	}
+	switch resp.BrokerState {
+	case mqtypes.BrokerStateDeletionInProgress:
+		return "", false, beingDeletedError("MQ broker", aws.ToString(resp.BrokerName), brokerID)
+	case mqtypes.BrokerStateCreationFailed, mqtypes.BrokerStateCriticalActionRequired:
+		return "", false, &liveStateError{
+			Category: "WARNING",
+			LiveID:   brokerID,
+			Message:  fmt.Sprintf("MQ broker '%s' exists but is %s.", aws.ToString(resp.BrokerName), resp.BrokerState),
+		}
+	}
+	return brokerID, true, nil
+}
+
+// verifyMQConfiguration checks if an Amazon MQ configuration exists in AWS by its ID.
+func (c *AWSClient) verifyMQConfiguration(ctx context.Context, configurationID string) (string, bool, error) {
+	_, err := c.MQClient.DescribeConfiguration(ctx, &mq.DescribeConfigurationInput{
+		ConfigurationId: aws.String(configurationID),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFoundException") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe MQ configuration '%s': %w", configurationID, err)
+	}
+	return configurationID, true, nil
+}
+
+// verifyNeptuneCluster checks if a Neptune DB cluster exists in AWS. Its status and deletion protection
+// are reported as for RDS clusters.
+func (c *AWSClient) verifyNeptuneCluster(ctx context.Context, clusterIdentifier string, deletionProtection *bool) (string, bool, error) {
+	resp, err := c.NeptuneClient.DescribeDBClusters(ctx, &neptune.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(clusterIdentifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBClusterNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Neptune DB cluster '%s': %w", clusterIdentifier, err)
+	}
+	for _, cluster := range resp.DBClusters {
+		if !strings.EqualFold(aws.ToString(cluster.DBClusterIdentifier), clusterIdentifier) {
+			continue
+		}
+		liveID := aws.ToString(cluster.DBClusterIdentifier)
+		if err := rdsStatusError("Neptune cluster", clusterIdentifier, liveID, aws.ToString(cluster.Status), deletionProtection, aws.ToBool(cluster.DeletionProtection)); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // Neptune cluster not found
+}
+
+// verifyNeptuneInstance checks if a Neptune DB instance exists in AWS. An instance of another cluster than
+// the state records is STALE.
+func (c *AWSClient) verifyNeptuneInstance(ctx context.Context, identifier, clusterIdentifier string) (string, bool, error) {
+	resp, err := c.NeptuneClient.DescribeDBInstances(ctx, &neptune.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(identifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBInstanceNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Neptune DB instance '%s': %w", identifier, err)
+	}
+	for _, instance := range resp.DBInstances {
+		if !strings.EqualFold(aws.ToString(instance.DBInstanceIdentifier), identifier) {
+			continue
+		}
+		liveID := aws.ToString(instance.DBInstanceIdentifier)
+		liveCluster := aws.ToString(instance.DBClusterIdentifier)
+		if clusterIdentifier != "" && !strings.EqualFold(liveCluster, clusterIdentifier) {
+			return "", false, &liveStateError{
+				Category: "STALE",
+				LiveID:   liveID,
+				Message:  fmt.Sprintf("Neptune instance '%s' belongs to cluster '%s' but state references '%s'.", identifier, liveCluster, clusterIdentifier),
+			}
+		}
+		if err := rdsStatusError("Neptune instance", identifier, liveID, aws.ToString(instance.DBInstanceStatus), nil, false); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // Neptune instance not found
+}
+
+// verifyNeptuneSubnetGroup checks if a Neptune DB subnet group exists in AWS.
+func (c *AWSClient) verifyNeptuneSubnetGroup(ctx context.Context, groupName string) (string, bool, error) {
+	resp, err := c.NeptuneClient.DescribeDBSubnetGroups(ctx, &neptune.DescribeDBSubnetGroupsInput{
+		DBSubnetGroupName: aws.String(groupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBSubnetGroupNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Neptune subnet group '%s': %w", groupName, err)
+	}
+	for _, group := range resp.DBSubnetGroups {
+		if strings.EqualFold(aws.ToString(group.DBSubnetGroupName), groupName) {
+			return aws.ToString(group.DBSubnetGroupName), true, nil
+		}
+	}
+	return "", false, nil // Subnet group not found
+}
+
+// verifyDocDBCluster checks if a DocumentDB cluster exists in AWS, reporting its status and deletion
+// protection like those of RDS clusters.
+func (c *AWSClient) verifyDocDBCluster(ctx context.Context, clusterIdentifier string, deletionProtection *bool) (string, bool, error) {
+	resp, err := c.DocDBClient.DescribeDBClusters(ctx, &docdb.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(clusterIdentifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBClusterNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe DocumentDB cluster '%s': %w", clusterIdentifier, err)
+	}
+	for _, cluster := range resp.DBClusters {
+		if !strings.EqualFold(aws.ToString(cluster.DBClusterIdentifier), clusterIdentifier) {
+			continue
+		}
+		liveID := aws.ToString(cluster.DBClusterIdentifier)
+		if err := rdsStatusError("DocumentDB cluster", clusterIdentifier, liveID, aws.ToString(cluster.Status), deletionProtection, aws.ToBool(cluster.DeletionProtection)); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // DocumentDB cluster not found
+}
+
+// verifyDocDBInstance checks if a DocumentDB instance exists in AWS and belongs to the cluster of the
+// state; an instance of another cluster is STALE.
+func (c *AWSClient) verifyDocDBInstance(ctx context.Context, identifier, clusterIdentifier string) (string, bool, error) {
+	resp, err := c.DocDBClient.DescribeDBInstances(ctx, &docdb.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(identifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBInstanceNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe DocumentDB instance '%s': %w", identifier, err)
+	}
+	for _, instance := range resp.DBInstances {
+		if !strings.EqualFold(aws.ToString(instance.DBInstanceIdentifier), identifier) {
+			continue
+		}
+		liveID := aws.ToString(instance.DBInstanceIdentifier)
+		liveCluster := aws.ToString(instance.DBClusterIdentifier)
+		if clusterIdentifier != "" && !strings.EqualFold(liveCluster, clusterIdentifier) {
+			return "", false, &liveStateError{
+				Category: "STALE",
+				LiveID:   liveID,
+				Message:  fmt.Sprintf("DocumentDB instance '%s' belongs to cluster '%s' but state references '%s'.", identifier, liveCluster, clusterIdentifier),
+			}
+		}
+		if err := rdsStatusError("DocumentDB instance", identifier, liveID, aws.ToString(instance.DBInstanceStatus), nil, false); err != nil {
+			return "", false, err
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // DocumentDB instance not found
+}
+
+// verifyDocDBSubnetGroup checks if a DocumentDB subnet group exists in AWS.
+func (c *AWSClient) verifyDocDBSubnetGroup(ctx context.Context, groupName string) (string, bool, error) {
+	resp, err := c.DocDBClient.DescribeDBSubnetGroups(ctx, &docdb.DescribeDBSubnetGroupsInput{
+		DBSubnetGroupName: aws.String(groupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "DBSubnetGroupNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe DocumentDB subnet group '%s': %w", groupName, err)
+	}
+	for _, group := range resp.DBSubnetGroups {
+		if strings.EqualFold(aws.ToString(group.DBSubnetGroupName), groupName) {
+			return aws.ToString(group.DBSubnetGroupName), true, nil
+		}
+	}
+	return "", false, nil // Subnet group not found
+}
+
+// verifyOpenSearchDomain checks if an OpenSearch domain, or a legacy Elasticsearch domain, exists in AWS
+// and returns its ARN. See openSearchDomainStatusError for how its status is reported.
+func (c *AWSClient) verifyOpenSearchDomain(ctx context.Context, resourceType, domainName string) (string, bool, error) {
+	status, err := c.describeOpenSearchDomain(ctx, resourceType, domainName)
+	if err != nil || status == nil {
+		return "", false, err
+	}
+	if err := openSearchDomainStatusError(status); err != nil {
+		return "", false, err
+	}
+	return status.arn, true, nil
+}
+
+// verifyOpenSearchDomainPolicy checks if an OpenSearch or Elasticsearch domain has an access policy in AWS.
+func (c *AWSClient) verifyOpenSearchDomainPolicy(ctx context.Context, resourceType, domainName string) (string, bool, error) {
+	status, err := c.describeOpenSearchDomain(ctx, resourceType, domainName)
+	if err != nil || status == nil {
+		return "", false, err // Domain not found, so neither is its policy
+	}
+	if status.deleted {
+		return "", false, beingDeletedError(status.kind, domainName, status.arn)
+	}
+	if status.accessPolicies == "" || status.accessPolicies == "{}" {
+		return "", false, nil // Domain has no policy
+	}
+	return domainName, true, nil
+}
+
+// openSearchDomainStatusError reports a domain being deleted as DANGEROUS and a domain processing a
+// configuration change or upgrade as WARNING, since Terraform waits for it before applying changes.
+// It returns nil for active domains.
+func openSearchDomainStatusError(status *openSearchDomainStatus) error {
+	switch {
+	case status.deleted:
+		return beingDeletedError(status.kind, status.name, status.arn)
+	case status.processing:
+		return &liveStateError{
+			Category: "WARNING",
+			LiveID:   status.arn,
+			Message:  fmt.Sprintf("%s '%s' exists but is processing a configuration change; Terraform waits for it to finish before applying changes.", status.kind, status.name),
+		}
+	case status.upgradeProcessing:
+		return &liveStateError{
+			Category: "WARNING",
+			LiveID:   status.arn,
+			Message:  fmt.Sprintf("%s '%s' exists but is being upgraded; Terraform waits for it to finish before applying changes.", status.kind, status.name),
+		}
+	}
+	return nil
+}
+
+// redshiftUnhealthyStatuses are the Redshift cluster statuses in which the cluster exists but does not
+// work, reported as WARNING instead of OK.
+var redshiftUnhealthyStatuses = map[string]bool{
+	"hardware-failure":        true,
+	"incompatible-hsm":        true,
+	"incompatible-network":    true,
+	"incompatible-parameters": true,
+	"incompatible-restore":    true,
+	"storage-full":            true,
+}
+
+// verifyRedshiftCluster checks if a Redshift cluster exists in AWS. Clusters being deleted are DANGEROUS
+// and those in one of redshiftUnhealthyStatuses are reported as WARNING.
+func (c *AWSClient) verifyRedshiftCluster(ctx context.Context, clusterIdentifier string) (string, bool, error) {
+	resp, err := c.RedshiftClient.DescribeClusters(ctx, &redshift.DescribeClustersInput{
+		ClusterIdentifier: aws.String(clusterIdentifier),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ClusterNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Redshift cluster '%s': %w", clusterIdentifier, err)
+	}
+	for _, cluster := range resp.Clusters {
+		if !strings.EqualFold(aws.ToString(cluster.ClusterIdentifier), clusterIdentifier) {
+			continue
+		}
+		liveID := aws.ToString(cluster.ClusterIdentifier)
+		switch status := aws.ToString(cluster.ClusterStatus); {
+		case status == "deleting" || status == "final-snapshot":
+			return "", false, beingDeletedError("Redshift cluster", clusterIdentifier, liveID)
+		case redshiftUnhealthyStatuses[status]:
+			return "", false, &liveStateError{
+				Category: "WARNING",
+				LiveID:   liveID,
+				Message:  fmt.Sprintf("Redshift cluster '%s' exists but is %s; Terraform cannot modify it until it recovers.", clusterIdentifier, status),
+			}
+		}
+		return liveID, true, nil
+	}
+	return "", false, nil // Redshift cluster not found
+}
+
+// verifyRedshiftSubnetGroup checks if a Redshift cluster subnet group exists in AWS.
+func (c *AWSClient) verifyRedshiftSubnetGroup(ctx context.Context, groupName string) (string, bool, error) {
+	resp, err := c.RedshiftClient.DescribeClusterSubnetGroups(ctx, &redshift.DescribeClusterSubnetGroupsInput{
+		ClusterSubnetGroupName: aws.String(groupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ClusterSubnetGroupNotFoundFault") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Redshift subnet group '%s': %w", groupName, err)
+	}
+	for _, group := range resp.ClusterSubnetGroups {
+		if strings.EqualFold(aws.ToString(group.ClusterSubnetGroupName), groupName) {
+			return aws.ToString(group.ClusterSubnetGroupName), true, nil
+		}
+	}
+	return "", false, nil // Subnet group not found
+}
+
+// verifyRedshiftParameterGroup checks if a Redshift cluster parameter group exists in AWS.
+func (c *AWSClient) verifyRedshiftParameterGroup(ctx context.Context, groupName string) (string, bool, error) {
+	resp, err := c.RedshiftClient.DescribeClusterParameterGroups(ctx, &redshift.DescribeClusterParameterGroupsInput{
+		ParameterGroupName: aws.String(groupName),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ClusterParameterGroupNotFound") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to describe Redshift parameter group '%s': %w", groupName, err)
+	}
+	for _, group := range resp.ParameterGroups {
+		if strings.EqualFold(aws.ToString(group.ParameterGroupName), groupName) {
+			return aws.ToString(group.ParameterGroupName), true, nil
+		}
+	}
+	return "", false, nil // Parameter group not found
+}
+
+// onOff renders a boolean setting for messages.
+func onOff(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// describeACMCertificate is a cached DescribeCertificate shared by the certificate and validation checks.
+func (c *AWSClient) describeACMCertificate(ctx context.Context, certARN string) (*acm.DescribeCertificateOutput, error) {
+	return cachedCall(ctx, c, cacheKey("acm", "DescribeCertificate", certARN), func() (*acm.DescribeCertificateOutput, error) {
+		return c.ACMClient.DescribeCertificate(ctx, &acm.DescribeCertificateInput{
+			CertificateArn: aws.String(certARN),
+		})
+	})
+}
+
+// describeDynamoDBTable is a cached DescribeTable; every item of a table checks the table too.
+func (c *AWSClient) describeDynamoDBTable(ctx context.Context, tableName string) (*dynamodb.DescribeTableOutput, error) {
+	return cachedCall(ctx, c, cacheKey("dynamodb", "DescribeTable", tableName), func() (*dynamodb.DescribeTableOutput, error) {
+		return c.DynamoDBClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+			TableName: aws.String(tableName),
+		})
+	})
+}
+
+// getSNSTopicAttributes is a cached GetTopicAttributes shared by the topic and topic policy checks.
+func (c *AWSClient) getSNSTopicAttributes(ctx context.Context, topicARN string) (*sns.GetTopicAttributesOutput, error) {
+	return cachedCall(ctx, c, cacheKey("sns", "GetTopicAttributes", topicARN), func() (*sns.GetTopicAttributesOutput, error) {
+		return c.SNSClient.GetTopicAttributes(ctx, &sns.GetTopicAttributesInput{
+			TopicArn: aws.String(topicARN),
+		})
+	})
+}
+
+// describeKMSKey is a cached DescribeKey shared by the key, alias and key policy checks.
+func (c *AWSClient) describeKMSKey(ctx context.Context, keyID string) (*kms.DescribeKeyOutput, error) {
+	return cachedCall(ctx, c, cacheKey("kms", "DescribeKey", keyID), func() (*kms.DescribeKeyOutput, error) {
+		return c.KMSClient.DescribeKey(ctx, &kms.DescribeKeyInput{
+			KeyId: aws.String(keyID),
+		})
+	})
+}
+
+// describeEBSVolume is a cached DescribeVolumes shared by the volume and attachment checks. It returns
+// nil if the volume is not found.
+func (c *AWSClient) describeEBSVolume(ctx context.Context, volumeID string) (*ec2types.Volume, error) {
+	resp, err := cachedCall(ctx, c, cacheKey("ec2", "DescribeVolumes", volumeID), func() (*ec2.DescribeVolumesOutput, error) {
+		return c.EC2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+			VolumeIds: []string{volumeID},
+		})
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidVolume.NotFound") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe EBS volume '%s': %w", volumeID, err)
+	}
+	for i := range resp.Volumes {
+		if aws.ToString(resp.Volumes[i].VolumeId) == volumeID {
+			return &resp.Volumes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// describeVPCEndpoint is a cached DescribeVpcEndpoints shared by the endpoint and route table association
+// checks. It returns nil if the endpoint is not found.
+func (c *AWSClient) describeVPCEndpoint(ctx context.Context, endpointID string) (*ec2types.VpcEndpoint, error) {
+	resp, err := cachedCall(ctx, c, cacheKey("ec2", "DescribeVpcEndpoints", endpointID), func() (*ec2.DescribeVpcEndpointsOutput, error) {
+		return c.EC2Client.DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			VpcEndpointIds: []string{endpointID},
+		})
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidVpcEndpointId.NotFound") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe VPC endpoint '%s': %w", endpointID, err)
+	}
+	for i := range resp.VpcEndpoints {
+		if aws.ToString(resp.VpcEndpoints[i].VpcEndpointId) == endpointID {
+			return &resp.VpcEndpoints[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// describeNetworkACL is a cached DescribeNetworkAcls shared by the network ACL and rule checks, as
+// every rule of an ACL looks up the same ACL. It returns nil if the network ACL is not found.
+func (c *AWSClient) describeNetworkACL(ctx context.Context, networkACLID string) (*ec2types.NetworkAcl, error) {
+	resp, err := cachedCall(ctx, c, cacheKey("ec2", "DescribeNetworkAcls", networkACLID), func() (*ec2.DescribeNetworkAclsOutput, error) {
+		return c.EC2Client.DescribeNetworkAcls(ctx, &ec2.DescribeNetworkAclsInput{
+			NetworkAclIds: []string{networkACLID},
+		})
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidNetworkAclID.NotFound") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe network ACL '%s': %w", networkACLID, err)
+	}
+	for i := range resp.NetworkAcls {
+		if aws.ToString(resp.NetworkAcls[i].NetworkAclId) == networkACLID {
+			return &resp.NetworkAcls[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// guardDutyDetectorExists reports whether detectorID is one of the GuardDuty detectors of the account
+// in the region, listed once and shared by the detector, filter and member checks. GetDetector
+// rejects unknown detectors as a bad request, indistinguishable from other bad requests.
+func (c *AWSClient) guardDutyDetectorExists(ctx context.Context, detectorID string) (bool, error) {
+	detectorIDs, err := cachedCall(ctx, c, cacheKey("guardduty", "ListDetectors"), func() ([]string, error) {
+		var detectorIDs []string
+		paginator := guardduty.NewListDetectorsPaginator(c.GuardDutyClient, &guardduty.ListDetectorsInput{})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			detectorIDs = append(detectorIDs, page.DetectorIds...)
+		}
+		return detectorIDs, nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list GuardDuty detectors: %w", err)
+	}
+	return slices.Contains(detectorIDs, detectorID), nil
+}
+
+// describeCodeBuildProject is a cached BatchGetProjects of one project, by name or ARN, shared by the
+// project and webhook checks. It returns nil if the project is not found.
+func (c *AWSClient) describeCodeBuildProject(ctx context.Context, projectName string) (*codebuildtypes.Project, error) {
+	resp, err := cachedCall(ctx, c, cacheKey("codebuild", "BatchGetProjects", projectName), func() (*codebuild.BatchGetProjectsOutput, error) {
+		return c.CodeBuildClient.BatchGetProjects(ctx, &codebuild.BatchGetProjectsInput{
+			Names: []string{projectName},
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CodeBuild project '%s': %w", projectName, err)
+	}
+	for i := range resp.Projects {
+		if aws.ToString(resp.Projects[i].Name) == projectName || aws.ToString(resp.Projects[i].Arn) == projectName {
+			return &resp.Projects[i], nil
+		}
+	}
+	return nil, nil // Listed in ProjectsNotFound
+}
+
+// describeOpenSearchDomain is a cached DescribeDomain, or DescribeElasticsearchDomain for the legacy
+// aws_elasticsearch_ resource types, shared by the domain and domain policy checks. It returns nil if
+// the domain is not found.
+func (c *AWSClient) describeOpenSearchDomain(ctx context.Context, resourceType, domainName string) (*openSearchDomainStatus, error) {
+	if strings.HasPrefix(resourceType, "aws_elasticsearch_") {
+		resp, err := cachedCall(ctx, c, cacheKey("es", "DescribeElasticsearchDomain", domainName), func() (*elasticsearchservice.DescribeElasticsearchDomainOutput, error) {
+			return c.ElasticsearchClient.DescribeElasticsearchDomain(ctx, &elasticsearchservice.DescribeElasticsearchDomainInput{
+				DomainName: aws.String(domainName),
+			})
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "ResourceNotFoundException") {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to describe Elasticsearch domain '%s': %w", domainName, err)
+		}
+		if resp.DomainStatus == nil {
+			return nil, nil
+		}
+		return &openSearchDomainStatus{
+			kind:              "Elasticsearch domain",
+			name:              domainName,
+			arn:               aws.ToString(resp.DomainStatus.ARN),
+			accessPolicies:    aws.ToString(resp.DomainStatus.AccessPolicies),
+			deleted:           aws.ToBool(resp.DomainStatus.Deleted),
+			processing:        aws.ToBool(resp.DomainStatus.Processing),
+			upgradeProcessing: aws.ToBool(resp.DomainStatus.UpgradeProcessing),
+		}, nil
+	}
+	resp, err := cachedCall(ctx, c, cacheKey("opensearch", "DescribeDomain", domainName), func() (*opensearch.DescribeDomainOutput, error) {
+		return c.OpenSearchClient.DescribeDomain(ctx, &opensearch.DescribeDomainInput{
+			DomainName: aws.String(domainName),
+		})
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe OpenSearch domain '%s': %w", domainName, err)
+	}
+	if resp.DomainStatus == nil {
+		return nil, nil
+	}
+	return &openSearchDomainStatus{
+		kind:              "OpenSearch domain",
+		name:              domainName,
+		arn:               aws.ToString(resp.DomainStatus.ARN),
+		accessPolicies:    aws.ToString(resp.DomainStatus.AccessPolicies),
+		deleted:           aws.ToBool(resp.DomainStatus.Deleted),
+		processing:        aws.ToBool(resp.DomainStatus.Processing),
+		upgradeProcessing: aws.ToBool(resp.DomainStatus.UpgradeProcessing),
+	}, nil
+}
+
+// describeECSTaskDefinition is a cached DescribeTaskDefinition; family lookups repeat for every revision in state.
+func (c *AWSClient) describeECSTaskDefinition(ctx context.Context, taskDefinition string) (*ecs.DescribeTaskDefinitionOutput, error) {
+	return cachedCall(ctx, c, cacheKey("ecs", "DescribeTaskDefinition", taskDefinition), func() (*ecs.DescribeTaskDefinitionOutput, error) {
+		return c.ECSClient.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{
+			TaskDefinition: aws.String(taskDefinition),
+		})
+	})
+}
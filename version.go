@@ -1,12 +1,19 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"embed"
 	"encoding/json"
-	"errors"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 
 	gover "github.com/hashicorp/go-version"
 )
@@ -16,6 +23,16 @@ var versionBytes embed.FS
 
 var currentVersion string
 
+// buildCommit and buildDate are set at link time by the Makefile (-X main.buildCommit=...). Without
+// them the VCS information Go embeds in binaries built from a checkout is used.
+var buildCommit, buildDate string
+
+// supportedStateVersions are the state format versions Read accepts.
+var supportedStateVersions = []uint64{3, 4}
+
+// releasesURL is the GitHub API endpoint of the latest release, used by version --check-update.
+const releasesURL = "https://api.github.com/repos/andreimerlescu/reconcile-tfstate/releases/latest"
+
 func Version() string {
 	if len(currentVersion) == 0 {
 		versionBytes, err := versionBytes.ReadFile("VERSION")
@@ -28,77 +45,127 @@ func Version() string {
 	return currentVersion
 }
 
-// sniffJSONStateVersion parses []byte from the state file and returns the version as a uint64 or an error
-func sniffJSONStateVersion(src []byte) (uint64, error) {
-	type VersionSniff struct {
-		Version *uint64 `json:"version"`
+// buildVersionInfo collects the version, build and platform details of the running binary.
+func buildVersionInfo() versionInfo {
+	info := versionInfo{
+		StateVersions: supportedStateVersions,
+		Version:       Version(),
+		Commit:        buildCommit,
+		BuildDate:     buildDate,
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
 	}
-	var sniff VersionSniff
-	err := json.Unmarshal(src, &sniff)
-	if err != nil {
-		if errors.Is(err, &json.SyntaxError{}) {
-			var e *json.SyntaxError
-			if ok := errors.As(err, &e); ok {
-				return 0, fmt.Errorf("the state file could not be parsed as JSON: syntax error at byte offset %d", e.Offset)
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = cmp.Or(info.Commit, setting.Value)
+			case "vcs.time":
+				info.BuildDate = cmp.Or(info.BuildDate, setting.Value)
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
 			}
-			return 0, fmt.Errorf("the state file could not be parsed as JSON due to err: %w", err)
-		} else if errors.Is(err, &json.UnmarshalTypeError{}) {
-			var e *json.UnmarshalTypeError
-			if ok := errors.As(err, &e); ok {
-				return 0, fmt.Errorf("the version in the state file is %s. A positive whole number is required", e.Value)
-			}
-			return 0, fmt.Errorf("the state file could not be parsed as JSON: %w", err)
-		} else {
-			return 0, fmt.Errorf("the state file could not be parsed as JSON: %w", err)
 		}
 	}
+	info.Commit = cmp.Or(info.Commit, "unknown")
+	info.BuildDate = cmp.Or(info.BuildDate, "unknown")
+	return info
+}
 
-	if sniff.Version == nil {
-		return 0, errors.New("the state file does not have a \"version\" attribute, which is required to identify the format version")
+// latestRelease returns the tag of the latest GitHub release.
+func latestRelease(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releasesURL, nil)
+	if err != nil {
+		return "", err
 	}
-
-	return *sniff.Version, nil
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query GitHub releases: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to query GitHub releases: %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to decode the latest GitHub release: %w", err)
+	}
+	return release.TagName, nil
 }
 
-// sniffJSONStateTerraformVersion accepts []byte of state file and returns the version in a string format
-func sniffJSONStateTerraformVersion(src []byte) string {
-	type VersionSniff struct {
-		Version string `json:"terraform_version"`
+// runVersion prints the version and build details, and with --check-update whether a newer release exists.
+func runVersion(fs *flag.FlagSet, args []string) error {
+	checkUpdate := fs.Bool("check-update", false, "If true, check GitHub releases for a newer version.")
+	jsonOutput := fs.Bool("json", false, "If true, print the version details as JSON.")
+	parseFlags(fs, args)
+
+	info := buildVersionInfo()
+	var updateErr error
+	if *checkUpdate {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		info.LatestVersion, updateErr = latestRelease(ctx)
 	}
-	var sniff VersionSniff
 
-	err := json.Unmarshal(src, &sniff)
-	if err != nil {
-		return ""
+	if *jsonOutput {
+		data, err := json.MarshalIndent(info, "", "\t")
+		if err != nil {
+			return fmt.Errorf("failed to marshal version details: %w", err)
+		}
+		fmt.Println(string(data))
+		return updateErr
+	}
+	commit := info.Commit
+	if info.Modified {
+		commit += " (modified)"
+	}
+	stateVersions := make([]string, len(info.StateVersions))
+	for i, v := range info.StateVersions {
+		stateVersions[i] = strconv.FormatUint(v, 10)
 	}
+	fmt.Printf("%s %s\n", programName, info.Version)
+	fmt.Printf("Commit:         %s\n", commit)
+	fmt.Printf("Build date:     %s\n", info.BuildDate)
+	fmt.Printf("Go version:     %s\n", info.GoVersion)
+	fmt.Printf("Platform:       %s\n", info.Platform)
+	fmt.Printf("State versions: %s\n", strings.Join(stateVersions, ", "))
+	if updateErr != nil {
+		return updateErr
+	}
+	if info.LatestVersion != "" {
+		fmt.Println(updateMessage(info.Version, info.LatestVersion))
+	}
+	return nil
+}
 
-	// Attempt to parse the string as a version so we won't report garbage
-	// as a version number.
-	_, err = gover.NewVersion(sniff.Version)
+// updateMessage compares the running version with the latest release.
+func updateMessage(current, latest string) string {
+	currentVersion, err := gover.NewVersion(current)
 	if err != nil {
-		return ""
+		return fmt.Sprintf("Latest release: %s (cannot compare with %s)", latest, current)
 	}
-
-	return sniff.Version
+	latestVersion, err := gover.NewVersion(latest)
+	if err != nil {
+		return fmt.Sprintf("Latest release: %s (cannot compare with %s)", latest, current)
+	}
+	if latestVersion.GreaterThan(currentVersion) {
+		return fmt.Sprintf("A newer version is available: %s. Update with: go install github.com/andreimerlescu/reconcile-tfstate@%s", latest, latest)
+	}
+	return fmt.Sprintf("You are running the latest release (%s).", latest)
 }
 
-// looksLikeVersion0 determines if the version in the []byte terraform state file is of type version 0
-func looksLikeVersion0(src []byte) bool {
-	// Version 0 was a custom binary format, which would not begin with '{'
-	// or '[' characters.
-	for _, b := range src {
-		switch b {
-		case ' ', '\t', '\n', '\r':
-			continue
-		case '{', '[':
-			return false
-		default:
-			// Any other non-whitespace character at the start means it's
-			// probably not JSON, and so it must be a version 0 file.
-			return true
-		}
+// validTerraformVersion returns v if it parses as a Terraform version, so we won't report garbage
+// as a version number, and an empty string otherwise.
+func validTerraformVersion(v string) string {
+	if _, err := gover.NewVersion(v); err != nil {
+		return ""
 	}
-	return false // Empty or all whitespace, so not version 0
+	return v
 }
 
 func (sv StateVersionV4) MarshalJSON() ([]byte, error) {
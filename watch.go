@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// watchQueueRetryDelay is how long to wait before polling --watch-queue-url again after an error.
+const watchQueueRetryDelay = 30 * time.Second
+
+// runWatch reconciles the state every --interval, and as soon as --watch-queue-url reports a change
+// to it, until the process is interrupted. A failed run is logged and retried on the next trigger.
+func runWatch(config Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := &watcher{}
+	if config.WatchListen != "" {
+		server := &http.Server{Addr: config.WatchListen, Handler: w.handler(), ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Failed to serve --watch-listen %s: %v", config.WatchListen, err)
+			}
+		}()
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+		log.Printf("Serving the last result on %s (/results, /metrics, /healthz)", config.WatchListen)
+	}
+
+	changes := make(chan string, 1)
+	if config.WatchQueueURL != "" {
+		clients, err := NewAWSClient(ctx, config)
+		if err != nil {
+			return fmt.Errorf("failed to initialize AWS clients for --watch-queue-url: %w", err)
+		}
+		go watchQueue(ctx, clients.StateSQSClient, config.WatchQueueURL, config.S3Bucket, config.S3Key, changes)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	trigger := "startup"
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Stopping --watch.")
+			return nil
+		case <-timer.C:
+		case event := <-changes:
+			trigger = event
+		}
+		w.run(config, trigger)
+		timer.Reset(config.WatchInterval)
+		trigger = "schedule"
+		log.Printf("Next reconciliation in %s, at %s", config.WatchInterval, time.Now().Add(config.WatchInterval).Format(time.RFC3339))
+	}
+}
+
+// run performs one reconciliation and records its outcome. A panic is recovered and reported as the
+// run's error so the watch keeps going.
+func (w *watcher) run(config Config, trigger string) {
+	started := time.Now()
+	prepareRun(config) // Every run gets its own timestamp and so its own backup directory
+	log.Printf("Starting reconciliation (trigger: %s)", trigger)
+
+	err := runApplicationRecovered(config)
+	if err != nil {
+		log.Printf("ERROR: Reconciliation failed: %v", err)
+	}
+
+	snapshot := newRunSnapshot(globalResults, started, trigger, err)
+	w.mu.Lock()
+	snapshot.Runs = w.last.Runs + 1
+	w.last = snapshot
+	w.mu.Unlock()
+}
+
+// runApplicationRecovered runs runApplication in a long-running process, returning a panic as an
+// error instead of exiting like the panic handler of a single run does.
+func runApplicationRecovered(config Config) (err error) {
+	defer globalTemps.cleanup()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("application crashed: %v", r)
+		}
+	}()
+	return runApplication(config)
+}
+
+// newRunSnapshot summarizes the results of a run that started at started.
+func newRunSnapshot(results *categorizedResults, started time.Time, trigger string, err error) watchSnapshot {
+	snapshot := watchSnapshot{
+		Results: JSONResults{
+			InfoResults:            convertResourceStatusToJSONItem(results.InfoResults),
+			OkResults:              convertResourceStatusToJSONItem(results.OkResults),
+			PotentialImportResults: convertResourceStatusToJSONItem(results.PotentialImportResults),
+			RegionMismatchResults:  convertResourceStatusToJSONItem(results.RegionMismatchResults),
+			WarningResults:         convertResourceStatusToJSONItem(results.WarningResults),
+			ErrorResults:           convertResourceStatusToJSONItem(results.ErrorResults),
+			DangerousResults:       convertResourceStatusToJSONItem(results.DangerousResults),
+			StaleResults:           convertResourceStatusToJSONItem(results.StaleResults),
+			PendingDeletionResults: convertResourceStatusToJSONItem(results.PendingDeletionResults),
+			SkippedResults:         convertResourceStatusToJSONItem(results.SkippedResults),
+		},
+		Counts: map[string]int{
+			"INFO":             len(results.InfoResults),
+			"OK":               len(results.OkResults),
+			"POTENTIAL_IMPORT": len(results.PotentialImportResults),
+			"REGION_MISMATCH":  len(results.RegionMismatchResults),
+			"WARNING":          len(results.WarningResults),
+			"ERROR":            len(results.ErrorResults),
+			"DANGEROUS":        len(results.DangerousResults),
+			"STALE":            len(results.StaleResults),
+			"PENDING_DELETION": len(results.PendingDeletionResults),
+			"SKIPPED":          len(results.SkippedResults),
+		},
+		Commands:        results.RunCommands,
+		StartedAt:       started,
+		FinishedAt:      time.Now(),
+		Trigger:         trigger,
+		DurationSeconds: time.Since(started).Seconds(),
+	}
+	if err != nil {
+		snapshot.Error = err.Error()
+	}
+	return snapshot
+}
+
+// snapshot returns the outcome of the last run, and false before the first run finished.
+func (w *watcher) snapshot() (watchSnapshot, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.last, w.last.Runs > 0
+}
+
+// handler serves the last result as JSON on /results, as Prometheus metrics on /metrics, and the
+// liveness of the process on /healthz.
+func (w *watcher) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(rw, "ok")
+	})
+	mux.HandleFunc("/results", func(rw http.ResponseWriter, r *http.Request) {
+		snapshot, ok := w.snapshot()
+		if !ok {
+			http.Error(rw, "no reconciliation has finished yet", http.StatusServiceUnavailable)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(rw)
+		encoder.SetIndent("", "\t")
+		_ = encoder.Encode(snapshot)
+	})
+	mux.HandleFunc("/metrics", func(rw http.ResponseWriter, r *http.Request) {
+		snapshot, _ := w.snapshot()
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = fmt.Fprint(rw, renderWatchMetrics(snapshot))
+	})
+	return mux
+}
+
+// renderWatchMetrics renders a snapshot in the Prometheus text exposition format.
+func renderWatchMetrics(snapshot watchSnapshot) string {
+	var builder strings.Builder
+	builder.WriteString("# HELP reconcile_tfstate_resources Resource instances per result category in the last reconciliation.\n")
+	builder.WriteString("# TYPE reconcile_tfstate_resources gauge\n")
+	for _, category := range []string{"INFO", "OK", "POTENTIAL_IMPORT", "REGION_MISMATCH", "WARNING", "ERROR", "DANGEROUS", "STALE", "PENDING_DELETION", "SKIPPED"} {
+		builder.WriteString(fmt.Sprintf("reconcile_tfstate_resources{category=%q} %d\n", category, snapshot.Counts[category]))
+	}
+	builder.WriteString("# HELP reconcile_tfstate_runs_total Reconciliations finished since the process started.\n")
+	builder.WriteString("# TYPE reconcile_tfstate_runs_total counter\n")
+	builder.WriteString(fmt.Sprintf("reconcile_tfstate_runs_total %d\n", snapshot.Runs))
+	if snapshot.Runs == 0 {
+		return builder.String()
+	}
+	success := 1
+	if snapshot.Error != "" {
+		success = 0
+	}
+	builder.WriteString("# HELP reconcile_tfstate_last_run_success Whether the last reconciliation finished without an application error.\n")
+	builder.WriteString("# TYPE reconcile_tfstate_last_run_success gauge\n")
+	builder.WriteString(fmt.Sprintf("reconcile_tfstate_last_run_success %d\n", success))
+	builder.WriteString("# HELP reconcile_tfstate_last_run_timestamp_seconds When the last reconciliation finished.\n")
+	builder.WriteString("# TYPE reconcile_tfstate_last_run_timestamp_seconds gauge\n")
+	builder.WriteString(fmt.Sprintf("reconcile_tfstate_last_run_timestamp_seconds %d\n", snapshot.FinishedAt.Unix()))
+	builder.WriteString("# HELP reconcile_tfstate_last_run_duration_seconds How long the last reconciliation took.\n")
+	builder.WriteString("# TYPE reconcile_tfstate_last_run_duration_seconds gauge\n")
+	builder.WriteString(fmt.Sprintf("reconcile_tfstate_last_run_duration_seconds %.3f\n", snapshot.DurationSeconds))
+	return builder.String()
+}
+
+// watchQueue long-polls the SQS queue for S3 event notifications and sends a trigger on changes
+// when one reports a write to or deletion of the state object. Every message is deleted once read.
+func watchQueue(ctx context.Context, client *sqs.Client, queueURL, bucket, key string, changes chan<- string) {
+	for ctx.Err() == nil {
+		out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(queueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     20,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("WARNING: Failed to receive from %s, retrying in %s: %v", queueURL, watchQueueRetryDelay, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchQueueRetryDelay):
+			}
+			continue
+		}
+		for _, message := range out.Messages {
+			if event, ok := stateChangeEvent(aws.ToString(message.Body), bucket, key); ok {
+				select {
+				case changes <- event:
+				default: // A reconciliation is already pending
+				}
+			}
+			if _, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: aws.String(queueURL), ReceiptHandle: message.ReceiptHandle}); err != nil && ctx.Err() == nil {
+				log.Printf("WARNING: Failed to delete message from %s: %v", queueURL, err)
+			}
+		}
+	}
+}
+
+// stateChangeEvent returns the name of the first event in an S3 notification body that concerns
+// s3://bucket/key, e.g. "ObjectCreated:Put".
+func stateChangeEvent(body, bucket, key string) (string, bool) {
+	var notification s3EventNotification
+	if err := json.Unmarshal([]byte(body), &notification); err != nil {
+		return "", false
+	}
+	if notification.Message != "" {
+		return stateChangeEvent(notification.Message, bucket, key)
+	}
+	for _, record := range notification.Records {
+		// Object keys are URL-encoded in notifications, with spaces as '+'.
+		objectKey, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			objectKey = record.S3.Object.Key
+		}
+		if record.S3.Bucket.Name == bucket && objectKey == key {
+			return record.EventName, true
+		}
+	}
+	return "", false
+}
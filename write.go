@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Write writes state to w as a version 4 state file with a stable layout: two-space indentation, keys in
+// the order Terraform writes them, map keys sorted, resources ordered by module, mode, type and name as Terraform orders them,
+// their instances by index key and deposed key, and check results by address. Keys this program does not
+// model, such as the resource identity of Terraform 1.12, are written back as they were read. Writing the
+// same state twice produces the same bytes, and reading the output back yields the same state. The serial and lineage
+// are written as they are; use writeStateFile to write a modified state in place of the one it was read from.
+func Write(state *TFStateFile, w io.Writer) error {
+	if state == nil {
+		return ErrNoState
+	}
+	if state.UpgradedFrom != 0 {
+		return fmt.Errorf("the state was upgraded in memory from format version %d and is read-only. Upgrade it with Terraform first", state.UpgradedFrom)
+	}
+
+	sV4 := StateFileV4{
+		RootOutputs:      state.RootOutputs,
+		Resources:        sortedResources(state.Resources),
+		CheckResults:     sortedCheckResults(state.CheckResults),
+		Serial:           state.Serial,
+		TerraformVersion: state.TerraformVersion,
+		Lineage:          state.Lineage,
+		Unknown:          state.Unknown,
+	}
+	if sV4.RootOutputs == nil {
+		sV4.RootOutputs = make(map[string]OutputStateV4)
+	}
+	if sV4.Resources == nil {
+		sV4.Resources = []ResourceStateV4{}
+	}
+
+	src, err := json.MarshalIndent(sV4, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	src = append(src, '\n')
+	if _, err := w.Write(src); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// writeStateFile writes state to path as the next snapshot of previous, the state it was read from. The
+// lineage of previous is kept and the serial is incremented past that of previous when the content changed,
+// as Terraform does, so an older snapshot written over previous is not refused as stale; a state of another
+// lineage is refused. With a nil previous the state is written as a new snapshot and
+// must already have a lineage. The file is replaced atomically, so readers never see a partial state.
+func writeStateFile(path string, state, previous *TFStateFile) (err error) {
+	if state == nil {
+		return ErrNoState
+	}
+	if previous != nil {
+		if state.Lineage == "" {
+			state.Lineage = previous.Lineage
+		}
+		if state.Lineage != previous.Lineage {
+			return fmt.Errorf("refusing to write state of lineage %q over state of lineage %q", state.Lineage, previous.Lineage)
+		}
+		serial, err := nextSerial(state, previous)
+		if err != nil {
+			return err
+		}
+		state.Serial = serial
+	}
+	if state.Lineage == "" {
+		return errors.New("refusing to write a state without a lineage")
+	}
+
+	var buf bytes.Buffer
+	if err := Write(state, &buf); err != nil {
+		return err
+	}
+	return replaceStateFile(path, buf.Bytes())
+}
+
+// replaceStateFile writes src to path through a temporary file renamed over it, keeping the permissions of
+// the file it replaces, so readers never see a partial state.
+func replaceStateFile(path string, src []byte) (err error) {
+	tmpPath := path + ".tmp"
+	done := globalTemps.trackPartial(tmpPath)
+	defer func() { done(err) }()
+	mode := os.FileMode(0o644)
+	if info, statErr := os.Stat(path); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+	if err = os.WriteFile(tmpPath, src, mode); err != nil {
+		return fmt.Errorf("failed to write state file '%s': %w", tmpPath, err)
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace state file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// nextSerial returns the serial of state written as the next snapshot of previous: incremented past that of
+// previous when the content changed, and never below it. States upgraded from version 3 are taken as changed.
+func nextSerial(state, previous *TFStateFile) (uint64, error) {
+	changed := true
+	if state.UpgradedFrom == 0 {
+		var err error
+		if changed, err = stateContentChanged(state, previous); err != nil {
+			return 0, err
+		}
+	}
+	switch {
+	case changed && state.Serial <= previous.Serial:
+		return previous.Serial + 1, nil
+	case state.Serial < previous.Serial:
+		return previous.Serial, nil
+	}
+	return state.Serial, nil
+}
+
+// setStateSerial returns the state document src with its top-level serial replaced by serial and every
+// other byte left as it is.
+func setStateSerial(src []byte, serial uint64) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		keyToken, err := dec.Token()
+		if err != nil {
+			return nil, stateSyntaxError(err)
+		}
+		keyEnd := dec.InputOffset()
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, stateSyntaxError(err)
+		}
+		if keyToken != "serial" {
+			continue
+		}
+		end := int(dec.InputOffset())
+		start := end - len(value)
+		if start < int(keyEnd) || !bytes.Equal(src[start:end], value) {
+			return nil, errors.New("the serial of the state file could not be located")
+		}
+		edited := make([]byte, 0, len(src)+20)
+		edited = append(edited, src[:start]...)
+		edited = strconv.AppendUint(edited, serial, 10)
+		return append(edited, src[end:]...), nil
+	}
+	return nil, errors.New("the state file does not have a \"serial\" attribute")
+}
+
+// stateContentChanged reports whether state differs from previous in anything but the serial.
+func stateContentChanged(state, previous *TFStateFile) (bool, error) {
+	var a, b bytes.Buffer
+	next, prev := *state, *previous
+	next.Serial, prev.Serial = 0, 0
+	prev.UpgradedFrom = 0
+	if err := Write(&next, &a); err != nil {
+		return false, err
+	}
+	if err := Write(&prev, &b); err != nil {
+		return false, err
+	}
+	return !bytes.Equal(a.Bytes(), b.Bytes()), nil
+}
+
+// MarshalJSON encodes the state with its keys in the order Terraform writes them.
+func (s StateFileV4) MarshalJSON() ([]byte, error) {
+	return marshalOrdered(struct {
+		Version          StateVersionV4           `json:"version"`
+		TerraformVersion string                   `json:"terraform_version"`
+		Serial           uint64                   `json:"serial"`
+		Lineage          string                   `json:"lineage"`
+		RootOutputs      map[string]OutputStateV4 `json:"outputs"`
+		Resources        []ResourceStateV4        `json:"resources"`
+		CheckResults     []CheckResultsV4         `json:"check_results"`
+	}{s.Version, s.TerraformVersion, s.Serial, s.Lineage, s.RootOutputs, s.Resources, s.CheckResults}, s.Unknown, stateKeysV4)
+}
+
+// MarshalJSON encodes the resource with its keys in the order Terraform writes them.
+func (r ResourceStateV4) MarshalJSON() ([]byte, error) {
+	return marshalOrdered(struct {
+		Module         string                  `json:"module,omitempty"`
+		Mode           string                  `json:"mode"`
+		Type           string                  `json:"type"`
+		Name           string                  `json:"name"`
+		EachMode       string                  `json:"each,omitempty"`
+		ProviderConfig string                  `json:"provider"`
+		Instances      []InstanceObjectStateV4 `json:"instances"`
+	}{r.Module, r.Mode, r.Type, r.Name, r.EachMode, r.ProviderConfig, r.Instances}, r.Unknown, resourceKeysV4)
+}
+
+// MarshalJSON encodes the instance with its keys in the order Terraform writes them.
+func (i InstanceObjectStateV4) MarshalJSON() ([]byte, error) {
+	return marshalOrdered(struct {
+		IndexKey                interface{}       `json:"index_key,omitempty"`
+		Status                  string            `json:"status,omitempty"`
+		Deposed                 string            `json:"deposed,omitempty"`
+		SchemaVersion           uint64            `json:"schema_version"`
+		AttributesRaw           json.RawMessage   `json:"attributes,omitempty"`
+		AttributesFlat          map[string]string `json:"attributes_flat,omitempty"`
+		AttributeSensitivePaths json.RawMessage   `json:"sensitive_attributes,omitempty"`
+		PrivateRaw              []byte            `json:"private,omitempty"`
+		Dependencies            []string          `json:"dependencies,omitempty"`
+		CreateBeforeDestroy     bool              `json:"create_before_destroy,omitempty"`
+	}{i.IndexKey, i.Status, i.Deposed, i.SchemaVersion, i.AttributesRaw, i.AttributesFlat, i.AttributeSensitivePaths, i.PrivateRaw, i.Dependencies, i.CreateBeforeDestroy}, i.Unknown, instanceKeysV4)
+}
+
+// Keys of the state, resource and instance objects in the order Terraform writes them, including keys
+// this program does not model.
+var (
+	stateKeysV4    = []string{"version", "terraform_version", "serial", "lineage", "outputs", "resources", "check_results"}
+	resourceKeysV4 = []string{"module", "mode", "type", "name", "each", "provider", "instances"}
+	instanceKeysV4 = []string{
+		"index_key", "status", "deposed", "schema_version", "attributes", "attributes_flat", "sensitive_attributes",
+		"identity_schema_version", "identity", "private", "dependencies", "create_before_destroy",
+	}
+)
+
+// marshalOrdered encodes known and adds the keys of unknown that known does not have. Keys listed in
+// order are written in that order and any others after them, sorted.
+func marshalOrdered(known interface{}, unknown map[string]json.RawMessage, order []string) ([]byte, error) {
+	src, err := json.Marshal(known)
+	if err != nil || len(unknown) == 0 {
+		return src, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(src, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range unknown {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for _, key := range order {
+		if _, ok := fields[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range fields {
+		if !slices.Contains(order, key) {
+			rest = append(rest, key)
+		}
+	}
+	slices.Sort(rest)
+	keys = append(keys, rest...)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for n, key := range keys {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(fields[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalJSON encodes the check results with their keys in the order Terraform writes them.
+func (c CheckResultsV4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ObjectKind string                 `json:"object_kind"`
+		ConfigAddr string                 `json:"config_addr"`
+		Status     string                 `json:"status"`
+		Objects    []CheckResultsObjectV4 `json:"objects"`
+	}{c.ObjectKind, c.ConfigAddr, c.Status, c.Objects})
+}
+
+// MarshalJSON encodes the check object with its keys in the order Terraform writes them.
+func (o CheckResultsObjectV4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ObjectAddr      string   `json:"object_addr"`
+		Status          string   `json:"status"`
+		FailureMessages []string `json:"failure_messages,omitempty"`
+	}{o.ObjectAddr, o.Status, o.FailureMessages})
+}
+
+// sortedResources returns a copy of resources, and of their instances, in the order Terraform writes them.
+func sortedResources(resources []ResourceStateV4) []ResourceStateV4 {
+	if resources == nil {
+		return nil
+	}
+	sorted := slices.Clone(resources)
+	for i := range sorted {
+		sorted[i].Instances = slices.Clone(sorted[i].Instances)
+		slices.SortStableFunc(sorted[i].Instances, func(a, b InstanceObjectStateV4) int {
+			if c := compareIndexKeys(a.IndexKey, b.IndexKey); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Deposed, b.Deposed)
+		})
+	}
+	slices.SortStableFunc(sorted, func(a, b ResourceStateV4) int {
+		if c := strings.Compare(a.Module, b.Module); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Mode, b.Mode); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return sorted
+}
+
+// compareIndexKeys orders instances without an index key first, then count indexes numerically, then
+// for_each keys as strings. Decoded states hold count indexes as float64.
+func compareIndexKeys(a, b interface{}) int {
+	rank := func(key interface{}) int {
+		switch key.(type) {
+		case nil:
+			return 0
+		case float64:
+			return 1
+		default:
+			return 2
+		}
+	}
+	if c := rank(a) - rank(b); c != 0 {
+		return c
+	}
+	switch x := a.(type) {
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case nil:
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+	return 0
+}
+
+// sortedCheckResults returns a copy of results, and of their objects, ordered by address.
+func sortedCheckResults(results []CheckResultsV4) []CheckResultsV4 {
+	if results == nil {
+		return nil
+	}
+	sorted := slices.Clone(results)
+	for i := range sorted {
+		sorted[i].Objects = slices.Clone(sorted[i].Objects)
+		slices.SortStableFunc(sorted[i].Objects, func(a, b CheckResultsObjectV4) int {
+			return strings.Compare(a.ObjectAddr, b.ObjectAddr)
+		})
+	}
+	slices.SortStableFunc(sorted, func(a, b CheckResultsV4) int {
+		if c := strings.Compare(a.ObjectKind, b.ObjectKind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ConfigAddr, b.ConfigAddr)
+	})
+	return sorted
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testStateV4 = `{
+  "version": 4,
+  "terraform_version": "1.9.5",
+  "serial": 7,
+  "lineage": "3f1c9a52-8d4e-4b6a-9c1e-2b7a5d0e6f11",
+  "outputs": {
+    "bucket": {
+      "value": "logs",
+      "type": "string"
+    },
+    "token": {
+      "value": "secret",
+      "type": "string",
+      "sensitive": true
+    }
+  },
+  "resources": [
+    {
+      "mode": "data",
+      "type": "aws_caller_identity",
+      "name": "current",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "schema_version": 0,
+          "attributes": {
+            "account_id": "123456789012"
+          }
+        }
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "schema_version": 0,
+          "attributes": {
+            "bucket": "logs",
+            "id": "logs",
+            "tags": {
+              "env": "dev",
+              "team": "platform"
+            }
+          },
+          "sensitive_attributes": [],
+          "private": "eyJzY2hlbWFfdmVyc2lvbiI6IjAifQ==",
+          "create_before_destroy": true
+        }
+      ]
+    },
+    {
+      "module": "module.network[\"eu.west\"]",
+      "mode": "managed",
+      "type": "aws_subnet",
+      "name": "private",
+      "each": "list",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "index_key": 0,
+          "schema_version": 1,
+          "attributes": {
+            "id": "subnet-0a"
+          },
+          "dependencies": [
+            "module.network.aws_vpc.main"
+          ]
+        },
+        {
+          "index_key": 1,
+          "status": "tainted",
+          "schema_version": 1,
+          "attributes": {
+            "id": "subnet-0b"
+          }
+        },
+        {
+          "index_key": 1,
+          "deposed": "00000001",
+          "schema_version": 1,
+          "attributes": {
+            "id": "subnet-0c"
+          }
+        }
+      ]
+    }
+  ],
+  "check_results": [
+    {
+      "object_kind": "resource",
+      "config_addr": "aws_s3_bucket.logs",
+      "status": "fail",
+      "objects": [
+        {
+          "object_addr": "aws_s3_bucket.logs",
+          "status": "fail",
+          "failure_messages": [
+            "versioning must be enabled"
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+
+// testStateV4Identity is a Terraform 1.12 state with keys this program does not model, written in the
+// order Terraform writes them.
+const testStateV4Identity = `{
+  "version": 4,
+  "terraform_version": "1.12.1",
+  "serial": 3,
+  "lineage": "9b2e4c1d-0f3a-4e7b-8c6d-5a1f2e3b4c5d",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "schema_version": 0,
+          "attributes": {
+            "id": "logs"
+          },
+          "sensitive_attributes": [],
+          "identity_schema_version": 0,
+          "identity": {
+            "account_id": "123456789012",
+            "bucket": "logs",
+            "region": "eu-west-1"
+          },
+          "private": "bnVsbA=="
+        }
+      ],
+      "x_resource_note": "kept"
+    }
+  ],
+  "check_results": null,
+  "x_state_note": "kept"
+}
+`
+
+// writeState writes state and fails the test on error.
+func writeState(t *testing.T, state *TFStateFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(state, &buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// readTestState reads src and fails the test on error.
+func readTestState(t *testing.T, src string) *TFStateFile {
+	t.Helper()
+	state, err := Read(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return state
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"full", testStateV4},
+		{"identity", testStateV4Identity},
+		{"empty", `{"version": 4, "terraform_version": "1.5.7", "serial": 1, "lineage": "a", "outputs": {}, "resources": [], "check_results": null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written := writeState(t, readTestState(t, tt.src))
+
+			var want, got interface{}
+			if err := json.Unmarshal([]byte(tt.src), &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(written, &got); err != nil {
+				t.Fatalf("Write produced invalid JSON: %v\n%s", err, written)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("Read→Write changed the state:\nwant %s\ngot  %s", tt.src, written)
+			}
+
+			if again := writeState(t, readTestState(t, string(written))); !bytes.Equal(written, again) {
+				t.Errorf("Read→Write→Read→Write is not stable:\nfirst  %s\nsecond %s", written, again)
+			}
+		})
+	}
+}
+
+func TestWriteKeyOrder(t *testing.T) {
+	for _, src := range []string{testStateV4, testStateV4Identity} {
+		written := writeState(t, readTestState(t, src))
+		if !bytes.Equal(written, []byte(src)) {
+			t.Errorf("Write did not reproduce the layout Terraform writes:\nwant %s\ngot  %s", src, written)
+		}
+	}
+}
+
+func TestWriteSortsResources(t *testing.T) {
+	state := readTestState(t, testStateV4)
+	for i, j := 0, len(state.Resources)-1; i < j; i, j = i+1, j-1 {
+		state.Resources[i], state.Resources[j] = state.Resources[j], state.Resources[i]
+	}
+	subnets := state.Resources[0].Instances
+	subnets[0], subnets[2] = subnets[2], subnets[0]
+
+	if written := writeState(t, state); !bytes.Equal(written, []byte(testStateV4)) {
+		t.Errorf("Write did not sort resources and instances:\nwant %s\ngot  %s", testStateV4, written)
+	}
+}
+
+func TestWriteRefusesUpgradedState(t *testing.T) {
+	state := readTestState(t, testStateV4)
+	state.UpgradedFrom = 3
+	if err := Write(state, &bytes.Buffer{}); err == nil {
+		t.Error("Write of a state upgraded from version 3 succeeded, want an error")
+	}
+}
+
+func TestWriteStateFileSerial(t *testing.T) {
+	tests := []struct {
+		name       string
+		serial     uint64
+		change     bool
+		wantSerial uint64
+	}{
+		{"unchanged keeps the serial", 7, false, 7},
+		{"changed bumps the serial", 7, true, 8},
+		{"changed with a higher serial keeps it", 12, true, 12},
+		{"older snapshot unchanged takes the serial", 3, false, 7},
+		{"older snapshot changed bumps past the serial", 3, true, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := readTestState(t, testStateV4)
+			state := readTestState(t, testStateV4)
+			state.Serial = tt.serial
+			if tt.change {
+				state.Resources = state.Resources[1:]
+			}
+
+			path := filepath.Join(t.TempDir(), "terraform.tfstate")
+			if err := writeStateFile(path, state, previous); err != nil {
+				t.Fatalf("writeStateFile: %v", err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			written, err := Read(f)
+			if err != nil {
+				t.Fatalf("Read of the written state: %v", err)
+			}
+			if written.Serial != tt.wantSerial {
+				t.Errorf("serial = %d, want %d", written.Serial, tt.wantSerial)
+			}
+			if written.Lineage != previous.Lineage {
+				t.Errorf("lineage = %q, want %q", written.Lineage, previous.Lineage)
+			}
+		})
+	}
+}
+
+func TestWriteStateFileLineage(t *testing.T) {
+	dir := t.TempDir()
+	previous := readTestState(t, testStateV4)
+
+	other := readTestState(t, testStateV4)
+	other.Lineage = "another-lineage"
+	path := filepath.Join(dir, "other.tfstate")
+	if err := writeStateFile(path, other, previous); err == nil || !strings.Contains(err.Error(), "lineage") {
+		t.Errorf("writeStateFile over another lineage: err = %v, want a lineage error", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("writeStateFile over another lineage wrote %s", path)
+	}
+
+	inherited := readTestState(t, testStateV4)
+	inherited.Lineage = ""
+	path = filepath.Join(dir, "inherited.tfstate")
+	if err := writeStateFile(path, inherited, previous); err != nil {
+		t.Fatalf("writeStateFile without a lineage: %v", err)
+	}
+	if inherited.Lineage != previous.Lineage {
+		t.Errorf("lineage = %q, want the lineage of the previous state %q", inherited.Lineage, previous.Lineage)
+	}
+
+	orphan := readTestState(t, testStateV4)
+	orphan.Lineage = ""
+	if err := writeStateFile(filepath.Join(dir, "orphan.tfstate"), orphan, nil); err == nil {
+		t.Error("writeStateFile of a new snapshot without a lineage succeeded, want an error")
+	}
+}
+
+func TestSetStateSerial(t *testing.T) {
+	src := []byte("{\n  \"version\": 4,\n  \"serial\" :  7 ,\n  \"lineage\": \"a\",\n  \"outputs\": {\"serial\": {\"value\": 1, \"type\": \"number\"}}\n}\n")
+	got, err := setStateSerial(src, 12)
+	if err != nil {
+		t.Fatalf("setStateSerial: %v", err)
+	}
+	want := strings.Replace(string(src), "  7 ,", "  12 ,", 1)
+	if string(got) != want {
+		t.Errorf("setStateSerial changed more than the serial:\nwant %s\ngot  %s", want, got)
+	}
+
+	if _, err := setStateSerial([]byte(`{"version": 4, "lineage": "a"}`), 1); err == nil {
+		t.Error("setStateSerial of a state without a serial succeeded, want an error")
+	}
+}